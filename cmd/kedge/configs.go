@@ -11,6 +11,8 @@ import (
 	http_bp "github.com/improbable-eng/kedge/pkg/kedge/http/backendpool"
 	http_adhoc "github.com/improbable-eng/kedge/pkg/kedge/http/director/adhoc"
 	http_router "github.com/improbable-eng/kedge/pkg/kedge/http/director/router"
+	tcp_bp "github.com/improbable-eng/kedge/pkg/kedge/tcp/backendpool"
+	tcp_router "github.com/improbable-eng/kedge/pkg/kedge/tcp/director/router"
 	"github.com/improbable-eng/kedge/pkg/sharedflags"
 	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	"github.com/improbable-eng/kedge/protogen/kedge/config/common"
@@ -28,7 +30,7 @@ var (
 		},
 		"Contents of the Kedge Director configuration. Dynamically settable or read from file").
 		WithFileFlag("default_director.json").
-		WithValidator(generalValidator).
+		WithValidator(directorConfigValidator).
 		WithNotifier(directorConfigReload)
 
 	flagConfigBackendpool = protoflagz.DynProto3(sharedflags.Set,
@@ -45,6 +47,8 @@ var (
 	httpBackendPool = http_bp.NewDynamic(logrus.StandardLogger())
 	grpcRouter      = grpc_router.NewDynamic(logrus.StandardLogger())
 	httpRouter      = http_router.NewDynamic()
+	tcpBackendPool  = tcp_bp.NewDynamic(logrus.StandardLogger())
+	tcpRouter       = tcp_router.NewDynamic()
 	httpAddresser   = common.NewDynamic(http_adhoc.NewStaticAddresser([]*kedge_config_common.Adhoc{}))
 	grpcAddresser   = common.NewDynamic(grpc_adhoc.NewStaticAddresser([]*kedge_config_common.Adhoc{}))
 
@@ -60,6 +64,20 @@ func generalValidator(msg proto.Message) error {
 	return nil
 }
 
+func directorConfigValidator(msg proto.Message) error {
+	if err := generalValidator(msg); err != nil {
+		return err
+	}
+	cnf := msg.(*pb_config.DirectorConfig)
+	if err := grpc_router.ValidateRoutes(cnf.GetGrpc().GetRoutes()); err != nil {
+		return err
+	}
+	if err := http_router.ValidateRoutes(cnf.GetHttp().GetRoutes()); err != nil {
+		return err
+	}
+	return tcp_router.ValidateRoutes(cnf.GetTcp().GetRoutes())
+}
+
 func directorConfigReload(_ proto.Message, newValue proto.Message) {
 	newConfig := newValue.(*pb_config.DirectorConfig)
 
@@ -68,6 +86,7 @@ func directorConfigReload(_ proto.Message, newValue proto.Message) {
 	grpcAddresser.Update(grpc_adhoc.NewStaticAddresser(newConfig.Grpc.AdhocRules))
 	httpRouter.Update(newConfig.GetHttp().Routes)
 	httpAddresser.Update(http_adhoc.NewStaticAddresser(newConfig.Http.AdhocRules))
+	tcpRouter.Update(newConfig.GetTcp().GetRoutes())
 }
 
 func backendConfigReloaded(_ proto.Message, newValue proto.Message) {
@@ -76,6 +95,10 @@ func backendConfigReloaded(_ proto.Message, newValue proto.Message) {
 
 	newConfig := newValue.(*pb_config.BackendPoolConfig)
 
+	// TLS configs need to be known before backends referencing them are added or updated.
+	grpcBackendPool.UpdateTLSServerConfigs(newConfig.TlsServerConfigs)
+	httpBackendPool.UpdateTLSServerConfigs(newConfig.TlsServerConfigs)
+
 	// The gRPC and HTTP fields are guaranteed to be there because of validation.
 	grpcBackendInNewConfig := make(map[string]struct{})
 	grpcBackendInOldConfig := grpcBackendPool.Configs()
@@ -120,4 +143,23 @@ func backendConfigReloaded(_ proto.Message, newValue proto.Message) {
 			}
 		}
 	}
+
+	tcpBackendInNewConfig := make(map[string]struct{})
+	tcpBackendInOldConfig := tcpBackendPool.Configs()
+	for _, backend := range newConfig.GetTcp().GetBackends() {
+		_, err := tcpBackendPool.AddOrUpdate(backend)
+		if err != nil {
+			logrus.Errorf("failed to add or update tcp backend %v: %v", backend.Name, err)
+		}
+		tcpBackendInNewConfig[backend.Name] = struct{}{}
+	}
+
+	for backendName := range tcpBackendInOldConfig {
+		if _, exists := tcpBackendInNewConfig[backendName]; !exists {
+			err := tcpBackendPool.Remove(backendName)
+			if err != nil {
+				logrus.Errorf("failed to remove tcp backend %v: %v", backendName, err)
+			}
+		}
+	}
 }
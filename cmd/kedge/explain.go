@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/explain"
+	"github.com/spf13/pflag"
+)
+
+const explainPath = "/debug/explain"
+
+// routeExplainer explains routing using the live routers, adhoc rules and backend pools.
+func routeExplainer() http.Handler {
+	auth := explain.Auth{Required: *flagOIDCProvider != ""}
+	if auth.Required {
+		auth.Issuer = *flagOIDCProvider
+		auth.ClientID = *flagOIDCClientID
+		auth.Permissions = *flagOIDCWhiteListPerms
+	}
+	return explain.New(httpRouter, httpAddresser, httpBackendPool, grpcRouter, grpcAddresser, grpcBackendPool, auth)
+}
+
+// runExplain runs the "explain" subcommand that asks a running kedge how it would route the given request or call.
+func runExplain(args []string) int {
+	flags := pflag.NewFlagSet("explain", pflag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: kedge explain [flags]\n\nShows how a running kedge would route the given HTTP request or gRPC call.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+	var (
+		kedgeURL   = flags.String("kedge_debug_url", "http://localhost:8080", "URL of the kedge HTTP debug port.")
+		token      = flags.String("token", "", "Proxy auth token (e.g. OIDC ID token) to access the debug endpoint, if needed.")
+		rawURL     = flags.String("url", "", "URL of the HTTP request.")
+		method     = flags.String("method", "GET", "Method of the HTTP request.")
+		host       = flags.String("host", "", "Host of the HTTP request. Defaults to the host of --url.")
+		headers    = flags.StringSlice("header", nil, "Header of the HTTP request in 'Name: value' form. Can be repeated.")
+		proxyMode  = flags.String("proxy_mode", "forward", "Proxy mode of the HTTP request: forward or reverse.")
+		grpcMethod = flags.String("grpc_method", "", "Full method of the gRPC call, e.g. /pkg.Service/Method. If set, the gRPC call is explained instead of the HTTP request.")
+		authority  = flags.String("authority", "", "Authority of the gRPC call.")
+		md         = flags.StringSlice("metadata", nil, "Metadata of the gRPC call in 'key: value' form. Can be repeated.")
+		timeout    = flags.Duration("timeout", 10*time.Second, "Timeout for asking kedge.")
+	)
+	flags.Parse(args)
+
+	q := url.Values{}
+	if *grpcMethod != "" {
+		q.Set("grpc_method", *grpcMethod)
+		q.Set("authority", *authority)
+		q["metadata"] = *md
+	} else {
+		if *rawURL == "" {
+			fmt.Fprintln(os.Stderr, "Either --url or --grpc_method needs to be given.")
+			return 2
+		}
+		q.Set("url", *rawURL)
+		q.Set("method", *method)
+		q.Set("host", *host)
+		q.Set("proxy_mode", *proxyMode)
+		q["header"] = *headers
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimRight(*kedgeURL, "/")+explainPath+"?"+q.Encode(), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --kedge_debug_url: %v\n", err)
+		return 2
+	}
+	if *token != "" {
+		req.Header.Set("Proxy-Authorization", "Bearer "+*token)
+	}
+	resp, err := (&http.Client{Timeout: *timeout}).Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to ask kedge: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "kedge responded with %s: ", resp.Status)
+		io.Copy(os.Stderr, resp.Body)
+		return 1
+	}
+	io.Copy(os.Stdout, resp.Body)
+	return 0
+}
@@ -14,6 +14,7 @@ import (
 
 	"strings"
 
+	"github.com/Bplotka/oidc/authorize"
 	"github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
@@ -27,11 +28,17 @@ import (
 	"github.com/improbable-eng/kedge/pkg/discovery"
 	"github.com/improbable-eng/kedge/pkg/http/ctxtags"
 	"github.com/improbable-eng/kedge/pkg/http/header"
+	"github.com/improbable-eng/kedge/pkg/kedge/admin"
 	grpc_director "github.com/improbable-eng/kedge/pkg/kedge/grpc/director"
+	grpc_adhoc "github.com/improbable-eng/kedge/pkg/kedge/grpc/director/adhoc"
+	"github.com/improbable-eng/kedge/pkg/kedge/grpc/grpcweb"
 	http_director "github.com/improbable-eng/kedge/pkg/kedge/http/director"
+	tcp_director "github.com/improbable-eng/kedge/pkg/kedge/tcp/director"
 	"github.com/improbable-eng/kedge/pkg/logstash"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/improbable-eng/kedge/pkg/tracing"
+	pb_admin "github.com/improbable-eng/kedge/protogen/kedge/admin"
 	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/mwitkow/go-conntrack/connhelpers"
@@ -54,10 +61,16 @@ var (
 	flagHttpTlsPort = sharedflags.Set.Int("server_http_tls_port", 8443, "TCP port to listen on for HTTPS. If gRPC call will hit it will bounce to gRPC handler. If 0, no TLS will be open.")
 	flagHttpPort    = sharedflags.Set.Int("server_http_port", 8080, "TCP port to listen on for HTTP1.1/REST calls for debug endpoints like metrics, flagz page or optional pprof (insecure, but private only IP are allowed). If 0, no debug HTTP endpoint will be open.")
 
+	flagTcpTlsPassthroughPort = sharedflags.Set.Int("server_tcp_tls_passthrough_port", 0, "TCP port to listen on for TLS connections forwarded to TCP backends by SNI without terminating TLS. If 0, no TLS passthrough will be open.")
+
 	flagHttpMaxWriteTimeout = sharedflags.Set.Duration("server_http_max_write_timeout", 10*time.Second, "HTTP server config, max write duration.")
 	flagHttpMaxReadTimeout  = sharedflags.Set.Duration("server_http_max_read_timeout", 10*time.Second, "HTTP server config, max read duration.")
 	flagGrpcWithTracing     = sharedflags.Set.Bool("server_tracing_grpc_enabled", true, "Whether enable gRPC tracing (could be expensive).")
 
+	flagAdminAPIEnabled = sharedflags.Set.Bool("server_admin_api_enabled", false, "If enabled, the admin API for inspecting and "+
+		"editing routes, adhoc rules and backends is served on the HTTP debug port, both as gRPC and REST (under /admin/v1/). "+
+		"Requires OIDC authorization and server_admin_api_permissions to be configured.")
+
 	flagLogLevel        = sharedflags.Set.String("log_level", "info", "Log level")
 	flagLogstashAddress = sharedflags.Set.String("logstash_hostport", "", "Host:port of logstash for remote logging. If empty remote logging is disabled.")
 
@@ -70,7 +83,8 @@ var (
 )
 
 func isGRPCReq(header http.Header) bool {
-	// Do not treat "grpc-web" request as pure gRPC. It is pure HTTP/2 at this point.
+	// Do not treat "grpc-web" request as pure gRPC. It is pure HTTP/2 at this point; it is translated by the gRPC-Web
+	// handler only if it matches a gRPC route, otherwise it is proxied by the HTTP director.
 	if strings.HasPrefix(header.Get("content-type"), "application/grpc-web") {
 		return false
 	}
@@ -83,6 +97,10 @@ func isGRPCReq(header http.Header) bool {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		os.Exit(runExplain(os.Args[2:]))
+	}
+
 	if err := sharedflags.Set.Parse(os.Args); err != nil {
 		log.WithError(err).Fatalf("failed parsing flags")
 	}
@@ -112,6 +130,7 @@ func main() {
 	grpc.EnableTracing = *flagGrpcWithTracing
 	logEntry := log.NewEntry(log.StandardLogger())
 	grpc_logrus.ReplaceGrpcLogger(logEntry)
+	defer tracing.InitFromFlags("kedge", logEntry)()
 	tlsConfig, err := buildTLSConfigFromFlags()
 	if err != nil {
 		log.Fatalf("failed building TLS config from flags: %v", err)
@@ -154,18 +173,38 @@ func main() {
 		log.WithError(err).Fatal("failed to create authorizer.")
 	}
 
+	var (
+		adminServer     *admin.Server
+		adminAuthorizer authorize.Authorizer
+	)
+	if *flagAdminAPIEnabled {
+		adminAuthorizer, err = adminAuthorizerFromFlags()
+		if err != nil {
+			log.WithError(err).Fatal("failed to create admin API authorizer.")
+		}
+		if *flagHttpPort == 0 {
+			log.Fatal("admin API requires the HTTP debug port to be open.")
+		}
+		// Changes go through flags, so they are validated and applied the same way as config files or flagz changes.
+		adminServer = admin.New(flagConfigDirector, flagConfigBackendpool, logEntry.WithField("caller", "admin"))
+	}
+
 	var grpcServer *grpc.Server
 
 	if *flagGrpcTlsPort != 0 {
 		// Setup gRPC handling.
-		grpcDirector := grpc_director.New(grpcBackendPool, grpcAddresser, grpcRouter)
+		grpcAdhocConns := grpc_adhoc.NewConnPool()
+		defer grpcAdhocConns.Close()
+		grpcDirector := grpc_director.New(grpcBackendPool, grpcAddresser, grpcAdhocConns, grpcRouter)
 		grpcUnaryInterceptors := []grpc.UnaryServerInterceptor{
 			grpc_ctxtags.UnaryServerInterceptor(),
+			tracing.UnaryServerInterceptor("kedge.grpc"),
 			grpc_logrus.UnaryServerInterceptor(logEntry),
 			grpc_prometheus.UnaryServerInterceptor,
 		}
 		grpcStreamInterceptors := []grpc.StreamServerInterceptor{
 			grpc_ctxtags.StreamServerInterceptor(),
+			tracing.StreamServerInterceptor("kedge.grpc"),
 			grpc_logrus.StreamServerInterceptor(logEntry),
 			grpc_prometheus.StreamServerInterceptor,
 		}
@@ -176,6 +215,8 @@ func main() {
 
 			logEntry.Info("configured OIDC authorization for TLS gRPC.")
 		}
+		// Like for HTTP, only authorized calls are reported in latency metrics.
+		grpcStreamInterceptors = append(grpcStreamInterceptors, grpc_director.StreamServerInterceptor())
 
 		// GRPC kedge.
 		grpcServer = grpc.NewServer(
@@ -220,12 +261,17 @@ func main() {
 
 		handler := httpDirectorChain.Handler(httpDirector)
 		if grpcServer != nil {
+			grpcWebHandler := grpcweb.NewHandler(grpcServer, grpcRouter)
 			// Make HTTP handler bounce to gRPC if found proper content-type.
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				if isGRPCReq(req.Header) {
 					grpcServer.ServeHTTP(w, req)
 					return
 				}
+				if grpcWebHandler.Handles(req) {
+					grpcWebHandler.ServeHTTP(w, req)
+					return
+				}
 				httpDirectorChain.Handler(httpDirector).ServeHTTP(w, req)
 			})
 		}
@@ -260,6 +306,22 @@ func main() {
 		})
 	}
 
+	if *flagTcpTlsPassthroughPort != 0 {
+		tcpDirector := tcp_director.New(tcpBackendPool, tcpRouter, logEntry.WithField("caller", "tcp_tls_passthrough"))
+		tcpPassthroughListener := buildListenerOrFail("tcp_tls_passthrough", *flagTcpTlsPassthroughPort)
+
+		g.Add(func() error {
+			log.Infof("listening for TCP TLS passthrough on: %v", tcpPassthroughListener.Addr().String())
+			err := tcpDirector.Serve(tcpPassthroughListener)
+			if err != nil {
+				return errors.Wrap(err, "tcp_tls_passthrough")
+			}
+			return nil
+		}, func(error) {
+			tcpPassthroughListener.Close()
+		})
+	}
+
 	if *flagHttpPort != 0 {
 		// HTTP debug chain.
 		httpDebugChain := chi.Chain(
@@ -274,13 +336,50 @@ func main() {
 		// httpNonAuthDebugChain chain is shares the same base but will not include auth. It is for metrics and _healthz.
 		httpNonAuthDebugChain := httpDebugChain
 
+		// Admin API is always authorized with the admin permissions, regardless of the debug endpoints.
+		var adminHandler http.Handler
+		if adminServer != nil {
+			adminHandler = chi.Chain(
+				http_ctxtags.Middleware("admin"),
+				http_director.AuthMiddleware(adminAuthorizer),
+			).Handler(adminServer.HTTPHandler())
+		}
+
 		// Debug.
-		httpDebugServer, err := debugServer(logEntry, httpDebugChain, httpNonAuthDebugChain)
+		httpDebugServer, err := debugServer(logEntry, httpDebugChain, httpNonAuthDebugChain, adminHandler)
 		if err != nil {
 			log.WithError(err).Fatal("failed to create debug Server.")
 		}
 		httpPlainListener := buildListenerOrFail("http_plain", *flagHttpPort)
 
+		if adminServer != nil {
+			// gRPC admin calls are plain text HTTP/2, so they can be told apart from debug HTTP/1 requests.
+			var adminGrpcListener net.Listener
+			adminGrpcListener, httpPlainListener = admin.SplitListener(httpPlainListener)
+
+			adminGrpcAuth := grpc_director.NewGRPCAuthorizer(adminAuthorizer)
+			adminGrpcServer := grpc.NewServer(
+				grpc_middleware.WithUnaryServerChain(
+					grpc_ctxtags.UnaryServerInterceptor(),
+					grpc_logrus.UnaryServerInterceptor(logEntry),
+					grpc_auth.UnaryServerInterceptor(adminGrpcAuth),
+				),
+			)
+			pb_admin.RegisterAdminServer(adminGrpcServer, adminServer)
+
+			g.Add(func() error {
+				log.Infof("listening for gRPC admin API on: %v", adminGrpcListener.Addr().String())
+				err := adminGrpcServer.Serve(adminGrpcListener)
+				if err != nil {
+					return errors.Wrap(err, "grpc_admin")
+				}
+				return nil
+			}, func(error) {
+				adminGrpcServer.GracefulStop()
+				adminGrpcListener.Close()
+			})
+		}
+
 		g.Add(func() error {
 			log.Infof("listening for HTTP plain on: %v", httpPlainListener.Addr().String())
 			err := httpDebugServer.Serve(httpPlainListener)
@@ -312,7 +411,7 @@ func main() {
 	}
 }
 
-func debugServer(logEntry *log.Entry, middlewares chi.Middlewares, noAuthMiddlewares chi.Middlewares) (*http.Server, error) {
+func debugServer(logEntry *log.Entry, middlewares chi.Middlewares, noAuthMiddlewares chi.Middlewares, adminHandler http.Handler) (*http.Server, error) {
 	m := chi.NewMux()
 	m.Handle("/_healthz", noAuthMiddlewares.HandlerFunc(healthEndpoint))
 	m.Handle(*flagMetricsPath, noAuthMiddlewares.Handler(promhttp.Handler()))
@@ -333,6 +432,13 @@ func debugServer(logEntry *log.Entry, middlewares chi.Middlewares, noAuthMiddlew
 	m.Handle("/debug/traces", middlewares.HandlerFunc(trace.Traces))
 	m.Handle("/debug/events", middlewares.HandlerFunc(trace.Events))
 
+	// NOTE: Shows routing config and backend targets.
+	m.Handle(explainPath, middlewares.Handler(routeExplainer()))
+
+	if adminHandler != nil {
+		m.Mount(admin.RESTPrefix, adminHandler)
+	}
+
 	return &http.Server{
 		WriteTimeout: *flagHttpMaxWriteTimeout,
 		ReadTimeout:  *flagHttpMaxReadTimeout,
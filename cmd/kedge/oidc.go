@@ -20,6 +20,9 @@ var (
 		"Permissions satisfy Kedge access auth.")
 	flagEnableOIDCAuthForDebugEnpoints = sharedflags.Set.Bool("server_enable_oidc_for_debug_endpoints", false,
 		"If true, debug endpoints will be hidden by OIDC Auth with the same configuration as proxy.")
+	flagAdminAPIPermissions = sharedflags.Set.StringSlice("server_admin_api_permissions", []string(nil),
+		"Permissions satisfy Kedge admin API access auth. Separate from server_oidc_whitelist_perms, so proxy access does "+
+			"not allow changing routes and backends.")
 )
 
 func authorizerFromFlags(entry *logrus.Entry) (authorize.Authorizer, error) {
@@ -38,9 +41,24 @@ func authorizerFromFlags(entry *logrus.Entry) (authorize.Authorizer, error) {
 	if len(*flagOIDCWhiteListPerms) == 0 {
 		return nil, errors.New("OIDC flag validation failed. server_oidc_whitelist_perms flag cannot be empty.")
 	}
+	return oidcAuthorizer(*flagOIDCWhiteListPerms)
+}
+
+// adminAuthorizerFromFlags returns the authorizer for the admin API, which uses the same OIDC configuration as the
+// proxy, but requires one of the admin permissions.
+func adminAuthorizerFromFlags() (authorize.Authorizer, error) {
+	if *flagOIDCProvider == "" {
+		return nil, errors.New("admin API requires OIDC authorization to be configured.")
+	}
+	if len(*flagAdminAPIPermissions) == 0 {
+		return nil, errors.New("admin API requires server_admin_api_permissions flag to be set.")
+	}
+	return oidcAuthorizer(*flagAdminAPIPermissions)
+}
 
+func oidcAuthorizer(perms []string) (authorize.Authorizer, error) {
 	var condition []authorize.Condition
-	for _, permToWhitelist := range *flagOIDCWhiteListPerms {
+	for _, permToWhitelist := range perms {
 		condition = append(condition, authorize.Contains(permToWhitelist))
 	}
 
@@ -24,6 +24,7 @@ import (
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/sharedflags"
 	"github.com/improbable-eng/kedge/pkg/tls"
+	"github.com/improbable-eng/kedge/pkg/tracing"
 	"github.com/improbable-eng/kedge/pkg/winch"
 	"github.com/improbable-eng/kedge/pkg/winch/grpc"
 	"github.com/improbable-eng/kedge/pkg/winch/http"
@@ -110,6 +111,7 @@ func main() {
 	log.SetLevel(lvl)
 	logEntry := log.NewEntry(log.StandardLogger())
 	logEntry.Warn("Make sure you have enough file descriptors on your machine. Run ulimit -n <value> to set it for this terminal.")
+	defer tracing.InitFromFlags("winch", logEntry)()
 
 	tlsConfig, err := kedge_tls.BuildClientTLSConfigFromFlags()
 	if err != nil {
@@ -83,6 +83,10 @@ func cloneBaseConfigs(baseDirector *pb_config.DirectorConfig, baseBackendpool *p
 			resultBackendPool.GetGrpc().Backends = append(resultBackendPool.GetGrpc().Backends, backend)
 		}
 	}
+
+	// TCP routes and backends are not discovered, so base is used as it is.
+	resultDirectorConfig.Tcp = baseDirector.GetTcp()
+	resultBackendPool.Tcp = baseBackendpool.GetTcp()
 	return resultDirectorConfig, resultBackendPool
 }
 
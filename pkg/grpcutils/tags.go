@@ -0,0 +1,6 @@
+package grpcutils
+
+const (
+	// TagForProxyAuthSubject specifies the subject of the OIDC token used for proxy auth.
+	TagForProxyAuthSubject = "grpc.proxy.auth.subject"
+)
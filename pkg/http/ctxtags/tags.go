@@ -17,6 +17,9 @@ const (
 	// TagForScheme specifies which scheme request is using. It is specified by each server.
 	TagForScheme = "http.scheme"
 
+	// TagForProxyAuthSubject specifies the subject of the OIDC token used for proxy auth.
+	TagForProxyAuthSubject = "http.proxy.auth.subject"
+
 	// TagForProxyAuthTime specifies time that took to put valid proxy auth in Headers.
 	// It can sometimes take time in case of full OIDC login.
 	TagForProxyAuthTime = "http.proxy.auth.time"
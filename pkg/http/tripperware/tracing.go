@@ -0,0 +1,33 @@
+package tripperware
+
+import (
+	"net/http"
+
+	"github.com/improbable-eng/kedge/pkg/tracing"
+)
+
+// tracingTripper is a piece of tripperware that creates a span for every request proxied by winch, continuing the
+// trace of the application if its request carries one. The span context is propagated to kedge in request headers.
+// NOTE: It requires to have mappingTripper before itself to record the kedge the request is routed through.
+type tracingTripper struct {
+	parent http.RoundTripper
+}
+
+func (t *tracingTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := tracing.ExtractHTTP(req.Context(), req.Header)
+	ctx, span := tracing.StartSpan(ctx, "winch.proxy", tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.host", req.URL.Host)
+	if route, ok, _ := getRoute(ctx); ok {
+		span.SetAttribute("winch.kedge_url", route.URL.String())
+	}
+
+	resp, err := t.parent.RoundTrip(tracing.RequestWithSpan(req.WithContext(ctx)))
+	tracing.RecordHTTPResult(span, resp, err)
+	return resp, err
+}
+
+func WrapForTracing(parentTransport http.RoundTripper) http.RoundTripper {
+	return &tracingTripper{parent: parentTransport}
+}
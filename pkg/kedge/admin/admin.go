@@ -0,0 +1,270 @@
+// Package admin implements the admin API of kedge, used to inspect and edit routes, adhoc rules and backends at
+// runtime.
+//
+// Changes are applied by setting the director and backendpool config flags, the same way as through /debug/flagz,
+// so they go through the same validation and reload paths. Every change is audit logged.
+package admin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/improbable-eng/kedge/pkg/grpcutils"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/admin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ConfigFlag is a dynamic flag holding a config, e.g. protoflagz.DynProto3Value. Set validates the new value (given
+// in JSONPB) and applies it.
+type ConfigFlag interface {
+	Get() proto.Message
+	Set(value string) error
+}
+
+// Server implements the Admin gRPC service and its REST mapping (see HTTPHandler).
+type Server struct {
+	director    *versionedConfig
+	backendpool *versionedConfig
+	logger      logrus.FieldLogger
+}
+
+// New creates an admin server editing the given director and backendpool configs. Changes are audit logged to the
+// logger.
+func New(directorConfig ConfigFlag, backendpoolConfig ConfigFlag, logger logrus.FieldLogger) *Server {
+	return &Server{
+		director:    &versionedConfig{name: "director", flag: directorConfig},
+		backendpool: &versionedConfig{name: "backendpool", flag: backendpoolConfig},
+		logger:      logger,
+	}
+}
+
+// versionedConfig tracks the version of a config flag. The version is bumped whenever the flag holds a new value, no
+// matter whether it was set by the admin API, flagz or dynamic routing discovery.
+type versionedConfig struct {
+	name string
+	flag ConfigFlag
+
+	// changeMu serializes changes done through the admin API.
+	changeMu sync.Mutex
+
+	mu      sync.Mutex
+	last    proto.Message
+	version uint64
+}
+
+func (c *versionedConfig) current() (proto.Message, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cnf := c.flag.Get()
+	if cnf != c.last {
+		c.last = cnf
+		c.version++
+	}
+	return cnf, c.version
+}
+
+// change applies mutate to the resources of the kind, if the config is still at the given version.
+func (c *versionedConfig) change(k *kind, version uint64, mutate func([]*pb.Resource) ([]*pb.Resource, error)) (uint64, error) {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+
+	cnf, currentVersion := c.current()
+	if version != currentVersion {
+		return currentVersion, grpc.Errorf(codes.FailedPrecondition,
+			"%s config is at version %d, not %d; read it again before changing it", c.name, currentVersion, version)
+	}
+	newCnf := proto.Clone(cnf)
+	items, err := mutate(k.items(newCnf))
+	if err != nil {
+		return currentVersion, err
+	}
+	k.setItems(newCnf, items)
+
+	value, err := (&jsonpb.Marshaler{}).MarshalToString(newCnf)
+	if err != nil {
+		return currentVersion, grpc.Errorf(codes.Internal, "failed to marshal %s config: %v", c.name, err)
+	}
+	if err := c.flag.Set(value); err != nil {
+		return currentVersion, grpc.Errorf(codes.InvalidArgument, "invalid %s config: %v", c.name, err)
+	}
+	_, newVersion := c.current()
+	return newVersion, nil
+}
+
+func (s *Server) config(k *kind) *versionedConfig {
+	if k.director {
+		return s.director
+	}
+	return s.backendpool
+}
+
+// List returns all resources of the kind.
+func (s *Server) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	k, err := kindOf(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	cnf, version := s.config(k).current()
+	return &pb.ListResponse{Resources: k.items(cnf), Version: version}, nil
+}
+
+// Get returns a single resource.
+func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	k, err := kindOf(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	cnf, version := s.config(k).current()
+	items := k.items(cnf)
+	idx, err := find(items, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetResponse{Resource: items[idx], Version: version}, nil
+}
+
+// Add adds a resource. Routes and adhoc rules can be inserted before an existing one.
+func (s *Server) Add(ctx context.Context, req *pb.AddRequest) (*pb.ChangeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	k, err := kindOfResource(req.Resource)
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	version, err := s.config(k).change(k, req.Version, func(items []*pb.Resource) ([]*pb.Resource, error) {
+		idx := len(items)
+		if req.Before != "" && k.ordered {
+			var err error
+			if idx, err = find(items, req.Before); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items[:idx], append([]*pb.Resource{req.Resource}, items[idx:]...)...)
+		items = k.rename(items)
+		name = items[idx].Name
+		return items, checkUnique(items, idx)
+	})
+	s.audit(ctx, "add", k, name, req.Version, version, req.Resource, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ChangeResponse{Version: version}, nil
+}
+
+// Update replaces a resource.
+func (s *Server) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.ChangeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	k, err := kindOfResource(req.Resource)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.config(k).change(k, req.Version, func(items []*pb.Resource) ([]*pb.Resource, error) {
+		idx, err := find(items, req.Name)
+		if err != nil {
+			return nil, err
+		}
+		items[idx] = req.Resource
+		items = k.rename(items)
+		return items, checkUnique(items, idx)
+	})
+	s.audit(ctx, "update", k, req.Name, req.Version, version, req.Resource, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ChangeResponse{Version: version}, nil
+}
+
+// Delete removes a resource.
+func (s *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.ChangeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	k, err := kindOf(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.config(k).change(k, req.Version, func(items []*pb.Resource) ([]*pb.Resource, error) {
+		idx, err := find(items, req.Name)
+		if err != nil {
+			return nil, err
+		}
+		return append(items[:idx], items[idx+1:]...), nil
+	})
+	s.audit(ctx, "delete", k, req.Name, req.Version, version, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ChangeResponse{Version: version}, nil
+}
+
+func find(items []*pb.Resource, name string) (int, error) {
+	for i, item := range items {
+		if item.Name == name {
+			return i, nil
+		}
+	}
+	return 0, grpc.Errorf(codes.NotFound, "no resource named %q", name)
+}
+
+// checkUnique checks that the name of the changed resource is not used by any other resource.
+func checkUnique(items []*pb.Resource, changed int) error {
+	for i, item := range items {
+		if i != changed && item.Name == items[changed].Name {
+			return grpc.Errorf(codes.AlreadyExists, "resource named %q already exists", item.Name)
+		}
+	}
+	return nil
+}
+
+type subjectKey struct{}
+
+// contextWithSubject sets the authenticated subject of a REST request, for audit logs.
+func contextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+func subjectFromContext(ctx context.Context) string {
+	if subject, ok := ctx.Value(subjectKey{}).(string); ok {
+		return subject
+	}
+	subject, _ := grpc_ctxtags.Extract(ctx).Values()[grpcutils.TagForProxyAuthSubject].(string)
+	return subject
+}
+
+// audit logs a change, whether it succeeded or not.
+func (s *Server) audit(ctx context.Context, action string, k *kind, name string, version uint64, newVersion uint64, resource *pb.Resource, err error) {
+	fields := logrus.Fields{
+		"admin.action":  action,
+		"admin.kind":    k.kind.String(),
+		"admin.name":    name,
+		"admin.config":  s.config(k).name,
+		"admin.version": version,
+		"admin.subject": subjectFromContext(ctx),
+	}
+	if resource != nil {
+		value, jsonErr := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(resource)
+		if jsonErr != nil {
+			value = fmt.Sprintf("<failed to marshal: %v>", jsonErr)
+		}
+		fields["admin.resource"] = value
+	}
+	if err != nil {
+		s.logger.WithFields(fields).WithError(err).Warn("Admin API change rejected.")
+		return
+	}
+	fields["admin.new_version"] = newVersion
+	s.logger.WithFields(fields).Info("Admin API changed config.")
+}
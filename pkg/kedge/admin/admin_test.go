@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/admin"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
+	pb_grpcbackends "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	pb_httproutes "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/mwitkow/go-proto-validators"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeFlag behaves like protoflagz.DynProto3Value: every Set stores a new, validated message.
+type fakeFlag struct {
+	value proto.Message
+	sets  int
+}
+
+func (f *fakeFlag) Get() proto.Message {
+	return f.value
+}
+
+func (f *fakeFlag) Set(value string) error {
+	msg := proto.Clone(f.value)
+	msg.Reset()
+	if err := jsonpb.UnmarshalString(value, msg); err != nil {
+		return err
+	}
+	if err := msg.(validator.Validator).Validate(); err != nil {
+		return err
+	}
+	f.value = msg
+	f.sets++
+	return nil
+}
+
+func newTestServer() (*Server, *fakeFlag, *fakeFlag, *test.Hook) {
+	director := &fakeFlag{value: &pb_config.DirectorConfig{
+		Http: &pb_config.DirectorConfig_Http{
+			Routes: []*pb_httproutes.Route{
+				{Name: "first", BackendName: "backend_a"},
+				{BackendName: "backend_b"},
+			},
+		},
+	}}
+	backendpool := &fakeFlag{value: &pb_config.BackendPoolConfig{
+		Grpc: &pb_config.BackendPoolConfig_Grpc{
+			Backends: []*pb_grpcbackends.Backend{{Name: "backend_a"}},
+		},
+	}}
+	logger, hook := test.NewNullLogger()
+	return New(director, backendpool, logger), director, backendpool, hook
+}
+
+func httpRoute(name string, backend string) *pb.Resource {
+	return &pb.Resource{Resource: &pb.Resource_HttpRoute{HttpRoute: &pb_httproutes.Route{Name: name, BackendName: backend}}}
+}
+
+func TestListAndGet(t *testing.T) {
+	s, _, _, _ := newTestServer()
+	ctx := context.Background()
+
+	list, err := s.List(ctx, &pb.ListRequest{Kind: pb.Kind_HTTP_ROUTE})
+	require.NoError(t, err)
+	require.Len(t, list.Resources, 2)
+	assert.Equal(t, "first", list.Resources[0].Name)
+	assert.Equal(t, "1", list.Resources[1].Name, "unnamed routes are named by their index")
+	assert.Equal(t, uint64(1), list.Version)
+
+	list, err = s.List(ctx, &pb.ListRequest{Kind: pb.Kind_GRPC_ROUTE})
+	require.NoError(t, err)
+	assert.Empty(t, list.Resources)
+
+	got, err := s.Get(ctx, &pb.GetRequest{Kind: pb.Kind_GRPC_BACKEND, Name: "backend_a"})
+	require.NoError(t, err)
+	assert.Equal(t, "backend_a", got.Resource.GetGrpcBackend().Name)
+
+	_, err = s.Get(ctx, &pb.GetRequest{Kind: pb.Kind_GRPC_BACKEND, Name: "nope"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	_, err = s.List(ctx, &pb.ListRequest{Kind: pb.Kind_UNKNOWN})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+}
+
+func TestChanges(t *testing.T) {
+	s, director, backendpool, hook := newTestServer()
+	ctx := contextWithSubject(context.Background(), "admin@example.com")
+
+	resp, err := s.Add(ctx, &pb.AddRequest{Resource: httpRoute("new", "backend_c"), Before: "1", Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.Version)
+	routes := director.value.(*pb_config.DirectorConfig).Http.Routes
+	require.Len(t, routes, 3)
+	assert.Equal(t, []string{"first", "new", ""}, []string{routes[0].Name, routes[1].Name, routes[2].Name})
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "add", entry.Data["admin.action"])
+	assert.Equal(t, "HTTP_ROUTE", entry.Data["admin.kind"])
+	assert.Equal(t, "new", entry.Data["admin.name"])
+	assert.Equal(t, "admin@example.com", entry.Data["admin.subject"])
+	assert.Equal(t, uint64(2), entry.Data["admin.new_version"])
+
+	_, err = s.Update(ctx, &pb.UpdateRequest{Name: "new", Resource: httpRoute("new", "backend_d"), Version: 1})
+	assert.Equal(t, codes.FailedPrecondition, grpc.Code(err), "stale version should be rejected")
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+
+	_, err = s.Update(ctx, &pb.UpdateRequest{Name: "new", Resource: httpRoute("first", "backend_d"), Version: 2})
+	assert.Equal(t, codes.AlreadyExists, grpc.Code(err))
+
+	_, err = s.Update(ctx, &pb.UpdateRequest{Name: "new", Resource: httpRoute("new", "INVALID NAME"), Version: 2})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "config validator should reject the change")
+
+	resp, err = s.Update(ctx, &pb.UpdateRequest{Name: "new", Resource: httpRoute("new", "backend_d"), Version: 2})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), resp.Version)
+	assert.Equal(t, "backend_d", director.value.(*pb_config.DirectorConfig).Http.Routes[1].BackendName)
+
+	resp, err = s.Delete(ctx, &pb.DeleteRequest{Kind: pb.Kind_HTTP_ROUTE, Name: "first", Version: 3})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), resp.Version)
+	assert.Len(t, director.value.(*pb_config.DirectorConfig).Http.Routes, 2)
+
+	// Backends are versioned separately.
+	resp, err = s.Add(ctx, &pb.AddRequest{
+		Resource: &pb.Resource{Resource: &pb.Resource_GrpcBackend{GrpcBackend: &pb_grpcbackends.Backend{Name: "backend_b"}}},
+		Version:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.Version)
+	assert.Len(t, backendpool.value.(*pb_config.BackendPoolConfig).Grpc.Backends, 2)
+	assert.Equal(t, 3, director.sets)
+}
+
+func TestVersionBumpedOnExternalChange(t *testing.T) {
+	s, director, _, _ := newTestServer()
+	ctx := context.Background()
+
+	list, err := s.List(ctx, &pb.ListRequest{Kind: pb.Kind_HTTP_ROUTE})
+	require.NoError(t, err)
+
+	// E.g. flagz or dynamic routing discovery.
+	require.NoError(t, director.Set(`{"grpc": {}, "http": {"routes": [{"name": "other"}]}}`))
+
+	_, err = s.Delete(ctx, &pb.DeleteRequest{Kind: pb.Kind_HTTP_ROUTE, Name: "first", Version: list.Version})
+	assert.Equal(t, codes.FailedPrecondition, grpc.Code(err))
+}
+
+func TestREST(t *testing.T) {
+	s, director, _, _ := newTestServer()
+	handler := s.HTTPHandler()
+
+	do := func(method string, path string, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		resp := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+		return rec.Code, resp
+	}
+
+	code, resp := do("GET", "/admin/v1/http_routes", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, resp["resources"], 2)
+	assert.Equal(t, "1", resp["version"])
+
+	code, resp = do("GET", "/admin/v1/http_routes/first", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "backend_a", resp["resource"].(map[string]interface{})["http_route"].(map[string]interface{})["backend_name"])
+
+	code, _ = do("POST", "/admin/v1/http_routes?version=1", `{"http_route": {"name": "new", "backend_name": "backend_c"}}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, director.value.(*pb_config.DirectorConfig).Http.Routes, 3)
+
+	code, resp = do("PUT", "/admin/v1/http_routes/new?version=1", `{"http_route": {"name": "new"}}`)
+	assert.Equal(t, http.StatusPreconditionFailed, code)
+	assert.Equal(t, "FailedPrecondition", resp["code"])
+
+	code, _ = do("POST", "/admin/v1/http_routes?version=2", `{"grpc_backend": {"name": "backend_c"}}`)
+	assert.Equal(t, http.StatusBadRequest, code, "resource of other kind than the path")
+
+	code, _ = do("DELETE", "/admin/v1/http_routes/nope?version=2", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = do("DELETE", "/admin/v1/http_routes/new?version=2", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, director.value.(*pb_config.DirectorConfig).Http.Routes, 2)
+
+	code, _ = do("GET", "/admin/v1/unknown", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
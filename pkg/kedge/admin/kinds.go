@@ -0,0 +1,283 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/admin"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	grpcbackends "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	grpcroutes "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	httpbackends "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
+	httproutes "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	tcpbackends "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+	tcproutes "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// kind describes where resources of a kind live in the director or backendpool config.
+type kind struct {
+	kind pb.Kind
+	// director is true for resources of the director config, false for backendpool config.
+	director bool
+	// ordered is true for kinds whose order matters (routes and adhoc rules), so they can be inserted at a position.
+	ordered bool
+
+	items    func(cnf proto.Message) []*pb.Resource
+	setItems func(cnf proto.Message, items []*pb.Resource)
+	// name returns the name of the resource at the given index, the same as used in metrics.
+	name func(r *pb.Resource, idx int) string
+}
+
+// rename sets the names of resources after a change, as names of unnamed routes and adhoc rules depend on the order.
+func (k *kind) rename(items []*pb.Resource) []*pb.Resource {
+	for i, item := range items {
+		item.Name = k.name(item, i)
+	}
+	return items
+}
+
+// path is the name of the kind in REST paths, e.g. "grpc_routes".
+func (k *kind) path() string {
+	return strings.ToLower(k.kind.String()) + "s"
+}
+
+func indexName(_ *pb.Resource, idx int) string {
+	return strconv.Itoa(idx)
+}
+
+// routeName is the name of the route, or its index if unnamed (see routers).
+func routeName(name string, idx int) string {
+	if name != "" {
+		return name
+	}
+	return strconv.Itoa(idx)
+}
+
+func directorConfig(cnf proto.Message) *pb_config.DirectorConfig {
+	c := cnf.(*pb_config.DirectorConfig)
+	if c.Grpc == nil {
+		c.Grpc = &pb_config.DirectorConfig_Grpc{}
+	}
+	if c.Http == nil {
+		c.Http = &pb_config.DirectorConfig_Http{}
+	}
+	if c.Tcp == nil {
+		c.Tcp = &pb_config.DirectorConfig_Tcp{}
+	}
+	return c
+}
+
+func backendpoolConfig(cnf proto.Message) *pb_config.BackendPoolConfig {
+	c := cnf.(*pb_config.BackendPoolConfig)
+	if c.Grpc == nil {
+		c.Grpc = &pb_config.BackendPoolConfig_Grpc{}
+	}
+	if c.Http == nil {
+		c.Http = &pb_config.BackendPoolConfig_Http{}
+	}
+	if c.Tcp == nil {
+		c.Tcp = &pb_config.BackendPoolConfig_Tcp{}
+	}
+	return c
+}
+
+var kinds = []*kind{
+	{
+		kind:     pb.Kind_GRPC_ROUTE,
+		director: true,
+		ordered:  true,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for i, r := range cnf.(*pb_config.DirectorConfig).GetGrpc().GetRoutes() {
+				items = append(items, &pb.Resource{Name: routeName(r.Name, i), Resource: &pb.Resource_GrpcRoute{GrpcRoute: r}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var routes []*grpcroutes.Route
+			for _, item := range items {
+				routes = append(routes, item.GetGrpcRoute())
+			}
+			directorConfig(cnf).Grpc.Routes = routes
+		},
+		name: func(r *pb.Resource, idx int) string { return routeName(r.GetGrpcRoute().Name, idx) },
+	},
+	{
+		kind:     pb.Kind_HTTP_ROUTE,
+		director: true,
+		ordered:  true,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for i, r := range cnf.(*pb_config.DirectorConfig).GetHttp().GetRoutes() {
+				items = append(items, &pb.Resource{Name: routeName(r.Name, i), Resource: &pb.Resource_HttpRoute{HttpRoute: r}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var routes []*httproutes.Route
+			for _, item := range items {
+				routes = append(routes, item.GetHttpRoute())
+			}
+			directorConfig(cnf).Http.Routes = routes
+		},
+		name: func(r *pb.Resource, idx int) string { return routeName(r.GetHttpRoute().Name, idx) },
+	},
+	{
+		kind:     pb.Kind_TCP_ROUTE,
+		director: true,
+		ordered:  true,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for i, r := range cnf.(*pb_config.DirectorConfig).GetTcp().GetRoutes() {
+				items = append(items, &pb.Resource{Name: routeName(r.Name, i), Resource: &pb.Resource_TcpRoute{TcpRoute: r}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var routes []*tcproutes.Route
+			for _, item := range items {
+				routes = append(routes, item.GetTcpRoute())
+			}
+			directorConfig(cnf).Tcp.Routes = routes
+		},
+		name: func(r *pb.Resource, idx int) string { return routeName(r.GetTcpRoute().Name, idx) },
+	},
+	{
+		kind:     pb.Kind_GRPC_ADHOC_RULE,
+		director: true,
+		ordered:  true,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for i, r := range cnf.(*pb_config.DirectorConfig).GetGrpc().GetAdhocRules() {
+				items = append(items, &pb.Resource{Name: strconv.Itoa(i), Resource: &pb.Resource_GrpcAdhocRule{GrpcAdhocRule: r}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var rules []*pb_common.Adhoc
+			for _, item := range items {
+				rules = append(rules, item.GetGrpcAdhocRule())
+			}
+			directorConfig(cnf).Grpc.AdhocRules = rules
+		},
+		name: indexName,
+	},
+	{
+		kind:     pb.Kind_HTTP_ADHOC_RULE,
+		director: true,
+		ordered:  true,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for i, r := range cnf.(*pb_config.DirectorConfig).GetHttp().GetAdhocRules() {
+				items = append(items, &pb.Resource{Name: strconv.Itoa(i), Resource: &pb.Resource_HttpAdhocRule{HttpAdhocRule: r}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var rules []*pb_common.Adhoc
+			for _, item := range items {
+				rules = append(rules, item.GetHttpAdhocRule())
+			}
+			directorConfig(cnf).Http.AdhocRules = rules
+		},
+		name: indexName,
+	},
+	{
+		kind: pb.Kind_GRPC_BACKEND,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for _, b := range cnf.(*pb_config.BackendPoolConfig).GetGrpc().GetBackends() {
+				items = append(items, &pb.Resource{Name: b.Name, Resource: &pb.Resource_GrpcBackend{GrpcBackend: b}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var backends []*grpcbackends.Backend
+			for _, item := range items {
+				backends = append(backends, item.GetGrpcBackend())
+			}
+			backendpoolConfig(cnf).Grpc.Backends = backends
+		},
+		name: func(r *pb.Resource, _ int) string { return r.GetGrpcBackend().Name },
+	},
+	{
+		kind: pb.Kind_HTTP_BACKEND,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for _, b := range cnf.(*pb_config.BackendPoolConfig).GetHttp().GetBackends() {
+				items = append(items, &pb.Resource{Name: b.Name, Resource: &pb.Resource_HttpBackend{HttpBackend: b}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var backends []*httpbackends.Backend
+			for _, item := range items {
+				backends = append(backends, item.GetHttpBackend())
+			}
+			backendpoolConfig(cnf).Http.Backends = backends
+		},
+		name: func(r *pb.Resource, _ int) string { return r.GetHttpBackend().Name },
+	},
+	{
+		kind: pb.Kind_TCP_BACKEND,
+		items: func(cnf proto.Message) []*pb.Resource {
+			var items []*pb.Resource
+			for _, b := range cnf.(*pb_config.BackendPoolConfig).GetTcp().GetBackends() {
+				items = append(items, &pb.Resource{Name: b.Name, Resource: &pb.Resource_TcpBackend{TcpBackend: b}})
+			}
+			return items
+		},
+		setItems: func(cnf proto.Message, items []*pb.Resource) {
+			var backends []*tcpbackends.Backend
+			for _, item := range items {
+				backends = append(backends, item.GetTcpBackend())
+			}
+			backendpoolConfig(cnf).Tcp.Backends = backends
+		},
+		name: func(r *pb.Resource, _ int) string { return r.GetTcpBackend().Name },
+	},
+}
+
+func kindOf(k pb.Kind) (*kind, error) {
+	for _, kd := range kinds {
+		if kd.kind == k {
+			return kd, nil
+		}
+	}
+	return nil, grpc.Errorf(codes.InvalidArgument, "unknown kind %v", k)
+}
+
+func kindOfResource(r *pb.Resource) (*kind, error) {
+	switch r.GetResource().(type) {
+	case *pb.Resource_GrpcRoute:
+		return kindOf(pb.Kind_GRPC_ROUTE)
+	case *pb.Resource_HttpRoute:
+		return kindOf(pb.Kind_HTTP_ROUTE)
+	case *pb.Resource_TcpRoute:
+		return kindOf(pb.Kind_TCP_ROUTE)
+	case *pb.Resource_GrpcAdhocRule:
+		return kindOf(pb.Kind_GRPC_ADHOC_RULE)
+	case *pb.Resource_HttpAdhocRule:
+		return kindOf(pb.Kind_HTTP_ADHOC_RULE)
+	case *pb.Resource_GrpcBackend:
+		return kindOf(pb.Kind_GRPC_BACKEND)
+	case *pb.Resource_HttpBackend:
+		return kindOf(pb.Kind_HTTP_BACKEND)
+	case *pb.Resource_TcpBackend:
+		return kindOf(pb.Kind_TCP_BACKEND)
+	}
+	return nil, grpc.Errorf(codes.InvalidArgument, "resource has no content")
+}
+
+func kindOfPath(path string) (*kind, bool) {
+	for _, k := range kinds {
+		if k.path() == path {
+			return k, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// http2Preface is sent first by every HTTP/2 client, so also by every gRPC client.
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+const sniffTimeout = 10 * time.Second
+
+// SplitListener splits connections of the listener into plain text HTTP/2 (gRPC) and other (HTTP/1) connections, so
+// the admin gRPC service can share the port of the debug HTTP server. Closing either of the returned listeners closes
+// the underlying one.
+func SplitListener(l net.Listener) (grpcListener net.Listener, httpListener net.Listener) {
+	s := &splitListener{
+		Listener: l,
+		grpcC:    make(chan net.Conn),
+		httpC:    make(chan net.Conn),
+		closedC:  make(chan struct{}),
+	}
+	go s.run()
+	return &subListener{splitListener: s, connC: s.grpcC}, &subListener{splitListener: s, connC: s.httpC}
+}
+
+type splitListener struct {
+	net.Listener
+
+	grpcC   chan net.Conn
+	httpC   chan net.Conn
+	closedC chan struct{}
+
+	closeOnce sync.Once
+	err       error
+}
+
+func (s *splitListener) run() {
+	for {
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			s.closeWith(err)
+			return
+		}
+		go s.sniff(conn)
+	}
+}
+
+// sniff reads the beginning of the connection until it either matches or differs from the HTTP/2 preface.
+func (s *splitListener) sniff(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	buf := make([]byte, len(http2Preface))
+	n := 0
+	for n < len(buf) && bytes.Equal(buf[:n], http2Preface[:n]) {
+		read, err := conn.Read(buf[n:])
+		n += read
+		if err != nil {
+			conn.Close()
+			return
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	connC := s.httpC
+	if bytes.Equal(buf[:n], http2Preface) {
+		connC = s.grpcC
+	}
+	select {
+	case connC <- &prefixConn{Conn: conn, prefix: buf[:n]}:
+	case <-s.closedC:
+		conn.Close()
+	}
+}
+
+func (s *splitListener) closeWith(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.closedC)
+	})
+}
+
+func (s *splitListener) Close() error {
+	s.closeWith(errors.New("listener closed"))
+	return s.Listener.Close()
+}
+
+type subListener struct {
+	*splitListener
+	connC chan net.Conn
+}
+
+func (l *subListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.connC:
+		return conn, nil
+	case <-l.closedC:
+		return nil, l.err
+	}
+}
+
+// prefixConn is a connection whose first bytes were already read while sniffing.
+type prefixConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixConn) Read(b []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(b, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcL, httpL := SplitListener(l)
+	defer grpcL.Close()
+
+	send := func(data string) {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		conn.Write([]byte(data))
+		conn.Close()
+	}
+	accept := func(l net.Listener) string {
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		data, err := ioutil.ReadAll(conn)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	go send("GET / HTTP/1.1\r\n\r\n")
+	assert.Equal(t, "GET / HTTP/1.1\r\n\r\n", accept(httpL), "HTTP/1 connection should be passed with sniffed bytes")
+
+	go send(string(http2Preface) + "frames")
+	assert.Equal(t, string(http2Preface)+"frames", accept(grpcL))
+
+	go send("PRI * HTTP/1.1\r\n\r\n")
+	assert.Equal(t, "PRI * HTTP/1.1\r\n\r\n", accept(httpL), "partial preface match is not HTTP/2")
+
+	httpL.Close()
+	_, err = grpcL.Accept()
+	assert.Error(t, err, "closing either listener closes both")
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/improbable-eng/go-httpwares/tags"
+	"github.com/improbable-eng/kedge/pkg/http/ctxtags"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/admin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// RESTPrefix is the path prefix of the REST mapping of the admin API.
+const RESTPrefix = "/admin/v1/"
+
+// HTTPHandler returns the REST mapping of the admin API, serving under RESTPrefix:
+//
+//	GET    /admin/v1/{kind}                          List
+//	GET    /admin/v1/{kind}/{name}                   Get
+//	POST   /admin/v1/{kind}?version=&before=         Add, with the Resource in JSONPB as body
+//	PUT    /admin/v1/{kind}/{name}?version=          Update, with the Resource in JSONPB as body
+//	DELETE /admin/v1/{kind}/{name}?version=          Delete
+//
+// where kind is the lowercase plural of the Kind, e.g. "http_routes". Responses are the gRPC responses in JSONPB.
+// Authentication is up to the middlewares in front of the handler.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(s.serveREST)
+}
+
+func (s *Server) serveREST(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if subject, ok := http_ctxtags.ExtractInbound(req).Values()[ctxtags.TagForProxyAuthSubject].(string); ok {
+		ctx = contextWithSubject(ctx, subject)
+	}
+
+	path := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, RESTPrefix), "/"), "/")
+	k, ok := kindOfPath(path[0])
+	if !ok || len(path) > 2 {
+		writeRESTError(resp, grpc.Errorf(codes.NotFound, "unknown admin API path %q", req.URL.Path))
+		return
+	}
+	var name string
+	if len(path) == 2 {
+		name = path[1]
+	}
+
+	var version uint64
+	if v := req.URL.Query().Get("version"); v != "" {
+		var err error
+		if version, err = strconv.ParseUint(v, 10, 64); err != nil {
+			writeRESTError(resp, grpc.Errorf(codes.InvalidArgument, "invalid version %q", v))
+			return
+		}
+	}
+
+	var (
+		msg proto.Message
+		err error
+	)
+	switch {
+	case req.Method == http.MethodGet && name == "":
+		msg, err = s.List(ctx, &pb.ListRequest{Kind: k.kind})
+	case req.Method == http.MethodGet:
+		msg, err = s.Get(ctx, &pb.GetRequest{Kind: k.kind, Name: name})
+	case req.Method == http.MethodPost && name == "":
+		var resource *pb.Resource
+		if resource, err = readResource(req, k); err == nil {
+			msg, err = s.Add(ctx, &pb.AddRequest{Resource: resource, Before: req.URL.Query().Get("before"), Version: version})
+		}
+	case req.Method == http.MethodPut && name != "":
+		var resource *pb.Resource
+		if resource, err = readResource(req, k); err == nil {
+			msg, err = s.Update(ctx, &pb.UpdateRequest{Name: name, Resource: resource, Version: version})
+		}
+	case req.Method == http.MethodDelete && name != "":
+		msg, err = s.Delete(ctx, &pb.DeleteRequest{Kind: k.kind, Name: name, Version: version})
+	default:
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		writeRESTError(resp, err)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	(&jsonpb.Marshaler{OrigName: true}).Marshal(resp, msg)
+}
+
+func readResource(req *http.Request, k *kind) (*pb.Resource, error) {
+	resource := &pb.Resource{}
+	if err := jsonpb.Unmarshal(req.Body, resource); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid resource: %v", err)
+	}
+	if rk, err := kindOfResource(resource); err != nil || rk != k {
+		return nil, grpc.Errorf(codes.InvalidArgument, "resource is not of kind %v", k.kind)
+	}
+	return resource, nil
+}
+
+func writeRESTError(resp http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch grpc.Code(err) {
+	case codes.InvalidArgument:
+		status = http.StatusBadRequest
+	case codes.NotFound:
+		status = http.StatusNotFound
+	case codes.AlreadyExists:
+		status = http.StatusConflict
+	case codes.FailedPrecondition:
+		status = http.StatusPreconditionFailed
+	case codes.Unauthenticated:
+		status = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		status = http.StatusForbidden
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	json.NewEncoder(resp).Encode(map[string]string{"code": grpc.Code(err).String(), "message": grpc.ErrorDesc(err)})
+}
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// TokenSubject returns the "sub" claim of the given JWT token or empty string if it cannot be parsed.
+// The token is not verified, so it must be used only after successful authorization.
+func TokenSubject(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
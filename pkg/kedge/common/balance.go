@@ -0,0 +1,189 @@
+package common
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+const (
+	ringHashReplicas = 100
+	// maglevTableSize needs to be a prime number much bigger than the number of targets.
+	maglevTableSize = 65537
+)
+
+// ConsistentHash maps keys to targets so that only a small fraction of keys changes its target when targets change.
+type ConsistentHash interface {
+	// Get returns index of the target for the given key. Targets for which available returns false are skipped
+	// (and the key falls back to another target). It returns -1 if no target is available.
+	Get(key string, available func(idx int) bool) int
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	// FNV does not spread similar (e.g. suffixed) strings well, so finish with the splitmix64 finalizer.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+type ringEntry struct {
+	hash uint64
+	idx  int
+}
+
+type ringHash struct {
+	ring []ringEntry
+}
+
+// NewRingHash returns ConsistentHash using a hash ring with multiple virtual nodes for each target.
+func NewRingHash(targets []string) ConsistentHash {
+	r := &ringHash{}
+	for i, t := range targets {
+		for j := 0; j < ringHashReplicas; j++ {
+			r.ring = append(r.ring, ringEntry{hash: hash64(t + "#" + strconv.Itoa(j)), idx: i})
+		}
+	}
+	sort.Slice(r.ring, func(i, j int) bool { return r.ring[i].hash < r.ring[j].hash })
+	return r
+}
+
+func (r *ringHash) Get(key string, available func(idx int) bool) int {
+	if len(r.ring) == 0 {
+		return -1
+	}
+	h := hash64(key)
+	start := sort.Search(len(r.ring), func(i int) bool { return r.ring[i].hash >= h })
+	for i := 0; i < len(r.ring); i++ {
+		e := r.ring[(start+i)%len(r.ring)]
+		if available(e.idx) {
+			return e.idx
+		}
+	}
+	return -1
+}
+
+type maglev struct {
+	table   []int
+	targets int
+}
+
+// NewMaglev returns ConsistentHash using Maglev lookup table (see https://research.google.com/pubs/pub44824.html).
+func NewMaglev(targets []string) ConsistentHash {
+	m := &maglev{targets: len(targets)}
+	if len(targets) == 0 {
+		return m
+	}
+
+	offsets := make([]uint64, len(targets))
+	skips := make([]uint64, len(targets))
+	for i, t := range targets {
+		offsets[i] = hash64(t) % maglevTableSize
+		skips[i] = hash64(t+"#skip")%(maglevTableSize-1) + 1
+	}
+
+	m.table = make([]int, maglevTableSize)
+	for i := range m.table {
+		m.table[i] = -1
+	}
+	next := make([]uint64, len(targets))
+	for filled := 0; ; {
+		for i := range targets {
+			c := (offsets[i] + next[i]*skips[i]) % maglevTableSize
+			for m.table[c] >= 0 {
+				next[i]++
+				c = (offsets[i] + next[i]*skips[i]) % maglevTableSize
+			}
+			m.table[c] = i
+			next[i]++
+			filled++
+			if filled == maglevTableSize {
+				return m
+			}
+		}
+	}
+}
+
+func (m *maglev) Get(key string, available func(idx int) bool) int {
+	if m.targets == 0 {
+		return -1
+	}
+	// Rehash the key until available target is found. Give up after some attempts and check all targets in order.
+	for attempt := 0; attempt < 2*m.targets; attempt++ {
+		k := key
+		if attempt > 0 {
+			k = key + "#" + strconv.Itoa(attempt)
+		}
+		if idx := m.table[hash64(k)%maglevTableSize]; available(idx) {
+			return idx
+		}
+	}
+	for idx := 0; idx < m.targets; idx++ {
+		if available(idx) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// PickLeastLoaded returns index of the target with the least load out of n targets. With powerOfTwo it compares only
+// two randomly chosen targets instead of all of them. Ties are broken randomly.
+func PickLeastLoaded(n int, load func(idx int) int64, powerOfTwo bool) int {
+	if n <= 1 {
+		return n - 1
+	}
+	if powerOfTwo {
+		a := rand.Intn(n)
+		b := rand.Intn(n - 1)
+		if b >= a {
+			b++
+		}
+		if load(b) < load(a) {
+			return b
+		}
+		return a
+	}
+
+	// Start at random offset, so ties are not always resolved to the first target.
+	offset := rand.Intn(n)
+	best := offset
+	for i := 1; i < n; i++ {
+		idx := (offset + i) % n
+		if load(idx) < load(best) {
+			best = idx
+		}
+	}
+	return best
+}
+
+// PickWeighted returns index of the target for the given sequence number out of n targets, so that targets are picked
+// in proportion to their weights. Targets with zero weight are treated as having weight 1. Consecutive sequence numbers
+// are spread over targets (instead of picking every target weight times in a row).
+func PickWeighted(n int, seq uint64, weight func(idx int) uint32) int {
+	if n <= 1 {
+		return n - 1
+	}
+	w := func(i int) uint64 {
+		if wi := weight(i); wi > 0 {
+			return uint64(wi)
+		}
+		return 1
+	}
+	var total uint64
+	for i := 0; i < n; i++ {
+		total += w(i)
+	}
+	// Fractional parts of seq * golden ratio are evenly spread over [0, 1), scale them to the total weight.
+	pos, _ := bits.Mul64(seq*0x9e3779b97f4a7c15, total)
+	for i := 0; i < n; i++ {
+		if pos < w(i) {
+			return i
+		}
+		pos -= w(i)
+	}
+	return n - 1
+}
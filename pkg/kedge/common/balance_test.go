@@ -0,0 +1,82 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConsistentHash(t *testing.T, newHash func([]string) ConsistentHash) {
+	targets := []string{"1.1.1.1:80", "1.1.1.2:80", "1.1.1.3:80", "1.1.1.4:80"}
+	all := func(int) bool { return true }
+	h := newHash(targets)
+
+	assignments := map[string]int{}
+	counts := make([]int, len(targets))
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		idx := h.Get(key, all)
+		assert.Equal(t, idx, h.Get(key, all), "same key should map to the same target")
+		assignments[key] = idx
+		counts[idx]++
+	}
+	for i, c := range counts {
+		assert.True(t, c > 100, "target %d got only %d keys", i, c)
+	}
+
+	// When a target is unavailable, only its keys should move.
+	moved := 0
+	for key, idx := range assignments {
+		newIdx := h.Get(key, func(i int) bool { return i != 1 })
+		assert.NotEqual(t, 1, newIdx)
+		if idx != 1 && newIdx != idx {
+			moved++
+		}
+	}
+	assert.Equal(t, 0, moved, "keys of available targets should not move")
+
+	assert.Equal(t, -1, h.Get("key", func(int) bool { return false }))
+	assert.Equal(t, -1, newHash(nil).Get("key", all))
+}
+
+func TestRingHash(t *testing.T) {
+	testConsistentHash(t, NewRingHash)
+}
+
+func TestMaglev(t *testing.T) {
+	testConsistentHash(t, NewMaglev)
+}
+
+func TestPickLeastLoaded(t *testing.T) {
+	loads := []int64{5, 3, 0, 7}
+	load := func(i int) int64 { return loads[i] }
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 2, PickLeastLoaded(len(loads), load, false))
+		assert.NotEqual(t, 3, PickLeastLoaded(len(loads), load, true), "most loaded target should never win with two choices")
+	}
+	assert.Equal(t, 0, PickLeastLoaded(1, load, true))
+	assert.Equal(t, -1, PickLeastLoaded(0, load, false))
+}
+
+func TestPickWeighted(t *testing.T) {
+	weights := []uint32{3, 1, 0}
+	weight := func(i int) uint32 { return weights[i] }
+	counts := make([]int, len(weights))
+	for seq := uint64(0); seq < 5000; seq++ {
+		counts[PickWeighted(len(weights), seq, weight)]++
+	}
+	assert.InDelta(t, 3000, counts[0], 30)
+	assert.InDelta(t, 1000, counts[1], 30)
+	assert.InDelta(t, 1000, counts[2], 30, "zero weight should be treated as 1")
+
+	inRow := 0
+	for seq := uint64(0); seq < 100; seq++ {
+		if PickWeighted(len(weights), seq, weight) == PickWeighted(len(weights), seq+1, weight) {
+			inRow++
+		}
+	}
+	assert.True(t, inRow < 60, "picks should be spread, got %d repeated picks", inRow)
+	assert.Equal(t, 0, PickWeighted(1, 7, weight))
+	assert.Equal(t, -1, PickWeighted(0, 7, weight))
+}
@@ -25,6 +25,14 @@ type Addresser interface {
 	Address(hostString string) (string, error)
 }
 
+// RuleAddresser is an Addresser that can also tell which adhoc rule allowed the address, for callers that need the
+// per-rule options (e.g. TLS) when dialing it.
+type RuleAddresser interface {
+	Addresser
+	// AddressWithRule works like Address and additionally returns the matching rule.
+	AddressWithRule(hostString string) (string, *pb.Adhoc, error)
+}
+
 type dynamic struct {
 	mu              sync.RWMutex
 	staticAddresser Addresser
@@ -42,6 +50,19 @@ func (d *dynamic) Address(hostString string) (string, error) {
 	return addresser.Address(hostString)
 }
 
+// AddressWithRule returns the address together with the matching rule. The rule is nil if the underlying addresser
+// does not implement RuleAddresser.
+func (d *dynamic) AddressWithRule(hostString string) (string, *pb.Adhoc, error) {
+	d.mu.RLock()
+	addresser := d.staticAddresser
+	d.mu.RUnlock()
+	if ruleAddresser, ok := addresser.(RuleAddresser); ok {
+		return ruleAddresser.AddressWithRule(hostString)
+	}
+	addr, err := addresser.Address(hostString)
+	return addr, nil, err
+}
+
 // Update sets addresser behaviour to the provided set of adhoc rules.
 func (d *dynamic) Update(add Addresser) {
 	d.mu.Lock()
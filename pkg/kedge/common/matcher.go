@@ -0,0 +1,63 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/pkg/errors"
+)
+
+// StringMatcher is a compiled pb.StringMatcher.
+type StringMatcher struct {
+	cnf *pb.StringMatcher
+	// regex is used for both wildcard and regex matchers.
+	regex *regexp.Regexp
+}
+
+// NewStringMatcher compiles given matcher config. Nil config gives a matcher that matches everything.
+func NewStringMatcher(cnf *pb.StringMatcher) (*StringMatcher, error) {
+	m := &StringMatcher{cnf: cnf}
+	var pattern string
+	switch {
+	case cnf.GetWildcard() != "":
+		parts := strings.Split(cnf.GetWildcard(), "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		pattern = strings.Join(parts, ".*")
+	case cnf.GetRegex() != "":
+		pattern = cnf.GetRegex()
+	default:
+		return m, nil
+	}
+
+	var err error
+	m.regex, err = regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compile matcher %v", cnf)
+	}
+	return m, nil
+}
+
+// IsEmpty returns true if the matcher was created from nil config and matches everything.
+func (m *StringMatcher) IsEmpty() bool {
+	return m.cnf.GetMatch() == nil
+}
+
+// Match returns true if the value matches.
+func (m *StringMatcher) Match(value string) bool {
+	if m.regex != nil {
+		return m.regex.MatchString(value)
+	}
+
+	switch match := m.cnf.GetMatch().(type) {
+	case *pb.StringMatcher_Exact:
+		return value == match.Exact
+	case *pb.StringMatcher_Prefix:
+		return strings.HasPrefix(value, match.Prefix)
+	case *pb.StringMatcher_Suffix:
+		return strings.HasSuffix(value, match.Suffix)
+	}
+	return true
+}
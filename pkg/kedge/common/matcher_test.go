@@ -0,0 +1,69 @@
+package common
+
+import (
+	"testing"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		cnf        *pb.StringMatcher
+		matches    []string
+		notMatches []string
+	}{
+		{
+			name:    "Nil",
+			matches: []string{"", "anything"},
+		},
+		{
+			name:       "Exact",
+			cnf:        &pb.StringMatcher{Match: &pb.StringMatcher_Exact{Exact: "a.example.com"}},
+			matches:    []string{"a.example.com"},
+			notMatches: []string{"b.a.example.com", "a.example.co"},
+		},
+		{
+			name:       "Prefix",
+			cnf:        &pb.StringMatcher{Match: &pb.StringMatcher_Prefix{Prefix: "/api/"}},
+			matches:    []string{"/api/", "/api/x"},
+			notMatches: []string{"/api", "/other/api/"},
+		},
+		{
+			name:       "Suffix",
+			cnf:        &pb.StringMatcher{Match: &pb.StringMatcher_Suffix{Suffix: ".svc.example.com"}},
+			matches:    []string{"a.svc.example.com"},
+			notMatches: []string{"a.svc.example.com.evil.org", "svc.example.com"},
+		},
+		{
+			name:       "Wildcard",
+			cnf:        &pb.StringMatcher{Match: &pb.StringMatcher_Wildcard{Wildcard: "api-*.example.com"}},
+			matches:    []string{"api-1.example.com", "api-.example.com"},
+			notMatches: []string{"api-1.exampleXcom", "web-1.example.com"},
+		},
+		{
+			name:       "Regex",
+			cnf:        &pb.StringMatcher{Match: &pb.StringMatcher_Regex{Regex: "[a-z]+-[0-9]+|other"}},
+			matches:    []string{"abc-123", "other"},
+			notMatches: []string{"abc-123x", "xother"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewStringMatcher(tc.cnf)
+			require.NoError(t, err)
+			for _, v := range tc.matches {
+				assert.True(t, m.Match(v), "%q should match", v)
+			}
+			for _, v := range tc.notMatches {
+				assert.False(t, m.Match(v), "%q should not match", v)
+			}
+		})
+	}
+}
+
+func TestStringMatcher_InvalidRegex(t *testing.T) {
+	_, err := NewStringMatcher(&pb.StringMatcher{Match: &pb.StringMatcher_Regex{Regex: "(unclosed"}})
+	assert.Error(t, err)
+}
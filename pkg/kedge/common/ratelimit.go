@@ -0,0 +1,85 @@
+package common
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+)
+
+const rateLimiterCleanupInterval = 1 * time.Minute
+
+// RateLimiter is a token bucket rate limiter keeping a separate bucket for each key.
+type RateLimiter struct {
+	ratePerSec float64
+	burst      float64
+
+	now func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewRateLimiter creates a RateLimiter from the given config.
+func NewRateLimiter(cnf *pb.RateLimit) *RateLimiter {
+	burst := float64(cnf.Burst)
+	if burst == 0 {
+		burst = math.Ceil(cnf.RequestsPerSecond)
+	}
+	return &RateLimiter{
+		ratePerSec:  cnf.RequestsPerSecond,
+		burst:       burst,
+		now:         time.Now,
+		buckets:     map[string]*tokenBucket{},
+		lastCleanup: time.Now(),
+	}
+}
+
+// RateLimiterKey identifies the rate limiter of a route by its name and config. Routers use it to keep limiters (and their
+// buckets) of routes with unchanged rate limit when routes are updated.
+func RateLimiterKey(routeName string, cnf *pb.RateLimit) string {
+	return routeName + "/" + proto.CompactTextString(cnf)
+}
+
+// Allow takes a token from the bucket of the given key. It returns false if the bucket is empty.
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) > rateLimiterCleanupInterval {
+		l.cleanup(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, lastRefill: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.lastRefill).Seconds()*l.ratePerSec)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// cleanup removes buckets that are full by now, since they are the same as new ones.
+func (l *RateLimiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.lastRefill).Seconds()*l.ratePerSec >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
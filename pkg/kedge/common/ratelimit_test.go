@@ -0,0 +1,37 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter(&pb.RateLimit{RequestsPerSecond: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"), "requests within burst should be allowed")
+	}
+	assert.False(t, l.Allow("a"), "request over burst should not be allowed")
+	assert.True(t, l.Allow("b"), "other keys should have separate buckets")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow("a"), "bucket should be refilled with one token after 0.5s")
+	assert.False(t, l.Allow("a"))
+
+	now = now.Add(2 * rateLimiterCleanupInterval)
+	assert.True(t, l.Allow("a"))
+	assert.Len(t, l.buckets, 1, "full buckets should be cleaned up")
+}
+
+func TestRateLimiter_DefaultBurst(t *testing.T) {
+	l := NewRateLimiter(&pb.RateLimit{RequestsPerSecond: 0.5})
+	l.now = func() time.Time { return time.Unix(0, 0) }
+
+	assert.True(t, l.Allow("a"))
+	assert.False(t, l.Allow("a"))
+}
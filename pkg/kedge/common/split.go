@@ -0,0 +1,58 @@
+package common
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+)
+
+// WeightedBackends picks one of multiple backends proportionally to their weights.
+type WeightedBackends struct {
+	names []string
+	// cumulativeWeights[i] is the sum of weights of backends [0, i].
+	cumulativeWeights []uint64
+}
+
+// NewWeightedBackends creates WeightedBackends from backend names and their corresponding weights.
+// If all weights are 0, all backends are picked with the same probability.
+func NewWeightedBackends(names []string, weights []uint32) *WeightedBackends {
+	var total uint64
+	for _, w := range weights {
+		total += uint64(w)
+	}
+
+	w := &WeightedBackends{names: names, cumulativeWeights: make([]uint64, len(names))}
+	var sum uint64
+	for i := range names {
+		if total == 0 {
+			sum++
+		} else {
+			sum += uint64(weights[i])
+		}
+		w.cumulativeWeights[i] = sum
+	}
+	return w
+}
+
+// Pick returns the name of the chosen backend. For the same non-empty stickyKey the same backend is returned as long
+// as backends and weights do not change. If stickyKey is empty, the backend is chosen randomly.
+func (w *WeightedBackends) Pick(stickyKey string) string {
+	if len(w.names) == 0 {
+		return ""
+	}
+
+	total := w.cumulativeWeights[len(w.cumulativeWeights)-1]
+	var point uint64
+	if stickyKey == "" {
+		point = uint64(rand.Int63n(int64(total)))
+	} else {
+		h := fnv.New64a()
+		h.Write([]byte(stickyKey))
+		point = h.Sum64() % total
+	}
+
+	i := sort.Search(len(w.cumulativeWeights), func(i int) bool {
+		return w.cumulativeWeights[i] > point
+	})
+	return w.names[i]
+}
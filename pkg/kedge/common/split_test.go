@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedBackends_Pick(t *testing.T) {
+	w := NewWeightedBackends([]string{"a", "b", "c"}, []uint32{90, 10, 0})
+
+	picked := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		picked[w.Pick("")]++
+	}
+	assert.Equal(t, 0, picked["c"], "backend with 0 weight should not be picked")
+	assert.InDelta(t, 9000, picked["a"], 500)
+	assert.InDelta(t, 1000, picked["b"], 500)
+}
+
+func TestWeightedBackends_PickSticky(t *testing.T) {
+	w := NewWeightedBackends([]string{"a", "b"}, []uint32{50, 50})
+
+	picked := map[string]int{}
+	for i := 0; i < 100; i++ {
+		picked[w.Pick("user-1")]++
+	}
+	assert.Len(t, picked, 1, "the same sticky key should always pick the same backend")
+}
+
+func TestWeightedBackends_PickAllZeroWeights(t *testing.T) {
+	w := NewWeightedBackends([]string{"a", "b"}, []uint32{0, 0})
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		picked[w.Pick("")]++
+	}
+	assert.Len(t, picked, 2, "with all weights 0, all backends should be picked")
+}
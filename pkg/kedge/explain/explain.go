@@ -0,0 +1,316 @@
+// Package explain shows how kedge would route a synthetic HTTP request or gRPC call, using the live routers, adhoc
+// rules and backend pools. It helps debugging "unknown route to service" errors.
+package explain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	grpc_bp "github.com/improbable-eng/kedge/pkg/kedge/grpc/backendpool"
+	grpc_router "github.com/improbable-eng/kedge/pkg/kedge/grpc/director/router"
+	"github.com/improbable-eng/kedge/pkg/kedge/http/director/proxyreq"
+	http_router "github.com/improbable-eng/kedge/pkg/kedge/http/director/router"
+	"github.com/improbable-eng/kedge/pkg/kedge/http/lbtransport"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// HTTPRouter is an HTTP router able to explain its decisions.
+type HTTPRouter interface {
+	Explain(req *http.Request) *http_router.Explanation
+}
+
+// GRPCRouter is a gRPC router able to explain its decisions.
+type GRPCRouter interface {
+	Explain(ctx context.Context, fullMethodName string) *grpc_router.Explanation
+}
+
+// HTTPPool is an HTTP backend pool able to list targets of its backends.
+type HTTPPool interface {
+	Targets(backendName string) ([]lbtransport.TargetStatus, error)
+}
+
+// GRPCPool is a gRPC backend pool able to list targets of its backends.
+type GRPCPool interface {
+	Targets(backendName string) ([]grpc_bp.TargetStatus, error)
+}
+
+// Auth describes the proxy auth that kedge requires for all requests and calls.
+type Auth struct {
+	Required    bool     `json:"required"`
+	Header      string   `json:"header,omitempty"`
+	Issuer      string   `json:"issuer,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Result is the explanation of routing a request or call.
+type Result struct {
+	Protocol string `json:"protocol"`
+	// SkippedRoutes are the routes checked before the matched one (or all routes), with the reason they do not match.
+	SkippedRoutes []SkippedRoute `json:"skipped_routes,omitempty"`
+	Route         string         `json:"route,omitempty"`
+	// RouteConfig is the matched route in JSONPB.
+	RouteConfig json.RawMessage `json:"route_config,omitempty"`
+	// Backend is the backend the request or call is sent to. If the route splits traffic, it is set only if the sticky
+	// key picks it.
+	Backend string `json:"backend,omitempty"`
+	// Split lists the weighted backends of the matched route, if it splits traffic between them.
+	Split     []SplitBackend `json:"split,omitempty"`
+	StickyKey string         `json:"sticky_key,omitempty"`
+	Targets   []Target       `json:"targets,omitempty"`
+	// Adhoc is set if no route matched and the adhoc rules were consulted.
+	Adhoc *Adhoc `json:"adhoc,omitempty"`
+	// Error is the error the request or call would fail with before being sent upstream, if any.
+	Error string `json:"error,omitempty"`
+	Auth  Auth   `json:"auth"`
+}
+
+// SkippedRoute is a route that does not match.
+type SkippedRoute struct {
+	Route  string `json:"route"`
+	Reason string `json:"reason"`
+}
+
+// SplitBackend is one of the weighted backends of a route with its targets.
+type SplitBackend struct {
+	Backend string   `json:"backend"`
+	Weight  uint32   `json:"weight"`
+	Targets []Target `json:"targets,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Target is a currently resolved target of the backend. Blacklisted is set for HTTP targets that recently failed to
+// dial, Connected for gRPC targets.
+type Target struct {
+	Address     string `json:"address"`
+	Blacklisted bool   `json:"blacklisted,omitempty"`
+	Connected   *bool  `json:"connected,omitempty"`
+}
+
+// Adhoc is the result of adhoc rules.
+type Adhoc struct {
+	Host    string `json:"host"`
+	Address string `json:"address,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Explainer explains routing of synthetic requests given as query parameters:
+//
+//	url, method (default GET), host (default host of url), header ("Name: value", repeated) and proxy_mode
+//	("forward", default, or "reverse") for HTTP,
+//	grpc_method (e.g. /pkg.Service/Method), authority and metadata ("key: value", repeated) for gRPC.
+type Explainer struct {
+	httpRouter HTTPRouter
+	httpAdhoc  common.Addresser
+	httpPool   HTTPPool
+	grpcRouter GRPCRouter
+	grpcAdhoc  common.Addresser
+	grpcPool   GRPCPool
+	auth       Auth
+}
+
+// New creates an Explainer using the given live routers, adhoc addressers and pools.
+func New(httpRouter HTTPRouter, httpAdhoc common.Addresser, httpPool HTTPPool, grpcRouter GRPCRouter,
+	grpcAdhoc common.Addresser, grpcPool GRPCPool, auth Auth) *Explainer {
+	return &Explainer{
+		httpRouter: httpRouter,
+		httpAdhoc:  httpAdhoc,
+		httpPool:   httpPool,
+		grpcRouter: grpcRouter,
+		grpcAdhoc:  grpcAdhoc,
+		grpcPool:   grpcPool,
+		auth:       auth,
+	}
+}
+
+func (e *Explainer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	var (
+		result *Result
+		err    error
+	)
+	if q.Get("grpc_method") != "" {
+		result, err = e.ExplainGRPC(q.Get("grpc_method"), q.Get("authority"), parsePairs(q["metadata"]))
+	} else {
+		result, err = e.ExplainHTTP(q.Get("method"), q.Get("url"), q.Get("host"), parsePairs(q["header"]), q.Get("proxy_mode"))
+	}
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(resp)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+}
+
+// parsePairs parses "key: value" pairs.
+func parsePairs(pairs []string) map[string][]string {
+	ret := map[string][]string{}
+	for _, p := range pairs {
+		kv := strings.SplitN(p, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		k := strings.TrimSpace(kv[0])
+		ret[k] = append(ret[k], strings.TrimSpace(kv[1]))
+	}
+	return ret
+}
+
+// ExplainHTTP explains routing of an HTTP request.
+func (e *Explainer) ExplainHTTP(method string, rawURL string, host string, headers map[string][]string, proxyMode string) (*Result, error) {
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" && host == "" {
+		return nil, errors.Errorf("url %q needs to be absolute or host needs to be given", rawURL)
+	}
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid request")
+	}
+	if host != "" {
+		req.Host = host
+	}
+	for k, vals := range headers {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
+	switch proxyMode {
+	case "", "forward":
+		req.RequestURI = u.String()
+	case "reverse":
+		req.RequestURI = u.RequestURI()
+	default:
+		return nil, errors.Errorf("unknown proxy_mode %q, expected forward or reverse", proxyMode)
+	}
+	// Same as the director does.
+	req = proxyreq.NormalizeInboundRequest(req)
+	req.URL.Host = req.Host
+
+	result := &Result{Protocol: "http", Auth: e.auth}
+	if result.Auth.Required {
+		result.Auth.Header = "Proxy-Authorization"
+	}
+	explanation := e.httpRouter.Explain(req)
+	for _, s := range explanation.Skipped {
+		result.SkippedRoutes = append(result.SkippedRoutes, SkippedRoute{Route: s.Name, Reason: s.Reason})
+	}
+	if explanation.Route == nil {
+		result.Adhoc = explainAdhoc(e.httpAdhoc, req.URL.Host)
+		if result.Adhoc.Error != "" {
+			result.Error = http_router.ErrRouteNotFound.Error()
+		}
+		return result, nil
+	}
+	result.Route = explanation.RouteName
+	result.RouteConfig = marshalRoute(explanation.Route)
+	result.Backend = explanation.BackendName
+	result.StickyKey = explanation.StickyKey
+	for _, b := range explanation.Split {
+		split := SplitBackend{Backend: b.Name, Weight: b.Weight}
+		split.Targets, split.Error = e.httpTargets(b.Name)
+		result.Split = append(result.Split, split)
+	}
+	if result.Backend != "" {
+		result.Targets, result.Error = e.httpTargets(result.Backend)
+	}
+	return result, nil
+}
+
+func (e *Explainer) httpTargets(backendName string) ([]Target, string) {
+	targets, err := e.httpPool.Targets(backendName)
+	if err != nil {
+		return nil, err.Error()
+	}
+	var ret []Target
+	for _, t := range targets {
+		ret = append(ret, Target{Address: t.DialAddr, Blacklisted: t.Blacklisted})
+	}
+	return ret, ""
+}
+
+// ExplainGRPC explains routing of a gRPC call.
+func (e *Explainer) ExplainGRPC(fullMethodName string, authority string, md map[string][]string) (*Result, error) {
+	if !strings.HasPrefix(fullMethodName, "/") {
+		fullMethodName = "/" + fullMethodName
+	}
+	incoming := metadata.MD{}
+	for k, vals := range md {
+		incoming[strings.ToLower(k)] = vals
+	}
+	if authority != "" {
+		incoming[":authority"] = []string{authority}
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), incoming)
+
+	result := &Result{Protocol: "grpc", Auth: e.auth}
+	if result.Auth.Required {
+		result.Auth.Header = "proxy-authorization"
+	}
+	explanation := e.grpcRouter.Explain(ctx, fullMethodName)
+	for _, s := range explanation.Skipped {
+		result.SkippedRoutes = append(result.SkippedRoutes, SkippedRoute{Route: s.Name, Reason: s.Reason})
+	}
+	if explanation.Route == nil {
+		result.Adhoc = explainAdhoc(e.grpcAdhoc, authority)
+		if result.Adhoc.Error != "" {
+			result.Error = grpc_router.ErrRouteNotFound.Error()
+		}
+		return result, nil
+	}
+	result.Route = explanation.RouteName
+	result.RouteConfig = marshalRoute(explanation.Route)
+	result.Backend = explanation.BackendName
+	result.StickyKey = explanation.StickyKey
+	for _, b := range explanation.Split {
+		split := SplitBackend{Backend: b.Name, Weight: b.Weight}
+		split.Targets, split.Error = e.grpcTargets(b.Name)
+		result.Split = append(result.Split, split)
+	}
+	if result.Backend != "" {
+		result.Targets, result.Error = e.grpcTargets(result.Backend)
+	}
+	return result, nil
+}
+
+func (e *Explainer) grpcTargets(backendName string) ([]Target, string) {
+	targets, err := e.grpcPool.Targets(backendName)
+	if err != nil {
+		return nil, err.Error()
+	}
+	var ret []Target
+	for _, t := range targets {
+		connected := t.Connected
+		ret = append(ret, Target{Address: t.Addr, Connected: &connected})
+	}
+	return ret, ""
+}
+
+func explainAdhoc(addresser common.Addresser, host string) *Adhoc {
+	adhoc := &Adhoc{Host: host}
+	addr, err := addresser.Address(host)
+	if err != nil {
+		adhoc.Error = err.Error()
+	}
+	adhoc.Address = addr
+	return adhoc
+}
+
+func marshalRoute(route proto.Message) json.RawMessage {
+	value, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(route)
+	if err != nil {
+		return nil
+	}
+	return json.RawMessage(value)
+}
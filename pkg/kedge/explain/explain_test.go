@@ -0,0 +1,223 @@
+package explain
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	grpc_bp "github.com/improbable-eng/kedge/pkg/kedge/grpc/backendpool"
+	grpc_router "github.com/improbable-eng/kedge/pkg/kedge/grpc/director/router"
+	http_router "github.com/improbable-eng/kedge/pkg/kedge/http/director/router"
+	"github.com/improbable-eng/kedge/pkg/kedge/http/lbtransport"
+	pb_grpcroutes "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	pb_httproutes "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAddresser map[string]string
+
+func (a fakeAddresser) Address(host string) (string, error) {
+	addr, ok := a[host]
+	if !ok {
+		return "", errors.New("no adhoc rule")
+	}
+	return addr, nil
+}
+
+type fakeHTTPPool map[string][]lbtransport.TargetStatus
+
+func (p fakeHTTPPool) Targets(backendName string) ([]lbtransport.TargetStatus, error) {
+	targets, ok := p[backendName]
+	if !ok {
+		return nil, errors.New("unknown backend")
+	}
+	return targets, nil
+}
+
+type fakeGRPCPool map[string][]grpc_bp.TargetStatus
+
+func (p fakeGRPCPool) Targets(backendName string) ([]grpc_bp.TargetStatus, error) {
+	targets, ok := p[backendName]
+	if !ok {
+		return nil, errors.New("unknown backend")
+	}
+	return targets, nil
+}
+
+func newTestExplainer() *Explainer {
+	httpRouter := http_router.NewStatic([]*pb_httproutes.Route{
+		{Name: "reverse", BackendName: "backend_reverse", HostMatcher: "a.example.com", ProxyMode: pb_httproutes.ProxyMode_REVERSE_PROXY},
+		{Name: "forward", BackendName: "backend_forward", HostMatcher: "a.example.com", PathRules: []string{"/api/*"}},
+		{Name: "headers", BackendName: "backend_missing", HostMatcher: "a.example.com", HeaderMatcher: map[string]string{"X-Canary": "yes"}},
+	})
+	grpcRouter := grpc_router.NewStatic(logrus.New(), []*pb_grpcroutes.Route{
+		{Name: "a", BackendName: "backend_a", ServiceNameMatcher: "com.example.*", AuthorityHostMatcher: "a.example.com"},
+	})
+	return New(
+		httpRouter,
+		fakeAddresser{"pod.cluster.local": "10.0.0.1:80"},
+		fakeHTTPPool{"backend_forward": {{DialAddr: "10.0.0.2:80"}, {DialAddr: "10.0.0.3:80", Blacklisted: true}}},
+		grpcRouter,
+		fakeAddresser{},
+		fakeGRPCPool{"backend_a": {{Addr: "10.0.1.1:443", Connected: true}}},
+		Auth{Required: true, Issuer: "https://issuer.example.com"},
+	)
+}
+
+func TestExplainHTTP(t *testing.T) {
+	e := newTestExplainer()
+
+	result, err := e.ExplainHTTP("", "http://a.example.com/api/x", "", nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, []SkippedRoute{{Route: "reverse", Reason: "request is not in proxy_mode REVERSE_PROXY"}}, result.SkippedRoutes)
+	assert.Equal(t, "forward", result.Route)
+	assert.Equal(t, "backend_forward", result.Backend)
+	assert.Equal(t, []Target{{Address: "10.0.0.2:80"}, {Address: "10.0.0.3:80", Blacklisted: true}}, result.Targets)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "Proxy-Authorization", result.Auth.Header)
+
+	result, err = e.ExplainHTTP("", "/other", "a.example.com", nil, "reverse")
+	require.NoError(t, err)
+	assert.Equal(t, "reverse", result.Route)
+	assert.Equal(t, "unknown backend", result.Error)
+
+	result, err = e.ExplainHTTP("POST", "http://a.example.com/other", "", map[string][]string{"X-Canary": {"yes"}}, "forward")
+	require.NoError(t, err)
+	assert.Equal(t, "headers", result.Route)
+	assert.Len(t, result.SkippedRoutes, 2)
+
+	result, err = e.ExplainHTTP("", "http://pod.cluster.local/", "", nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Route)
+	assert.Len(t, result.SkippedRoutes, 3)
+	assert.Equal(t, &Adhoc{Host: "pod.cluster.local", Address: "10.0.0.1:80"}, result.Adhoc)
+	assert.Empty(t, result.Error)
+
+	result, err = e.ExplainHTTP("", "http://unknown.example.com/", "", nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "no adhoc rule", result.Adhoc.Error)
+	assert.Equal(t, http_router.ErrRouteNotFound.Error(), result.Error)
+
+	_, err = e.ExplainHTTP("", "/relative", "", nil, "")
+	assert.Error(t, err, "host is required")
+	_, err = e.ExplainHTTP("", "http://a.example.com/", "", nil, "sideways")
+	assert.Error(t, err)
+}
+
+func TestExplainGRPC(t *testing.T) {
+	e := newTestExplainer()
+
+	result, err := e.ExplainGRPC("com.example.Service/Method", "a.example.com", map[string][]string{"X-Key": {"value"}})
+	require.NoError(t, err)
+	assert.Empty(t, result.SkippedRoutes)
+	assert.Equal(t, "a", result.Route)
+	assert.Equal(t, "backend_a", result.Backend)
+	connected := true
+	assert.Equal(t, []Target{{Address: "10.0.1.1:443", Connected: &connected}}, result.Targets)
+	assert.Equal(t, "proxy-authorization", result.Auth.Header)
+
+	result, err = e.ExplainGRPC("/com.example.Service/Method", "b.example.com", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SkippedRoute{{Route: "a", Reason: `authority "b.example.com" does not match authority_host_matcher`}}, result.SkippedRoutes)
+	assert.Equal(t, "b.example.com", result.Adhoc.Host)
+	assert.Equal(t, grpc_router.ErrRouteNotFound.Error(), result.Error)
+}
+
+func TestServeHTTP(t *testing.T) {
+	e := newTestExplainer()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/explain?url=http://a.example.com/api/x&header=X-Canary:%20yes", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	result := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "forward", result["route"])
+	assert.Equal(t, "backend_forward", result["route_config"].(map[string]interface{})["backend_name"])
+	assert.Equal(t, true, result["auth"].(map[string]interface{})["required"])
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/explain?grpc_method=/com.example.Service/Method&authority=a.example.com", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	result = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "grpc", result["protocol"])
+	assert.Equal(t, "backend_a", result["backend"])
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/explain?url=/relative", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExplainWeightedSplit(t *testing.T) {
+	httpRouter := http_router.NewStatic([]*pb_httproutes.Route{
+		{
+			Name:        "split",
+			HostMatcher: "split.example.com",
+			WeightedBackends: []*pb_httproutes.WeightedBackend{
+				{BackendName: "backend_stable", Weight: 90},
+				{BackendName: "backend_canary", Weight: 10},
+			},
+			StickySplit: &pb_httproutes.StickySplit{Key: &pb_httproutes.StickySplit_Header{Header: "X-User"}},
+		},
+	})
+	grpcRouter := grpc_router.NewStatic(logrus.New(), []*pb_grpcroutes.Route{
+		{
+			Name:               "split",
+			ServiceNameMatcher: "com.example.*",
+			WeightedBackends: []*pb_grpcroutes.WeightedBackend{
+				{BackendName: "backend_stable", Weight: 3},
+				{BackendName: "backend_canary", Weight: 1},
+			},
+			StickyMetadataKey: "x-user",
+		},
+	})
+	e := New(
+		httpRouter,
+		fakeAddresser{},
+		fakeHTTPPool{"backend_stable": {{DialAddr: "10.0.0.1:80"}}, "backend_canary": {{DialAddr: "10.0.0.2:80"}}},
+		grpcRouter,
+		fakeAddresser{},
+		fakeGRPCPool{"backend_stable": {{Addr: "10.0.1.1:443", Connected: true}}},
+		Auth{},
+	)
+
+	result, err := e.ExplainHTTP("", "http://split.example.com/", "", nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Backend, "backend is picked randomly without sticky key")
+	assert.Empty(t, result.Targets)
+	assert.Empty(t, result.StickyKey)
+	assert.Equal(t, []SplitBackend{
+		{Backend: "backend_stable", Weight: 90, Targets: []Target{{Address: "10.0.0.1:80"}}},
+		{Backend: "backend_canary", Weight: 10, Targets: []Target{{Address: "10.0.0.2:80"}}},
+	}, result.Split)
+
+	result, err = e.ExplainHTTP("", "http://split.example.com/", "", map[string][]string{"X-User": {"user1"}}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", result.StickyKey)
+	require.NotEmpty(t, result.Backend, "sticky key should pick the backend")
+	assert.Len(t, result.Split, 2)
+	assert.Len(t, result.Targets, 1)
+	for i := 0; i < 10; i++ {
+		again, err := e.ExplainHTTP("", "http://split.example.com/", "", map[string][]string{"X-User": {"user1"}}, "")
+		require.NoError(t, err)
+		assert.Equal(t, result.Backend, again.Backend)
+	}
+
+	result, err = e.ExplainGRPC("/com.example.Service/Method", "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Backend)
+	connected := true
+	assert.Equal(t, []SplitBackend{
+		{Backend: "backend_stable", Weight: 3, Targets: []Target{{Address: "10.0.1.1:443", Connected: &connected}}},
+		{Backend: "backend_canary", Weight: 1, Error: "unknown backend"},
+	}, result.Split)
+
+	result, err = e.ExplainGRPC("/com.example.Service/Method", "", map[string][]string{"X-User": {"user1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "user1", result.StickyKey)
+	assert.NotEmpty(t, result.Backend)
+}
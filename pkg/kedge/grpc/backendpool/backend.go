@@ -7,12 +7,16 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
-	"github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/improbable-eng/kedge/pkg/resolvers/health"
 	"github.com/improbable-eng/kedge/pkg/resolvers/host"
 	"github.com/improbable-eng/kedge/pkg/resolvers/k8s"
 	"github.com/improbable-eng/kedge/pkg/resolvers/srv"
+	"github.com/improbable-eng/kedge/pkg/tls"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/mwitkow/grpc-proxy/proxy"
@@ -21,7 +25,6 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/naming"
 )
 
 var (
@@ -38,7 +41,14 @@ type backend struct {
 	closed bool
 
 	target   string
-	resolver naming.Resolver
+	resolver resolvers.Resolver
+
+	// tlsConfig is nil for insecure (plain text) backends.
+	tlsConfig *tls.Config
+	// tlsServerConfig is the TlsServerConfig referenced by config (if any). Used for diffing.
+	tlsServerConfig *pb_config.TlsServerConfig
+	// balancer is nil until the connection is dialled.
+	balancer *addrTagBalancer
 }
 
 func (b *backend) Conn() (*grpc.ClientConn, error) {
@@ -58,21 +68,33 @@ func (b *backend) Conn() (*grpc.ClientConn, error) {
 	if b.closed {
 		return nil, grpc.Errorf(codes.Internal, "backend already closed")
 	}
-	target, resolver, err := chooseNamingResolver(b.config)
+	target, resolver, err := chooseResolver(b.config)
 	if err != nil {
 		return nil, err
 	}
 	b.target = target
 	b.resolver = resolver
 
-	cc, err = buildClientConn(b.config, target, resolver)
+	cc, balancer, err := buildClientConn(b.config, b.tlsConfig, target, resolver)
 	if err != nil {
 		return nil, err
 	}
 	b.conn = cc
+	b.balancer = balancer
 	return cc, nil
 }
 
+// Targets returns the addresses currently resolved for the backend. It is used for debugging.
+func (b *backend) Targets() []TargetStatus {
+	b.mu.RLock()
+	balancer := b.balancer
+	b.mu.RUnlock()
+	if balancer == nil {
+		return nil
+	}
+	return balancer.targets()
+}
+
 func (b *backend) Close() error {
 	b.mu.Lock()
 	b.closed = true
@@ -83,31 +105,119 @@ func (b *backend) Close() error {
 	return nil
 }
 
-func newBackend(cnf *pb.Backend) (*backend, error) {
+// newBackend creates backend from given configuration.
+// tlsServerConfigs are named TLS configs that backend's security settings can refer to.
+func newBackend(cnf *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*backend, error) {
 	b := &backend{
-		config: cnf,
+		config:          cnf,
+		tlsServerConfig: kedge_tls.FindTLSServerConfig(cnf.GetSecurity().GetConfigName(), tlsServerConfigs),
 	}
-	target, resolver, err := chooseNamingResolver(cnf)
+	target, resolver, err := chooseResolver(cnf)
 	if err != nil {
 		return nil, err
 	}
 	b.target = target
 	b.resolver = resolver
 
-	cc, err := buildClientConn(cnf, target, resolver)
+	if sec := cnf.GetSecurity(); sec != nil {
+		b.tlsConfig, err = kedge_tls.BuildBackendTLSConfig(sec, tlsServerConfigs)
+		if err != nil {
+			return nil, fmt.Errorf("backend '%v' TLS config error: %v", cnf.Name, err)
+		}
+	}
+
+	cc, balancer, err := buildClientConn(cnf, b.tlsConfig, target, resolver)
 	if err != nil && err.Error() == "grpc: there is no address available to dial" {
 		return b, nil // make this lazy
 	} else if err != nil {
 		return nil, fmt.Errorf("backend '%v' dial error: %v", cnf.Name, err)
 	}
 	b.conn = cc
+	b.balancer = balancer
 	return b, nil
 }
 
+// TargetStatus is an address currently resolved for a backend.
+type TargetStatus struct {
+	Addr      string
+	Connected bool
+}
+
 // addrTagBalancer is a hacky way (and only one) to add gRPC tag with the actual IP:port address that was chosen by internal
-// gRPC balancer (using our resolver) for given RPC. Tag is required for logging.
+// gRPC balancer (using our resolver) for given RPC. Tag is required for logging, the address is also reported in latency
+// metrics. It also records the addresses notified to gRPC and their connectivity for debugging.
 type addrTagBalancer struct {
 	grpc.Balancer
+
+	notifyOnce sync.Once
+	notifyCh   chan []grpc.Address
+
+	mu        sync.Mutex
+	addrs     []grpc.Address
+	connected map[grpc.Address]bool
+}
+
+func (b *addrTagBalancer) Notify() <-chan []grpc.Address {
+	b.notifyOnce.Do(func() {
+		inner := b.Balancer.Notify()
+		if inner == nil {
+			return
+		}
+		b.notifyCh = make(chan []grpc.Address)
+		go func() {
+			defer close(b.notifyCh)
+			for addrs := range inner {
+				b.setAddrs(addrs)
+				b.notifyCh <- addrs
+			}
+		}()
+	})
+	return b.notifyCh
+}
+
+func (b *addrTagBalancer) Up(addr grpc.Address) func(error) {
+	down := b.Balancer.Up(addr)
+	b.setConnected(addr, true)
+	return func(err error) {
+		b.setConnected(addr, false)
+		if down != nil {
+			down(err)
+		}
+	}
+}
+
+func (b *addrTagBalancer) setAddrs(addrs []grpc.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addrs = addrs
+	for a := range b.connected {
+		found := false
+		for _, addr := range addrs {
+			found = found || addr == a
+		}
+		if !found {
+			delete(b.connected, a)
+		}
+	}
+}
+
+func (b *addrTagBalancer) setConnected(addr grpc.Address, connected bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.connected == nil {
+		b.connected = map[grpc.Address]bool{}
+	}
+	b.connected[addr] = connected
+}
+
+func (b *addrTagBalancer) targets() []TargetStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var statuses []TargetStatus
+	for _, a := range b.addrs {
+		statuses = append(statuses, TargetStatus{Addr: a.Addr, Connected: b.connected[a]})
+	}
+	return statuses
 }
 
 func (b *addrTagBalancer) Get(ctx context.Context, opts grpc.BalancerGetOptions) (grpc.Address, func(), error) {
@@ -118,17 +228,37 @@ func (b *addrTagBalancer) Get(ctx context.Context, opts grpc.BalancerGetOptions)
 
 	// Retrieve resolved IP that will be used for this call. All retries will have separate log line with resolved IP.
 	grpc_ctxtags.Extract(ctx).Set("grpc.target.address", addr.Addr)
+	metrics.ProxyRequestFromContext(ctx).SetTarget(addr.Addr)
 	return addr, put, err
 }
 
-func buildClientConn(cnf *pb.Backend, target string, resolver naming.Resolver) (*grpc.ClientConn, error) {
+func buildClientConn(cnf *pb.Backend, tlsConfig *tls.Config, target string, resolver resolvers.Resolver) (*grpc.ClientConn, *addrTagBalancer, error) {
+	if hc := cnf.GetHealthCheck(); hc != nil {
+		if hc.GetGrpc() == nil {
+			return nil, nil, fmt.Errorf("backend '%v': only grpc health check is supported for gRPC backends", cnf.Name)
+		}
+		// Probes dial without conntrack, so health checks are not counted as connections of the backend.
+		probe := healthresolver.NewGRPCProbe(hc.GetGrpc(), grpc.WithDialer(func(addr string, t time.Duration) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), t)
+			defer cancel()
+			return ParentDialFunc(ctx, "tcp", addr)
+		}), chooseSecurityOpt(tlsConfig))
+		resolver = healthresolver.New(cnf.Name, resolver, probe, healthresolver.OptionsFromConfig(hc))
+	}
+
 	var opts []grpc.DialOption
 	opts = append(opts, chooseDialFuncOpt(cnf))
-	opts = append(opts, chooseSecurityOpt(cnf))
+	opts = append(opts, chooseSecurityOpt(tlsConfig))
 	opts = append(opts, grpc.WithCodec(proxy.Codec())) // needed for the director to function at all.
-	opts = append(opts, chooseInterceptors(cnf)...)
-	opts = append(opts, grpc.WithBalancer(&addrTagBalancer{chooseBalancerPolicy(cnf, resolver)}))
-	return grpc.Dial(target, opts...)
+	interceptorOpts, err := chooseInterceptors(cnf)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, interceptorOpts...)
+	balancer := &addrTagBalancer{Balancer: chooseBalancerPolicy(cnf, resolver)}
+	opts = append(opts, grpc.WithBalancer(balancer))
+	cc, err := grpc.Dial(target, opts...)
+	return cc, balancer, err
 }
 
 func chooseDialFuncOpt(cnf *pb.Backend) grpc.DialOption {
@@ -149,39 +279,15 @@ func chooseDialFuncOpt(cnf *pb.Backend) grpc.DialOption {
 	})
 }
 
-func chooseSecurityOpt(cnf *pb.Backend) grpc.DialOption {
-	if sec := cnf.GetSecurity(); sec != nil {
-		tlsConfig := &tls.Config{InsecureSkipVerify: true}
-		if !sec.InsecureSkipVerify {
-			// TODO(mwitkow): add configuration TlsConfig fetching by name here.
-			panic("Not implemented") // Ugly but this matters.
-		}
+func chooseSecurityOpt(tlsConfig *tls.Config) grpc.DialOption {
+	if tlsConfig != nil {
 		return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
 	} else {
 		return grpc.WithInsecure()
 	}
 }
 
-func chooseInterceptors(cnf *pb.Backend) []grpc.DialOption {
-	var (
-		unary  []grpc.UnaryClientInterceptor
-		stream []grpc.StreamClientInterceptor
-	)
-
-	for _, i := range cnf.GetInterceptors() {
-		if prom := i.GetPrometheus(); prom {
-			unary = append(unary, grpc_prometheus.UnaryClientInterceptor)
-			stream = append(stream, grpc_prometheus.StreamClientInterceptor)
-		}
-		// new interceptors are to be added here as else if statements.
-	}
-	return []grpc.DialOption{
-		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(unary...)),
-		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(stream...)),
-	}
-}
-
-func chooseNamingResolver(cnf *pb.Backend) (string, naming.Resolver, error) {
+func chooseResolver(cnf *pb.Backend) (string, resolvers.Resolver, error) {
 	if s := cnf.GetSrv(); s != nil {
 		return srvresolver.NewFromConfig(s)
 	}
@@ -192,15 +298,25 @@ func chooseNamingResolver(cnf *pb.Backend) (string, naming.Resolver, error) {
 	if k := cnf.GetHost(); k != nil {
 		return hostresolver.NewFromConfig(k)
 	}
-	return "", nil, fmt.Errorf("unspecified naming resolver for %v", cnf.Name)
+	return "", nil, fmt.Errorf("unspecified resolver for %v", cnf.Name)
 }
 
-func chooseBalancerPolicy(cnf *pb.Backend, resolver naming.Resolver) grpc.Balancer {
+func chooseBalancerPolicy(cnf *pb.Backend, resolver resolvers.Resolver) grpc.Balancer {
 	switch cnf.GetBalancer() {
 	case pb.Balancer_ROUND_ROBIN:
-		return grpc.RoundRobin(resolver)
+		return newRoundRobinBalancer(resolver)
+	case pb.Balancer_LEAST_REQUEST:
+		return newLeastRequestBalancer(resolver, false)
+	case pb.Balancer_POWER_OF_TWO_CHOICES:
+		return newLeastRequestBalancer(resolver, true)
+	case pb.Balancer_RING_HASH:
+		return newHashBalancer(resolver, cnf.GetHashPolicy().GetMetadataKey(), common.NewRingHash)
+	case pb.Balancer_MAGLEV:
+		return newHashBalancer(resolver, cnf.GetHashPolicy().GetMetadataKey(), common.NewMaglev)
+	case pb.Balancer_WEIGHTED_ROUND_ROBIN:
+		return newWeightedRoundRobinBalancer(resolver)
 	default:
-		return grpc.RoundRobin(resolver)
+		return newRoundRobinBalancer(resolver)
 	}
 }
 
@@ -214,11 +330,14 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 		return
 	}
 
-	var updates []*naming.Update
+	var (
+		mu    sync.Mutex
+		addrs []resolvers.Address
+	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	go func() {
 		for ctx.Err() == nil {
-			u, err := watcher.Next()
+			a, err := watcher.Next()
 			if err != nil {
 				if ctx.Err() != nil {
 					cancel()
@@ -228,7 +347,9 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 				continue
 			}
 
-			updates = append(updates, u...)
+			mu.Lock()
+			addrs = a
+			mu.Unlock()
 		}
 
 	}()
@@ -241,15 +362,7 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 	cancel()
 	watcher.Close()
 
-	addresses := map[string]struct{}{}
-	for _, u := range updates {
-		if u.Op == naming.Add {
-			addresses[u.Addr] = struct{}{}
-		}
-		if u.Op == naming.Delete {
-			delete(addresses, u.Addr)
-		}
-	}
-
-	logger.Infof("Resolved Addresses: %v", addresses)
+	mu.Lock()
+	defer mu.Unlock()
+	logger.Infof("Resolved Addresses: %+v", addrs)
 }
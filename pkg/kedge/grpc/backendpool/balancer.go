@@ -0,0 +1,298 @@
+package backendpool
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type balancedAddr struct {
+	addr        grpc.Address
+	connected   bool
+	outstanding int64
+}
+
+// pickFunc chooses one of the connected addresses for the RPC. It is called under balancer lock.
+type pickFunc func(ctx context.Context, connected []*balancedAddr) *balancedAddr
+
+// pickingBalancer is a grpc.Balancer that works like grpc.RoundRobin, but uses resolvers.Resolver directly and chooses
+// the address for every RPC using given pickFunc. It keeps the number of outstanding RPCs for every address. Only ready
+// addresses are used; Metadata of every address is its resolvers.Address.
+type pickingBalancer struct {
+	r    resolvers.Resolver
+	pick pickFunc
+
+	mu     sync.Mutex
+	w      resolvers.Watcher
+	addrs  []*balancedAddr
+	addrCh chan []grpc.Address
+	waitCh chan struct{}
+	next   int
+	done   bool
+}
+
+func newRoundRobinBalancer(r resolvers.Resolver) grpc.Balancer {
+	b := &pickingBalancer{r: r}
+	b.pick = func(_ context.Context, connected []*balancedAddr) *balancedAddr {
+		return b.roundRobin(connected)
+	}
+	return b
+}
+
+// newWeightedRoundRobinBalancer returns balancer picking addresses in proportion to their resolved weights.
+func newWeightedRoundRobinBalancer(r resolvers.Resolver) grpc.Balancer {
+	b := &pickingBalancer{r: r}
+	var seq uint64
+	b.pick = func(_ context.Context, connected []*balancedAddr) *balancedAddr {
+		seq++
+		return connected[common.PickWeighted(len(connected), seq, func(i int) uint32 {
+			a, _ := connected[i].addr.Metadata.(resolvers.Address)
+			return a.Weight
+		})]
+	}
+	return b
+}
+
+func newLeastRequestBalancer(r resolvers.Resolver, powerOfTwo bool) grpc.Balancer {
+	return &pickingBalancer{
+		r: r,
+		pick: func(_ context.Context, connected []*balancedAddr) *balancedAddr {
+			return connected[common.PickLeastLoaded(len(connected), func(i int) int64 {
+				return connected[i].outstanding
+			}, powerOfTwo)]
+		},
+	}
+}
+
+// newHashBalancer returns balancer picking address using consistent hashing of the given metadata value.
+// RPCs without the metadata are balanced in round robin manner.
+func newHashBalancer(r resolvers.Resolver, metadataKey string, newHash func(targets []string) common.ConsistentHash) grpc.Balancer {
+	b := &pickingBalancer{r: r}
+
+	var (
+		hashedAddrs string
+		hash        common.ConsistentHash
+	)
+	b.pick = func(ctx context.Context, connected []*balancedAddr) *balancedAddr {
+		key := ""
+		if metadataKey != "" {
+			key = metautils.ExtractOutgoing(ctx).Get(metadataKey)
+		}
+		if key == "" {
+			return b.roundRobin(connected)
+		}
+
+		// Hash all addresses (not only connected ones), so keys do not move when connection is temporarily down.
+		addrs := make([]string, len(b.addrs))
+		for i, a := range b.addrs {
+			addrs[i] = a.addr.Addr
+		}
+		if joined := strings.Join(addrs, ","); hash == nil || joined != hashedAddrs {
+			hash = newHash(addrs)
+			hashedAddrs = joined
+		}
+		idx := hash.Get(key, func(i int) bool { return b.addrs[i].connected })
+		if idx < 0 {
+			return b.roundRobin(connected)
+		}
+		return b.addrs[idx]
+	}
+	return b
+}
+
+func (b *pickingBalancer) roundRobin(connected []*balancedAddr) *balancedAddr {
+	b.next = (b.next + 1) % len(connected)
+	return connected[b.next]
+}
+
+func (b *pickingBalancer) Start(target string, _ grpc.BalancerConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return grpc.ErrClientConnClosing
+	}
+
+	w, err := b.r.Resolve(target)
+	if err != nil {
+		return err
+	}
+	b.w = w
+	b.addrCh = make(chan []grpc.Address, 1)
+	go func() {
+		for {
+			if err := b.watchAddrUpdates(); err != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *pickingBalancer) watchAddrUpdates() error {
+	resolved, err := b.w.Next()
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Keep the state of addresses that are still resolved with the same attributes.
+	existing := make(map[grpc.Address]*balancedAddr, len(b.addrs))
+	for _, a := range b.addrs {
+		existing[a.addr] = a
+	}
+	var addrs []*balancedAddr
+	for _, r := range resolvers.Ready(resolved) {
+		addr := grpc.Address{Addr: r.Addr, Metadata: r}
+		a, ok := existing[addr]
+		if !ok {
+			if containsAddr(addrs, addr) {
+				continue
+			}
+			a = &balancedAddr{addr: addr}
+		}
+		delete(existing, addr)
+		addrs = append(addrs, a)
+	}
+	b.addrs = addrs
+	if b.done {
+		return grpc.ErrClientConnClosing
+	}
+
+	open := make([]grpc.Address, len(b.addrs))
+	for i, a := range b.addrs {
+		open[i] = a.addr
+	}
+	// Notify gRPC internals about the newest addresses only.
+	select {
+	case <-b.addrCh:
+	default:
+	}
+	b.addrCh <- open
+	return nil
+}
+
+func containsAddr(addrs []*balancedAddr, addr grpc.Address) bool {
+	for _, a := range addrs {
+		if a.addr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *pickingBalancer) Up(addr grpc.Address) func(error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	connected := 0
+	for _, a := range b.addrs {
+		if a.addr == addr {
+			if a.connected {
+				return nil
+			}
+			a.connected = true
+		}
+		if a.connected {
+			connected++
+		}
+	}
+	// First connected address. Notify blocked Get calls.
+	if connected == 1 && b.waitCh != nil {
+		close(b.waitCh)
+		b.waitCh = nil
+	}
+	return func(error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, a := range b.addrs {
+			if a.addr == addr {
+				a.connected = false
+				break
+			}
+		}
+	}
+}
+
+func (b *pickingBalancer) Get(ctx context.Context, opts grpc.BalancerGetOptions) (grpc.Address, func(), error) {
+	for {
+		b.mu.Lock()
+		if b.done {
+			b.mu.Unlock()
+			return grpc.Address{}, nil, grpc.ErrClientConnClosing
+		}
+
+		var connected []*balancedAddr
+		for _, a := range b.addrs {
+			if a.connected {
+				connected = append(connected, a)
+			}
+		}
+		if len(connected) > 0 {
+			a := b.pick(ctx, connected)
+			a.outstanding++
+			b.mu.Unlock()
+
+			var once sync.Once
+			return a.addr, func() {
+				once.Do(func() {
+					b.mu.Lock()
+					a.outstanding--
+					b.mu.Unlock()
+				})
+			}, nil
+		}
+
+		if !opts.BlockingWait {
+			defer b.mu.Unlock()
+			if len(b.addrs) == 0 {
+				return grpc.Address{}, nil, grpc.Errorf(codes.Unavailable, "there is no address available")
+			}
+			// For fail-fast RPCs return any address gRPC is connecting to.
+			b.next = (b.next + 1) % len(b.addrs)
+			return b.addrs[b.next].addr, nil, nil
+		}
+
+		if b.waitCh == nil {
+			b.waitCh = make(chan struct{})
+		}
+		ch := b.waitCh
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return grpc.Address{}, nil, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (b *pickingBalancer) Notify() <-chan []grpc.Address {
+	return b.addrCh
+}
+
+func (b *pickingBalancer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return nil
+	}
+	b.done = true
+	if b.w != nil {
+		b.w.Close()
+	}
+	if b.waitCh != nil {
+		close(b.waitCh)
+		b.waitCh = nil
+	}
+	if b.addrCh != nil {
+		close(b.addrCh)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package backendpool
+
+import (
+	"testing"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type staticResolver []string
+
+func (r staticResolver) Resolve(string) (resolvers.Watcher, error) {
+	w := &staticWatcher{updatesC: make(chan []resolvers.Address, 1), closeC: make(chan struct{})}
+	var addrs []resolvers.Address
+	for _, addr := range r {
+		addrs = append(addrs, resolvers.Address{Addr: addr, Ready: true})
+	}
+	w.updatesC <- addrs
+	return w, nil
+}
+
+type staticWatcher struct {
+	updatesC chan []resolvers.Address
+	closeC   chan struct{}
+}
+
+func (w *staticWatcher) Next() ([]resolvers.Address, error) {
+	select {
+	case u := <-w.updatesC:
+		return u, nil
+	case <-w.closeC:
+		return nil, grpc.ErrClientConnClosing
+	}
+}
+
+func (w *staticWatcher) Close() { close(w.closeC) }
+
+func startBalancer(t *testing.T, b grpc.Balancer, addrs []string) {
+	require.NoError(t, b.Start("target", grpc.BalancerConfig{}))
+	notified := <-b.Notify()
+	require.Len(t, notified, len(addrs))
+	for _, a := range notified {
+		b.Up(a)
+	}
+}
+
+func TestLeastRequestBalancer(t *testing.T) {
+	addrs := []string{"1.1.1.1:80", "1.1.1.2:80", "1.1.1.3:80"}
+	b := newLeastRequestBalancer(staticResolver(addrs), false)
+	defer b.Close()
+	startBalancer(t, b, addrs)
+
+	ctx := context.Background()
+	picked := map[string]func(){}
+	for range addrs {
+		addr, put, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+		require.NoError(t, err)
+		picked[addr.Addr] = put
+	}
+	assert.Len(t, picked, len(addrs), "every address should get exactly one outstanding RPC")
+
+	picked["1.1.1.2:80"]()
+	addr, _, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.2:80", addr.Addr, "address with finished RPC should be picked")
+}
+
+func TestHashBalancer(t *testing.T) {
+	addrs := []string{"1.1.1.1:80", "1.1.1.2:80", "1.1.1.3:80"}
+	b := newHashBalancer(staticResolver(addrs), "x-user", common.NewMaglev)
+	defer b.Close()
+	startBalancer(t, b, addrs)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-user", "some-user"))
+	first, _, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		addr, _, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+		require.NoError(t, err)
+		assert.Equal(t, first, addr, "RPCs with the same metadata should go to the same address")
+	}
+}
+
+type watcherResolver struct {
+	w *staticWatcher
+}
+
+func (r watcherResolver) Resolve(string) (resolvers.Watcher, error) {
+	return r.w, nil
+}
+
+func TestPickingBalancerFollowsResolver(t *testing.T) {
+	w := &staticWatcher{updatesC: make(chan []resolvers.Address, 1), closeC: make(chan struct{})}
+	b := newLeastRequestBalancer(watcherResolver{w: w}, false)
+	defer b.Close()
+
+	a1 := resolvers.Address{Addr: "1.1.1.1:80", Weight: 1, Ready: true}
+	a2 := resolvers.Address{Addr: "1.1.1.2:80", Weight: 1, Ready: true}
+	a3NotReady := resolvers.Address{Addr: "1.1.1.3:80", Weight: 1, Ready: false}
+	w.updatesC <- []resolvers.Address{a1, a2, a3NotReady}
+	startBalancer(t, b, []string{a1.Addr, a2.Addr})
+
+	ctx := context.Background()
+	first, _, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+	require.NoError(t, err)
+	picked, ok := first.Metadata.(resolvers.Address)
+	require.True(t, ok, "metadata should be the resolved address")
+
+	// Outstanding RPC of the picked address needs to be remembered across updates.
+	a3 := a3NotReady
+	a3.Ready = true
+	w.updatesC <- []resolvers.Address{picked, a3}
+	notified := <-b.Notify()
+	assert.Equal(t, []grpc.Address{first, {Addr: a3.Addr, Metadata: a3}}, notified)
+	b.Up(notified[1])
+	addr, _, err := b.Get(ctx, grpc.BalancerGetOptions{BlockingWait: true})
+	require.NoError(t, err)
+	assert.Equal(t, a3.Addr, addr.Addr, "address without outstanding RPCs should be picked")
+}
+
+func TestWeightedRoundRobinBalancer(t *testing.T) {
+	w := &staticWatcher{updatesC: make(chan []resolvers.Address, 1), closeC: make(chan struct{})}
+	b := newWeightedRoundRobinBalancer(watcherResolver{w: w})
+	defer b.Close()
+
+	w.updatesC <- []resolvers.Address{
+		{Addr: "1.1.1.1:80", Weight: 3, Ready: true},
+		{Addr: "1.1.1.2:80", Weight: 1, Ready: true},
+	}
+	startBalancer(t, b, []string{"1.1.1.1:80", "1.1.1.2:80"})
+
+	picked := map[string]int{}
+	for i := 0; i < 400; i++ {
+		addr, _, err := b.Get(context.Background(), grpc.BalancerGetOptions{BlockingWait: true})
+		require.NoError(t, err)
+		picked[addr.Addr]++
+	}
+	assert.InDelta(t, 300, picked["1.1.1.1:80"], 10)
+	assert.InDelta(t, 100, picked["1.1.1.2:80"], 10)
+}
@@ -4,7 +4,10 @@ import (
 	"hash/fnv"
 	"sync"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/tls"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -12,10 +15,11 @@ import (
 
 // dynamic is a Pool to which you can update or remove routes.
 type dynamic struct {
-	backends       map[string]*backend
-	mu             sync.RWMutex
-	backendFactory func(backend *pb.Backend) (*backend, error)
-	logger         logrus.FieldLogger
+	backends         map[string]*backend
+	tlsServerConfigs []*pb_config.TlsServerConfig
+	mu               sync.RWMutex
+	backendFactory   func(backend *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*backend, error)
+	logger           logrus.FieldLogger
 }
 
 func (s *dynamic) Close() error {
@@ -43,9 +47,18 @@ func (s *dynamic) Conn(backendName string) (*grpc.ClientConn, error) {
 	return be.Conn()
 }
 
+// UpdateTLSServerConfigs sets named TLS configs that backends can refer to in their security settings.
+// It does not affect existing backends until they are updated using AddOrUpdate.
+func (s *dynamic) UpdateTLSServerConfigs(tlsServerConfigs []*pb_config.TlsServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsServerConfigs = tlsServerConfigs
+}
+
 // AddOrUpdate checks tries to perform the least destructive operation of adding a new backend.
 //
-// If a backend of a given name already exists, and the configuration hasn't changed, no new work will be done.
+// If a backend of a given name already exists, and the configuration (including referenced TLS config) hasn't changed,
+// no new work will be done.
 // If a backend requires changes, the previous one will be removed and closed.
 func (s *dynamic) AddOrUpdate(config *pb.Backend, logTestResolution bool) (changed bool, err error) {
 	s.mu.RLock()
@@ -85,7 +98,11 @@ func (s *dynamic) AddOrUpdate(config *pb.Backend, logTestResolution bool) (chang
 }
 
 func (s *dynamic) addNewBackend(config *pb.Backend) error {
-	be, err := s.backendFactory(config)
+	s.mu.RLock()
+	tlsServerConfigs := s.tlsServerConfigs
+	s.mu.RUnlock()
+
+	be, err := s.backendFactory(config, tlsServerConfigs)
 	if err != nil {
 		return err
 	}
@@ -96,7 +113,11 @@ func (s *dynamic) addNewBackend(config *pb.Backend) error {
 }
 
 func (s *dynamic) updateBackendWithDiffing(existing *backend, config *pb.Backend) (changed bool, err error) {
-	if configsAreTheSame(existing.config, config) {
+	s.mu.RLock()
+	tlsServerConfig := kedge_tls.FindTLSServerConfig(config.GetSecurity().GetConfigName(), s.tlsServerConfigs)
+	s.mu.RUnlock()
+
+	if configsAreTheSame(existing.config, config) && configsAreTheSame(existing.tlsServerConfig, tlsServerConfig) {
 		return false, nil
 	}
 	if err := s.addNewBackend(config); err != nil {
@@ -119,12 +140,24 @@ func (s *dynamic) Remove(backendName string) error {
 	delete(s.backends, backendName)
 	s.mu.Unlock()
 	existing.Close()
+	metrics.ForgetProxyBackend("grpc", backendName)
 
 	s.logger.Infof("Removed grpc backend: %v", backendName)
 	metrics.BackendGRPCConfigurationCounter.WithLabelValues(backendName, metrics.ConfiguationActionDelete).Inc()
 	return nil
 }
 
+// Targets returns the addresses currently resolved for the backend. It is used for debugging.
+func (s *dynamic) Targets(backendName string) ([]TargetStatus, error) {
+	s.mu.RLock()
+	be, ok := s.backends[backendName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownBackend
+	}
+	return be.Targets(), nil
+}
+
 // Configs returns a map of all active backends and their configuration.
 func (s *dynamic) Configs() map[string]*pb.Backend {
 	ret := make(map[string]*pb.Backend)
@@ -136,10 +169,10 @@ func (s *dynamic) Configs() map[string]*pb.Backend {
 	return ret
 }
 
-func configsAreTheSame(c1 *pb.Backend, c2 *pb.Backend) bool {
+func configsAreTheSame(c1 proto.Message, c2 proto.Message) bool {
 	h1 := fnv.New64a()
 	h2 := fnv.New64a()
-	h1.Write([]byte(c1.String()))
-	h2.Write([]byte(c2.String()))
+	h1.Write([]byte(proto.CompactTextString(c1)))
+	h2.Write([]byte(proto.CompactTextString(c2)))
 	return h1.Sum64() == h2.Sum64()
 }
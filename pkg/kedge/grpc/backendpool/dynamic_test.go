@@ -3,6 +3,8 @@ package backendpool
 import (
 	"testing"
 
+	"github.com/improbable-eng/kedge/pkg/tls"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb_resolvers "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 	"github.com/sirupsen/logrus"
@@ -12,7 +14,7 @@ import (
 
 func TestDynamic_Operations(t *testing.T) {
 	d := NewDynamic(logrus.New())
-	d.backendFactory = func(config *pb.Backend) (*backend, error) {
+	d.backendFactory = func(config *pb.Backend, _ []*pb_config.TlsServerConfig) (*backend, error) {
 		return &backend{config: config}, nil
 	}
 	assert.Len(t, d.Configs(), 0, "at first there needs to be nothing")
@@ -59,3 +61,31 @@ func TestDynamic_Operations(t *testing.T) {
 	assert.NoError(t, d.Remove("foobar"), "removing a non existing backend should return error")
 	assert.Len(t, d.Configs(), 1, "we now should have two")
 }
+
+func TestDynamic_TLSServerConfigChanges(t *testing.T) {
+	d := NewDynamic(logrus.New())
+	d.backendFactory = func(config *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*backend, error) {
+		return &backend{config: config, tlsServerConfig: kedge_tls.FindTLSServerConfig(config.GetSecurity().GetConfigName(), tlsServerConfigs)}, nil
+	}
+	backendCnf := &pb.Backend{Name: "foobar", Security: &pb.Security{ConfigName: "some_tls"}}
+
+	d.UpdateTLSServerConfigs([]*pb_config.TlsServerConfig{{Name: "some_tls", ServerName: "foobar.example.com"}})
+	changed, err := d.AddOrUpdate(backendCnf, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	d.UpdateTLSServerConfigs([]*pb_config.TlsServerConfig{
+		{Name: "other_tls"},
+		{Name: "some_tls", ServerName: "foobar.example.com"},
+	})
+	changed, err = d.AddOrUpdate(backendCnf, false)
+	require.NoError(t, err)
+	assert.False(t, changed, "referenced TLS config did not change, so backend should not change.")
+
+	oldFoobar := d.backends["foobar"]
+	d.UpdateTLSServerConfigs([]*pb_config.TlsServerConfig{{Name: "some_tls", ServerName: "other.example.com"}})
+	changed, err = d.AddOrUpdate(backendCnf, false)
+	require.NoError(t, err)
+	assert.True(t, changed, "referenced TLS config changed, so backend should be recreated.")
+	assert.True(t, oldFoobar.closed, "oldFoobar should enter closed state")
+}
@@ -0,0 +1,290 @@
+package backendpool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/improbable-eng/kedge/pkg/kedge/grpc/retry"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/tracing"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func chooseInterceptors(cnf *pb.Backend) ([]grpc.DialOption, error) {
+	var (
+		opts        []grpc.DialOption
+		unary       []grpc.UnaryClientInterceptor
+		stream      []grpc.StreamClientInterceptor
+		retryPolicy *retry.Policy
+	)
+
+	for _, i := range cnf.GetInterceptors() {
+		if prom := i.GetPrometheus(); prom {
+			unary = append(unary, grpc_prometheus.UnaryClientInterceptor)
+			stream = append(stream, grpc_prometheus.StreamClientInterceptor)
+		} else if r := i.GetRetry(); r != nil {
+			var err error
+			retryPolicy, err = retry.NewPolicy(r)
+			if err != nil {
+				return nil, fmt.Errorf("backend '%v': %v", cnf.Name, err)
+			}
+		} else if i.GetLogging() {
+			l := &callLogger{logger: logrus.StandardLogger().WithField("backend", cnf.Name)}
+			unary = append(unary, l.unary)
+			stream = append(stream, l.stream)
+		} else if i.GetTracing() {
+			t := &callTracer{backendName: cnf.Name}
+			unary = append(unary, t.unary)
+			stream = append(stream, t.stream)
+		} else if md := i.GetMetadata(); md != nil {
+			injector, err := newMetadataInjector(md)
+			if err != nil {
+				return nil, fmt.Errorf("backend '%v': %v", cnf.Name, err)
+			}
+			unary = append(unary, injector.unary)
+			stream = append(stream, injector.stream)
+		} else if limits := i.GetMessageLimits(); limits != nil {
+			limitOpts, err := messageLimitOpts(limits)
+			if err != nil {
+				return nil, fmt.Errorf("backend '%v': %v", cnf.Name, err)
+			}
+			opts = append(opts, limitOpts...)
+		}
+		// new interceptors are to be added here as else if statements.
+	}
+	// Retries are always the innermost interceptor, as routes can enable them even if the backend does not. Upstream
+	// time includes all the attempts.
+	stream = append(stream, metrics.UpstreamStreamClientInterceptor, retry.StreamClientInterceptor(cnf.Name, retryPolicy))
+	return append(opts,
+		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(unary...)),
+		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(stream...)),
+	), nil
+}
+
+// finishedStream calls onFinish once the call ends, i.e. when RecvMsg returns an error. The proxy receives until then.
+type finishedStream struct {
+	grpc.ClientStream
+	once     sync.Once
+	onFinish func(err error)
+}
+
+func (s *finishedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				s.onFinish(nil)
+				return
+			}
+			s.onFinish(err)
+		})
+	}
+	return err
+}
+
+func streamWithFinish(cs grpc.ClientStream, err error, onFinish func(err error)) (grpc.ClientStream, error) {
+	if err != nil {
+		onFinish(err)
+		return nil, err
+	}
+	return &finishedStream{ClientStream: cs, onFinish: onFinish}, nil
+}
+
+// targetAddress returns the resolved address of the call's target, tagged by the balancer.
+func targetAddress(ctx context.Context) string {
+	addr, _ := grpc_ctxtags.Extract(ctx).Values()["grpc.target.address"].(string)
+	return addr
+}
+
+// callLogger logs finished calls to a backend.
+type callLogger struct {
+	logger logrus.FieldLogger
+}
+
+func (l *callLogger) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	l.log(ctx, method, start, err)
+	return err
+}
+
+func (l *callLogger) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	start := time.Now()
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	return streamWithFinish(cs, err, func(err error) {
+		l.log(ctx, method, start, err)
+	})
+}
+
+func (l *callLogger) log(ctx context.Context, method string, start time.Time, err error) {
+	code := grpc.Code(err)
+	entry := l.logger.WithFields(logrus.Fields{
+		"grpc.service":        path.Dir(method)[1:],
+		"grpc.method":         path.Base(method),
+		"grpc.target.address": targetAddress(ctx),
+		"grpc.code":           code.String(),
+		"grpc.time_ms":        float32(time.Since(start).Nanoseconds()/1000) / 1000,
+	})
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	switch grpc_logrus.DefaultClientCodeToLevel(code) {
+	case logrus.DebugLevel:
+		entry.Debug("Finished backend call.")
+	case logrus.InfoLevel:
+		entry.Info("Finished backend call.")
+	default:
+		entry.Warn("Finished backend call.")
+	}
+}
+
+// callTracer traces calls to a backend in /debug/requests and as client spans (see pkg/tracing).
+type callTracer struct {
+	backendName string
+}
+
+func (t *callTracer) start(ctx context.Context, method string) (context.Context, trace.Trace, *tracing.Span) {
+	tr := trace.New("grpc.Backend."+t.backendName, method)
+	ctx, span := tracing.StartSpan(trace.NewContext(ctx, tr), "kedge.grpc.backend", tracing.KindClient)
+	span.SetAttribute("kedge.backend.name", t.backendName)
+	span.SetAttribute("grpc.method", method)
+	// Backend should continue the trace from the span of this call.
+	return tracing.OutgoingContext(ctx), tr, span
+}
+
+func (t *callTracer) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	callCtx, tr, span := t.start(ctx, method)
+	err := invoker(callCtx, method, req, reply, cc, opts...)
+	t.finish(ctx, tr, span, err)
+	return err
+}
+
+func (t *callTracer) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	callCtx, tr, span := t.start(ctx, method)
+	cs, err := streamer(callCtx, desc, cc, method, opts...)
+	return streamWithFinish(cs, err, func(err error) {
+		t.finish(ctx, tr, span, err)
+	})
+}
+
+func (t *callTracer) finish(ctx context.Context, tr trace.Trace, span *tracing.Span, err error) {
+	target := targetAddress(ctx)
+	tr.LazyPrintf("target: %v", target)
+	if err != nil {
+		tr.LazyPrintf("error: %v", err)
+		tr.SetError()
+	}
+	tr.Finish()
+
+	span.SetAttribute("kedge.target.address", target)
+	span.SetAttribute("grpc.code", grpc.Code(err).String())
+	span.SetError(err)
+	span.End()
+}
+
+// metadataInjector sets templated metadata on outgoing calls.
+type metadataInjector struct {
+	templates map[string]*template.Template
+}
+
+// metadataTemplateData is available to metadata templates as '.'.
+type metadataTemplateData struct {
+	// Metadata is the incoming metadata of the call.
+	Metadata metautils.NiceMD
+}
+
+// metadataEnvPrefix is the prefix of environment variables that metadata templates can read, so backend configs cannot
+// expose other (e.g. secret) variables of kedge to backends.
+const metadataEnvPrefix = "KEDGE_MD_"
+
+func metadataEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, metadataEnvPrefix) {
+		return "", fmt.Errorf("env: only variables with %s prefix can be used, got %q", metadataEnvPrefix, name)
+	}
+	return os.Getenv(name), nil
+}
+
+func newMetadataInjector(cnf *pb.MetadataInjection) (*metadataInjector, error) {
+	m := &metadataInjector{templates: map[string]*template.Template{}}
+	for k, v := range cnf.Values {
+		tmpl, err := template.New(k).Funcs(template.FuncMap{"env": metadataEnv}).Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata template for %v: %v", k, err)
+		}
+		// Check the template upfront (e.g. for disallowed env variables) instead of failing every call.
+		if err := tmpl.Execute(ioutil.Discard, metadataTemplateData{}); err != nil {
+			return nil, fmt.Errorf("invalid metadata template for %v: %v", k, err)
+		}
+		m.templates[strings.ToLower(k)] = tmpl
+	}
+	return m, nil
+}
+
+func (m *metadataInjector) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, err := m.inject(ctx)
+	if err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (m *metadataInjector) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, err := m.inject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+func (m *metadataInjector) inject(ctx context.Context) (context.Context, error) {
+	data := metadataTemplateData{Metadata: metautils.ExtractIncoming(ctx)}
+	md := metautils.ExtractOutgoing(ctx).Clone()
+	for k, tmpl := range m.templates {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return ctx, grpc.Errorf(codes.Internal, "failed to build metadata %v for backend: %v", k, err)
+		}
+		md.Set(k, b.String())
+	}
+	return md.ToOutgoing(ctx), nil
+}
+
+func messageLimitOpts(cnf *pb.MessageLimits) ([]grpc.DialOption, error) {
+	var callOpts []grpc.CallOption
+	if cnf.MaxSendBytes > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(int(cnf.MaxSendBytes)))
+	}
+	if cnf.MaxReceiveBytes > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(int(cnf.MaxReceiveBytes)))
+	}
+	opts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(callOpts...),
+		grpc.WithDecompressor(grpc.NewGZIPDecompressor()),
+	}
+	switch cnf.Compression {
+	case "":
+	case "gzip":
+		opts = append(opts, grpc.WithCompressor(grpc.NewGZIPCompressor()))
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", cnf.Compression)
+	}
+	return opts, nil
+}
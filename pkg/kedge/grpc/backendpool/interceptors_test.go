@@ -0,0 +1,84 @@
+package backendpool
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/improbable-eng/kedge/pkg/tracing"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetadataInjector(t *testing.T) {
+	os.Setenv("KEDGE_MD_TEST_API_KEY", "secret")
+	defer os.Unsetenv("KEDGE_MD_TEST_API_KEY")
+
+	injector, err := newMetadataInjector(&pb.MetadataInjection{Values: map[string]string{
+		"X-Api-Key":        `{{env "KEDGE_MD_TEST_API_KEY"}}`,
+		"x-forwarded-user": `user-{{.Metadata.Get "x-user"}}`,
+	}})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user", "alice"))
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("x-user", "alice", "x-api-key", "from-client"))
+	ctx, err = injector.inject(ctx)
+	require.NoError(t, err)
+
+	md := metautils.ExtractOutgoing(ctx)
+	assert.Equal(t, []string{"secret"}, md["x-api-key"], "injected metadata should override client's")
+	assert.Equal(t, "user-alice", md.Get("x-forwarded-user"))
+	assert.Equal(t, "alice", md.Get("x-user"), "other metadata should be kept")
+}
+
+func TestChooseInterceptors_InvalidConfig(t *testing.T) {
+	for _, i := range []*pb.Interceptor{
+		{Interceptor: &pb.Interceptor_Metadata{Metadata: &pb.MetadataInjection{Values: map[string]string{"x": "{{"}}}},
+		{Interceptor: &pb.Interceptor_Metadata{Metadata: &pb.MetadataInjection{Values: map[string]string{"x": `{{env "HOME"}}`}}}},
+		{Interceptor: &pb.Interceptor_MessageLimits{MessageLimits: &pb.MessageLimits{Compression: "snappy"}}},
+		{Interceptor: &pb.Interceptor_Retry{Retry: &pb.RetryPolicy{RetryableCodes: []string{"NOPE"}}}},
+	} {
+		_, err := chooseInterceptors(&pb.Backend{Name: "a", Interceptors: []*pb.Interceptor{i}})
+		assert.Error(t, err, "interceptor %v should be rejected", i)
+	}
+
+	_, err := chooseInterceptors(&pb.Backend{Name: "a", Interceptors: []*pb.Interceptor{
+		{Interceptor: &pb.Interceptor_Prometheus{Prometheus: true}},
+		{Interceptor: &pb.Interceptor_Logging{Logging: true}},
+		{Interceptor: &pb.Interceptor_Tracing{Tracing: true}},
+		{Interceptor: &pb.Interceptor_MessageLimits{MessageLimits: &pb.MessageLimits{MaxReceiveBytes: 1024, Compression: "gzip"}}},
+	}})
+	assert.NoError(t, err)
+}
+
+type recordingExporter struct {
+	spans []*tracing.Span
+}
+
+func (e *recordingExporter) Export(s *tracing.Span) {
+	e.spans = append(e.spans, s)
+}
+
+func TestCallTracer_EmitsClientSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracing.SetGlobal(tracing.NewTracer(exporter, 1))
+	defer tracing.SetGlobal(tracing.NewTracer(nil, 0))
+
+	tracer := &callTracer{backendName: "backend_a"}
+	var sent tracing.SpanContext
+	err := tracer.unary(context.Background(), "/pkg.Service/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			sent, _ = tracing.Extract(tracing.MetadataCarrier(md))
+			return grpc.Errorf(codes.Unavailable, "down")
+		})
+	require.Error(t, err)
+
+	require.Len(t, exporter.spans, 1)
+	assert.Equal(t, exporter.spans[0].Context(), sent, "backend should get the context of the call's span")
+}
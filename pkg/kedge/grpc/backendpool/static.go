@@ -3,6 +3,7 @@ package backendpool
 import (
 	"fmt"
 
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 	"google.golang.org/grpc"
 )
@@ -20,10 +21,11 @@ func (s *static) Close() error {
 }
 
 // NewStatic creates a backend pool that has static configuration.
-func NewStatic(backends []*pb.Backend) (Pool, error) {
+// tlsServerConfigs are named TLS configs that backends can refer to in their security settings.
+func NewStatic(backends []*pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (Pool, error) {
 	s := &static{backends: make(map[string]*backend)}
 	for _, beCnf := range backends {
-		be, err := newBackend(beCnf)
+		be, err := newBackend(beCnf, tlsServerConfigs)
 		if err != nil {
 			return nil, fmt.Errorf("failed creating backend '%v': %v", beCnf.Name, err)
 		}
@@ -20,9 +20,14 @@ func NewStaticAddresser(rules []*kedge_config_common.Adhoc) *static {
 }
 
 func (a *static) Address(hostString string) (string, error) {
+	addr, _, err := a.AddressWithRule(hostString)
+	return addr, err
+}
+
+func (a *static) AddressWithRule(hostString string) (string, *kedge_config_common.Adhoc, error) {
 	hostName, port, err := common.ExtractHostPort(hostString)
 	if err != nil {
-		return "", status.Errorf(codes.InvalidArgument, "adhoc: malformed port number: %v", err)
+		return "", nil, status.Errorf(codes.InvalidArgument, "adhoc: malformed port number: %v", err)
 	}
 	for _, rule := range a.rules {
 		if !common.HostMatches(hostName, rule.DnsNameMatcher) {
@@ -37,15 +42,15 @@ func (a *static) Address(hostString string) (string, error) {
 			}
 		}
 		if !common.PortAllowed(portForRule, rule.Port) {
-			return "", status.Errorf(codes.InvalidArgument, "adhoc: port %d is not allowed", portForRule)
+			return "", nil, status.Errorf(codes.InvalidArgument, "adhoc: port %d is not allowed", portForRule)
 		}
 
 		ipAddr, err := common.AdhocResolveHost(hostName, rule.DnsNameReplace)
 		if err != nil {
-			return "", status.Errorf(codes.NotFound, "adhoc: cannot resolve %s host: %v", hostString, err)
+			return "", nil, status.Errorf(codes.NotFound, "adhoc: cannot resolve %s host: %v", hostString, err)
 		}
-		return net.JoinHostPort(ipAddr, strconv.FormatInt(int64(portForRule), 10)), nil
+		return net.JoinHostPort(ipAddr, strconv.FormatInt(int64(portForRule), 10)), rule, nil
 
 	}
-	return "", router.ErrRouteNotFound
+	return "", nil, router.ErrRouteNotFound
 }
@@ -0,0 +1,240 @@
+package adhoc
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/mwitkow/go-conntrack"
+	"github.com/mwitkow/grpc-proxy/proxy"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	flagMaxConns = sharedflags.Set.Int("grpc_adhoc_max_connections", 256,
+		"Maximum number of cached connections to gRPC adhoc targets. The least recently used idle connections are "+
+			"closed when it is exceeded.")
+	flagConnIdleTimeout = sharedflags.Set.Duration("grpc_adhoc_connection_idle_timeout", 5*time.Minute,
+		"Cached connections to gRPC adhoc targets that are not used by any call for this long are closed. If 0, idle "+
+			"connections are closed only when grpc_adhoc_max_connections is exceeded.")
+
+	connsCached = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "grpc",
+			Name:      "adhoc_connections_cached",
+			Help:      "Number of currently cached connections to gRPC adhoc targets.",
+		},
+	)
+	connsEvicted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "grpc",
+			Name:      "adhoc_connections_evicted_total",
+			Help:      "Total number of closed cached connections to gRPC adhoc targets, by the reason of eviction.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(connsCached)
+	prometheus.MustRegister(connsEvicted)
+}
+
+// connKey identifies connections that can be shared between calls: the same resolved address dialed with the same
+// security settings.
+type connKey struct {
+	addr               string
+	tls                bool
+	serverName         string
+	insecureSkipVerify bool
+}
+
+type cachedConn struct {
+	cc       *grpc.ClientConn
+	refs     int
+	lastUsed time.Time
+}
+
+// ConnPool is a bounded cache of connections to adhoc targets. Connections are shared by concurrent calls to the same
+// target and closed when they are not used for the idle timeout, or when the pool is over its size. Idle connections
+// are also closed in the background, so they do not stay open when no new calls come.
+type ConnPool struct {
+	mu          sync.Mutex
+	conns       map[connKey]*cachedConn
+	maxConns    int
+	idleTimeout time.Duration
+	dialFunc    func(ctx context.Context, network, addr string) (net.Conn, error)
+	now         func() time.Time
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewConnPool creates a ConnPool sized according to the flags.
+func NewConnPool() *ConnPool {
+	return newConnPool(*flagMaxConns, *flagConnIdleTimeout)
+}
+
+func newConnPool(maxConns int, idleTimeout time.Duration) *ConnPool {
+	p := &ConnPool{
+		conns:       make(map[connKey]*cachedConn),
+		maxConns:    maxConns,
+		idleTimeout: idleTimeout,
+		dialFunc: conntrack.NewDialContextFunc(
+			conntrack.DialWithName("grpc_adhoc"),
+			conntrack.DialWithTracing(),
+		),
+		now:  time.Now,
+		done: make(chan struct{}),
+	}
+	if idleTimeout > 0 {
+		go p.runJanitor(idleTimeout / 2)
+	}
+	return p
+}
+
+// runJanitor evicts idle connections every interval until the pool is closed.
+func (p *ConnPool) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.evictIdle()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Conn returns connection to the given ip:port, reusing a cached one if possible. hostName is the requested host
+// name, used to verify the target's certificate if TLS is enabled and the rule does not specify the server name.
+// The returned release func needs to be called once the call is done with the connection.
+func (p *ConnPool) Conn(addr string, hostName string, tlsCnf *pb.Adhoc_Tls) (*grpc.ClientConn, func(), error) {
+	key := connKey{addr: addr}
+	if tlsCnf != nil {
+		key.tls = true
+		key.serverName = tlsCnf.ServerName
+		if key.serverName == "" {
+			key.serverName = hostName
+		}
+		key.insecureSkipVerify = tlsCnf.InsecureSkipVerify
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictIdle()
+	c, ok := p.conns[key]
+	if !ok {
+		cc, err := p.dial(key)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to dial to adhoc backend %v", addr)
+		}
+		c = &cachedConn{cc: cc}
+		p.conns[key] = c
+		connsCached.Inc()
+	}
+	c.refs++
+	c.lastUsed = p.now()
+	p.evictOverCapacity()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			c.refs--
+			c.lastUsed = p.now()
+			p.mu.Unlock()
+		})
+	}
+	return c.cc, release, nil
+}
+
+func (p *ConnPool) dial(key connKey) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithCodec(proxy.Codec()), // needed for the director to function at all.
+		grpc.WithStreamInterceptor(metrics.UpstreamStreamClientInterceptor),
+		grpc.WithDialer(func(addr string, t time.Duration) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), t)
+			defer cancel()
+			return p.dialFunc(ctx, "tcp", addr)
+		}),
+	}
+	if key.tls {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			ServerName:         key.serverName,
+			InsecureSkipVerify: key.insecureSkipVerify,
+		})))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	// Dial does not block, so unreachable targets fail the calls instead of holding the lock.
+	return grpc.Dial(key.addr, opts...)
+}
+
+// evictIdle closes connections not used by any call for the idle timeout, if there is one. Must be called with the lock
+// held.
+func (p *ConnPool) evictIdle() {
+	if p.idleTimeout <= 0 {
+		return
+	}
+	now := p.now()
+	for key, c := range p.conns {
+		if c.refs == 0 && now.Sub(c.lastUsed) >= p.idleTimeout {
+			p.evict(key, "idle")
+		}
+	}
+}
+
+// evictOverCapacity closes the least recently used connections not used by any call until the pool fits its size.
+// Connections in use are never closed, so the pool can temporarily grow over its size. Must be called with the lock
+// held.
+func (p *ConnPool) evictOverCapacity() {
+	for len(p.conns) > p.maxConns {
+		var (
+			lruKey connKey
+			lru    *cachedConn
+		)
+		for key, c := range p.conns {
+			if c.refs == 0 && (lru == nil || c.lastUsed.Before(lru.lastUsed)) {
+				lruKey, lru = key, c
+			}
+		}
+		if lru == nil {
+			return
+		}
+		p.evict(lruKey, "capacity")
+	}
+}
+
+func (p *ConnPool) evict(key connKey, reason string) {
+	p.conns[key].cc.Close()
+	delete(p.conns, key)
+	connsCached.Dec()
+	connsEvicted.WithLabelValues(reason).Inc()
+}
+
+// Close stops the background eviction and closes all cached connections.
+func (p *ConnPool) Close() {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, c := range p.conns {
+		c.cc.Close()
+		delete(p.conns, key)
+		connsCached.Dec()
+	}
+}
@@ -0,0 +1,155 @@
+package adhoc
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestConnPool(maxConns int, idleTimeout time.Duration) (*ConnPool, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := newConnPool(maxConns, idleTimeout)
+	p.now = clock.Now
+	return p, clock
+}
+
+func TestConnPool_ReusesConnectionsForTheSameTarget(t *testing.T) {
+	p, _ := newTestConnPool(10, time.Minute)
+	defer p.Close()
+
+	cc1, release1, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	cc2, release2, err := p.Conn("127.0.0.1:81", "b.local", nil)
+	require.NoError(t, err)
+	assert.True(t, cc1 == cc2, "plain text connections to the same address should be shared")
+	release1()
+	release2()
+
+	tls1, release3, err := p.Conn("127.0.0.1:81", "a.local", &pb.Adhoc_Tls{})
+	require.NoError(t, err)
+	defer release3()
+	assert.False(t, tls1 == cc1, "TLS connection should not be shared with the plain text one")
+
+	tls2, release4, err := p.Conn("127.0.0.1:81", "b.local", &pb.Adhoc_Tls{})
+	require.NoError(t, err)
+	defer release4()
+	assert.False(t, tls1 == tls2, "TLS connections verifying different server names should not be shared")
+
+	tls3, release5, err := p.Conn("127.0.0.1:81", "b.local", &pb.Adhoc_Tls{ServerName: "a.local"})
+	require.NoError(t, err)
+	defer release5()
+	assert.True(t, tls1 == tls3, "TLS connections verifying the same server name should be shared")
+	assert.Len(t, p.conns, 3)
+}
+
+func TestConnPool_EvictsIdleConnections(t *testing.T) {
+	p, clock := newTestConnPool(10, time.Minute)
+	defer p.Close()
+
+	_, releaseUsed, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	defer releaseUsed()
+	_, releaseIdle, err := p.Conn("127.0.0.1:82", "a.local", nil)
+	require.NoError(t, err)
+	releaseIdle()
+
+	clock.now = clock.now.Add(30 * time.Second)
+	_, release, err := p.Conn("127.0.0.1:83", "a.local", nil)
+	require.NoError(t, err)
+	release()
+	assert.Len(t, p.conns, 3, "connections should not be evicted before the idle timeout")
+
+	clock.now = clock.now.Add(time.Minute)
+	_, release, err = p.Conn("127.0.0.1:84", "a.local", nil)
+	require.NoError(t, err)
+	defer release()
+	assert.Len(t, p.conns, 2, "only connections in use or just dialed should be kept")
+	assert.Contains(t, p.conns, connKey{addr: "127.0.0.1:81"})
+	assert.Contains(t, p.conns, connKey{addr: "127.0.0.1:84"})
+}
+
+func TestConnPool_EvictsLeastRecentlyUsedOverCapacity(t *testing.T) {
+	p, clock := newTestConnPool(2, time.Hour)
+	defer p.Close()
+
+	for _, addr := range []string{"127.0.0.1:81", "127.0.0.1:82"} {
+		_, release, err := p.Conn(addr, "a.local", nil)
+		require.NoError(t, err)
+		release()
+		clock.now = clock.now.Add(time.Second)
+	}
+	// Use the first one again, so the second one is the least recently used.
+	_, release, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	release()
+	clock.now = clock.now.Add(time.Second)
+
+	_, release, err = p.Conn("127.0.0.1:83", "a.local", nil)
+	require.NoError(t, err)
+	defer release()
+	assert.Len(t, p.conns, 2)
+	assert.NotContains(t, p.conns, connKey{addr: "127.0.0.1:82"})
+}
+
+func TestConnPool_DoesNotEvictConnectionsInUse(t *testing.T) {
+	p, _ := newTestConnPool(1, time.Hour)
+	defer p.Close()
+
+	_, release1, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	defer release1()
+	_, release2, err := p.Conn("127.0.0.1:82", "a.local", nil)
+	require.NoError(t, err)
+	assert.Len(t, p.conns, 2, "pool should grow over its size rather than close connections in use")
+
+	release2()
+	_, release3, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	defer release3()
+	assert.Len(t, p.conns, 1)
+}
+
+func TestConnPool_EvictsIdleConnectionsInBackground(t *testing.T) {
+	p := newConnPool(10, 20*time.Millisecond)
+	defer p.Close()
+
+	_, release, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	release()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		p.mu.Lock()
+		n := len(p.conns)
+		p.mu.Unlock()
+		if n == 0 {
+			return
+		}
+	}
+	t.Fatal("idle connection should be evicted without new calls")
+}
+
+func TestConnPool_ZeroIdleTimeoutKeepsIdleConnections(t *testing.T) {
+	p, clock := newTestConnPool(10, 0)
+	defer p.Close()
+
+	_, release, err := p.Conn("127.0.0.1:81", "a.local", nil)
+	require.NoError(t, err)
+	release()
+
+	clock.now = clock.now.Add(time.Hour)
+	_, release, err = p.Conn("127.0.0.1:82", "a.local", nil)
+	require.NoError(t, err)
+	release()
+	assert.Len(t, p.conns, 2, "idle connections should not expire without idle timeout")
+}
@@ -10,57 +10,93 @@ import (
 	"github.com/improbable-eng/kedge/pkg/grpcutils"
 	"github.com/improbable-eng/kedge/pkg/kedge/common"
 	"github.com/improbable-eng/kedge/pkg/kedge/grpc/backendpool"
+	"github.com/improbable-eng/kedge/pkg/kedge/grpc/director/adhoc"
 	"github.com/improbable-eng/kedge/pkg/kedge/grpc/director/router"
+	"github.com/improbable-eng/kedge/pkg/kedge/grpc/retry"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
+	"github.com/improbable-eng/kedge/pkg/tracing"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 	"github.com/mwitkow/grpc-proxy/proxy"
-	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 )
 
-// New builds a StreamDirector based off a backend pool and a router.
-func New(pool backendpool.Pool, adhocRouter common.Addresser, grpcRouter router.Router) proxy.StreamDirector {
+// New builds a StreamDirector based off a backend pool and a router. Calls not matching any route are sent to the
+// adhoc targets using connections from adhocConns. Calls matching a route are limited by the route's timeouts and
+// retried according to its retry policy (if it overrides the backend's one).
+func New(pool backendpool.Pool, adhocRouter common.RuleAddresser, adhocConns *adhoc.ConnPool, grpcRouter router.Router) proxy.StreamDirector {
 	return func(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
-		beName, err := grpcRouter.Route(ctx, fullMethodName)
+		_, span := tracing.StartSpan(ctx, "kedge.grpc.director", tracing.KindInternal)
+		defer span.End()
+		beName, route, err := grpcRouter.Route(ctx, fullMethodName)
 
 		// Try adhoc router if RouteNotFound.
 		if err == router.ErrRouteNotFound {
-			ipPort, err := adhocRouter.Address(metautils.ExtractIncoming(ctx).Get(":authority"))
+			hostString := metautils.ExtractIncoming(ctx).Get(":authority")
+			ipPort, rule, err := resolveAdhoc(ctx, adhocRouter, hostString)
 			if err != nil {
+				span.SetError(err)
+				metrics.ProxyRequestFromContext(ctx).SetErrorType(string(errtypes.NoRoute))
 				return ctx, nil, err
 			}
-
-			var opts []grpc.DialOption
-			opts = append(opts,
-				grpc.WithInsecure(),
-				grpc.WithBlock(),
-				grpc.WithAuthority(fullMethodName),
-				grpc.WithCodec(proxy.Codec()),
-			)
-			cc, err := grpc.Dial(ipPort, opts...)
+			hostName, _, _ := common.ExtractHostPort(hostString)
+			metrics.ProxyRequestFromContext(ctx).SetAdhoc(ipPort)
+			cc, release, err := adhocConns.Conn(ipPort, hostName, rule.GetTls())
 			if err != nil {
-				return ctx, nil, errors.Wrapf(err, "failed to dial to adhoc backend %v", ipPort)
+				span.SetError(err)
+				metrics.ProxyRequestFromContext(ctx).SetErrorType(string(errtypes.TransportUnknownError))
+				return ctx, nil, err
 			}
 
 			go func() {
 				<-ctx.Done()
-				cc.Close()
+				release()
 			}()
 			grpc_ctxtags.Extract(ctx).Set("grpc.proxy.adhoc", ipPort)
-			return grpcutils.CloneIncomingToOutgoingMD(ctx), cc, err
+			span.SetAttribute("kedge.adhoc", ipPort)
+			return tracing.OutgoingContext(grpcutils.CloneIncomingToOutgoingMD(ctx)), cc, nil
 		}
 
 		// Return all other errors.
 		if err != nil {
+			span.SetError(err)
+			errType := errtypes.RouteUnknownError
+			if err == router.ErrRateLimited {
+				errType = errtypes.RateLimited
+			}
+			metrics.ProxyRequestFromContext(ctx).SetErrorType(string(errType))
 			return ctx, nil, err
 		}
 
 		grpc_ctxtags.Extract(ctx).Set("grpc.proxy.backend", beName)
+		span.SetAttribute("kedge.backend", beName)
+		ctx = withTimeouts(ctx, route.Timeouts)
+		if policy := route.RetryPolicy(); policy != nil {
+			ctx = retry.WithPolicy(ctx, policy)
+		}
 		cc, err := pool.Conn(beName)
-		return grpcutils.CloneIncomingToOutgoingMD(ctx), cc, err
+		if err != nil {
+			span.SetError(err)
+			metrics.ProxyRequestFromContext(ctx).SetErrorType(string(errtypes.NoBackend))
+		}
+		// Backends continue the span of the call (see tracing.StreamServerInterceptor), not the one of the director.
+		return tracing.OutgoingContext(grpcutils.CloneIncomingToOutgoingMD(ctx)), cc, err
 	}
 }
 
+// resolveAdhoc resolves the address of the adhoc target within its own span, as it may need a DNS lookup.
+func resolveAdhoc(ctx context.Context, adhocRouter common.RuleAddresser, hostPort string) (string, *pb.Adhoc, error) {
+	_, span := tracing.StartSpan(ctx, "kedge.adhoc.resolve", tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("kedge.adhoc.host", hostPort)
+	addr, rule, err := adhocRouter.AddressWithRule(hostPort)
+	span.SetError(err)
+	span.SetAttribute("kedge.adhoc.address", addr)
+	return addr, rule, err
+}
+
 // NewGRPCAuthorizer builds a grpc_auth.AuthFunc that checks authorization header from gRPC request.
 func NewGRPCAuthorizer(authorizer authorize.Authorizer) grpc_auth.AuthFunc {
 	return func(ctx context.Context) (context.Context, error) {
@@ -69,7 +105,13 @@ func NewGRPCAuthorizer(authorizer authorize.Authorizer) grpc_auth.AuthFunc {
 			return ctx, err
 		}
 
-		return ctx, authorizer.IsAuthorized(ctx, token)
+		if err := authorizer.IsAuthorized(ctx, token); err != nil {
+			return ctx, err
+		}
+		if subject := common.TokenSubject(token); subject != "" {
+			grpc_ctxtags.Extract(ctx).Set(grpcutils.TagForProxyAuthSubject, subject)
+		}
+		return ctx, nil
 	}
 }
 
@@ -0,0 +1,20 @@
+package director
+
+import (
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"google.golang.org/grpc"
+)
+
+// StreamServerInterceptor reports latency of the calls handled by the director in kedge_proxy_*_duration_seconds
+// metrics.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		r := metrics.NewProxyRequest("grpc", metrics.ModeReverse)
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = metrics.ContextWithProxyRequest(stream.Context(), r)
+		err := handler(srv, wrapped)
+		r.Finish()
+		return err
+	}
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/improbable-eng/kedge/pkg/grpcutils"
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	"github.com/improbable-eng/kedge/pkg/kedge/grpc/retry"
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Route is a configured route together with helpers precomputed for routing.
+type Route struct {
+	*pb.Route
+
+	// Name is the name of the route used in metrics. It defaults to the position of the route.
+	Name string
+	// split is nil if the route does not specify weighted backends.
+	split *common.WeightedBackends
+
+	serviceNamePattern   *common.StringMatcher
+	methodPattern        *common.StringMatcher
+	authorityHostPattern *common.StringMatcher
+	metadataPatterns     map[string]*common.StringMatcher
+
+	// rateLimiter is nil if the route does not specify rate limit.
+	rateLimiter *common.RateLimiter
+	// retryPolicy is nil if the route does not override the backend's retry policy.
+	retryPolicy *retry.Policy
+}
+
+// newRoute compiles the route. Rate limiter of the route is taken from limiters (keyed by common.RateLimiterKey) if
+// present there.
+func newRoute(idx int, cnf *pb.Route, limiters map[string]*common.RateLimiter) (*Route, error) {
+	r := &Route{Route: cnf, Name: cnf.Name}
+	if r.Name == "" {
+		r.Name = strconv.Itoa(idx)
+	}
+
+	if len(cnf.WeightedBackends) > 0 {
+		var names []string
+		var weights []uint32
+		for _, b := range cnf.WeightedBackends {
+			names = append(names, b.BackendName)
+			weights = append(weights, b.Weight)
+		}
+		r.split = common.NewWeightedBackends(names, weights)
+	}
+
+	if cnf.RateLimit != nil {
+		r.rateLimiter = limiters[common.RateLimiterKey(r.Name, cnf.RateLimit)]
+		if r.rateLimiter == nil {
+			r.rateLimiter = common.NewRateLimiter(cnf.RateLimit)
+		}
+	}
+
+	var err error
+	if r.retryPolicy, err = retry.NewPolicy(cnf.Retry); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid retry", r.Name)
+	}
+	if r.serviceNamePattern, err = common.NewStringMatcher(cnf.ServiceNamePattern); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid service_name_pattern", r.Name)
+	}
+	if r.methodPattern, err = common.NewStringMatcher(cnf.MethodPattern); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid method_pattern", r.Name)
+	}
+	if r.authorityHostPattern, err = common.NewStringMatcher(cnf.AuthorityHostPattern); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid authority_host_pattern", r.Name)
+	}
+	r.metadataPatterns = map[string]*common.StringMatcher{}
+	for k, p := range cnf.MetadataPatterns {
+		if r.metadataPatterns[k], err = common.NewStringMatcher(p); err != nil {
+			return nil, errors.Wrapf(err, "route %v: invalid metadata_patterns", r.Name)
+		}
+	}
+	return r, nil
+}
+
+// ValidateRoutes returns an error if any of the routes cannot be used by the router, e.g. because of an invalid regex.
+func ValidateRoutes(routes []*pb.Route) error {
+	for i, r := range routes {
+		route, err := newRoute(i, r, nil)
+		if err != nil {
+			return err
+		}
+		if r.BackendName == "" && len(r.WeightedBackends) == 0 {
+			return errors.Errorf("route %v: either backend_name or weighted_backends is required", route.Name)
+		}
+	}
+	return nil
+}
+
+// RetryPolicy returns the retry policy of the route, or nil if it does not override the backend's policy.
+func (r *Route) RetryPolicy() *retry.Policy {
+	return r.retryPolicy
+}
+
+// allowRequest returns false if the call exceeds the rate limit of the route for the client that made it.
+func (r *Route) allowRequest(ctx context.Context, md metautils.NiceMD) bool {
+	if r.rateLimiter == nil {
+		return true
+	}
+
+	var key string
+	switch r.RateLimit.Key {
+	case pb_common.RateLimit_OIDC_SUBJECT:
+		key, _ = grpc_ctxtags.Extract(ctx).Values()[grpcutils.TagForProxyAuthSubject].(string)
+	case pb_common.RateLimit_CLIENT_CERT_CN:
+		if p, ok := peer.FromContext(ctx); ok {
+			if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+				key = tlsInfo.State.PeerCertificates[0].Subject.CommonName
+			}
+		}
+	case pb_common.RateLimit_HEADER:
+		key = md.Get(r.RateLimit.Header)
+	default:
+		if p, ok := peer.FromContext(ctx); ok {
+			key, _, _ = net.SplitHostPort(p.Addr.String())
+		}
+	}
+	return r.rateLimiter.Allow(key)
+}
@@ -1,12 +1,16 @@
 package router
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -18,12 +22,13 @@ import (
 var (
 	emptyMd          = metadata.Pairs()
 	ErrRouteNotFound = status.Errorf(codes.Unimplemented, "unknown route to service")
+	ErrRateLimited   = status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
 )
 
 // Router is an interface that decides what backend a given stream should be directed to.
 type Router interface {
-	// Route returns a backend name for a given call, or an error.
-	Route(ctx context.Context, fullMethodName string) (backendName string, err error)
+	// Route returns a backend name for a given call together with the matched route, or an error.
+	Route(ctx context.Context, fullMethodName string) (backendName string, route *Route, err error)
 }
 
 type dynamic struct {
@@ -37,16 +42,40 @@ func NewDynamic(logger logrus.FieldLogger) *dynamic {
 	return &dynamic{logger: logger, staticRouter: NewStatic(logger, []*pb.Route{})}
 }
 
-func (d *dynamic) Route(ctx context.Context, fullMethodName string) (backendName string, err error) {
+func (d *dynamic) Route(ctx context.Context, fullMethodName string) (backendName string, route *Route, err error) {
 	d.mu.RLock()
 	staticRouter := d.staticRouter
 	d.mu.RUnlock()
 	return staticRouter.Route(ctx, fullMethodName)
 }
 
-// Update sets the routing table to the provided set of routes.
+func (d *dynamic) Matches(ctx context.Context, fullMethodName string) bool {
+	d.mu.RLock()
+	staticRouter := d.staticRouter
+	d.mu.RUnlock()
+	return staticRouter.Matches(ctx, fullMethodName)
+}
+
+func (d *dynamic) CorsPolicy(ctx context.Context, fullMethodName string) *pb.Cors {
+	d.mu.RLock()
+	staticRouter := d.staticRouter
+	d.mu.RUnlock()
+	return staticRouter.CorsPolicy(ctx, fullMethodName)
+}
+
+func (d *dynamic) Explain(ctx context.Context, fullMethodName string) *Explanation {
+	d.mu.RLock()
+	staticRouter := d.staticRouter
+	d.mu.RUnlock()
+	return staticRouter.Explain(ctx, fullMethodName)
+}
+
+// Update sets the routing table to the provided set of routes. Rate limits of routes that did not change keep their state.
 func (d *dynamic) Update(routes []*pb.Route) {
-	staticRouter := NewStatic(d.logger, routes)
+	d.mu.RLock()
+	previous := d.staticRouter
+	d.mu.RUnlock()
+	staticRouter := newStatic(d.logger, routes, previous.rateLimiters)
 	d.mu.Lock()
 	d.staticRouter = staticRouter
 	d.mu.Unlock()
@@ -54,38 +83,179 @@ func (d *dynamic) Update(routes []*pb.Route) {
 
 type static struct {
 	logger logrus.FieldLogger
-	routes []*pb.Route
+	routes []*Route
+	// rateLimiters are rate limiters of the routes keyed by common.RateLimiterKey.
+	rateLimiters map[string]*common.RateLimiter
 }
 
+// NewStatic creates a router with the given routes. Routes that fail to compile are skipped, use ValidateRoutes to check
+// them upfront.
 func NewStatic(logger logrus.FieldLogger, routes []*pb.Route) *static {
-	return &static{logger: logger, routes: routes}
+	return newStatic(logger, routes, nil)
+}
+
+// newStatic creates a router reusing the given rate limiters for routes with the same name and rate limit.
+func newStatic(logger logrus.FieldLogger, routes []*pb.Route, rateLimiters map[string]*common.RateLimiter) *static {
+	s := &static{logger: logger, rateLimiters: map[string]*common.RateLimiter{}}
+	for i, r := range routes {
+		rt, err := newRoute(i, r, rateLimiters)
+		if err != nil {
+			logger.WithError(err).Error("Skipping invalid gRPC route.")
+			continue
+		}
+		if rt.rateLimiter != nil {
+			s.rateLimiters[common.RateLimiterKey(rt.Name, r.RateLimit)] = rt.rateLimiter
+		}
+		s.routes = append(s.routes, rt)
+	}
+	return s
 }
 
-func (r *static) Route(ctx context.Context, fullMethodName string) (backendName string, err error) {
+func (r *static) Route(ctx context.Context, fullMethodName string) (backendName string, matched *Route, err error) {
 	md := metautils.ExtractIncoming(ctx)
 
 	tags := grpc_ctxtags.Extract(ctx)
 	tags.Set("grpc.target.authority", md.Get(":authority"))
 
-	if strings.HasPrefix(fullMethodName, "/") {
-		fullMethodName = fullMethodName[1:]
+	route := r.match(md, fullMethodName)
+	if route == nil {
+		return "", nil, ErrRouteNotFound
+	}
+	backendName = r.pickBackend(md, route)
+	metrics.ProxyRequestFromContext(ctx).SetRoute(route.Name, backendName)
+	if !route.allowRequest(ctx, md) {
+		// There is no reporter for gRPC, so the error is counted here.
+		metrics.KedgeProxyErrors.WithLabelValues(backendName, string(errtypes.RateLimited)).Inc()
+		return "", nil, ErrRateLimited
+	}
+	metrics.RouteGRPCRequestsCounter.WithLabelValues(route.Name, backendName).Inc()
+	return backendName, route, nil
+}
+
+// Matches returns true if any route matches the given call. Unlike Route, it does not count the call in metrics or rate
+// limits.
+func (r *static) Matches(ctx context.Context, fullMethodName string) bool {
+	return r.match(metautils.ExtractIncoming(ctx), fullMethodName) != nil
+}
+
+// CorsPolicy returns the CORS policy of the route matching the given call, or nil if there is no such route or it
+// does not enable CORS. Unlike Route, it does not count the call in metrics or rate limits.
+func (r *static) CorsPolicy(ctx context.Context, fullMethodName string) *pb.Cors {
+	route := r.match(metautils.ExtractIncoming(ctx), fullMethodName)
+	if route == nil {
+		return nil
 	}
+	return route.Cors
+}
+
+// Explanation describes how a call is routed. It is used for debugging.
+type Explanation struct {
+	// Skipped are the routes before the matched one (or all routes if none matched) with the reason they do not match.
+	Skipped []SkippedRoute
+	// RouteName is the name of the matched route. Route and BackendName are empty if no route matched.
+	RouteName   string
+	Route       *pb.Route
+	BackendName string
+	// Split is set if the matched route splits traffic between weighted backends. BackendName is then the backend picked
+	// for StickyKey, or empty if the call has no sticky key and every call picks one of them randomly.
+	Split     []SplitBackend
+	StickyKey string
+}
+
+// SplitBackend is one of the weighted backends of a route.
+type SplitBackend struct {
+	Name   string
+	Weight uint32
+}
+
+// SkippedRoute is a route that does not match a call.
+type SkippedRoute struct {
+	Name   string
+	Reason string
+}
+
+// Explain routes the call like Route, but without counting it in metrics or rate limits, and tells why the routes
+// before the matched one were skipped.
+func (r *static) Explain(ctx context.Context, fullMethodName string) *Explanation {
+	md := metautils.ExtractIncoming(ctx)
+	e := &Explanation{}
 	for _, route := range r.routes {
-		if !r.serviceNameMatches(fullMethodName, route.ServiceNameMatcher) {
+		if reason := r.mismatch(md, strings.TrimPrefix(fullMethodName, "/"), route); reason != "" {
+			e.Skipped = append(e.Skipped, SkippedRoute{Name: route.Name, Reason: reason})
 			continue
 		}
-		if !r.authorityHostMatches(md, route.AuthorityHostMatcher) {
-			continue
+		e.RouteName = route.Name
+		e.Route = route.Route
+		if route.split == nil {
+			e.BackendName = route.BackendName
+			break
 		}
-		if !r.authorityPortMatches(md, route.AuthorityPortMatcher) {
-			continue
+		for _, b := range route.WeightedBackends {
+			e.Split = append(e.Split, SplitBackend{Name: b.BackendName, Weight: b.Weight})
 		}
-		if !r.metadataMatches(md, route.MetadataMatcher) {
-			continue
+		if e.StickyKey = r.stickyKey(md, route); e.StickyKey != "" {
+			e.BackendName = route.split.Pick(e.StickyKey)
+		}
+		break
+	}
+	return e
+}
+
+// match returns the first route matching the call, or nil.
+func (r *static) match(md metautils.NiceMD, fullMethodName string) *Route {
+	if strings.HasPrefix(fullMethodName, "/") {
+		fullMethodName = fullMethodName[1:]
+	}
+	for _, route := range r.routes {
+		if r.mismatch(md, fullMethodName, route) == "" {
+			return route
 		}
-		return route.BackendName, nil
 	}
-	return "", ErrRouteNotFound
+	return nil
+}
+
+// mismatch returns the reason why the route does not match the call, or empty string if it matches.
+func (r *static) mismatch(md metautils.NiceMD, fullMethodName string, route *Route) string {
+	if !r.serviceNameMatches(fullMethodName, route.ServiceNameMatcher) {
+		return fmt.Sprintf("method %q does not match service_name_matcher", fullMethodName)
+	}
+	if !r.authorityHostMatches(md, route.AuthorityHostMatcher) {
+		return fmt.Sprintf("authority %q does not match authority_host_matcher", md.Get(":authority"))
+	}
+	if !r.authorityPortMatches(md, route.AuthorityPortMatcher) {
+		return fmt.Sprintf("authority %q does not match authority_port_matcher", md.Get(":authority"))
+	}
+	if !r.metadataMatches(md, route.MetadataMatcher) {
+		return "metadata does not match metadata_matcher"
+	}
+	if !route.serviceNamePattern.Match(fullMethodName) {
+		return fmt.Sprintf("method %q does not match service_name_pattern", fullMethodName)
+	}
+	if !route.methodPattern.Match(fullMethodName) {
+		return fmt.Sprintf("method %q does not match method_pattern", fullMethodName)
+	}
+	if !r.authorityHostPatternMatches(md, route.authorityHostPattern) {
+		return fmt.Sprintf("authority %q does not match authority_host_pattern", md.Get(":authority"))
+	}
+	if !r.metadataPatternsMatch(md, route.metadataPatterns) {
+		return "metadata does not match metadata_patterns"
+	}
+	return ""
+}
+
+func (r *static) pickBackend(md metautils.NiceMD, route *Route) string {
+	if route.split == nil {
+		return route.BackendName
+	}
+	return route.split.Pick(r.stickyKey(md, route))
+}
+
+// stickyKey returns the metadata value that makes the weighted backend choice sticky, or empty string.
+func (r *static) stickyKey(md metautils.NiceMD, route *Route) string {
+	if route.StickyMetadataKey == "" {
+		return ""
+	}
+	return md.Get(route.StickyMetadataKey)
 }
 
 func (r *static) serviceNameMatches(fullMethodName string, matcher string) bool {
@@ -148,6 +318,37 @@ func (r *static) metadataMatches(md metautils.NiceMD, expectedKv map[string]stri
 	return true
 }
 
+func (r *static) authorityHostPatternMatches(md metautils.NiceMD, matcher *common.StringMatcher) bool {
+	if matcher.IsEmpty() {
+		return true
+	}
+	auth := md.Get(":authority")
+	if auth == "" {
+		return false // there was no authority header and it was expected
+	}
+	return matcher.Match(stripPort(auth))
+}
+
+func (r *static) metadataPatternsMatch(md metautils.NiceMD, matchers map[string]*common.StringMatcher) bool {
+	for k, m := range matchers {
+		vals, ok := md[strings.ToLower(k)]
+		if !ok {
+			return false // key doesn't exist
+		}
+		found := false
+		for _, v := range vals {
+			if m.Match(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func stripPort(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
 	if colon == -1 {
@@ -6,6 +6,7 @@ import (
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -56,7 +57,7 @@ func TestRouteMatches(t *testing.T) {
 ]}`
 	config := &pb.DirectorConfig_Grpc{}
 	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
-	r := &static{routes: config.Routes}
+	r := NewStatic(logrus.New(), config.Routes)
 
 	for _, tcase := range []struct {
 		name            string
@@ -122,7 +123,7 @@ func TestRouteMatches(t *testing.T) {
 	} {
 		t.Run(tcase.name, func(t *testing.T) {
 			ctx := metautils.NiceMD(tcase.md).ToIncoming(context.TODO())
-			be, err := r.Route(ctx, tcase.fullServiceName)
+			be, _, err := r.Route(ctx, tcase.fullServiceName)
 			if tcase.expectedErr != nil {
 				assert.Equal(t, tcase.expectedErr, err)
 				return
@@ -134,3 +135,303 @@ func TestRouteMatches(t *testing.T) {
 
 	}
 }
+
+func TestRouteWeightedBackends(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"serviceNameMatcher": "com.*",
+		"weightedBackends": [
+			{"backendName": "backend_stable", "weight": 50},
+			{"backendName": "backend_canary", "weight": 50},
+			{"backendName": "backend_disabled", "weight": 0}
+		],
+		"stickyMetadataKey": "x-user-id"
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		be, _, err := r.Route(context.TODO(), "com.example.MyService/Method")
+		require.NoError(t, err)
+		picked[be]++
+	}
+	assert.Len(t, picked, 2, "both non-zero weight backends should be picked")
+	assert.Equal(t, 0, picked["backend_disabled"])
+
+	stickyCtx := metautils.NiceMD(metadata.Pairs("x-user-id", "user1")).ToIncoming(context.TODO())
+	first, _, err := r.Route(stickyCtx, "com.example.MyService/Method")
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		be, _, err := r.Route(stickyCtx, "com.example.MyService/Method")
+		require.NoError(t, err)
+		assert.Equal(t, first, be, "requests with the same sticky metadata should be routed to the same backend")
+	}
+}
+
+func TestRoutePatterns(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"backendName": "backend_wildcard",
+		"serviceNamePattern": {"regex": "com\\.example\\.(a|b)\\..*"},
+		"authorityHostPattern": {"wildcard": "*.svc.example.com"}
+	},
+	{
+		"backendName": "backend_metadata",
+		"metadataPatterns": {"x-version": {"prefix": "canary-"}}
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+
+	for _, tcase := range []struct {
+		name            string
+		fullServiceName string
+		md              metadata.MD
+		expectedBackend string
+		expectedErr     error
+	}{
+		{
+			name:            "MatchesWildcardAuthorityAndRegexService",
+			fullServiceName: "com.example.a.MyService/Method",
+			md:              metadata.Pairs(":authority", "foo.svc.example.com:443"),
+			expectedBackend: "backend_wildcard",
+		},
+		{
+			name:            "NotMatchingServiceRegex",
+			fullServiceName: "com.example.c.MyService/Method",
+			md:              metadata.Pairs(":authority", "foo.svc.example.com:443"),
+			expectedErr:     ErrRouteNotFound,
+		},
+		{
+			name:            "NotMatchingAuthorityWildcard",
+			fullServiceName: "com.example.a.MyService/Method",
+			md:              metadata.Pairs(":authority", "foo.example.com"),
+			expectedErr:     ErrRouteNotFound,
+		},
+		{
+			name:            "MatchesMetadataPrefix",
+			fullServiceName: "com.example.c.MyService/Method",
+			md:              metadata.Pairs("x-version", "v1", "x-version", "canary-1"),
+			expectedBackend: "backend_metadata",
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			ctx := metautils.NiceMD(tcase.md).ToIncoming(context.TODO())
+			be, _, err := r.Route(ctx, tcase.fullServiceName)
+			if tcase.expectedErr != nil {
+				assert.Equal(t, tcase.expectedErr, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expectedBackend, be, "must match expected backend")
+		})
+	}
+}
+
+func TestRouteRateLimit(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"backendName": "backend_limited",
+		"rateLimit": {"requestsPerSecond": 0.001, "burst": 2, "key": "HEADER", "header": "x-client"}
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+
+	ctxA := metautils.NiceMD(metadata.Pairs("x-client", "a")).ToIncoming(context.TODO())
+	ctxB := metautils.NiceMD(metadata.Pairs("x-client", "b")).ToIncoming(context.TODO())
+	for i := 0; i < 2; i++ {
+		_, _, err := r.Route(ctxA, "com.example.MyService/Method")
+		require.NoError(t, err)
+	}
+	_, _, err := r.Route(ctxA, "com.example.MyService/Method")
+	assert.Equal(t, ErrRateLimited, err)
+
+	_, _, err = r.Route(ctxB, "com.example.MyService/Method")
+	assert.NoError(t, err, "other clients should not be limited")
+}
+
+func TestDynamicUpdateKeepsRateLimits(t *testing.T) {
+	routes := func(configJson string) *pb.DirectorConfig_Grpc {
+		config := &pb.DirectorConfig_Grpc{}
+		require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+		return config
+	}
+	limited := `{"name": "limited", "backendName": "backend_limited", "rateLimit": {"requestsPerSecond": 0.001, "burst": 1}}`
+	other := `{"name": "other", "backendName": "backend_other", "serviceNameMatcher": "com.example.Other*"}`
+	r := NewDynamic(logrus.New())
+	r.Update(routes(`{"routes": [` + limited + `]}`).Routes)
+
+	ctx := metautils.NiceMD(metadata.Pairs()).ToIncoming(context.TODO())
+	_, _, err := r.Route(ctx, "com.example.MyService/Method")
+	require.NoError(t, err)
+
+	r.Update(routes(`{"routes": [` + other + `, ` + limited + `]}`).Routes)
+	_, _, err = r.Route(ctx, "com.example.MyService/Method")
+	assert.Equal(t, ErrRateLimited, err, "update of other routes should not reset the rate limit")
+
+	r.Update(routes(`{"routes": [{"name": "limited", "backendName": "backend_limited", "rateLimit": {"requestsPerSecond": 0.001, "burst": 2}}]}`).Routes)
+	_, _, err = r.Route(ctx, "com.example.MyService/Method")
+	assert.NoError(t, err, "changed rate limit should start with a full bucket")
+}
+
+func TestRouteCorsPolicy(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"backendName": "backend_web",
+		"serviceNameMatcher": "com.example.web.*",
+		"rateLimit": {"requestsPerSecond": 0.001, "burst": 1},
+		"cors": {"allowedOrigins": ["https://app.example.com"]}
+	},
+	{
+		"backendName": "backend_other",
+		"serviceNameMatcher": "*"
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+
+	ctx := metautils.NiceMD(metadata.Pairs()).ToIncoming(context.TODO())
+	for i := 0; i < 2; i++ {
+		policy := r.CorsPolicy(ctx, "/com.example.web.MyService/Method")
+		require.NotNil(t, policy)
+		assert.Equal(t, []string{"https://app.example.com"}, policy.AllowedOrigins)
+	}
+	_, _, err := r.Route(ctx, "/com.example.web.MyService/Method")
+	assert.NoError(t, err, "looking up CORS policy should not count in the rate limit")
+
+	assert.Nil(t, r.CorsPolicy(ctx, "/com.example.other.MyService/Method"))
+}
+
+func TestRouteMethodPatternAndTimeouts(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"backendName": "backend_watch",
+		"serviceNameMatcher": "com.example.*",
+		"methodPattern": {"wildcard": "com.example.MyService/Watch*"},
+		"timeouts": {"maxStreamDurationMs": 3600000}
+	},
+	{
+		"backendName": "backend_unary",
+		"serviceNameMatcher": "com.example.*",
+		"timeouts": {"defaultDeadlineMs": 1000, "maxDeadlineMs": 5000}
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+	ctx := metautils.NiceMD(metadata.Pairs()).ToIncoming(context.TODO())
+
+	be, route, err := r.Route(ctx, "/com.example.MyService/WatchItems")
+	require.NoError(t, err)
+	assert.Equal(t, "backend_watch", be)
+	assert.Equal(t, uint32(3600000), route.Timeouts.MaxStreamDurationMs)
+
+	be, route, err = r.Route(ctx, "/com.example.MyService/GetItem")
+	require.NoError(t, err)
+	assert.Equal(t, "backend_unary", be)
+	require.NotNil(t, route.Timeouts)
+	assert.Equal(t, uint32(1000), route.Timeouts.DefaultDeadlineMs)
+	assert.Equal(t, uint32(5000), route.Timeouts.MaxDeadlineMs)
+
+	_, route, err = r.Route(ctx, "/org.example.MyService/GetItem")
+	assert.Equal(t, ErrRouteNotFound, err)
+	assert.Nil(t, route)
+}
+
+func TestRouteRetryPolicy(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"backendName": "backend_a",
+		"methodPattern": {"wildcard": "com.example.MyService/Get*"},
+		"retry": {"maxAttempts": 3, "retryableCodes": ["UNAVAILABLE", "ABORTED"]}
+	},
+	{
+		"backendName": "backend_a"
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	require.NoError(t, ValidateRoutes(config.Routes))
+	r := NewStatic(logrus.New(), config.Routes)
+	ctx := metautils.NiceMD(metadata.Pairs()).ToIncoming(context.TODO())
+
+	_, route, err := r.Route(ctx, "/com.example.MyService/GetItem")
+	require.NoError(t, err)
+	assert.NotNil(t, route.RetryPolicy())
+	_, route, err = r.Route(ctx, "/com.example.MyService/DeleteItem")
+	require.NoError(t, err)
+	assert.Nil(t, route.RetryPolicy(), "backend's policy should be used")
+
+	config.Routes[0].Retry.RetryableCodes = []string{"NOT_A_CODE"}
+	assert.Error(t, ValidateRoutes(config.Routes))
+}
+
+func TestValidateRoutes_RequiresBackend(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"name": "weighted",
+		"weightedBackends": [{"backendName": "backendA", "weight": 1}],
+		"serviceNameMatcher": "com.example.*"
+	},
+	{
+		"name": "no_backend",
+		"serviceNameMatcher": "com.example.*"
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	require.NoError(t, ValidateRoutes(config.Routes[:1]))
+	assert.Error(t, ValidateRoutes(config.Routes))
+}
+
+func TestExplain(t *testing.T) {
+	configJson := `
+{ "routes": [
+	{
+		"name": "a",
+		"backendName": "backendA",
+		"serviceNameMatcher": "com.example.a.*"
+	},
+	{
+		"name": "b",
+		"backendName": "backendB",
+		"serviceNameMatcher": "com.*",
+		"authorityHostMatcher": "authority_b.service.local"
+	},
+	{
+		"backendName": "backendC",
+		"serviceNameMatcher": "com.*"
+	}
+]}`
+	config := &pb.DirectorConfig_Grpc{}
+	require.NoError(t, jsonpb.UnmarshalString(configJson, config))
+	r := NewStatic(logrus.New(), config.Routes)
+
+	ctx := metautils.NiceMD(metadata.Pairs(":authority", "authority_c.service.local")).ToIncoming(context.TODO())
+	e := r.Explain(ctx, "/com.example.b.MyService/Method")
+	assert.Equal(t, "2", e.RouteName)
+	assert.Equal(t, "backendC", e.BackendName)
+	assert.Equal(t, []SkippedRoute{
+		{Name: "a", Reason: `method "com.example.b.MyService/Method" does not match service_name_matcher`},
+		{Name: "b", Reason: `authority "authority_c.service.local" does not match authority_host_matcher`},
+	}, e.Skipped)
+
+	e = r.Explain(ctx, "/org.example.MyService/Method")
+	assert.Nil(t, e.Route)
+	assert.Len(t, e.Skipped, 3)
+}
@@ -0,0 +1,91 @@
+package director
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	"golang.org/x/net/context"
+)
+
+// withTimeouts applies timeouts of the matched route to the context of a call. Deadlines end up in the context
+// deadline, so they are propagated to the backend. The max stream duration is enforced only by kedge.
+func withTimeouts(ctx context.Context, timeouts *pb.Timeouts) context.Context {
+	if timeouts == nil {
+		return ctx
+	}
+
+	var cancels []context.CancelFunc
+	if _, ok := ctx.Deadline(); !ok && timeouts.DefaultDeadlineMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, msToDuration(timeouts.DefaultDeadlineMs))
+		cancels = append(cancels, cancel)
+	}
+	if timeouts.MaxDeadlineMs > 0 {
+		maxDeadline := msToDuration(timeouts.MaxDeadlineMs)
+		if deadline, ok := ctx.Deadline(); !ok || deadline.Sub(time.Now()) > maxDeadline {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, maxDeadline)
+			cancels = append(cancels, cancel)
+		}
+	}
+	if timeouts.MaxStreamDurationMs > 0 {
+		ctx = withMaxStreamDuration(ctx, msToDuration(timeouts.MaxStreamDurationMs))
+	}
+
+	if len(cancels) > 0 {
+		// The call ends when the server stream context is done. Release the timers then.
+		go func() {
+			<-ctx.Done()
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}()
+	}
+	return ctx
+}
+
+func msToDuration(ms uint32) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// streamDurationCtx is done with DeadlineExceeded once the max stream duration passes. Unlike context.WithTimeout, it
+// does not change the Deadline of the context, so the limit is not sent to the backend as the call's deadline.
+type streamDurationCtx struct {
+	context.Context
+	done chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func withMaxStreamDuration(parent context.Context, maxDuration time.Duration) context.Context {
+	c := &streamDurationCtx{Context: parent, done: make(chan struct{})}
+	go func() {
+		timer := time.NewTimer(maxDuration)
+		defer timer.Stop()
+
+		var err error
+		select {
+		case <-timer.C:
+			err = context.DeadlineExceeded
+		case <-parent.Done():
+			err = parent.Err()
+		}
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+	}()
+	return c
+}
+
+func (c *streamDurationCtx) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *streamDurationCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
@@ -0,0 +1,65 @@
+package director
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestWithTimeouts_AppliesDefaultDeadline(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := withTimeouts(parent, &pb.Timeouts{DefaultDeadlineMs: 1000})
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok, "call without deadline should get the default one")
+	assert.InDelta(t, float64(time.Second), float64(deadline.Sub(time.Now())), float64(100*time.Millisecond))
+
+	parentWithDeadline, cancel := context.WithTimeout(parent, time.Hour)
+	defer cancel()
+	ctx = withTimeouts(parentWithDeadline, &pb.Timeouts{DefaultDeadlineMs: 1000})
+	deadline, _ = ctx.Deadline()
+	assert.True(t, deadline.Sub(time.Now()) > time.Minute, "deadline sent by the client should be kept")
+}
+
+func TestWithTimeouts_CapsDeadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	ctx := withTimeouts(parent, &pb.Timeouts{MaxDeadlineMs: 1000})
+	deadline, _ := ctx.Deadline()
+	assert.InDelta(t, float64(time.Second), float64(deadline.Sub(time.Now())), float64(100*time.Millisecond))
+
+	ctx = withTimeouts(context.Background(), &pb.Timeouts{MaxDeadlineMs: 1000})
+	_, ok := ctx.Deadline()
+	assert.True(t, ok, "call without deadline should get the max one")
+
+	shortParent, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ctx = withTimeouts(shortParent, &pb.Timeouts{MaxDeadlineMs: 1000})
+	assert.True(t, ctx == shortParent, "deadline within the cap should be kept")
+}
+
+func TestWithTimeouts_EndsLongStreams(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := withTimeouts(parent, &pb.Timeouts{MaxStreamDurationMs: 20})
+	_, ok := ctx.Deadline()
+	assert.False(t, ok, "max stream duration should not be propagated as deadline")
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream should end after max stream duration")
+	}
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	ctx = withTimeouts(parent, &pb.Timeouts{MaxStreamDurationMs: 1000})
+	cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err(), "stream should end with its parent")
+}
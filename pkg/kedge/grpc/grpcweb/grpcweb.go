@@ -0,0 +1,285 @@
+// Package grpcweb translates gRPC-Web requests (https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md) into
+// native gRPC, so browser clients can call gRPC backends through kedge.
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	contentTypeGrpc        = "application/grpc"
+	contentTypeGrpcWeb     = "application/grpc-web"
+	contentTypeGrpcWebText = "application/grpc-web-text"
+
+	// trailerFrameFlag marks the gRPC-Web frame carrying trailers.
+	trailerFrameFlag = 0x80
+)
+
+var (
+	// Headers that gRPC-Web clients send and need to be always allowed.
+	defaultAllowedHeaders = []string{"content-type", "x-grpc-web", "x-user-agent", "grpc-timeout"}
+	// Headers that gRPC-Web clients read and need to be always exposed.
+	defaultExposedHeaders = []string{"grpc-status", "grpc-message"}
+)
+
+// Routes are the gRPC routes that gRPC-Web requests are served for.
+type Routes interface {
+	// Matches returns true if any route matches the given call.
+	Matches(ctx context.Context, fullMethodName string) bool
+	// CorsPolicy returns CORS policy of the route for the given call, or nil if CORS is not enabled for it.
+	CorsPolicy(ctx context.Context, fullMethodName string) *pb.Cors
+}
+
+// IsGrpcWebRequest returns true for gRPC-Web calls and for CORS preflight requests made by gRPC-Web clients.
+func IsGrpcWebRequest(req *http.Request) bool {
+	if req.Method == http.MethodPost && strings.HasPrefix(req.Header.Get("content-type"), contentTypeGrpcWeb) {
+		return true
+	}
+	return isCorsPreflight(req)
+}
+
+func isCorsPreflight(req *http.Request) bool {
+	if req.Method != http.MethodOptions || req.Header.Get("Origin") == "" {
+		return false
+	}
+	for _, h := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), "x-grpc-web") {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler serves gRPC-Web requests using the native gRPC handler (e.g. grpc.Server).
+type Handler struct {
+	grpcHandler http.Handler
+	routes      Routes
+}
+
+// NewHandler returns handler translating gRPC-Web requests for grpcHandler. CORS is allowed according to policies of
+// the routes matching the requests.
+func NewHandler(grpcHandler http.Handler, routes Routes) *Handler {
+	return &Handler{grpcHandler: grpcHandler, routes: routes}
+}
+
+// Handles returns true for gRPC-Web calls (and their CORS preflight requests) matching a gRPC route. Other requests,
+// including gRPC-Web calls for HTTP backends, should be served by the HTTP proxy. Preflight requests do not carry
+// metadata, so only routes not depending on it can be matched for them.
+func (h *Handler) Handles(req *http.Request) bool {
+	return IsGrpcWebRequest(req) && h.routes.Matches(incomingContext(req), req.URL.Path)
+}
+
+func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if isCorsPreflight(req) {
+		h.servePreflight(resp, req)
+		return
+	}
+
+	if origin := req.Header.Get("Origin"); origin != "" {
+		if policy := h.corsPolicy(req); originAllowed(policy, origin) {
+			setCorsHeaders(resp.Header(), policy, origin)
+			resp.Header().Set("Access-Control-Expose-Headers", strings.Join(append(defaultExposedHeaders, policy.ExposedHeaders...), ", "))
+		}
+	}
+
+	contentType := req.Header.Get("content-type")
+	text := strings.HasPrefix(contentType, contentTypeGrpcWebText)
+	w := &responseWriter{wrapped: resp, header: http.Header{}, contentType: contentType, text: text}
+	h.grpcHandler.ServeHTTP(w, translateRequest(req, text))
+	w.finish()
+}
+
+func (h *Handler) servePreflight(resp http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	policy := h.corsPolicy(req)
+	if !originAllowed(policy, origin) {
+		resp.WriteHeader(http.StatusForbidden)
+		return
+	}
+	setCorsHeaders(resp.Header(), policy, origin)
+	resp.Header().Set("Access-Control-Allow-Methods", http.MethodPost)
+	resp.Header().Set("Access-Control-Allow-Headers", strings.Join(append(defaultAllowedHeaders, policy.AllowedHeaders...), ", "))
+	if policy.MaxAgeS > 0 {
+		resp.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAgeS)))
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// corsPolicy finds policy of the route for the request. Preflight requests do not carry metadata, so only routes
+// not depending on it can be matched for them.
+func (h *Handler) corsPolicy(req *http.Request) *pb.Cors {
+	return h.routes.CorsPolicy(incomingContext(req), req.URL.Path)
+}
+
+// incomingContext returns context of the request with its headers as incoming gRPC metadata, as routers expect.
+func incomingContext(req *http.Request) context.Context {
+	md := metadata.MD{}
+	for k, v := range req.Header {
+		md[strings.ToLower(k)] = v
+	}
+	md[":authority"] = []string{req.Host}
+	return metadata.NewIncomingContext(req.Context(), md)
+}
+
+func originAllowed(policy *pb.Cors, origin string) bool {
+	for _, o := range policy.GetAllowedOrigins() {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func setCorsHeaders(header http.Header, policy *pb.Cors, origin string) {
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Add("Vary", "Origin")
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// translateRequest returns native gRPC request for the given gRPC-Web request.
+func translateRequest(req *http.Request, text bool) *http.Request {
+	grpcReq := req.WithContext(req.Context())
+	// gRPC handler accepts only HTTP/2 requests, but it does not depend on the protocol otherwise.
+	grpcReq.Proto, grpcReq.ProtoMajor, grpcReq.ProtoMinor = "HTTP/2.0", 2, 0
+
+	grpcReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		grpcReq.Header[k] = v
+	}
+	contentType := req.Header.Get("content-type")
+	if text {
+		contentType = strings.TrimPrefix(contentType, contentTypeGrpcWebText)
+	} else {
+		contentType = strings.TrimPrefix(contentType, contentTypeGrpcWeb)
+	}
+	grpcReq.Header.Set("content-type", contentTypeGrpc+contentType)
+	grpcReq.Header.Del("content-length")
+	grpcReq.ContentLength = -1
+
+	if text {
+		grpcReq.Body = &readCloser{
+			Reader: base64.NewDecoder(base64.StdEncoding, req.Body),
+			Closer: req.Body,
+		}
+	}
+	return grpcReq
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter translates native gRPC response into gRPC-Web. gRPC sends trailers as HTTP/2 trailers, which are
+// not available to browsers, so they are sent in the last frame of the body instead.
+type responseWriter struct {
+	wrapped http.ResponseWriter
+	header  http.Header
+	// contentType of the response is the same as of the request.
+	contentType string
+	text        bool
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	declaredTrailers := map[string]bool{}
+	for _, k := range w.header["Trailer"] {
+		declaredTrailers[http.CanonicalHeaderKey(k)] = true
+	}
+	for k, v := range w.header {
+		if k == "Trailer" || declaredTrailers[k] || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		w.wrapped.Header()[k] = v
+	}
+
+	w.wrapped.Header().Set("content-type", w.contentType)
+	w.wrapped.Header().Del("content-length")
+	w.wrapped.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.text {
+		return w.wrapped.Write(b)
+	}
+	// Every write is encoded separately; gRPC-Web clients accept concatenated base64 chunks.
+	if _, err := io.WriteString(w.wrapped, base64.StdEncoding.EncodeToString(b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.wrapped.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.wrapped.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// finish writes trailers as the last gRPC-Web frame.
+func (w *responseWriter) finish() {
+	trailers := map[string][]string{}
+	for _, k := range w.header["Trailer"] {
+		if v, ok := w.header[http.CanonicalHeaderKey(k)]; ok {
+			trailers[strings.ToLower(k)] = v
+		}
+	}
+	for k, v := range w.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			trailers[strings.ToLower(strings.TrimPrefix(k, http.TrailerPrefix))] = v
+		}
+	}
+
+	keys := make([]string, 0, len(trailers))
+	for k := range trailers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	payload := &bytes.Buffer{}
+	for _, k := range keys {
+		for _, v := range trailers[k] {
+			fmt.Fprintf(payload, "%s: %s\r\n", k, v)
+		}
+	}
+
+	frame := make([]byte, 5, 5+payload.Len())
+	frame[0] = trailerFrameFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(payload.Len()))
+	w.Write(append(frame, payload.Bytes()...))
+	w.Flush()
+}
@@ -0,0 +1,186 @@
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeRoutes has a single route for the "kedge.example.com" authority.
+type fakeRoutes struct {
+	policy *pb.Cors
+}
+
+func (f *fakeRoutes) Matches(ctx context.Context, _ string) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return len(md[":authority"]) > 0 && md[":authority"][0] == "kedge.example.com"
+}
+
+func (f *fakeRoutes) CorsPolicy(ctx context.Context, fullMethodName string) *pb.Cors {
+	if !f.Matches(ctx, fullMethodName) {
+		return nil
+	}
+	return f.policy
+}
+
+func newTestHandler(policy *pb.Cors) *Handler {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("svc", healthpb.HealthCheckResponse_SERVING)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	return NewHandler(grpcServer, &fakeRoutes{policy: policy})
+}
+
+func frame(flag byte, payload []byte) []byte {
+	f := make([]byte, 5)
+	f[0] = flag
+	binary.BigEndian.PutUint32(f[1:], uint32(len(payload)))
+	return append(f, payload...)
+}
+
+func healthCheckRequest(t *testing.T, contentType string, text bool) *http.Request {
+	msg, err := proto.Marshal(&healthpb.HealthCheckRequest{Service: "svc"})
+	require.NoError(t, err)
+	body := frame(0, msg)
+	if text {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+	req := httptest.NewRequest("POST", "https://kedge.example.com/grpc.health.v1.Health/Check", bytes.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	req.Header.Set("x-grpc-web", "1")
+	return req
+}
+
+// readFrames returns data frame payloads and trailers from the gRPC-Web response body.
+func readFrames(t *testing.T, body []byte) (data [][]byte, trailers string) {
+	for len(body) > 0 {
+		require.True(t, len(body) >= 5, "frame header expected")
+		length := binary.BigEndian.Uint32(body[1:5])
+		payload := body[5 : 5+length]
+		if body[0]&trailerFrameFlag != 0 {
+			trailers = string(payload)
+		} else {
+			data = append(data, payload)
+		}
+		body = body[5+length:]
+	}
+	return data, trailers
+}
+
+func assertHealthCheckResponse(t *testing.T, body []byte) {
+	data, trailers := readFrames(t, body)
+	require.Len(t, data, 1)
+	resp := &healthpb.HealthCheckResponse{}
+	require.NoError(t, proto.Unmarshal(data[0], resp))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Contains(t, trailers, "grpc-status: 0\r\n")
+}
+
+func TestHandler_Binary(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestHandler(nil).ServeHTTP(rec, healthCheckRequest(t, "application/grpc-web+proto", false))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/grpc-web+proto", rec.Header().Get("content-type"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), "CORS is not enabled")
+	assertHealthCheckResponse(t, rec.Body.Bytes())
+}
+
+func TestHandler_Text(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestHandler(nil).ServeHTTP(rec, healthCheckRequest(t, "application/grpc-web-text", true))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/grpc-web-text", rec.Header().Get("content-type"))
+	body, err := decodeBase64Chunks(rec.Body.String())
+	require.NoError(t, err)
+	assertHealthCheckResponse(t, body)
+}
+
+// decodeBase64Chunks decodes concatenated base64 chunks, each with its own padding.
+func decodeBase64Chunks(s string) ([]byte, error) {
+	var out []byte
+	for len(s) > 0 {
+		end := strings.IndexByte(s, '=')
+		if end < 0 {
+			end = len(s)
+		}
+		for end < len(s) && s[end] == '=' {
+			end++
+		}
+		b, err := base64.StdEncoding.DecodeString(s[:end])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+		s = s[end:]
+	}
+	return out, nil
+}
+
+func TestHandler_CorsAllowedOrigin(t *testing.T) {
+	h := newTestHandler(&pb.Cors{AllowedOrigins: []string{"https://app.example.com"}, ExposedHeaders: []string{"x-custom"}, MaxAgeS: 600})
+
+	preflight := httptest.NewRequest("OPTIONS", "https://kedge.example.com/grpc.health.v1.Health/Check", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", "POST")
+	preflight.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+	require.True(t, IsGrpcWebRequest(preflight))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "x-grpc-web")
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+
+	req := healthCheckRequest(t, "application/grpc-web+proto", false)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "grpc-status, grpc-message, x-custom", rec.Header().Get("Access-Control-Expose-Headers"))
+	assertHealthCheckResponse(t, rec.Body.Bytes())
+}
+
+func TestHandler_CorsNotAllowedOrigin(t *testing.T) {
+	h := newTestHandler(&pb.Cors{AllowedOrigins: []string{"https://app.example.com"}})
+
+	preflight := httptest.NewRequest("OPTIONS", "https://kedge.example.com/grpc.health.v1.Health/Check", nil)
+	preflight.Header.Set("Origin", "https://evil.example.com")
+	preflight.Header.Set("Access-Control-Request-Headers", "x-grpc-web")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestHandler_HandlesOnlyRoutedRequests(t *testing.T) {
+	h := newTestHandler(&pb.Cors{AllowedOrigins: []string{"*"}})
+
+	assert.True(t, h.Handles(healthCheckRequest(t, "application/grpc-web+proto", false)))
+
+	req := healthCheckRequest(t, "application/grpc-web+proto", false)
+	req.Host = "http-backend.example.com"
+	assert.False(t, h.Handles(req), "gRPC-Web request without gRPC route should be left to the HTTP proxy")
+
+	preflight := httptest.NewRequest("OPTIONS", "https://http-backend.example.com/grpc.health.v1.Health/Check", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Headers", "x-grpc-web")
+	assert.False(t, h.Handles(preflight))
+
+	assert.False(t, h.Handles(httptest.NewRequest("GET", "https://kedge.example.com/", nil)))
+}
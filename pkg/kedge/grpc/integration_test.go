@@ -35,6 +35,7 @@ import (
 	"github.com/mwitkow/go-conntrack/connhelpers"
 	"github.com/mwitkow/grpc-proxy/proxy"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -209,6 +210,7 @@ type BackendPoolIntegrationTestSuite struct {
 	proxy         *grpc.Server
 	proxyListener net.Listener
 	pool          backendpool.Pool
+	adhocConns    *adhoc.ConnPool
 
 	proxyConn           *grpc.ClientConn
 	kedgeMapper         kedge_map.Mapper
@@ -265,18 +267,19 @@ func (s *BackendPoolIntegrationTestSuite) SetupSuite() {
 
 	s.buildBackends()
 
-	s.pool, err = backendpool.NewStatic(backendConfigs)
+	s.pool, err = backendpool.NewStatic(backendConfigs, nil)
 	require.NoError(s.T(), err, "backend pool creation must not fail")
 	staticRouter := router.NewStatic(logrus.New(), routeConfigs)
 	adhocAddresser := adhoc.NewStaticAddresser(adhocConfig)
-	dir := director.New(s.pool, adhocAddresser, staticRouter)
+	s.adhocConns = adhoc.NewConnPool()
+	dir := director.New(s.pool, adhocAddresser, s.adhocConns, staticRouter)
 
 	grpcAuth := director.NewGRPCAuthorizer(&testAuthorizer{expectedToken: testToken, returnErr: nil})
 	s.proxy = grpc.NewServer(
 		grpc.CustomCodec(proxy.Codec()),
 		grpc.UnknownServiceHandler(proxy.TransparentHandler(dir)),
 		grpc_middleware.WithUnaryServerChain(grpc_auth.UnaryServerInterceptor(grpcAuth)),
-		grpc_middleware.WithStreamServerChain(grpc_auth.StreamServerInterceptor(grpcAuth)),
+		grpc_middleware.WithStreamServerChain(grpc_auth.StreamServerInterceptor(grpcAuth), director.StreamServerInterceptor()),
 		grpc.Creds(credentials.NewTLS(s.tlsConfigForTest())),
 	)
 
@@ -332,6 +335,34 @@ func (s *BackendPoolIntegrationTestSuite) TestCallToNonSecureBackend() {
 	assert.Equal(s.T(), "nonsecure_localbackends", resp.Backend)
 }
 
+func (s *BackendPoolIntegrationTestSuite) TestCallToNonSecureBackend_ReportsLatencyMetrics() {
+	resp := &unknownResponse{}
+	err := grpc.Invoke(s.SimpleCtx(), "/hand_rolled.non_secure.SomeService/Method", &unknownResponse{}, resp, s.proxyConn)
+	require.NoError(s.T(), err, "no error on simple call")
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(s.T(), err)
+	var upstreamSamples uint64
+	for _, f := range families {
+		if f.GetName() != "kedge_proxy_upstream_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["protocol"] == "grpc" && labels["backend_name"] == "non_secure" && labels["mode"] == "reverse" &&
+				labels["error_type"] == "none" {
+				assert.NotEmpty(s.T(), labels["route"], "route should be reported")
+				assert.NotEmpty(s.T(), labels["target"], "target picked by the balancer should be reported")
+				upstreamSamples += m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	assert.True(s.T(), upstreamSamples > 0, "upstream duration should be reported for the route")
+}
+
 func (s *BackendPoolIntegrationTestSuite) TestCallToSecureBackend() {
 	resp := &unknownResponse{}
 	err := grpc.Invoke(s.SimpleCtx(), "/hand_rolled.secure.SomeService/Method", &unknownResponse{}, resp, s.proxyConn)
@@ -423,6 +454,7 @@ func (s *BackendPoolIntegrationTestSuite) TestCallToUnknownBackend() {
 func (s *BackendPoolIntegrationTestSuite) TearDownSuite() {
 	s.proxyConn.Close()
 	s.pool.Close()
+	s.adhocConns.Close()
 	// Restore old resolver.
 	if s.originalSrvResolver != nil {
 		srvresolver.ParentSrvResolver = s.originalSrvResolver
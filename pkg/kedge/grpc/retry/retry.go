@@ -0,0 +1,420 @@
+// Package retry retries and hedges gRPC calls proxied to backends.
+//
+// The proxy streams frames in both directions without knowing whether a call is unary or streaming. Calls are retried
+// only if the client sent a single (buffered) request message and closed its side of the stream, which is the case for
+// unary and server streaming calls. Once any response message is received from the backend the call is committed to
+// that attempt and is not retried anymore.
+package retry
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	"github.com/mwitkow/grpc-proxy/proxy"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	defaultMaxBufferBytes = 64 * 1024
+	// maxHalfCloseWait is how long a failed attempt waits for the client to finish sending the request, so the
+	// request can be replayed. Calls that do not finish sending in time (e.g. bidi streams) are not retried.
+	maxHalfCloseWait = 100 * time.Millisecond
+)
+
+var (
+	attemptsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "grpc",
+			Name:      "backend_additional_attempts_total",
+			Help:      "Total number of additional attempts of gRPC calls to backends, by the kind of attempt (retry or hedge).",
+		},
+		[]string{"backend", "kind"},
+	)
+
+	codesByName = map[string]codes.Code{
+		"CANCELLED":           codes.Canceled,
+		"UNKNOWN":             codes.Unknown,
+		"INVALID_ARGUMENT":    codes.InvalidArgument,
+		"DEADLINE_EXCEEDED":   codes.DeadlineExceeded,
+		"NOT_FOUND":           codes.NotFound,
+		"ALREADY_EXISTS":      codes.AlreadyExists,
+		"PERMISSION_DENIED":   codes.PermissionDenied,
+		"RESOURCE_EXHAUSTED":  codes.ResourceExhausted,
+		"FAILED_PRECONDITION": codes.FailedPrecondition,
+		"ABORTED":             codes.Aborted,
+		"OUT_OF_RANGE":        codes.OutOfRange,
+		"UNIMPLEMENTED":       codes.Unimplemented,
+		"INTERNAL":            codes.Internal,
+		"UNAVAILABLE":         codes.Unavailable,
+		"DATA_LOSS":           codes.DataLoss,
+		"UNAUTHENTICATED":     codes.Unauthenticated,
+	}
+)
+
+func init() {
+	prometheus.MustRegister(attemptsCounter)
+}
+
+// Policy is a compiled pb.RetryPolicy.
+type Policy struct {
+	maxAttempts    int
+	retryableCodes map[codes.Code]bool
+	backoff        time.Duration
+	hedgingDelay   time.Duration
+	maxBufferBytes int
+}
+
+// NewPolicy compiles given policy config. Nil config gives nil policy.
+func NewPolicy(cnf *pb.RetryPolicy) (*Policy, error) {
+	if cnf == nil {
+		return nil, nil
+	}
+	p := &Policy{
+		maxAttempts:    int(cnf.MaxAttempts),
+		retryableCodes: map[codes.Code]bool{},
+		backoff:        time.Duration(cnf.BackoffMs) * time.Millisecond,
+		hedgingDelay:   time.Duration(cnf.HedgingDelayMs) * time.Millisecond,
+		maxBufferBytes: int(cnf.MaxBufferBytes),
+	}
+	if p.maxBufferBytes == 0 {
+		p.maxBufferBytes = defaultMaxBufferBytes
+	}
+	if len(cnf.RetryableCodes) == 0 {
+		p.retryableCodes[codes.Unavailable] = true
+	}
+	for _, name := range cnf.RetryableCodes {
+		code, ok := codesByName[strings.ToUpper(name)]
+		if !ok {
+			return nil, errors.Errorf("unknown gRPC status code %q in retry policy", name)
+		}
+		p.retryableCodes[code] = true
+	}
+	return p, nil
+}
+
+func (p *Policy) retryable(err error) bool {
+	return p.retryableCodes[grpc.Code(err)]
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a context that makes calls use the given policy instead of the backend's one.
+func WithPolicy(ctx context.Context, policy *Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// StreamClientInterceptor returns an interceptor retrying calls to the given backend according to the policy from the
+// call's context (see WithPolicy) or, if there is none, the backend's policy. Policy can be nil.
+func StreamClientInterceptor(backendName string, backendPolicy *Policy) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		policy := backendPolicy
+		if p, ok := ctx.Value(policyKey{}).(*Policy); ok {
+			policy = p
+		}
+		if policy == nil || policy.maxAttempts < 2 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		s := &stream{
+			ctx:         ctx,
+			backendName: backendName,
+			policy:      policy,
+			sendDone:    make(chan struct{}),
+			newStream: func(ctx context.Context) (grpc.ClientStream, error) {
+				return streamer(ctx, desc, cc, method, opts...)
+			},
+		}
+		s.current = s.newAttempt()
+		if err := s.current.err; err != nil && !policy.retryable(err) {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// attempt is a single try of a call.
+type attempt struct {
+	// cs is nil if creating the stream failed with err.
+	cs     grpc.ClientStream
+	err    error
+	cancel context.CancelFunc
+}
+
+type recvResult struct {
+	attempt *attempt
+	msg     []byte
+	err     error
+}
+
+// stream is a grpc.ClientStream that retries the call until the first response message is received.
+type stream struct {
+	ctx         context.Context
+	backendName string
+	policy      *Policy
+	newStream   func(ctx context.Context) (grpc.ClientStream, error)
+
+	mu sync.Mutex
+	// current is the first attempt until the call is committed and the winning attempt after.
+	current   *attempt
+	committed bool
+	sent      int
+	buffered  []byte
+	// replayable is false if the request cannot be sent again: it had more than one message or it was too big.
+	replayable bool
+	halfClosed bool
+	// sendDone is closed when the client closed its side of the stream or the request became not replayable.
+	sendDone     chan struct{}
+	sendDoneOnce sync.Once
+}
+
+func (s *stream) newAttempt() *attempt {
+	ctx, cancel := context.WithCancel(s.ctx)
+	cs, err := s.newStream(ctx)
+	if err != nil {
+		cancel()
+	}
+	return &attempt{cs: cs, err: err, cancel: cancel}
+}
+
+func (s *stream) markSendDone() {
+	s.sendDoneOnce.Do(func() { close(s.sendDone) })
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stream) Header() (metadata.MD, error) {
+	s.mu.Lock()
+	a := s.current
+	s.mu.Unlock()
+	if a.cs == nil {
+		return nil, a.err
+	}
+	return a.cs.Header()
+}
+
+func (s *stream) Trailer() metadata.MD {
+	s.mu.Lock()
+	a := s.current
+	s.mu.Unlock()
+	if a.cs == nil {
+		return nil
+	}
+	return a.cs.Trailer()
+}
+
+func (s *stream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	a := s.current
+	if !s.committed {
+		s.sent++
+		s.replayable = false
+		if s.sent == 1 {
+			if payload, err := proxy.Codec().Marshal(m); err == nil && len(payload) <= s.policy.maxBufferBytes {
+				s.buffered = append([]byte{}, payload...)
+				s.replayable = true
+			}
+		}
+		if !s.replayable {
+			s.markSendDone()
+		}
+	}
+	s.mu.Unlock()
+
+	if a.cs == nil {
+		// The error is returned by RecvMsg.
+		return nil
+	}
+	return a.cs.SendMsg(m)
+}
+
+func (s *stream) CloseSend() error {
+	s.mu.Lock()
+	a := s.current
+	s.halfClosed = true
+	s.markSendDone()
+	s.mu.Unlock()
+
+	if a.cs == nil {
+		return nil
+	}
+	return a.cs.CloseSend()
+}
+
+func (s *stream) RecvMsg(m interface{}) error {
+	s.mu.Lock()
+	a, committed := s.current, s.committed
+	s.mu.Unlock()
+	if committed {
+		if a.cs == nil {
+			return a.err
+		}
+		return a.cs.RecvMsg(m)
+	}
+
+	msg, err := s.recvFirst()
+	if err != nil {
+		return err
+	}
+	return proxy.Codec().Unmarshal(msg, m)
+}
+
+// canReplay returns true if the whole request was received from the client and can be sent again.
+func (s *stream) canReplay() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.halfClosed && (s.replayable || s.sent == 0)
+}
+
+// recvFirst receives the first response message, retrying and hedging attempts according to the policy, and commits
+// the call to the attempt that returned it (or the final error).
+func (s *stream) recvFirst() ([]byte, error) {
+	s.mu.Lock()
+	first := s.current
+	s.mu.Unlock()
+
+	results := make(chan recvResult, s.policy.maxAttempts)
+	attempts := []*attempt{first}
+	running := 1
+	go s.recv(first, results)
+
+	var hedge <-chan time.Time
+	sendDone := s.sendDone
+	for {
+		select {
+		case <-sendDone:
+			sendDone = nil
+			if s.policy.hedgingDelay > 0 && s.canReplay() {
+				hedge = time.After(s.policy.hedgingDelay)
+			}
+		case <-hedge:
+			hedge = nil
+			if len(attempts) >= s.policy.maxAttempts {
+				continue
+			}
+			attemptsCounter.WithLabelValues(s.backendName, "hedge").Inc()
+			a := s.startAttempt(results)
+			attempts = append(attempts, a)
+			running++
+			if len(attempts) < s.policy.maxAttempts {
+				hedge = time.After(s.policy.hedgingDelay)
+			}
+		case r := <-results:
+			running--
+			if r.err == nil || !s.policy.retryable(r.err) {
+				return s.commit(r, attempts)
+			}
+			if running > 0 {
+				// Hedged attempts are still in flight, one of them may succeed.
+				continue
+			}
+			if len(attempts) >= s.policy.maxAttempts || !s.waitForRetry() {
+				return s.commit(r, attempts)
+			}
+			attemptsCounter.WithLabelValues(s.backendName, "retry").Inc()
+			a := s.startAttempt(results)
+			attempts = append(attempts, a)
+			running++
+			if s.policy.hedgingDelay > 0 && len(attempts) < s.policy.maxAttempts {
+				hedge = time.After(s.policy.hedgingDelay)
+			}
+		}
+	}
+}
+
+// waitForRetry waits for the backoff and for the client to finish sending the request. It returns false if the call
+// should not be retried.
+func (s *stream) waitForRetry() bool {
+	backoff := time.NewTimer(s.policy.backoff)
+	defer backoff.Stop()
+	halfClose := time.NewTimer(maxHalfCloseWait)
+	defer halfClose.Stop()
+
+	sendDone := s.sendDone
+	backedOff := false
+	for sendDone != nil || !backedOff {
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-sendDone:
+			if !s.canReplay() {
+				return false
+			}
+			sendDone = nil
+		case <-halfClose.C:
+			if sendDone != nil {
+				return false
+			}
+		case <-backoff.C:
+			backedOff = true
+		}
+	}
+	return true
+}
+
+// startAttempt creates a new attempt, sends the buffered request to it and starts receiving its response.
+func (s *stream) startAttempt(results chan<- recvResult) *attempt {
+	a := s.newAttempt()
+	if a.cs != nil {
+		s.mu.Lock()
+		sent, msg := s.sent, bufferedMsg(s.buffered)
+		s.mu.Unlock()
+		// Send errors are returned by RecvMsg.
+		if sent > 0 {
+			a.cs.SendMsg(&msg)
+		}
+		a.cs.CloseSend()
+	}
+	go s.recv(a, results)
+	return a
+}
+
+func (s *stream) recv(a *attempt, results chan<- recvResult) {
+	if a.cs == nil {
+		results <- recvResult{attempt: a, err: a.err}
+		return
+	}
+	var msg bufferedMsg
+	err := a.cs.RecvMsg(&msg)
+	results <- recvResult{attempt: a, msg: msg, err: err}
+}
+
+// commit makes the given attempt's result the result of the call and cancels the other attempts.
+func (s *stream) commit(r recvResult, attempts []*attempt) ([]byte, error) {
+	for _, a := range attempts {
+		if a != r.attempt {
+			a.cancel()
+		}
+	}
+	s.mu.Lock()
+	s.current = r.attempt
+	s.committed = true
+	s.mu.Unlock()
+	return r.msg, r.err
+}
+
+// bufferedMsg is a raw message. It implements proto marshalling, so it can be sent and received by the proxy codec
+// (which falls back to proto for types other than its own frames).
+type bufferedMsg []byte
+
+func (m *bufferedMsg) Reset()         { *m = nil }
+func (m *bufferedMsg) String() string { return string(*m) }
+func (*bufferedMsg) ProtoMessage()    {}
+
+func (m *bufferedMsg) Marshal() ([]byte, error) {
+	return *m, nil
+}
+
+func (m *bufferedMsg) Unmarshal(data []byte) error {
+	*m = append((*m)[:0], data...)
+	return nil
+}
@@ -0,0 +1,195 @@
+package retry
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+	"github.com/mwitkow/grpc-proxy/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var bidiDesc = &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+
+// testBackend replies to every call with the concatenation of the received messages. Calls are answered by the
+// respond func given the number of the call (starting from 1).
+type testBackend struct {
+	mu       sync.Mutex
+	calls    int
+	requests []string
+
+	respond func(call int) error
+}
+
+func (b *testBackend) handler(srv interface{}, stream grpc.ServerStream) error {
+	var req string
+	for {
+		var msg bufferedMsg
+		if err := stream.RecvMsg(&msg); err != nil {
+			break
+		}
+		req += string(msg)
+	}
+
+	b.mu.Lock()
+	b.calls++
+	call := b.calls
+	b.requests = append(b.requests, req)
+	b.mu.Unlock()
+
+	if err := b.respond(call); err != nil {
+		return err
+	}
+	resp := bufferedMsg("resp:" + req)
+	return stream.SendMsg(&resp)
+}
+
+func (b *testBackend) callCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+func startBackend(t *testing.T, b *testBackend, policy *pb.RetryPolicy) (*grpc.ClientConn, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.CustomCodec(proxy.Codec()), grpc.UnknownServiceHandler(b.handler))
+	go srv.Serve(lis)
+
+	p, err := NewPolicy(policy)
+	require.NoError(t, err)
+	cc, err := grpc.Dial(lis.Addr().String(),
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithCodec(proxy.Codec()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor("backend_a", p)),
+	)
+	require.NoError(t, err)
+	return cc, func() {
+		cc.Close()
+		srv.Stop()
+	}
+}
+
+func call(ctx context.Context, cc *grpc.ClientConn, requests ...string) (string, error) {
+	cs, err := grpc.NewClientStream(ctx, bidiDesc, cc, "/test.Service/Method")
+	if err != nil {
+		return "", err
+	}
+	for _, req := range requests {
+		msg := bufferedMsg(req)
+		if err := cs.SendMsg(&msg); err != nil {
+			return "", err
+		}
+	}
+	if err := cs.CloseSend(); err != nil {
+		return "", err
+	}
+	var resp bufferedMsg
+	if err := cs.RecvMsg(&resp); err != nil {
+		return "", err
+	}
+	return string(resp), nil
+}
+
+func failFirst(n int, code codes.Code) func(int) error {
+	return func(call int) error {
+		if call <= n {
+			return status.Errorf(code, "failing call %d", call)
+		}
+		return nil
+	}
+}
+
+func TestRetry_RetriesRetryableCodes(t *testing.T) {
+	b := &testBackend{respond: failFirst(2, codes.Unavailable)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3})
+	defer stop()
+
+	resp, err := call(context.Background(), cc, "req")
+	require.NoError(t, err)
+	assert.Equal(t, "resp:req", resp)
+	assert.Equal(t, []string{"req", "req", "req"}, b.requests, "every attempt should get the buffered request")
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	b := &testBackend{respond: failFirst(5, codes.Unavailable)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3})
+	defer stop()
+
+	_, err := call(context.Background(), cc, "req")
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, 3, b.callCount())
+}
+
+func TestRetry_DoesNotRetryOtherCodes(t *testing.T) {
+	b := &testBackend{respond: failFirst(1, codes.Internal)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3})
+	defer stop()
+
+	_, err := call(context.Background(), cc, "req")
+	assert.Equal(t, codes.Internal, grpc.Code(err))
+	assert.Equal(t, 1, b.callCount())
+}
+
+func TestRetry_DoesNotRetryClientStreams(t *testing.T) {
+	b := &testBackend{respond: failFirst(1, codes.Unavailable)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3})
+	defer stop()
+
+	_, err := call(context.Background(), cc, "req1", "req2")
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, 1, b.callCount())
+}
+
+func TestRetry_DoesNotRetryRequestsOverBufferLimit(t *testing.T) {
+	b := &testBackend{respond: failFirst(1, codes.Unavailable)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3, MaxBufferBytes: 4})
+	defer stop()
+
+	_, err := call(context.Background(), cc, "too long request")
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, 1, b.callCount())
+}
+
+func TestRetry_RouteOverridesBackendPolicy(t *testing.T) {
+	b := &testBackend{respond: failFirst(1, codes.Unavailable)}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 3})
+	defer stop()
+
+	noRetries, err := NewPolicy(&pb.RetryPolicy{MaxAttempts: 1})
+	require.NoError(t, err)
+	_, err = call(WithPolicy(context.Background(), noRetries), cc, "req")
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, 1, b.callCount())
+}
+
+func TestRetry_HedgesSlowAttempts(t *testing.T) {
+	b := &testBackend{respond: func(call int) error {
+		if call == 1 {
+			time.Sleep(2 * time.Second)
+		}
+		return nil
+	}}
+	cc, stop := startBackend(t, b, &pb.RetryPolicy{MaxAttempts: 2, HedgingDelayMs: 50})
+	defer stop()
+
+	start := time.Now()
+	resp, err := call(context.Background(), cc, "req")
+	require.NoError(t, err)
+	assert.Equal(t, "resp:req", resp)
+	assert.True(t, time.Since(start) < time.Second, "hedged attempt should respond before the slow one")
+	assert.Equal(t, 2, b.callCount())
+}
+
+func TestNewPolicy_RejectsUnknownCodes(t *testing.T) {
+	_, err := NewPolicy(&pb.RetryPolicy{MaxAttempts: 2, RetryableCodes: []string{"unavailable", "NOT_A_CODE"}})
+	assert.Error(t, err)
+}
@@ -13,15 +13,18 @@ import (
 	"github.com/improbable-eng/kedge/pkg/kedge/http/lbtransport"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/improbable-eng/kedge/pkg/resolvers/health"
 	"github.com/improbable-eng/kedge/pkg/resolvers/host"
 	"github.com/improbable-eng/kedge/pkg/resolvers/k8s"
 	"github.com/improbable-eng/kedge/pkg/resolvers/srv"
+	"github.com/improbable-eng/kedge/pkg/tls"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/http2"
-	"google.golang.org/grpc/naming"
 )
 
 var (
@@ -45,10 +48,22 @@ type backend struct {
 	cancel context.CancelFunc
 
 	target    string
-	resolver  naming.Resolver
+	resolver  resolvers.Resolver
 	transport *http.Transport
 	tripper   http.RoundTripper
+	lb        targetDialer
+	dialFunc  lbtransport.DialFunc
 	config    *pb.Backend
+
+	// tlsServerConfig is the TlsServerConfig referenced by config (if any). Used for diffing.
+	tlsServerConfig *pb_config.TlsServerConfig
+}
+
+// targetDialer dials raw connections to targets picked by the load balancer.
+type targetDialer interface {
+	Dial(r *http.Request, dial lbtransport.DialFunc) (net.Conn, error)
+	// Targets returns the currently resolved targets.
+	Targets() ([]lbtransport.TargetStatus, error)
 }
 
 // Tripper returns tripper that should be used for this (and only this backend).
@@ -62,6 +77,24 @@ func (b *backend) Tripper() http.RoundTripper {
 	return b.tripper
 }
 
+// Dial returns a raw TCP connection to one of the backend's targets. It is used for CONNECT tunnels.
+func (b *backend) Dial(req *http.Request) (net.Conn, error) {
+	b.mu.RLock()
+	closed := b.ctx.Err() != nil
+	b.mu.RUnlock()
+	if closed {
+		err := errors.New("backend transport closed")
+		reporter.Extract(req).ReportError(errtypes.BackendTransportClosed, err)
+		return nil, err
+	}
+	return b.lb.Dial(req, b.dialFunc)
+}
+
+// Targets returns the currently resolved targets of the backend. It is used for debugging.
+func (b *backend) Targets() ([]lbtransport.TargetStatus, error) {
+	return b.lb.Targets()
+}
+
 // Close is used when backend is removed from configuration dynamically.
 func (b *backend) Close() error {
 	b.mu.Lock()
@@ -75,13 +108,15 @@ func (b *backend) Close() error {
 }
 
 // newBackend creates backend from given configuration.
-func newBackend(cnf *pb.Backend) (*backend, error) {
+// tlsServerConfigs are named TLS configs that backend's security settings can refer to.
+func newBackend(cnf *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*backend, error) {
 	b := &backend{
-		config: cnf,
+		config:          cnf,
+		tlsServerConfig: kedge_tls.FindTLSServerConfig(cnf.GetSecurity().GetConfigName(), tlsServerConfigs),
 	}
 	b.ctx, b.cancel = context.WithCancel(context.Background())
 
-	target, resolver, err := chooseNamingResolver(cnf)
+	target, resolver, err := chooseResolver(cnf)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to construct resolver for backend %s", cnf.Name)
 	}
@@ -97,7 +132,10 @@ func newBackend(cnf *pb.Backend) (*backend, error) {
 		)
 	}
 
-	scheme, tlsConfig := buildTls(cnf)
+	scheme, tlsConfig, err := buildTls(cnf, tlsServerConfigs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build TLS config for backend %s", cnf.Name)
+	}
 	b.transport = &http.Transport{
 		DialContext:         dialFunc,
 		TLSClientConfig:     tlsConfig,
@@ -110,11 +148,25 @@ func newBackend(cnf *pb.Backend) (*backend, error) {
 		return nil, err
 	}
 
-	b.tripper, err = lbtransport.New(b.ctx, target, b.transport, resolver, chooseBalancerPolicy(b.ctx, cnf))
+	if hc := cnf.GetHealthCheck(); hc != nil {
+		if hc.GetHttp() == nil {
+			return nil, errors.Errorf("backend %s: only http health check is supported for HTTP backends", cnf.Name)
+		}
+		probe := healthresolver.NewHTTPProbe(b.transport, scheme, hc.GetHttp())
+		resolver = healthresolver.New(cnf.Name, resolver, probe, healthresolver.OptionsFromConfig(hc))
+	}
+
+	lb, err := lbtransport.New(b.ctx, target, b.transport, resolver, chooseBalancerPolicy(b.ctx, cnf))
 	if err != nil {
 		return nil, err
 	}
+	b.lb = lb
+	b.dialFunc = dialFunc
+	b.tripper = lb
 	b.tripper = buildTripperMiddlewareChain(cnf, b.tripper)
+	if sticky := cnf.GetStickySession(); sticky != nil {
+		b.tripper = lbtransport.NewStickySessionTripper(cnf.Name, b.tripper, stickySessionOptions(sticky))
+	}
 	b.tripper = &schemeTripper{expectedScheme: scheme, parent: b.tripper}
 	return b, nil
 }
@@ -129,11 +181,14 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 		return
 	}
 
-	var updates []*naming.Update
+	var (
+		mu    sync.Mutex
+		addrs []resolvers.Address
+	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	go func() {
 		for ctx.Err() == nil {
-			u, err := watcher.Next()
+			a, err := watcher.Next()
 			if err != nil {
 				if ctx.Err() != nil {
 					cancel()
@@ -143,7 +198,9 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 				continue
 			}
 
-			updates = append(updates, u...)
+			mu.Lock()
+			addrs = a
+			mu.Unlock()
 		}
 
 	}()
@@ -156,38 +213,87 @@ func (b *backend) LogTestResolution(logger logrus.FieldLogger) {
 	cancel()
 	watcher.Close()
 
-	addresses := map[string]struct{}{}
-	for _, u := range updates {
-		if u.Op == naming.Add {
-			addresses[u.Addr] = struct{}{}
-		}
-		if u.Op == naming.Delete {
-			delete(addresses, u.Addr)
-		}
-	}
-
-	logger.Infof("Resolved Addresses: %v", addresses)
+	mu.Lock()
+	defer mu.Unlock()
+	logger.Infof("Resolved Addresses: %+v", addrs)
 }
 
-func buildTls(cnf *pb.Backend) (scheme string, tlsConfig *tls.Config) {
+func buildTls(cnf *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (scheme string, tlsConfig *tls.Config, err error) {
 	if sec := cnf.GetSecurity(); sec != nil {
-		tlsConfig = &tls.Config{InsecureSkipVerify: true}
-		if !sec.InsecureSkipVerify {
-			// TODO(mwitkow): add configuration TlsConfig fetching by name here.
-			panic("Not implemented") // Ugly but this matters.
+		tlsConfig, err = kedge_tls.BuildBackendTLSConfig(sec, tlsServerConfigs)
+		if err != nil {
+			return "", nil, err
 		}
-		return "https", tlsConfig
+		return "https", tlsConfig, nil
 	} else {
-		return "http", nil
+		return "http", nil, nil
 	}
 }
 
 func buildTripperMiddlewareChain(cnf *pb.Backend, parent http.RoundTripper) http.RoundTripper {
-	// TODO(mwitkow): Add tripper middleware
+	// Middlewares are executed from left to right, so the first one needs to be the outermost.
+	middlewares := cnf.GetMiddlewares()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if retry := middlewares[i].GetRetry(); retry != nil {
+			parent = lbtransport.NewRetryTripper(cnf.Name, parent, retryOptions(retry))
+		} else if breaker := middlewares[i].GetCircuitBreaker(); breaker != nil {
+			parent = lbtransport.NewCircuitBreakerTripper(cnf.Name, parent, circuitBreakerOptions(breaker))
+		}
+		// new middlewares are to be added here as else if statements.
+	}
 	return parent
 }
 
-func chooseNamingResolver(cnf *pb.Backend) (string, naming.Resolver, error) {
+func retryOptions(cnf *pb.Middleware_Retry) lbtransport.RetryOptions {
+	opts := lbtransport.RetryOptions{
+		RetryCount:           int(cnf.GetRetryCount()),
+		BodyBufferLimitBytes: int64(cnf.GetBodyBufferLimitBytes()),
+		RetryNonIdempotent:   cnf.GetRetryNonIdempotent(),
+	}
+	for _, code := range cnf.GetOnCodes() {
+		opts.OnCodes = append(opts.OnCodes, int(code))
+	}
+	return opts
+}
+
+func circuitBreakerOptions(cnf *pb.Middleware_CircuitBreaker) lbtransport.CircuitBreakerOptions {
+	opts := lbtransport.CircuitBreakerOptions{
+		ConsecutiveFailures: int(cnf.GetConsecutiveFailures()),
+		FailureRatio:        cnf.GetFailureRatio(),
+		TimeoutRatio:        cnf.GetTimeoutRatio(),
+		MinRequests:         10,
+		Window:              10 * time.Second,
+		OpenDuration:        5 * time.Second,
+		HalfOpenRequests:    1,
+	}
+	if cnf.GetMinRequests() > 0 {
+		opts.MinRequests = int(cnf.GetMinRequests())
+	}
+	if cnf.GetWindowMs() > 0 {
+		opts.Window = time.Duration(cnf.GetWindowMs()) * time.Millisecond
+	}
+	if cnf.GetOpenDurationMs() > 0 {
+		opts.OpenDuration = time.Duration(cnf.GetOpenDurationMs()) * time.Millisecond
+	}
+	if cnf.GetHalfOpenRequests() > 0 {
+		opts.HalfOpenRequests = int(cnf.GetHalfOpenRequests())
+	}
+	return opts
+}
+
+func stickySessionOptions(cnf *pb.StickySession) lbtransport.StickySessionOptions {
+	opts := lbtransport.StickySessionOptions{
+		CookieName: "kedge_session",
+		MaxAge:     time.Duration(cnf.GetMaxAgeS()) * time.Second,
+		Secret:     lbtransport.StickySessionSecretFromFlags(),
+	}
+	if cnf.GetCookieName() != "" {
+		opts.CookieName = cnf.GetCookieName()
+	}
+	return opts
+}
+
+func chooseResolver(cnf *pb.Backend) (string, resolvers.Resolver, error) {
 	if s := cnf.GetSrv(); s != nil {
 		return srvresolver.NewFromConfig(s)
 	}
@@ -198,18 +304,42 @@ func chooseNamingResolver(cnf *pb.Backend) (string, naming.Resolver, error) {
 	if k := cnf.GetHost(); k != nil {
 		return hostresolver.NewFromConfig(k)
 	}
-	return "", nil, fmt.Errorf("unspecified naming resolver for %v", cnf.Name)
+	return "", nil, fmt.Errorf("unspecified resolver for %v", cnf.Name)
 }
 
 func chooseBalancerPolicy(ctx context.Context, cnf *pb.Backend) lbtransport.LBPolicy {
 	switch cnf.GetBalancer() {
 	case pb.Balancer_ROUND_ROBIN:
 		return lbtransport.RoundRobinPolicyFromFlags(ctx)
+	case pb.Balancer_LEAST_REQUEST:
+		return lbtransport.LeastRequestPolicyFromFlags(ctx, false)
+	case pb.Balancer_POWER_OF_TWO_CHOICES:
+		return lbtransport.LeastRequestPolicyFromFlags(ctx, true)
+	case pb.Balancer_RING_HASH:
+		return lbtransport.RingHashPolicyFromFlags(ctx, chooseHashKey(cnf.GetHashPolicy()))
+	case pb.Balancer_MAGLEV:
+		return lbtransport.MaglevPolicyFromFlags(ctx, chooseHashKey(cnf.GetHashPolicy()))
+	case pb.Balancer_WEIGHTED_ROUND_ROBIN:
+		return lbtransport.WeightedRoundRobinPolicyFromFlags(ctx)
 	default:
 		return lbtransport.RoundRobinPolicyFromFlags(ctx)
 	}
 }
 
+func chooseHashKey(cnf *pb.HashPolicy) lbtransport.HashKeyFunc {
+	if h := cnf.GetHeader(); h != "" {
+		return lbtransport.HeaderHashKey(h)
+	}
+	if c := cnf.GetCookie(); c != "" {
+		return lbtransport.CookieHashKey(c)
+	}
+	if cnf.GetPath() {
+		return lbtransport.PathHashKey
+	}
+	// No key, so all requests are balanced in round robin manner.
+	return func(*http.Request) string { return "" }
+}
+
 // SchemeTripper rewrites the request's proto scheme to enforce the backend properties.
 type schemeTripper struct {
 	expectedScheme string
@@ -2,10 +2,15 @@ package backendpool
 
 import (
 	"hash/fnv"
+	"net"
 	"net/http"
 	"sync"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/improbable-eng/kedge/pkg/kedge/http/lbtransport"
 	"github.com/improbable-eng/kedge/pkg/metrics"
+	"github.com/improbable-eng/kedge/pkg/tls"
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
 	"github.com/sirupsen/logrus"
 )
@@ -14,9 +19,10 @@ import (
 type dynamic struct {
 	mu sync.RWMutex
 
-	backends       map[string]*backend
-	backendFactory func(backend *pb.Backend) (*backend, error)
-	logger         logrus.FieldLogger
+	backends         map[string]*backend
+	tlsServerConfigs []*pb_config.TlsServerConfig
+	backendFactory   func(backend *pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*backend, error)
+	logger           logrus.FieldLogger
 }
 
 func (s *dynamic) Close() {
@@ -43,9 +49,39 @@ func (s *dynamic) Tripper(backendName string) (http.RoundTripper, error) {
 	return be.Tripper(), nil
 }
 
+func (s *dynamic) Dial(backendName string, req *http.Request) (net.Conn, error) {
+	s.mu.RLock()
+	be, ok := s.backends[backendName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownBackend
+	}
+	return be.Dial(req)
+}
+
+// Targets returns the currently resolved targets of the backend. It is used for debugging.
+func (s *dynamic) Targets(backendName string) ([]lbtransport.TargetStatus, error) {
+	s.mu.RLock()
+	be, ok := s.backends[backendName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownBackend
+	}
+	return be.Targets()
+}
+
+// UpdateTLSServerConfigs sets named TLS configs that backends can refer to in their security settings.
+// It does not affect existing backends until they are updated using AddOrUpdate.
+func (s *dynamic) UpdateTLSServerConfigs(tlsServerConfigs []*pb_config.TlsServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsServerConfigs = tlsServerConfigs
+}
+
 // AddOrUpdate checks tries to perform the least destructive operation of adding a new backend.
 //
-// If a backend of a given name already exists, and the configuration hasn't changed, no new work will be done.
+// If a backend of a given name already exists, and the configuration (including referenced TLS config) hasn't changed,
+// no new work will be done.
 // If a backend requires changes, the previous one will be removed and closed.
 func (s *dynamic) AddOrUpdate(config *pb.Backend, logTestResolution bool) (changed bool, err error) {
 	s.mu.RLock()
@@ -85,7 +121,11 @@ func (s *dynamic) AddOrUpdate(config *pb.Backend, logTestResolution bool) (chang
 }
 
 func (s *dynamic) addNewBackend(config *pb.Backend) error {
-	be, err := s.backendFactory(config)
+	s.mu.RLock()
+	tlsServerConfigs := s.tlsServerConfigs
+	s.mu.RUnlock()
+
+	be, err := s.backendFactory(config, tlsServerConfigs)
 	if err != nil {
 		return err
 	}
@@ -96,7 +136,11 @@ func (s *dynamic) addNewBackend(config *pb.Backend) error {
 }
 
 func (s *dynamic) updateBackendWithDiffing(existing *backend, config *pb.Backend) (changed bool, err error) {
-	if configsAreTheSame(existing.config, config) {
+	s.mu.RLock()
+	tlsServerConfig := kedge_tls.FindTLSServerConfig(config.GetSecurity().GetConfigName(), s.tlsServerConfigs)
+	s.mu.RUnlock()
+
+	if configsAreTheSame(existing.config, config) && configsAreTheSame(existing.tlsServerConfig, tlsServerConfig) {
 		return false, nil
 	}
 	if err := s.addNewBackend(config); err != nil {
@@ -119,6 +163,7 @@ func (s *dynamic) Remove(backendName string) error {
 	delete(s.backends, backendName)
 	s.mu.Unlock()
 	existing.Close()
+	metrics.ForgetProxyBackend("http", backendName)
 
 	s.logger.Infof("Removed http backend: %v", backendName)
 	metrics.BackendHTTPConfigurationCounter.WithLabelValues(backendName, metrics.ConfiguationActionDelete).Inc()
@@ -136,10 +181,10 @@ func (s *dynamic) Configs() map[string]*pb.Backend {
 	return ret
 }
 
-func configsAreTheSame(c1 *pb.Backend, c2 *pb.Backend) bool {
+func configsAreTheSame(c1 proto.Message, c2 proto.Message) bool {
 	h1 := fnv.New64a()
 	h2 := fnv.New64a()
-	h1.Write([]byte(c1.String()))
-	h2.Write([]byte(c2.String()))
+	h1.Write([]byte(proto.CompactTextString(c1)))
+	h2.Write([]byte(proto.CompactTextString(c2)))
 	return h1.Sum64() == h2.Sum64()
 }
@@ -1,6 +1,7 @@
 package backendpool
 
 import (
+	"net"
 	"net/http"
 
 	"google.golang.org/grpc"
@@ -14,5 +15,7 @@ var (
 type Pool interface {
 	// Tripper returns an already established http.RoundTripper just for this backend.
 	Tripper(backendName string) (http.RoundTripper, error)
+	// Dial returns a raw TCP connection to one of the targets of the backend. It is used for CONNECT tunnels.
+	Dial(backendName string, req *http.Request) (net.Conn, error)
 	Close()
 }
@@ -3,8 +3,10 @@ package backendpool
 import (
 	"fmt"
 
+	"net"
 	"net/http"
 
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
 	"github.com/sirupsen/logrus"
 )
@@ -15,10 +17,11 @@ type static struct {
 }
 
 // NewStatic creates a backend pool that has static configuration.
-func NewStatic(backends []*pb.Backend) (*static, error) {
+// tlsServerConfigs are named TLS configs that backends can refer to in their security settings.
+func NewStatic(backends []*pb.Backend, tlsServerConfigs []*pb_config.TlsServerConfig) (*static, error) {
 	s := &static{backends: make(map[string]*backend)}
 	for _, beCnf := range backends {
-		be, err := newBackend(beCnf)
+		be, err := newBackend(beCnf, tlsServerConfigs)
 		if err != nil {
 			return nil, fmt.Errorf("failed creating backend '%v': %v", beCnf.Name, err)
 		}
@@ -35,6 +38,14 @@ func (s *static) Tripper(backendName string) (http.RoundTripper, error) {
 	return be.Tripper(), nil
 }
 
+func (s *static) Dial(backendName string, req *http.Request) (net.Conn, error) {
+	be, ok := s.backends[backendName]
+	if !ok {
+		return nil, ErrUnknownBackend
+	}
+	return be.Dial(req)
+}
+
 func (s *static) LogTestResolution(logger logrus.FieldLogger) {
 	for k, backend := range s.backends {
 		backend.LogTestResolution(logger.WithField("backend", k))
@@ -0,0 +1,93 @@
+package director
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/improbable-eng/go-httpwares/tags"
+	"github.com/improbable-eng/kedge/pkg/reporter"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	connectTunnelsOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "http",
+			Name:      "connect_tunnels_open",
+			Help:      "Number of currently open CONNECT tunnels.",
+		},
+		[]string{"backend_name"},
+	)
+	connectTunnelsClosed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http",
+			Name:      "connect_tunnels_closed_total",
+			Help:      "Total number of closed CONNECT tunnels by the reason of closing.",
+		},
+		[]string{"backend_name", "reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(connectTunnelsOpen)
+	prometheus.MustRegister(connectTunnelsClosed)
+}
+
+// connectProxy tunnels TCP streams of CONNECT requests (forward proxy mode) to the destination.
+type connectProxy struct {
+	logEntry logrus.FieldLogger
+}
+
+// serve dials the destination using given dial func, responds to the client and tunnels the connection until one of
+// the sides closes it or limits are exceeded.
+func (c *connectProxy) serve(resp http.ResponseWriter, req *http.Request, backendName string, limits upgradeLimits, dial func() (net.Conn, error)) {
+	hijacker, ok := resp.(http.Hijacker)
+	if !ok {
+		// CONNECT over HTTP/2 is not supported.
+		c.respondWithError(resp, req, http.StatusBadRequest, errors.New("connect: connection cannot be hijacked"))
+		return
+	}
+
+	backendConn, err := dial()
+	if err != nil {
+		c.respondWithError(resp, req, http.StatusBadGateway, errors.Wrap(err, "connect: failed to dial destination"))
+		return
+	}
+	defer backendConn.Close()
+
+	clientConn, clientBuf, err := hijacker.Hijack()
+	if err != nil {
+		c.respondWithError(resp, req, http.StatusInternalServerError, errors.Wrap(err, "connect: failed to hijack connection"))
+		return
+	}
+	defer clientConn.Close()
+
+	// Clear deadlines set by the HTTP server (write and read timeouts). Limits of the tunnel are applied instead.
+	clientConn.SetDeadline(time.Time{})
+
+	clientBuf.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+	if err := clientBuf.Flush(); err != nil {
+		c.logEntry.WithFields(http_ctxtags.ExtractInbound(req).Values()).WithError(err).Warn("Failed to write CONNECT response")
+		return
+	}
+
+	connectTunnelsOpen.WithLabelValues(backendName).Inc()
+	reason := tunnel(clientConn, clientBuf, backendConn, limits)
+	connectTunnelsOpen.WithLabelValues(backendName).Dec()
+	connectTunnelsClosed.WithLabelValues(backendName, reason).Inc()
+}
+
+func (c *connectProxy) respondWithError(resp http.ResponseWriter, req *http.Request, status int, err error) {
+	tracker := reporter.Extract(req)
+	c.logEntry.WithFields(http_ctxtags.ExtractInbound(req).Values()).WithError(err).Warn("HTTP CONNECT failed")
+	reporter.SetKedgeErrorHeaders(resp.Header(), tracker)
+	resp.Header().Set("content-type", "text/plain")
+	resp.WriteHeader(status)
+	fmt.Fprintf(resp, "%v", err.Error())
+}
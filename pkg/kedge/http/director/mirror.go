@@ -0,0 +1,163 @@
+package director
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/http/backendpool"
+	"github.com/improbable-eng/kedge/pkg/reporter"
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mirrorResultSent        = "sent"
+	mirrorResultFailed      = "failed"
+	mirrorResultConcurrency = "dropped_concurrency_limit"
+	mirrorResultBodyTooBig  = "dropped_body_too_big"
+)
+
+var (
+	flagMirrorMaxConcurrentRequests = sharedflags.Set.Int("http_mirror_max_concurrent_requests", 100, "Maximum number of mirrored HTTP requests in flight. Requests above this limit are not mirrored.")
+	flagMirrorTimeout               = sharedflags.Set.Duration("http_mirror_timeout", 10*time.Second, "Timeout for a single mirrored HTTP request.")
+	flagMirrorBodyBufferLimitBytes  = sharedflags.Set.Int64("http_mirror_body_buffer_limit_bytes", 64*1024, "Maximum size (bytes) of request body that is buffered to be mirrored. Requests with bigger or unknown size bodies are not mirrored.")
+
+	mirrorRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http_mirror",
+			Name:      "requests_total",
+			Help:      "Count of HTTP requests chosen to be mirrored, by the result of mirroring.",
+		},
+		[]string{"backend_name", "result"},
+	)
+	mirrorInFlightGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "http_mirror",
+			Name:      "requests_in_flight",
+			Help:      "Number of mirrored HTTP requests in flight.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(mirrorRequestsCounter)
+	prometheus.MustRegister(mirrorInFlightGauge)
+}
+
+// mirror sends fire-and-forget copies of requests to backends from the pool.
+type mirror struct {
+	pool   backendpool.Pool
+	logger logrus.FieldLogger
+
+	timeout         time.Duration
+	bodyBufferLimit int64
+	// inFlight limits the number of concurrent mirrored requests.
+	inFlight chan struct{}
+}
+
+func newMirror(pool backendpool.Pool, logger logrus.FieldLogger) *mirror {
+	return &mirror{
+		pool:            pool,
+		logger:          logger,
+		timeout:         *flagMirrorTimeout,
+		bodyBufferLimit: *flagMirrorBodyBufferLimitBytes,
+		inFlight:        make(chan struct{}, *flagMirrorMaxConcurrentRequests),
+	}
+}
+
+// Mirror sends a copy of the request to the mirror backend, if it is sampled and it does not hit the concurrency limit.
+// It never blocks on the mirrored request. The request body is buffered (and restored for the caller) if needed.
+func (m *mirror) Mirror(req *http.Request, cnf *pb.Mirror) {
+	if cnf == nil || cnf.Percentage == 0 {
+		return
+	}
+	if cnf.Percentage < 100 && uint32(rand.Intn(100)) >= cnf.Percentage {
+		return
+	}
+
+	// Take the slot first, so requests over the limit are not buffered for nothing.
+	select {
+	case m.inFlight <- struct{}{}:
+	default:
+		mirrorRequestsCounter.WithLabelValues(cnf.BackendName, mirrorResultConcurrency).Inc()
+		return
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength < 0 || req.ContentLength > m.bodyBufferLimit {
+			<-m.inFlight
+			mirrorRequestsCounter.WithLabelValues(cnf.BackendName, mirrorResultBodyTooBig).Inc()
+			return
+		}
+		var err error
+		body, err = ioutil.ReadAll(io.LimitReader(req.Body, req.ContentLength))
+		req.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		if err != nil {
+			// Primary request will fail on body reading as well, let it report the error.
+			<-m.inFlight
+			return
+		}
+	}
+
+	mirrorReq, cancel := m.mirrorRequest(req, cnf.BackendName, body)
+	mirrorInFlightGauge.Inc()
+	go func() {
+		defer func() {
+			cancel()
+			mirrorInFlightGauge.Dec()
+			<-m.inFlight
+		}()
+
+		tripper, err := m.pool.Tripper(cnf.BackendName)
+		if err == nil {
+			var resp *http.Response
+			resp, err = tripper.RoundTrip(mirrorReq)
+			if err == nil {
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}
+		if err != nil {
+			mirrorRequestsCounter.WithLabelValues(cnf.BackendName, mirrorResultFailed).Inc()
+			m.logger.WithError(err).WithField("backend", cnf.BackendName).Debug("Mirrored HTTP request failed")
+			return
+		}
+		mirrorRequestsCounter.WithLabelValues(cnf.BackendName, mirrorResultSent).Inc()
+	}()
+}
+
+// mirrorRequest creates a copy of the request directed to the given backend, detached from the inbound request lifetime.
+func (m *mirror) mirrorRequest(req *http.Request, backendName string, body []byte) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	mirrorReq := req.WithContext(ctx)
+
+	u := *req.URL
+	u.Host = backendName
+	mirrorReq.URL = &u
+	mirrorReq.RequestURI = ""
+	mirrorReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		mirrorReq.Header[k] = append([]string(nil), v...)
+	}
+	mirrorReq.Body = nil
+	if body != nil {
+		mirrorReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	// Mirrored request has its own tracker, so its errors are not reported as errors of the inbound request.
+	return reporter.ReqWrappedWithTracker(mirrorReq, &reporter.Tracker{}), cancel
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,137 @@
+package director
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/improbable-eng/go-httpwares"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePool struct {
+	trippers map[string]http.RoundTripper
+}
+
+func (p *fakePool) Tripper(backendName string) (http.RoundTripper, error) {
+	t, ok := p.trippers[backendName]
+	if !ok {
+		return nil, errors.Errorf("unknown backend %v", backendName)
+	}
+	return t, nil
+}
+
+func (p *fakePool) Dial(string, *http.Request) (net.Conn, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakePool) Close() {}
+
+type mirroredReq struct {
+	req  *http.Request
+	body string
+}
+
+func recordingTripper(reqs chan<- mirroredReq, unblock <-chan struct{}) http.RoundTripper {
+	return httpwares.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-unblock
+		var body []byte
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+		}
+		reqs <- mirroredReq{req: req, body: string(body)}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+	})
+}
+
+func TestMirror_CopiesRequestWithBody(t *testing.T) {
+	reqs := make(chan mirroredReq, 1)
+	unblock := make(chan struct{})
+	close(unblock)
+	m := &mirror{
+		pool:            &fakePool{trippers: map[string]http.RoundTripper{"shadow": recordingTripper(reqs, unblock)}},
+		logger:          logrus.New(),
+		timeout:         5 * time.Second,
+		bodyBufferLimit: 1024,
+		inFlight:        make(chan struct{}, 1),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://some.example.com/some/path", bytes.NewBufferString("some body"))
+	req.URL.Host = "primary"
+	m.Mirror(req, &pb.Mirror{BackendName: "shadow", Percentage: 100})
+
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "some body", string(body), "body of the primary request must be preserved")
+	assert.Equal(t, "primary", req.URL.Host, "primary request must not be modified")
+
+	select {
+	case mirrored := <-reqs:
+		assert.Equal(t, "shadow", mirrored.req.URL.Host)
+		assert.Equal(t, "/some/path", mirrored.req.URL.Path)
+		assert.Equal(t, "some body", mirrored.body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+}
+
+func TestMirror_DropsOverConcurrencyLimitAndTooBigBodies(t *testing.T) {
+	reqs := make(chan mirroredReq, 10)
+	unblock := make(chan struct{})
+	m := &mirror{
+		pool:            &fakePool{trippers: map[string]http.RoundTripper{"shadow": recordingTripper(reqs, unblock)}},
+		logger:          logrus.New(),
+		timeout:         5 * time.Second,
+		bodyBufferLimit: 4,
+		inFlight:        make(chan struct{}, 1),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://some.example.com/", bytes.NewBufferString("some body"))
+	m.Mirror(req, &pb.Mirror{BackendName: "shadow", Percentage: 100})
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "some body", string(body), "body of the primary request must be preserved")
+
+	for i := 0; i < 3; i++ {
+		m.Mirror(httptest.NewRequest(http.MethodGet, "http://some.example.com/", nil), &pb.Mirror{BackendName: "shadow", Percentage: 100})
+	}
+	close(unblock)
+
+	select {
+	case <-reqs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, reqs, 0, "only one request should be mirrored")
+}
+
+func TestMirror_DoesNotBufferOverConcurrencyLimitOrWhenDisabled(t *testing.T) {
+	reqs := make(chan mirroredReq, 1)
+	m := &mirror{
+		pool:            &fakePool{trippers: map[string]http.RoundTripper{"shadow": recordingTripper(reqs, nil)}},
+		logger:          logrus.New(),
+		timeout:         5 * time.Second,
+		bodyBufferLimit: 1024,
+		inFlight:        make(chan struct{}, 1),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://some.example.com/", bytes.NewBufferString("some body"))
+	body := req.Body
+	m.Mirror(req, &pb.Mirror{BackendName: "shadow"})
+	assert.True(t, req.Body == body, "request should not be touched when mirroring is disabled")
+
+	m.inFlight <- struct{}{}
+	m.Mirror(req, &pb.Mirror{BackendName: "shadow", Percentage: 100})
+	assert.True(t, req.Body == body, "body should not be buffered over the concurrency limit")
+	assert.Len(t, m.inFlight, 1)
+	assert.Len(t, reqs, 0)
+}
@@ -2,11 +2,15 @@ package director
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Bplotka/oidc/authorize"
@@ -21,9 +25,11 @@ import (
 	"github.com/improbable-eng/kedge/pkg/kedge/http/backendpool"
 	"github.com/improbable-eng/kedge/pkg/kedge/http/director/proxyreq"
 	"github.com/improbable-eng/kedge/pkg/kedge/http/director/router"
+	"github.com/improbable-eng/kedge/pkg/metrics"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
 	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/improbable-eng/kedge/pkg/tracing"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/oxtoacart/bpool"
 	"github.com/sirupsen/logrus"
@@ -58,23 +64,33 @@ func New(pool backendpool.Pool, router router.Router, adhocRouter common.Address
 	p := &Proxy{
 		router:      router,
 		adhocRouter: adhocRouter,
+		pool:        pool,
+		mirror:      newMirror(pool, logEntry.WithField("caller", "mirror")),
+		connectProxy: &connectProxy{
+			logEntry: logEntry.WithField("caller", "connectProxy"),
+		},
 	}
 
 	clientMetrics := http_prometheus.ClientMetrics()
 	bufferpool := bpool.NewBytePool(*flagBufferCount, *flagBufferSizeBytes)
 
-	backendTripper := http_metrics.Tripperware(clientMetrics)(&backendPoolTripper{pool: pool})
+	backendTripper := http_metrics.Tripperware(clientMetrics)(timeUpstream(&backendPoolTripper{pool: pool}))
 	backendErrLog := http_logrus.AsHttpLogger(logEntry.WithField("caller", "backend reverseProxy"))
 	p.backendReverseProxy = &httputil.ReverseProxy{
-		Director:      func(*http.Request) {},
-		Transport:     reverseProxyErrHandler(backendTripper, logEntry.WithField("caller", "backend reverseProxy error handler")),
-		FlushInterval: *flagFlushingInterval,
-		BufferPool:    bufferpool,
-		ErrorLog:      backendErrLog,
+		Director:       func(*http.Request) {},
+		ModifyResponse: modifyResponseHeaders,
+		Transport:      reverseProxyErrHandler(backendTripper, logEntry.WithField("caller", "backend reverseProxy error handler")),
+		FlushInterval:  *flagFlushingInterval,
+		BufferPool:     bufferpool,
+		ErrorLog:       backendErrLog,
+	}
+	p.backendUpgradeProxy = &upgradeProxy{
+		tripper:  &backendPoolTripper{pool: pool},
+		logEntry: logEntry.WithField("caller", "backend upgradeProxy"),
 	}
 
 	AdhocTransport.DialContext = conntrack.NewDialContextFunc(conntrack.DialWithName("adhoc"), conntrack.DialWithTracing())
-	adhocTripper := http_metrics.Tripperware(clientMetrics)(AdhocTransport)
+	adhocTripper := http_metrics.Tripperware(clientMetrics)(timeUpstream(AdhocTransport))
 	adhocErrLog := http_logrus.AsHttpLogger(logEntry.WithField("caller", "adhoc reverseProxy"))
 	p.adhocReverseProxy = &httputil.ReverseProxy{
 		Director:      func(*http.Request) {},
@@ -83,6 +99,10 @@ func New(pool backendpool.Pool, router router.Router, adhocRouter common.Address
 		BufferPool:    bufferpool,
 		ErrorLog:      adhocErrLog,
 	}
+	p.adhocUpgradeProxy = &upgradeProxy{
+		tripper:  AdhocTransport,
+		logEntry: logEntry.WithField("caller", "adhoc upgradeProxy"),
+	}
 	return p
 }
 
@@ -90,9 +110,18 @@ func New(pool backendpool.Pool, router router.Router, adhocRouter common.Address
 type Proxy struct {
 	router      router.Router
 	adhocRouter common.Addresser
+	pool        backendpool.Pool
+	mirror      *mirror
 
 	backendReverseProxy *httputil.ReverseProxy
 	adhocReverseProxy   *httputil.ReverseProxy
+
+	// Upgrade proxies tunnel upgraded connections (e.g. WebSocket).
+	backendUpgradeProxy *upgradeProxy
+	adhocUpgradeProxy   *upgradeProxy
+
+	// connectProxy tunnels CONNECT requests.
+	connectProxy *connectProxy
 }
 
 func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
@@ -106,6 +135,18 @@ func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	tags := http_ctxtags.ExtractInbound(req)
 	tags.Set(http_ctxtags.TagForCallService, "proxy")
 
+	// Continue the trace of the caller (e.g. winch). Requests to backends carry this span unless lbtransport starts one
+	// per picked target.
+	ctx, span := tracing.StartSpan(tracing.ExtractHTTP(normReq.Context(), req.Header), "kedge.http", tracing.KindServer)
+	defer endProxySpan(span, normReq)
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.host", req.Host)
+
+	proxyReq := metrics.NewProxyRequest("http", proxyModeLabel(normReq))
+	defer finishProxyRequest(proxyReq, normReq)
+	ctx = metrics.ContextWithProxyRequest(ctx, proxyReq)
+	normReq = tracing.RequestWithSpan(normReq.WithContext(ctx))
+
 	// Perform routing.
 	// We can have one of these 4 cases:
 	// - backend routing
@@ -116,17 +157,31 @@ func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	// From go 1.9 we need to add that manually.
 	req.URL.Host = req.Host
 
-	backend, err := p.router.Route(req)
+	backend, route, err := p.router.Route(req)
 	if err == router.ErrRouteNotFound {
 		// Try adhoc.
 		var addr string
-		addr, err = p.adhocRouter.Address(req.URL.Host)
+		addr, err = resolveAdhoc(ctx, p.adhocRouter, req.URL.Host)
 		if err == nil {
+			span.SetAttribute("kedge.adhoc", addr)
+			proxyReq.SetAdhoc(addr)
 			// We need to explicitly overwrite scheme to plain HTTP.
 			normReq.URL.Scheme = "http"
 			normReq.URL.Host = addr
 			tags.Set(ctxtags.TagForProxyAdhoc, addr)
 			tags.Set(http_ctxtags.TagForHandlerName, "_adhoc")
+			if normReq.Method == http.MethodConnect {
+				proxyReq.Discard()
+				p.connectProxy.serve(resp, normReq, adhocUpgradeBackendName, upgradeLimitsForRoute(nil), func() (net.Conn, error) {
+					return AdhocTransport.DialContext(normReq.Context(), "tcp", addr)
+				})
+				return
+			}
+			if isUpgradeRequest(normReq) {
+				proxyReq.Discard()
+				p.adhocUpgradeProxy.serve(resp, normReq, adhocUpgradeBackendName, upgradeLimitsForRoute(nil))
+				return
+			}
 			p.adhocReverseProxy.ServeHTTP(resp, normReq)
 			return
 		}
@@ -136,7 +191,44 @@ func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		resp.Header().Set("x-kedge-backend-name", backend)
 		tags.Set(ctxtags.TagForProxyBackend, backend)
 		tags.Set(http_ctxtags.TagForHandlerName, backend)
+		span.SetAttribute("kedge.backend", backend)
+		proxyReq.SetRoute(route.Name, backend)
 		normReq.URL.Host = backend
+		headersData := headerTemplateData(req)
+		if !route.AllowRequest(req, headersData) {
+			respondWithError(router.ErrRateLimited, req, resp)
+			return
+		}
+		// Rate limited requests are not counted as routed, like in the gRPC router.
+		metrics.RouteHTTPRequestsCounter.WithLabelValues(route.Name, backend).Inc()
+		if normReq.Method == http.MethodConnect {
+			proxyReq.Discard()
+			p.connectProxy.serve(resp, normReq, backend, upgradeLimitsForRoute(route.GetUpgrade()), func() (net.Conn, error) {
+				conn, err := p.pool.Dial(backend, normReq)
+				if err == backendpool.ErrUnknownBackend {
+					reporter.Extract(normReq).ReportError(errtypes.NoBackend, err)
+				}
+				return conn, err
+			})
+			return
+		}
+		route.ApplyRequestHeaders(normReq.Header, headersData)
+		if route.ResponseHeaders != nil {
+			normReq = normReq.WithContext(context.WithValue(normReq.Context(), routeCtxKey{}, &routeWithHeadersData{route: route, data: headersData}))
+		}
+		if route.GetPathRewrite() != nil {
+			normReq.URL.Path = route.RewritePath(normReq.URL.Path)
+			normReq.URL.RawPath = ""
+		}
+		if route.HostRewrite != "" {
+			normReq.Host = route.HostRewrite
+		}
+		if isUpgradeRequest(normReq) {
+			proxyReq.Discard()
+			p.backendUpgradeProxy.serve(resp, normReq, backend, upgradeLimitsForRoute(route.GetUpgrade()))
+			return
+		}
+		p.mirror.Mirror(normReq, route.GetMirror())
 		p.backendReverseProxy.ServeHTTP(resp, normReq)
 		return
 	}
@@ -144,6 +236,103 @@ func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	respondWithError(err, req, resp)
 }
 
+// resolveAdhoc resolves the address of the adhoc target within its own span, as it may need a DNS lookup.
+func resolveAdhoc(ctx context.Context, adhocRouter common.Addresser, hostPort string) (string, error) {
+	_, span := tracing.StartSpan(ctx, "kedge.adhoc.resolve", tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("kedge.adhoc.host", hostPort)
+	addr, err := adhocRouter.Address(hostPort)
+	span.SetError(err)
+	span.SetAttribute("kedge.adhoc.address", addr)
+	return addr, err
+}
+
+// proxyModeLabel returns the proxy mode of the request for metrics. Adhoc mode is set once the request is routed.
+func proxyModeLabel(req *http.Request) string {
+	if proxyreq.GetProxyMode(req) == proxyreq.MODE_FORWARD_PROXY {
+		return metrics.ModeForward
+	}
+	return metrics.ModeReverse
+}
+
+// finishProxyRequest reports the latency metrics of the request with the error type reported while proxying.
+func finishProxyRequest(proxyReq *metrics.ProxyRequest, req *http.Request) {
+	proxyReq.SetErrorType(string(reporter.Extract(req).ErrType()))
+	proxyReq.Finish()
+}
+
+// timeUpstream adds the time from sending the request until its response body is closed (done by the reverse proxy
+// once the response is copied) as upstream time of the proxied request.
+func timeUpstream(next http.RoundTripper) http.RoundTripper {
+	return httpwares.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		proxyReq := metrics.ProxyRequestFromContext(req.Context())
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		if err != nil || proxyReq == nil {
+			proxyReq.AddUpstream(time.Since(start))
+			return resp, err
+		}
+		resp.Body = &upstreamBody{ReadCloser: resp.Body, onClose: func() {
+			proxyReq.AddUpstream(time.Since(start))
+		}}
+		return resp, nil
+	})
+}
+
+type upstreamBody struct {
+	io.ReadCloser
+	once    sync.Once
+	onClose func()
+}
+
+func (b *upstreamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.onClose)
+	return err
+}
+
+// endProxySpan records the error that prevented proxying the request (if any) and ends the span.
+func endProxySpan(span *tracing.Span, req *http.Request) {
+	if span == nil {
+		return
+	}
+	if errType, err := reporter.Extract(req).Error(); err != nil {
+		span.SetAttribute("kedge.error_type", string(errType))
+		span.SetError(err)
+	}
+	span.End()
+}
+
+type routeCtxKey struct{}
+
+type routeWithHeadersData struct {
+	route *router.Route
+	data  *router.HeaderTemplateData
+}
+
+func headerTemplateData(req *http.Request) *router.HeaderTemplateData {
+	tags := http_ctxtags.ExtractInbound(req).Values()
+	data := &router.HeaderTemplateData{
+		OriginalHost: req.Host,
+	}
+	data.OIDCSubject, _ = tags[ctxtags.TagForProxyAuthSubject].(string)
+	data.RequestID, _ = tags[ctxtags.TagRequestID].(string)
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		data.ClientCertCN = req.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	return data
+}
+
+// modifyResponseHeaders applies response header actions of the route the request was routed with.
+func modifyResponseHeaders(resp *http.Response) error {
+	r, ok := resp.Request.Context().Value(routeCtxKey{}).(*routeWithHeadersData)
+	if !ok {
+		return nil
+	}
+	r.route.ApplyResponseHeaders(resp.Header, r.data)
+	return nil
+}
+
 // backendPoolTripper assumes the response has been rewritten by the proxy to have the backend as req.URL.Host
 type backendPoolTripper struct {
 	pool backendpool.Pool
@@ -162,6 +351,8 @@ func respondWithError(err error, req *http.Request, resp http.ResponseWriter) {
 	errType := errtypes.RouteUnknownError
 	if err == router.ErrRouteNotFound {
 		errType = errtypes.NoRoute
+	} else if err == router.ErrRateLimited {
+		errType = errtypes.RateLimited
 	}
 	tracker := reporter.Extract(req)
 	tracker.ReportError(errType, err)
@@ -186,6 +377,10 @@ func AuthMiddleware(authorizer authorize.Authorizer) httpwares.Middleware {
 				return
 			}
 
+			if subject := bearerTokenSubject(req.Header.Get(tripperware.ProxyAuthHeader)); subject != "" {
+				http_ctxtags.ExtractInbound(req).Set(ctxtags.TagForProxyAuthSubject, subject)
+			}
+
 			// Strip out ProxyAuth header.
 			req.Header.Del(tripperware.ProxyAuthHeader)
 
@@ -195,6 +390,14 @@ func AuthMiddleware(authorizer authorize.Authorizer) httpwares.Middleware {
 	}
 }
 
+func bearerTokenSubject(authValue string) string {
+	parts := strings.Split(strings.TrimSpace(authValue), " ")
+	if len(parts) < 2 {
+		return ""
+	}
+	return common.TokenSubject(parts[1])
+}
+
 func respondWithUnauthorized(err error, req *http.Request, resp http.ResponseWriter) {
 	errType := errtypes.Unauthorized
 	reporter.Extract(req).ReportError(errType, err)
@@ -28,8 +28,9 @@ func NormalizeInboundRequest(r *http.Request) *http.Request {
 }
 
 func unnormalizedRequestMode(r *http.Request) ProxyMode {
-	if r.Method != "CONNECT" && strings.HasPrefix(r.RequestURI, "http") {
+	if r.Method == "CONNECT" || strings.HasPrefix(r.RequestURI, "http") {
 		// Forward Proxy requests embed the host information of the destination inside the RequestURI.
+		// CONNECT requests carry only the host:port of the destination there.
 		return MODE_FORWARD_PROXY
 	} else {
 		return MODE_REVERSE_PROXY
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"text/template"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/pkg/errors"
+)
+
+// HeaderTemplateData is the request data available in templates of header values, e.g. "{{.OIDCSubject}}".
+type HeaderTemplateData struct {
+	// OIDCSubject is the subject of the OIDC token used for proxy auth.
+	OIDCSubject string
+	// ClientCertCN is the common name of the client certificate.
+	ClientCertCN string
+	// RequestID is the kedge request ID.
+	RequestID string
+	// OriginalHost is the Host of the inbound request.
+	OriginalHost string
+}
+
+type headerActions struct {
+	add    map[string]*template.Template
+	set    map[string]*template.Template
+	remove []string
+}
+
+func newHeaderActions(cnf *pb.HeaderActions) (*headerActions, error) {
+	if cnf == nil {
+		return nil, nil
+	}
+
+	a := &headerActions{remove: cnf.Remove}
+	var err error
+	if a.add, err = compileHeaderTemplates(cnf.Add); err != nil {
+		return nil, err
+	}
+	if a.set, err = compileHeaderTemplates(cnf.Set); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func compileHeaderTemplates(headers map[string]string) (map[string]*template.Template, error) {
+	templates := map[string]*template.Template{}
+	for k, v := range headers {
+		tmpl, err := template.New(k).Parse(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse template of header %v", k)
+		}
+		// Execute once to catch references to unknown fields.
+		if err := tmpl.Execute(&bytes.Buffer{}, &HeaderTemplateData{}); err != nil {
+			return nil, errors.Wrapf(err, "failed to execute template of header %v", k)
+		}
+		templates[k] = tmpl
+	}
+	return templates, nil
+}
+
+func (a *headerActions) apply(h http.Header, data *HeaderTemplateData) {
+	if a == nil {
+		return
+	}
+
+	for _, k := range a.remove {
+		h.Del(k)
+	}
+	for k, tmpl := range a.set {
+		if v, ok := executeHeaderTemplate(tmpl, data); ok {
+			h.Set(k, v)
+		}
+	}
+	for k, tmpl := range a.add {
+		if v, ok := executeHeaderTemplate(tmpl, data); ok {
+			h.Add(k, v)
+		}
+	}
+}
+
+func executeHeaderTemplate(tmpl *template.Template, data *HeaderTemplateData) (string, bool) {
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		// Should not happen, templates are validated when creating the route.
+		return "", false
+	}
+	return buf.String(), true
+}
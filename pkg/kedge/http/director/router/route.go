@@ -0,0 +1,156 @@
+package router
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/pkg/errors"
+)
+
+// Route is a configured route together with helpers precomputed for routing and proxying.
+type Route struct {
+	*pb.Route
+
+	// Name is the name of the route used in metrics. It defaults to the position of the route.
+	Name string
+
+	// split is nil if the route does not specify weighted backends.
+	split *common.WeightedBackends
+	// pathRegex is nil if the route does not specify regex path rewrite.
+	pathRegex *regexp.Regexp
+
+	requestHeaders  *headerActions
+	responseHeaders *headerActions
+
+	hostPattern    *common.StringMatcher
+	pathPatterns   []*common.StringMatcher
+	headerPatterns map[string]*common.StringMatcher
+
+	// rateLimiter is nil if the route does not specify rate limit.
+	rateLimiter *common.RateLimiter
+}
+
+// newRoute compiles the route. Rate limiter of the route is taken from limiters (keyed by common.RateLimiterKey) if
+// present there.
+func newRoute(idx int, cnf *pb.Route, limiters map[string]*common.RateLimiter) (*Route, error) {
+	r := &Route{Route: cnf, Name: cnf.Name}
+	if r.Name == "" {
+		r.Name = strconv.Itoa(idx)
+	}
+
+	if len(cnf.WeightedBackends) > 0 {
+		var names []string
+		var weights []uint32
+		for _, b := range cnf.WeightedBackends {
+			names = append(names, b.BackendName)
+			weights = append(weights, b.Weight)
+		}
+		r.split = common.NewWeightedBackends(names, weights)
+	}
+
+	if regex := cnf.GetPathRewrite().GetRegex(); regex != nil {
+		var err error
+		r.pathRegex, err = regexp.Compile(regex.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "route %v: failed to compile path rewrite regex", r.Name)
+		}
+	}
+
+	if cnf.RateLimit != nil {
+		r.rateLimiter = limiters[common.RateLimiterKey(r.Name, cnf.RateLimit)]
+		if r.rateLimiter == nil {
+			r.rateLimiter = common.NewRateLimiter(cnf.RateLimit)
+		}
+	}
+
+	var err error
+	if r.hostPattern, err = common.NewStringMatcher(cnf.HostPattern); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid host_pattern", r.Name)
+	}
+	for _, p := range cnf.PathPatterns {
+		m, err := common.NewStringMatcher(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "route %v: invalid path_patterns", r.Name)
+		}
+		r.pathPatterns = append(r.pathPatterns, m)
+	}
+	r.headerPatterns = map[string]*common.StringMatcher{}
+	for k, p := range cnf.HeaderPatterns {
+		if r.headerPatterns[k], err = common.NewStringMatcher(p); err != nil {
+			return nil, errors.Wrapf(err, "route %v: invalid header_patterns", r.Name)
+		}
+	}
+
+	if r.requestHeaders, err = newHeaderActions(cnf.RequestHeaders); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid request_headers", r.Name)
+	}
+	if r.responseHeaders, err = newHeaderActions(cnf.ResponseHeaders); err != nil {
+		return nil, errors.Wrapf(err, "route %v: invalid response_headers", r.Name)
+	}
+	return r, nil
+}
+
+// ValidateRoutes returns an error if any of the routes cannot be used by the router, e.g. because of an invalid regex
+// or a missing backend.
+func ValidateRoutes(routes []*pb.Route) error {
+	for i, r := range routes {
+		route, err := newRoute(i, r, nil)
+		if err != nil {
+			return err
+		}
+		if r.BackendName == "" && len(r.WeightedBackends) == 0 {
+			return errors.Errorf("route %v: either backend_name or weighted_backends is required", route.Name)
+		}
+	}
+	return nil
+}
+
+// RewritePath returns the URL path that should be sent to the backend for the given request path.
+func (r *Route) RewritePath(path string) string {
+	if r.pathRegex != nil {
+		path = r.pathRegex.ReplaceAllString(path, r.GetPathRewrite().GetRegex().Replacement)
+	} else if prefix := r.GetPathRewrite().GetPrefix(); prefix != nil && strings.HasPrefix(path, prefix.From) {
+		path = prefix.To + strings.TrimPrefix(path, prefix.From)
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// AllowRequest returns false if the request exceeds the rate limit of the route for the client that sent it.
+func (r *Route) AllowRequest(req *http.Request, data *HeaderTemplateData) bool {
+	if r.rateLimiter == nil {
+		return true
+	}
+
+	var key string
+	switch r.RateLimit.Key {
+	case pb_common.RateLimit_OIDC_SUBJECT:
+		key = data.OIDCSubject
+	case pb_common.RateLimit_CLIENT_CERT_CN:
+		key = data.ClientCertCN
+	case pb_common.RateLimit_HEADER:
+		key = req.Header.Get(r.RateLimit.Header)
+	default:
+		key, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}
+	return r.rateLimiter.Allow(key)
+}
+
+// ApplyRequestHeaders modifies headers of the request sent to the backend according to the route's request_headers.
+func (r *Route) ApplyRequestHeaders(h http.Header, data *HeaderTemplateData) {
+	r.requestHeaders.apply(h, data)
+}
+
+// ApplyResponseHeaders modifies headers of the response sent to the client according to the route's response_headers.
+func (r *Route) ApplyResponseHeaders(h http.Header, data *HeaderTemplateData) {
+	r.responseHeaders.apply(h, data)
+}
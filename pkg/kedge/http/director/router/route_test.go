@@ -0,0 +1,99 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute_RewritePath(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rewrite  *pb.PathRewrite
+		path     string
+		expected string
+	}{
+		{
+			name:     "NoRewrite",
+			path:     "/teams/foo/api/x",
+			expected: "/teams/foo/api/x",
+		},
+		{
+			name:     "StripPrefix",
+			rewrite:  &pb.PathRewrite{Rewrite: &pb.PathRewrite_Prefix{Prefix: &pb.PrefixRewrite{From: "/teams/foo"}}},
+			path:     "/teams/foo/api/x",
+			expected: "/api/x",
+		},
+		{
+			name:     "StripWholePath",
+			rewrite:  &pb.PathRewrite{Rewrite: &pb.PathRewrite_Prefix{Prefix: &pb.PrefixRewrite{From: "/teams/foo"}}},
+			path:     "/teams/foo",
+			expected: "/",
+		},
+		{
+			name:     "ReplacePrefix",
+			rewrite:  &pb.PathRewrite{Rewrite: &pb.PathRewrite_Prefix{Prefix: &pb.PrefixRewrite{From: "/teams/foo", To: "/v2"}}},
+			path:     "/teams/foo/api/x",
+			expected: "/v2/api/x",
+		},
+		{
+			name:     "PrefixNotMatching",
+			rewrite:  &pb.PathRewrite{Rewrite: &pb.PathRewrite_Prefix{Prefix: &pb.PrefixRewrite{From: "/teams/bar"}}},
+			path:     "/teams/foo/api/x",
+			expected: "/teams/foo/api/x",
+		},
+		{
+			name: "Regex",
+			rewrite: &pb.PathRewrite{Rewrite: &pb.PathRewrite_Regex{Regex: &pb.RegexRewrite{
+				Pattern:     "^/teams/([a-z]+)/api/(.*)$",
+				Replacement: "/api/$1/$2",
+			}}},
+			path:     "/teams/foo/api/x",
+			expected: "/api/foo/x",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := newRoute(0, &pb.Route{PathRewrite: tc.rewrite}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, r.RewritePath(tc.path))
+		})
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	require.NoError(t, ValidateRoutes(routeConfigs))
+	assert.Error(t, ValidateRoutes([]*pb.Route{
+		{BackendName: "a", PathRewrite: &pb.PathRewrite{Rewrite: &pb.PathRewrite_Regex{Regex: &pb.RegexRewrite{Pattern: "(unclosed"}}}},
+	}))
+	assert.Error(t, ValidateRoutes([]*pb.Route{
+		{BackendName: "a", RequestHeaders: &pb.HeaderActions{Set: map[string]string{"X-User": "{{.Unknown}}"}}},
+	}))
+	assert.Error(t, ValidateRoutes([]*pb.Route{{Name: "no_backend", HostMatcher: "a.example.com"}}))
+	assert.NoError(t, ValidateRoutes([]*pb.Route{{WeightedBackends: []*pb.WeightedBackend{{BackendName: "a", Weight: 1}}}}))
+}
+
+func TestRoute_ApplyHeaders(t *testing.T) {
+	r, err := newRoute(0, &pb.Route{
+		RequestHeaders: &pb.HeaderActions{
+			Add:    map[string]string{"X-Added": "{{.RequestID}}"},
+			Set:    map[string]string{"X-User": "{{.OIDCSubject}}", "X-Cert": "cn={{.ClientCertCN}}"},
+			Remove: []string{"X-Removed", "X-User"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	h := http.Header{}
+	h.Set("X-Added", "existing")
+	h.Set("X-Removed", "something")
+	h.Set("X-User", "spoofed")
+	r.ApplyRequestHeaders(h, &HeaderTemplateData{OIDCSubject: "user1", ClientCertCN: "client1", RequestID: "req1"})
+	r.ApplyResponseHeaders(h, &HeaderTemplateData{})
+
+	assert.Equal(t, []string{"existing", "req1"}, h["X-Added"])
+	assert.Equal(t, "user1", h.Get("X-User"))
+	assert.Equal(t, "cn=client1", h.Get("X-Cert"))
+	assert.Empty(t, h.Get("X-Removed"))
+}
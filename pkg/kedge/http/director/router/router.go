@@ -8,20 +8,23 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
 	"github.com/improbable-eng/kedge/pkg/kedge/http/director/proxyreq"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/metadata"
 )
 
 var (
 	emptyMd          = metadata.Pairs()
 	ErrRouteNotFound = errors.New("unknown route to service")
+	ErrRateLimited   = NewError(http.StatusTooManyRequests, "rate limit exceeded")
 )
 
 type Router interface {
-	// Route returns a backend name for a given call, or an error.
+	// Route returns a backend name for a given call together with the matched route, or an error.
 	// Note: the request *must* be normalized.
-	Route(req *http.Request) (backendName string, err error)
+	Route(req *http.Request) (backendName string, route *Route, err error)
 }
 
 type dynamic struct {
@@ -34,59 +37,184 @@ func NewDynamic() *dynamic {
 	return &dynamic{staticRouter: NewStatic([]*pb.Route{})}
 }
 
-func (d *dynamic) Route(req *http.Request) (backendName string, err error) {
+func (d *dynamic) Route(req *http.Request) (backendName string, route *Route, err error) {
 	d.mu.RLock()
 	staticRouter := d.staticRouter
 	d.mu.RUnlock()
 	return staticRouter.Route(req)
 }
 
-// Update sets the routing table to the provided set of routes.
+func (d *dynamic) Explain(req *http.Request) *Explanation {
+	d.mu.RLock()
+	staticRouter := d.staticRouter
+	d.mu.RUnlock()
+	return staticRouter.Explain(req)
+}
+
+// Update sets the routing table to the provided set of routes. Rate limits of routes that did not change keep their state.
 func (d *dynamic) Update(routes []*pb.Route) {
-	staticRouter := NewStatic(routes)
+	d.mu.RLock()
+	previous := d.staticRouter
+	d.mu.RUnlock()
+	staticRouter := newStatic(routes, previous.rateLimiters)
 	d.mu.Lock()
 	d.staticRouter = staticRouter
 	d.mu.Unlock()
 }
 
 type static struct {
-	routes []*pb.Route
+	routes []*Route
+	// rateLimiters are rate limiters of the routes keyed by common.RateLimiterKey.
+	rateLimiters map[string]*common.RateLimiter
 }
 
+// NewStatic creates a router with the given routes. Routes that fail to compile are logged and skipped, use
+// ValidateRoutes to check them upfront.
 func NewStatic(routes []*pb.Route) *static {
-	return &static{routes: routes}
+	return newStatic(routes, nil)
 }
 
-func (r *static) Route(req *http.Request) (backendName string, err error) {
-	port := req.URL.Port()
-	if port == "" {
-		switch strings.ToLower(req.URL.Scheme) {
-		case "", "http":
-			port = "80"
-		case "https":
-			port = "443"
+// newStatic creates a router reusing the given rate limiters for routes with the same name and rate limit.
+func newStatic(routes []*pb.Route, rateLimiters map[string]*common.RateLimiter) *static {
+	s := &static{rateLimiters: map[string]*common.RateLimiter{}}
+	for i, r := range routes {
+		route, err := newRoute(i, r, rateLimiters)
+		if err != nil {
+			logrus.WithError(err).Error("Skipping invalid HTTP route.")
+			continue
 		}
+		if route.rateLimiter != nil {
+			s.rateLimiters[common.RateLimiterKey(route.Name, r.RateLimit)] = route.rateLimiter
+		}
+		s.routes = append(s.routes, route)
 	}
+	return s
+}
 
+func (r *static) Route(req *http.Request) (backendName string, matched *Route, err error) {
+	port := requestPort(req)
 	for _, route := range r.routes {
-		if !r.urlMatches(req.URL, route.PathRules) {
+		if r.mismatch(req, port, route) != "" {
 			continue
 		}
-		if !r.hostMatches(req.URL.Hostname(), route.HostMatcher) {
+		return r.pickBackend(req, route), route, nil
+	}
+	return "", nil, ErrRouteNotFound
+}
+
+// Explanation describes how a request is routed. It is used for debugging.
+type Explanation struct {
+	// Skipped are the routes before the matched one (or all routes if none matched) with the reason they do not match.
+	Skipped []SkippedRoute
+	// RouteName is the name of the matched route. Route and BackendName are empty if no route matched.
+	RouteName   string
+	Route       *pb.Route
+	BackendName string
+	// Split is set if the matched route splits traffic between weighted backends. BackendName is then the backend picked
+	// for StickyKey, or empty if the request has no sticky key and every request picks one of them randomly.
+	Split     []SplitBackend
+	StickyKey string
+}
+
+// SplitBackend is one of the weighted backends of a route.
+type SplitBackend struct {
+	Name   string
+	Weight uint32
+}
+
+// SkippedRoute is a route that does not match a request.
+type SkippedRoute struct {
+	Name   string
+	Reason string
+}
+
+// Explain routes the request like Route, but without counting it in metrics, and tells why the routes before the
+// matched one were skipped.
+func (r *static) Explain(req *http.Request) *Explanation {
+	port := requestPort(req)
+	e := &Explanation{}
+	for _, route := range r.routes {
+		if reason := r.mismatch(req, port, route); reason != "" {
+			e.Skipped = append(e.Skipped, SkippedRoute{Name: route.Name, Reason: reason})
 			continue
 		}
-		if !r.portMatches(port, route.PortMatcher) {
-			continue
+		e.RouteName = route.Name
+		e.Route = route.Route
+		if route.split == nil {
+			e.BackendName = route.BackendName
+			break
 		}
-		if !r.headersMatch(req.Header, route.HeaderMatcher) {
-			continue
+		for _, b := range route.WeightedBackends {
+			e.Split = append(e.Split, SplitBackend{Name: b.BackendName, Weight: b.Weight})
 		}
-		if !r.requestTypeMatch(proxyreq.GetProxyMode(req), route.ProxyMode) {
-			continue
+		if e.StickyKey = r.stickyKey(req, route); e.StickyKey != "" {
+			e.BackendName = route.split.Pick(e.StickyKey)
+		}
+		break
+	}
+	return e
+}
+
+func requestPort(req *http.Request) string {
+	port := req.URL.Port()
+	if port == "" {
+		switch strings.ToLower(req.URL.Scheme) {
+		case "", "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return port
+}
+
+// mismatch returns the reason why the route does not match the request, or empty string if it matches.
+func (r *static) mismatch(req *http.Request, port string, route *Route) string {
+	if !r.urlMatches(req.URL, route.PathRules) {
+		return fmt.Sprintf("path %q does not match path_rules", req.URL.Path)
+	}
+	if !r.hostMatches(req.URL.Hostname(), route.HostMatcher) {
+		return fmt.Sprintf("host %q does not match host_matcher", req.URL.Hostname())
+	}
+	if !r.portMatches(port, route.PortMatcher) {
+		return fmt.Sprintf("port %q does not match port_matcher", port)
+	}
+	if !r.headersMatch(req.Header, route.HeaderMatcher) {
+		return "headers do not match header_matcher"
+	}
+	if !r.requestTypeMatch(proxyreq.GetProxyMode(req), route.ProxyMode) {
+		return "request is not in proxy_mode " + route.ProxyMode.String()
+	}
+	if !r.pathPatternsMatch(req.URL.Path, route.pathPatterns) {
+		return fmt.Sprintf("path %q does not match path_patterns", req.URL.Path)
+	}
+	if !route.hostPattern.Match(req.URL.Hostname()) {
+		return fmt.Sprintf("host %q does not match host_pattern", req.URL.Hostname())
+	}
+	if !r.headerPatternsMatch(req.Header, route.headerPatterns) {
+		return "headers do not match header_patterns"
+	}
+	return ""
+}
+
+func (r *static) pickBackend(req *http.Request, route *Route) string {
+	if route.split == nil {
+		return route.BackendName
+	}
+	return route.split.Pick(r.stickyKey(req, route))
+}
+
+// stickyKey returns the part of the request that makes the weighted backend choice sticky, or empty string.
+func (r *static) stickyKey(req *http.Request, route *Route) string {
+	if header := route.StickySplit.GetHeader(); header != "" {
+		return req.Header.Get(header)
+	}
+	if cookieName := route.StickySplit.GetCookie(); cookieName != "" {
+		if cookie, err := req.Cookie(cookieName); err == nil {
+			return cookie.Value
 		}
-		return route.BackendName, nil
 	}
-	return "", ErrRouteNotFound
+	return ""
 }
 
 func (r *static) urlMatches(u *url.URL, matchers []string) bool {
@@ -144,6 +272,31 @@ func (r *static) headersMatch(header http.Header, expectedKv map[string]string)
 	return true
 }
 
+func (r *static) pathPatternsMatch(path string, matchers []*common.StringMatcher) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	for _, m := range matchers {
+		if m.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *static) headerPatternsMatch(header http.Header, matchers map[string]*common.StringMatcher) bool {
+	for k, m := range matchers {
+		headerVal := header.Get(k)
+		if headerVal == "" {
+			return false // key doesn't exist
+		}
+		if !m.Match(headerVal) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *static) requestTypeMatch(requestMode proxyreq.ProxyMode, routeMode pb.ProxyMode) bool {
 	if routeMode == pb.ProxyMode_ANY {
 		return true
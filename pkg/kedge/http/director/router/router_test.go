@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"testing"
 
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 	pb_route "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -162,7 +163,7 @@ func TestRoute_NoPathNoPort_PortExpanded(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, url, nil)
 		require.NoError(t, err)
 
-		route, err := r.Route(req)
+		route, _, err := r.Route(req)
 		if tc.expectedErr != nil {
 			require.Equal(t, tc.expectedErr, err, url)
 			continue
@@ -172,3 +173,145 @@ func TestRoute_NoPathNoPort_PortExpanded(t *testing.T) {
 		assert.Equal(t, tc.expectedBackend, route, url)
 	}
 }
+
+func TestRoute_WeightedBackends(t *testing.T) {
+	r := NewStatic([]*pb_route.Route{
+		{
+			HostMatcher: "header.example.com",
+			WeightedBackends: []*pb_route.WeightedBackend{
+				{BackendName: "stable", Weight: 90},
+				{BackendName: "canary", Weight: 10},
+			},
+			StickySplit: &pb_route.StickySplit{Key: &pb_route.StickySplit_Header{Header: "X-User"}},
+		},
+		{
+			HostMatcher: "cookie.example.com",
+			WeightedBackends: []*pb_route.WeightedBackend{
+				{BackendName: "stable", Weight: 50},
+				{BackendName: "canary", Weight: 50},
+			},
+			StickySplit: &pb_route.StickySplit{Key: &pb_route.StickySplit_Cookie{Cookie: "session"}},
+		},
+	})
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://header.example.com/", nil)
+		require.NoError(t, err)
+		be, _, err := r.Route(req)
+		require.NoError(t, err)
+		picked[be]++
+	}
+	assert.Len(t, picked, 2, "both backends should be picked without sticky header")
+	assert.True(t, picked["stable"] > picked["canary"], "stable backend should get most of the traffic")
+
+	for _, tc := range []struct {
+		url    string
+		header http.Header
+	}{
+		{url: "http://header.example.com/", header: http.Header{"X-User": []string{"user1"}}},
+		{url: "http://cookie.example.com/", header: http.Header{"Cookie": []string{"session=abc"}}},
+	} {
+		backends := map[string]struct{}{}
+		for i := 0; i < 100; i++ {
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			req.Header = tc.header
+			be, _, err := r.Route(req)
+			require.NoError(t, err)
+			backends[be] = struct{}{}
+		}
+		assert.Len(t, backends, 1, "sticky requests should always be routed to the same backend, %v", tc.url)
+	}
+}
+
+func TestRoute_Patterns(t *testing.T) {
+	r := NewStatic([]*pb_route.Route{
+		{
+			BackendName: "wildcard_host",
+			HostPattern: &pb_common.StringMatcher{Match: &pb_common.StringMatcher_Wildcard{Wildcard: "*.svc.example.com"}},
+			PathPatterns: []*pb_common.StringMatcher{
+				{Match: &pb_common.StringMatcher_Prefix{Prefix: "/api/"}},
+				{Match: &pb_common.StringMatcher_Regex{Regex: "/v[0-9]+/.*"}},
+			},
+		},
+		{
+			BackendName: "header_regex",
+			HeaderPatterns: map[string]*pb_common.StringMatcher{
+				"X-Version": {Match: &pb_common.StringMatcher_Regex{Regex: "canary-[a-z]+"}},
+			},
+		},
+	})
+
+	for _, tc := range []struct {
+		url    string
+		header http.Header
+
+		expectedBackend string
+		expectedErr     error
+	}{
+		{url: "http://a.svc.example.com/api/x", expectedBackend: "wildcard_host"},
+		{url: "http://b.svc.example.com/v2/x", expectedBackend: "wildcard_host"},
+		{url: "http://b.svc.example.com/other", expectedErr: ErrRouteNotFound},
+		{url: "http://svc.example.com/api/x", expectedErr: ErrRouteNotFound},
+		{url: "http://svc.example.com/api/x", header: http.Header{"X-Version": []string{"canary-abc"}}, expectedBackend: "header_regex"},
+		{url: "http://svc.example.com/api/x", header: http.Header{"X-Version": []string{"canary-123"}}, expectedErr: ErrRouteNotFound},
+	} {
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		require.NoError(t, err)
+		if tc.header != nil {
+			req.Header = tc.header
+		}
+
+		be, _, err := r.Route(req)
+		if tc.expectedErr != nil {
+			require.Equal(t, tc.expectedErr, err, tc.url)
+			continue
+		}
+		require.NoError(t, err, tc.url)
+		assert.Equal(t, tc.expectedBackend, be, tc.url)
+	}
+}
+
+func TestDynamicUpdate_KeepsRateLimits(t *testing.T) {
+	limited := &pb_route.Route{Name: "limited", BackendName: "a", RateLimit: &pb_common.RateLimit{RequestsPerSecond: 0.001, Burst: 1}}
+	other := &pb_route.Route{Name: "other", BackendName: "b", HostMatcher: "other.example.com"}
+	r := NewDynamic()
+	r.Update([]*pb_route.Route{limited})
+
+	req, _ := http.NewRequest("GET", "http://some.example.com/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	allow := func() bool {
+		_, route, err := r.Route(req)
+		require.NoError(t, err)
+		return route.AllowRequest(req, &HeaderTemplateData{})
+	}
+	assert.True(t, allow())
+
+	r.Update([]*pb_route.Route{other, limited})
+	assert.False(t, allow(), "update of other routes should not reset the rate limit")
+
+	changed := *limited
+	changed.RateLimit = &pb_common.RateLimit{RequestsPerSecond: 0.001, Burst: 2}
+	r.Update([]*pb_route.Route{&changed})
+	assert.True(t, allow(), "changed rate limit should start with a full bucket")
+}
+
+func TestExplain(t *testing.T) {
+	r := NewStatic(routeConfigs)
+
+	req, err := http.NewRequest(http.MethodGet, "http://path.port.example.com:83/some/strict/path", nil)
+	require.NoError(t, err)
+	e := r.Explain(req)
+	assert.Equal(t, "3", e.RouteName)
+	assert.Equal(t, "d", e.BackendName)
+	require.Len(t, e.Skipped, 3)
+	assert.Equal(t, SkippedRoute{Name: "0", Reason: `host "path.port.example.com" does not match host_matcher`}, e.Skipped[0])
+
+	req, err = http.NewRequest(http.MethodGet, "http://path.port.example.com:83/other/path", nil)
+	require.NoError(t, err)
+	e = r.Explain(req)
+	assert.Nil(t, e.Route)
+	require.Len(t, e.Skipped, len(routeConfigs))
+	assert.Equal(t, SkippedRoute{Name: "3", Reason: `path "/other/path" does not match path_rules`}, e.Skipped[3])
+}
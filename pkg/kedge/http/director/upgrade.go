@@ -0,0 +1,262 @@
+package director
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/improbable-eng/go-httpwares/tags"
+	"github.com/improbable-eng/kedge/pkg/reporter"
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const adhocUpgradeBackendName = "_adhoc"
+
+var (
+	flagUpgradeIdleTimeout = sharedflags.Set.Duration("http_upgrade_idle_timeout", 5*time.Minute,
+		"Default idle timeout of upgraded (e.g. WebSocket) connections. Can be overridden by the route. 0 disables it.")
+	flagUpgradeMaxDuration = sharedflags.Set.Duration("http_upgrade_max_duration", 0,
+		"Default maximum duration of upgraded (e.g. WebSocket) connections. Can be overridden by the route. 0 disables it.")
+
+	upgradedConnsOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "http",
+			Name:      "upgraded_connections_open",
+			Help:      "Number of currently open upgraded (e.g. WebSocket) connections tunnelled to backends.",
+		},
+		[]string{"backend_name"},
+	)
+	upgradedConnsClosed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http",
+			Name:      "upgraded_connections_closed_total",
+			Help:      "Total number of closed upgraded connections by the reason of closing.",
+		},
+		[]string{"backend_name", "reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(upgradedConnsOpen)
+	prometheus.MustRegister(upgradedConnsClosed)
+}
+
+// hopHeaders are hop-by-hop headers that are not passed to the backend.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// isUpgradeRequest returns true for requests asking for protocol upgrade, e.g. WebSocket or SPDY.
+func isUpgradeRequest(req *http.Request) bool {
+	if req.Header.Get("Upgrade") == "" {
+		return false
+	}
+	for _, v := range req.Header["Connection"] {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type upgradeLimits struct {
+	idleTimeout time.Duration
+	maxDuration time.Duration
+}
+
+// upgradeLimitsForRoute returns limits from the route, with flag defaults for those not specified.
+func upgradeLimitsForRoute(cnf *pb.Upgrade) upgradeLimits {
+	limits := upgradeLimits{
+		idleTimeout: *flagUpgradeIdleTimeout,
+		maxDuration: *flagUpgradeMaxDuration,
+	}
+	if cnf.GetIdleTimeoutMs() > 0 {
+		limits.idleTimeout = time.Duration(cnf.GetIdleTimeoutMs()) * time.Millisecond
+	}
+	if cnf.GetMaxDurationMs() > 0 {
+		limits.maxDuration = time.Duration(cnf.GetMaxDurationMs()) * time.Millisecond
+	}
+	return limits
+}
+
+// upgradeProxy tunnels upgraded connections. httputil.ReverseProxy is not used for that, because upgraded connections
+// would be killed by the write timeout of the HTTP server.
+type upgradeProxy struct {
+	tripper  http.RoundTripper
+	logEntry logrus.FieldLogger
+}
+
+func (u *upgradeProxy) serve(resp http.ResponseWriter, req *http.Request, backendName string, limits upgradeLimits) {
+	hijacker, ok := resp.(http.Hijacker)
+	if !ok {
+		// HTTP/2 does not support Connection: Upgrade.
+		u.respondWithError(resp, req, http.StatusBadRequest, errors.New("upgrade: connection cannot be upgraded"))
+		return
+	}
+
+	outReq := req.WithContext(req.Context())
+	outReq.RequestURI = ""
+	outReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		outReq.Header[k] = v
+	}
+	for _, h := range hopHeaders {
+		outReq.Header.Del(h)
+	}
+	outReq.Header.Set("Connection", "Upgrade")
+	outReq.Header.Set("Upgrade", req.Header.Get("Upgrade"))
+	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		outReq.Header.Set("X-Forwarded-For", strings.Join(append(req.Header["X-Forwarded-For"], clientIP), ", "))
+	}
+
+	backendResp, err := u.tripper.RoundTrip(outReq)
+	if err != nil {
+		u.respondWithError(resp, req, http.StatusBadGateway, err)
+		return
+	}
+	modifyResponseHeaders(backendResp)
+
+	if backendResp.StatusCode != http.StatusSwitchingProtocols {
+		// Backend refused to upgrade. Pass its response as it is.
+		defer backendResp.Body.Close()
+		for k, v := range backendResp.Header {
+			resp.Header()[k] = v
+		}
+		resp.WriteHeader(backendResp.StatusCode)
+		io.Copy(resp, backendResp.Body)
+		return
+	}
+
+	backendConn, ok := backendResp.Body.(io.ReadWriteCloser)
+	if !ok {
+		backendResp.Body.Close()
+		u.respondWithError(resp, req, http.StatusBadGateway, errors.New("upgrade: backend connection is not writable"))
+		return
+	}
+	defer backendConn.Close()
+
+	clientConn, clientBuf, err := hijacker.Hijack()
+	if err != nil {
+		u.respondWithError(resp, req, http.StatusInternalServerError, errors.Wrap(err, "upgrade: failed to hijack connection"))
+		return
+	}
+	defer clientConn.Close()
+
+	// Clear deadlines set by the HTTP server (write and read timeouts). Limits of the tunnel are applied instead.
+	clientConn.SetDeadline(time.Time{})
+
+	fmt.Fprintf(clientBuf, "HTTP/1.1 %s\r\n", backendResp.Status)
+	backendResp.Header.Write(clientBuf)
+	clientBuf.WriteString("\r\n")
+	if err := clientBuf.Flush(); err != nil {
+		u.logEntry.WithFields(http_ctxtags.ExtractInbound(req).Values()).WithError(err).Warn("Failed to write upgrade response")
+		return
+	}
+
+	upgradedConnsOpen.WithLabelValues(backendName).Inc()
+	reason := tunnel(clientConn, clientBuf, backendConn, limits)
+	upgradedConnsOpen.WithLabelValues(backendName).Dec()
+	upgradedConnsClosed.WithLabelValues(backendName, reason).Inc()
+}
+
+func (u *upgradeProxy) respondWithError(resp http.ResponseWriter, req *http.Request, status int, err error) {
+	tracker := reporter.Extract(req)
+	u.logEntry.WithFields(http_ctxtags.ExtractInbound(req).Values()).WithError(err).Warn("HTTP upgrade failed")
+	reporter.SetKedgeErrorHeaders(resp.Header(), tracker)
+	resp.Header().Set("content-type", "text/plain")
+	resp.WriteHeader(status)
+	fmt.Fprintf(resp, "%v", err.Error())
+}
+
+// activityReader records the time of the last read.
+type activityReader struct {
+	io.Reader
+	lastActivity *int64
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	n, err := a.Reader.Read(p)
+	if n > 0 {
+		atomic.StoreInt64(a.lastActivity, time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// tunnel copies data in both directions until one of the sides closes the connection or limits are exceeded.
+// It returns the reason of closing the tunnel.
+func tunnel(clientConn net.Conn, clientReader io.Reader, backendConn io.ReadWriteCloser, limits upgradeLimits) string {
+	lastActivity := time.Now().UnixNano()
+	doneC := make(chan string, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(backendConn, &activityReader{Reader: clientReader, lastActivity: &lastActivity})
+		doneC <- "client_closed"
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(clientConn, &activityReader{Reader: backendConn, lastActivity: &lastActivity})
+		doneC <- "backend_closed"
+	}()
+
+	var maxDurationC <-chan time.Time
+	if limits.maxDuration > 0 {
+		maxDurationC = time.After(limits.maxDuration)
+	}
+	var idleCheckC <-chan time.Time
+	if limits.idleTimeout > 0 {
+		ticker := time.NewTicker(idleCheckInterval(limits.idleTimeout))
+		defer ticker.Stop()
+		idleCheckC = ticker.C
+	}
+
+	var reason string
+	for reason == "" {
+		select {
+		case reason = <-doneC:
+		case <-maxDurationC:
+			reason = "max_duration"
+		case <-idleCheckC:
+			if time.Since(time.Unix(0, atomic.LoadInt64(&lastActivity))) >= limits.idleTimeout {
+				reason = "idle_timeout"
+			}
+		}
+	}
+
+	// Closing both sides unblocks the other copying goroutine.
+	clientConn.Close()
+	backendConn.Close()
+	wg.Wait()
+	return reason
+}
+
+func idleCheckInterval(idleTimeout time.Duration) time.Duration {
+	interval := idleTimeout / 10
+	if interval > time.Second {
+		return time.Second
+	}
+	if interval < time.Millisecond {
+		return time.Millisecond
+	}
+	return interval
+}
@@ -0,0 +1,117 @@
+package director
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoUpgradeHandler upgrades the connection to "echo" protocol which sends back every line it receives.
+func echoUpgradeHandler(resp http.ResponseWriter, req *http.Request) {
+	if !isUpgradeRequest(req) || req.Header.Get("Upgrade") != "echo" {
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	conn, buf, err := resp.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+	buf.Flush()
+	for {
+		line, err := buf.ReadString('\n')
+		if err != nil {
+			return
+		}
+		buf.WriteString(line)
+		buf.Flush()
+	}
+}
+
+func startUpgradeProxy(t *testing.T, limits upgradeLimits) (backend *httptest.Server, proxy *httptest.Server) {
+	backend = httptest.NewServer(http.HandlerFunc(echoUpgradeHandler))
+	backendURL, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	u := &upgradeProxy{tripper: &http.Transport{}, logEntry: logrus.New()}
+	proxy = httptest.NewUnstartedServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		req.URL.Scheme = "http"
+		req.URL.Host = backendURL.Host
+		u.serve(resp, req, "backend", limits)
+	}))
+	// Upgraded connections should not be affected by server timeouts.
+	proxy.Config.WriteTimeout = 100 * time.Millisecond
+	proxy.Config.ReadTimeout = 100 * time.Millisecond
+	proxy.Start()
+	return backend, proxy
+}
+
+func dialUpgrade(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: backend\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n"))
+	require.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	return conn, r
+}
+
+func TestUpgradeProxy_TunnelsLongLivedConnections(t *testing.T) {
+	backend, proxy := startUpgradeProxy(t, upgradeLimits{})
+	defer backend.Close()
+	defer proxy.Close()
+
+	conn, r := dialUpgrade(t, proxy.Listener.Addr().String())
+	defer conn.Close()
+
+	for _, msg := range []string{"hello\n", "world\n"} {
+		// Wait longer than server timeouts.
+		time.Sleep(150 * time.Millisecond)
+		_, err := conn.Write([]byte(msg))
+		require.NoError(t, err)
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, msg, line)
+	}
+}
+
+func TestUpgradeProxy_IdleTimeout(t *testing.T) {
+	backend, proxy := startUpgradeProxy(t, upgradeLimits{idleTimeout: 50 * time.Millisecond})
+	defer backend.Close()
+	defer proxy.Close()
+
+	conn, r := dialUpgrade(t, proxy.Listener.Addr().String())
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err := r.ReadString('\n')
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "timeout", "connection should be closed by the proxy")
+}
+
+func TestUpgradeProxy_BackendRefusesUpgrade(t *testing.T) {
+	backend, proxy := startUpgradeProxy(t, upgradeLimits{})
+	defer backend.Close()
+	defer proxy.Close()
+
+	req, err := http.NewRequest("GET", proxy.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "not-echo")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -14,6 +14,7 @@
 package http_integration
 
 import (
+	"bufio"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -44,6 +45,7 @@ import (
 	"github.com/improbable-eng/kedge/pkg/kedge/http/director/adhoc"
 	"github.com/improbable-eng/kedge/pkg/kedge/http/director/router"
 	"github.com/improbable-eng/kedge/pkg/map"
+	"github.com/improbable-eng/kedge/pkg/metrics"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/resolvers/srv"
 	"github.com/improbable-eng/kedge/protogen/kedge/config/common"
@@ -51,6 +53,8 @@ import (
 	pb_be "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
 	pb_route "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
 	"github.com/mwitkow/go-conntrack/connhelpers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -138,6 +142,41 @@ var (
 			HostMatcher: "secure.backends.test.local",
 			ProxyMode:   pb_route.ProxyMode_FORWARD_PROXY,
 		},
+		&pb_route.Route{
+			BackendName: "non_secure",
+			PathRules:   []string{"/teams/foo/*"},
+			HostMatcher: "rewrite.ext.example.com",
+			ProxyMode:   pb_route.ProxyMode_REVERSE_PROXY,
+			PathRewrite: &pb_route.PathRewrite{
+				Rewrite: &pb_route.PathRewrite_Prefix{Prefix: &pb_route.PrefixRewrite{From: "/teams/foo"}},
+			},
+			HostRewrite: "nonsecure.backends.test.local",
+		},
+		&pb_route.Route{
+			BackendName: "non_secure",
+			HostMatcher: "headers.ext.example.com",
+			ProxyMode:   pb_route.ProxyMode_REVERSE_PROXY,
+			RequestHeaders: &pb_route.HeaderActions{
+				Set:    map[string]string{"X-Custom": "host={{.OriginalHost}}"},
+				Remove: []string{"Authorization"},
+			},
+			ResponseHeaders: &pb_route.HeaderActions{
+				Add:    map[string]string{"X-Response-Custom": "{{.OriginalHost}}"},
+				Remove: []string{"x-test-req-proto"},
+			},
+		},
+		&pb_route.Route{
+			Name:        "ratelimited",
+			BackendName: "non_secure",
+			HostMatcher: "ratelimited.ext.example.com",
+			ProxyMode:   pb_route.ProxyMode_REVERSE_PROXY,
+			RateLimit: &kedge_config_common.RateLimit{
+				RequestsPerSecond: 0.001,
+				Burst:             1,
+				Key:               kedge_config_common.RateLimit_HEADER,
+				Header:            "X-Client",
+			},
+		},
 		&pb_route.Route{
 			BackendName: "killer",
 			HostMatcher: "nonsecure.killerbackend.test.local",
@@ -170,6 +209,7 @@ func unknownPingbackHandler(serverAddr string) http.Handler {
 		resp.Header().Set("x-test-backend-addr", serverAddr)
 		resp.Header().Set("x-test-auth-value", req.Header.Get("Authorization"))
 		resp.Header().Set("x-test-proxy-auth-value", req.Header.Get("Proxy-Authorization"))
+		resp.Header().Set("x-test-custom-value", req.Header.Get("X-Custom"))
 		resp.WriteHeader(http.StatusAccepted) // accepted to make sure stuff is slightly different.
 		resp.Write([]byte("TEST"))
 	})
@@ -341,7 +381,7 @@ func (s *HttpProxyingIntegrationSuite) SetupSuite() {
 
 	s.buildBackends()
 
-	s.backendPool, err = backendpool.NewStatic(backendConfigs)
+	s.backendPool, err = backendpool.NewStatic(backendConfigs, nil)
 	require.NoError(s.T(), err, "backend pool creation must not fail")
 	staticRouter := router.NewStatic(routeConfigs)
 	addresser := adhoc.NewStaticAddresser(adhocConfig)
@@ -473,6 +513,121 @@ func (s *HttpProxyingIntegrationSuite) TestSuccessOverForwardProxy_DialUsingAddr
 	assert.Equal(s.T(), resp.Header.Get("x-test-req-proto"), "1.1", "non secure backends are dialed over HTTP/1.1")
 }
 
+// connectOverProxy sends CONNECT request for the given destination to the plain proxy listener.
+func (s *HttpProxyingIntegrationSuite) connectOverProxy(hostPort string, proxySecret string) (net.Conn, *bufio.Reader, *http.Response) {
+	conn, err := net.Dial("tcp", s.proxyListenerPlain.Addr().String())
+	require.NoError(s.T(), err, "dialing proxy should not fail")
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: hostPort},
+		Host:   hostPort,
+		Header: http.Header{},
+	}
+	if proxySecret != "" {
+		req.Header.Set("Proxy-Authorization", proxySecret)
+	}
+	require.NoError(s.T(), req.Write(conn), "writing CONNECT request should not fail")
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	require.NoError(s.T(), err, "reading CONNECT response should not fail")
+	return conn, r, resp
+}
+
+func (s *HttpProxyingIntegrationSuite) assertSuccessfulPingbackOverTunnel(conn net.Conn, r *bufio.Reader) *http.Response {
+	req := testRequest("http://tunnelled.example.com/some/strict/path", "bearer tunnel", "")
+	require.NoError(s.T(), req.Write(conn), "writing request to the tunnel should not fail")
+	resp, err := http.ReadResponse(r, req)
+	s.assertSuccessfulPingback(req, resp, "bearer tunnel", err)
+	return resp
+}
+
+func (s *HttpProxyingIntegrationSuite) TestSuccessOverForwardProxy_ConnectUsingAddresser() {
+	addr := s.localBackends["_http._tcp.nonsecure.backends.test.local"].targets()[0].DialAddr
+	port := addr[strings.LastIndex(addr, ":")+1:]
+	conn, r, resp := s.connectOverProxy(fmt.Sprintf("127-0-0-1.pods.test.local:%s", port), testProxyAuthValue)
+	defer conn.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "CONNECT should succeed")
+
+	backendResp := s.assertSuccessfulPingbackOverTunnel(conn, r)
+	assert.Equal(s.T(), addr, backendResp.Header.Get("x-test-backend-addr"), "adhoc address should be dialed")
+}
+
+func (s *HttpProxyingIntegrationSuite) TestSuccessOverForwardProxy_ConnectToBackend() {
+	conn, r, resp := s.connectOverProxy("nonsecure.backends.test.local:80", testProxyAuthValue)
+	defer conn.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "CONNECT should succeed")
+
+	s.assertSuccessfulPingbackOverTunnel(conn, r)
+}
+
+func (s *HttpProxyingIntegrationSuite) TestFailOverForwardProxy_ConnectToNotAllowedPort() {
+	conn, _, resp := s.connectOverProxy("127-0-0-1.pods.test.local:80", testProxyAuthValue)
+	defer conn.Close()
+	resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "CONNECT should fail")
+	assert.Equal(s.T(), "adhoc: port 80 is not allowed", resp.Header.Get("x-kedge-error"), "routing error should be in the header")
+}
+
+func (s *HttpProxyingIntegrationSuite) TestFailOverForwardProxy_ConnectWithoutAuth() {
+	conn, _, resp := s.connectOverProxy("nonsecure.backends.test.local:80", "")
+	defer conn.Close()
+	resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusUnauthorized, resp.StatusCode, "CONNECT should fail")
+}
+
+func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_RewritesPathAndHost() {
+	req := testRequest("http://rewrite.ext.example.com/teams/foo/api/something", "bearer abc2", testProxyAuthValue)
+	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
+	require.NoError(s.T(), err, "no error on a call to a proxy addr")
+	resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+	assert.Equal(s.T(), "/api/something", resp.Header.Get("x-test-req-url"), "path seen on backend must be rewritten")
+	assert.Equal(s.T(), "nonsecure.backends.test.local", resp.Header.Get("x-test-req-host"), "host seen on backend must be rewritten")
+}
+
+func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_ModifiesHeaders() {
+	req := testRequest("http://headers.ext.example.com/some/path", "bearer abc2", testProxyAuthValue)
+	req.Header.Set("X-Custom", "overwritten")
+	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
+	require.NoError(s.T(), err, "no error on a call to a proxy addr")
+	resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+	assert.Equal(s.T(), "host=headers.ext.example.com", resp.Header.Get("x-test-custom-value"), "request header must be set")
+	assert.Empty(s.T(), resp.Header.Get("x-test-auth-value"), "request header must be removed")
+	assert.Equal(s.T(), "headers.ext.example.com", resp.Header.Get("X-Response-Custom"), "response header must be added")
+	assert.Empty(s.T(), resp.Header.Get("x-test-req-proto"), "response header must be removed")
+}
+
+func (s *HttpProxyingIntegrationSuite) TestFailOverReverseProxy_RateLimited() {
+	routed := func() float64 {
+		m := &dto.Metric{}
+		require.NoError(s.T(), metrics.RouteHTTPRequestsCounter.WithLabelValues("ratelimited", "non_secure").Write(m))
+		return m.GetCounter().GetValue()
+	}
+	routedBefore := routed()
+
+	for _, expectedStatus := range []int{http.StatusAccepted, http.StatusTooManyRequests} {
+		req := testRequest("http://ratelimited.ext.example.com/some/path", "", testProxyAuthValue)
+		req.Header.Set("X-Client", "batch-job")
+		resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
+		require.NoError(s.T(), err, "no error on a call to a proxy addr")
+		resp.Body.Close()
+		require.Equal(s.T(), expectedStatus, resp.StatusCode)
+	}
+	req := testRequest("http://ratelimited.ext.example.com/some/path", "", testProxyAuthValue)
+	req.Header.Set("X-Client", "other")
+	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
+	require.NoError(s.T(), err, "no error on a call to a proxy addr")
+	resp.Body.Close()
+	assert.Equal(s.T(), http.StatusAccepted, resp.StatusCode, "other clients should not be limited")
+	assert.Equal(s.T(), 2.0, routed()-routedBefore, "rate limited requests should not be counted as routed")
+}
+
 func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_ToNonSecure_OverPlain() {
 	req := testRequest("http://nonsecure.ext.example.com/some/strict/path", "bearer abc2", testProxyAuthValue)
 	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
@@ -480,6 +635,33 @@ func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_ToNonSecure_O
 	assert.Equal(s.T(), resp.Header.Get("x-test-req-proto"), "1.1", "non secure backends are dialed over HTTP/1.1")
 }
 
+func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_ReportsLatencyMetrics() {
+	req := testRequest("http://nonsecure.ext.example.com/some/strict/path", "bearer abc2", testProxyAuthValue)
+	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
+	s.assertSuccessfulPingback(req, resp, "bearer abc2", err)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(s.T(), err)
+	var upstreamSamples uint64
+	for _, f := range families {
+		if f.GetName() != "kedge_proxy_upstream_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["protocol"] == "http" && labels["route"] == "2" && labels["backend_name"] == "non_secure" &&
+				labels["mode"] == "reverse" && labels["error_type"] == "none" {
+				assert.NotEmpty(s.T(), labels["target"], "target picked by lbtransport should be reported")
+				upstreamSamples += m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	assert.True(s.T(), upstreamSamples > 0, "upstream duration should be reported for the route")
+}
+
 func (s *HttpProxyingIntegrationSuite) TestSuccessOverReverseProxy_ToSecure_OverPlain() {
 	req := testRequest("http://secure.ext.example.com/some/strict/path", "bearer abc3", testProxyAuthValue)
 	resp, err := s.reverseProxyClient(s.proxyListenerPlain).Do(req)
@@ -0,0 +1,362 @@
+package lbtransport
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/reporter"
+	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const backendBreakerLabel = "_backend"
+
+var (
+	breakerTripsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http_lbtransport",
+			Name:      "circuit_breaker_trips",
+			Help:      "Total number of circuit breaker trips per target (or whole backend, if target is '_backend').",
+		},
+		[]string{"backend", "target"},
+	)
+	breakerRejectedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http_lbtransport",
+			Name:      "circuit_breaker_rejected_requests",
+			Help:      "Total number of requests failed fast because of open circuit breaker.",
+		},
+		[]string{"backend"},
+	)
+)
+
+type targetBreakersCtxKey struct{}
+
+var errAllBreakersOpen = errors.New("circuit breaker: all targets are failing, try later")
+
+// CircuitBreakerOptions configures breakers created by NewCircuitBreakerTripper.
+// Conditions with zero values are disabled.
+type CircuitBreakerOptions struct {
+	// ConsecutiveFailures trips the breaker after the given number of consecutive failures.
+	ConsecutiveFailures int
+	// FailureRatio trips the breaker when the ratio of failures in the Window is above it.
+	FailureRatio float64
+	// TimeoutRatio trips the breaker when the ratio of timeouts in the Window is above it.
+	TimeoutRatio float64
+	// MinRequests is the minimum number of requests in the Window for the ratios to be checked.
+	MinRequests int
+	// Window is the length of the window the ratios are computed in.
+	Window time.Duration
+	// OpenDuration is the time the breaker stays open before letting probe requests through.
+	OpenDuration time.Duration
+	// HalfOpenRequests is the maximum number of concurrent probe requests in half-open state.
+	HalfOpenRequests int
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type requestResult int
+
+const (
+	resultSuccess requestResult = iota
+	resultFailure
+	resultTimeout
+	// resultIgnored is used for requests canceled by the client.
+	resultIgnored
+)
+
+// circuitBreaker is a single breaker of a target or a whole backend.
+type circuitBreaker struct {
+	opts CircuitBreakerOptions
+	// onTrip is invoked (under lock) every time the breaker opens.
+	onTrip func()
+
+	mu                  sync.Mutex
+	state               breakerState
+	openedAt            time.Time
+	probesInFlight      int
+	consecutiveFailures int
+	windowStart         time.Time
+	windowRequests      int
+	windowFailures      int
+	windowTimeouts      int
+
+	// For testing purposes.
+	timeNow func() time.Time
+}
+
+func newCircuitBreaker(opts CircuitBreakerOptions, onTrip func()) *circuitBreaker {
+	return &circuitBreaker{opts: opts, onTrip: onTrip, timeNow: time.Now}
+}
+
+// available returns true if a request can be sent through the breaker.
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		return b.timeNow().Sub(b.openedAt) >= b.opts.OpenDuration
+	case breakerHalfOpen:
+		return b.probesInFlight < b.opts.HalfOpenRequests
+	}
+	return true
+}
+
+// allow returns true and marks the beginning of a request if it can be sent through the breaker.
+// Every allowed request must be followed by report.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen && b.timeNow().Sub(b.openedAt) < b.opts.OpenDuration {
+		return false
+	}
+	if b.state == breakerHalfOpen && b.probesInFlight >= b.opts.HalfOpenRequests {
+		return false
+	}
+	b.start()
+	return true
+}
+
+// start marks the beginning of a request sent through the breaker. It must be called under lock.
+func (b *circuitBreaker) start() {
+	if b.state == breakerOpen && b.timeNow().Sub(b.openedAt) >= b.opts.OpenDuration {
+		b.state = breakerHalfOpen
+		b.probesInFlight = 0
+	}
+	if b.state == breakerHalfOpen {
+		b.probesInFlight++
+	}
+}
+
+func (b *circuitBreaker) report(result requestResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.probesInFlight--
+		switch result {
+		case resultSuccess:
+			b.reset(breakerClosed)
+		case resultFailure, resultTimeout:
+			b.trip()
+		}
+		return
+	}
+	if b.state == breakerOpen || result == resultIgnored {
+		return
+	}
+
+	now := b.timeNow()
+	if now.Sub(b.windowStart) > b.opts.Window {
+		b.windowStart = now
+		b.windowRequests, b.windowFailures, b.windowTimeouts = 0, 0, 0
+	}
+	b.windowRequests++
+	if result == resultSuccess {
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+	b.windowFailures++
+	if result == resultTimeout {
+		b.windowTimeouts++
+	}
+	if b.shouldTrip() {
+		b.trip()
+	}
+}
+
+func (b *circuitBreaker) shouldTrip() bool {
+	if b.opts.ConsecutiveFailures > 0 && b.consecutiveFailures >= b.opts.ConsecutiveFailures {
+		return true
+	}
+	if b.windowRequests < b.opts.MinRequests {
+		return false
+	}
+	if b.opts.FailureRatio > 0 && float64(b.windowFailures)/float64(b.windowRequests) > b.opts.FailureRatio {
+		return true
+	}
+	if b.opts.TimeoutRatio > 0 && float64(b.windowTimeouts)/float64(b.windowRequests) > b.opts.TimeoutRatio {
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) trip() {
+	b.reset(breakerOpen)
+	b.openedAt = b.timeNow()
+	if b.onTrip != nil {
+		b.onTrip()
+	}
+}
+
+func (b *circuitBreaker) reset(state breakerState) {
+	b.state = state
+	b.probesInFlight = 0
+	b.consecutiveFailures = 0
+	b.windowStart = b.timeNow()
+	b.windowRequests, b.windowFailures, b.windowTimeouts = 0, 0, 0
+}
+
+// targetBreakers keeps breakers for all targets of a single backend. It is passed to lbtransport tripper in the request
+// context, so the tripper can skip targets with open breakers and report results of requests.
+type targetBreakers struct {
+	backendName string
+	opts        CircuitBreakerOptions
+
+	mu       sync.Mutex
+	breakers map[Target]*circuitBreaker
+}
+
+func targetBreakersFromCtx(ctx context.Context) *targetBreakers {
+	b, _ := ctx.Value(targetBreakersCtxKey{}).(*targetBreakers)
+	return b
+}
+
+func (t *targetBreakers) get(target *Target) *circuitBreaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.breakers[*target]
+	if !ok {
+		dialAddr := target.DialAddr
+		b = newCircuitBreaker(t.opts, func() {
+			breakerTripsCounter.WithLabelValues(t.backendName, dialAddr).Inc()
+		})
+		t.breakers[*target] = b
+	}
+	return b
+}
+
+// available returns targets with breakers that let requests through.
+func (t *targetBreakers) available(targets []*Target) []*Target {
+	if t == nil {
+		return targets
+	}
+
+	t.mu.Lock()
+	if len(t.breakers) > len(targets) {
+		t.forgetRemoved(targets)
+	}
+	t.mu.Unlock()
+
+	var available []*Target
+	for _, target := range targets {
+		if t.get(target).available() {
+			available = append(available, target)
+		}
+	}
+	return available
+}
+
+// forgetRemoved removes breakers of targets that are no longer resolved. It must be called under lock.
+func (t *targetBreakers) forgetRemoved(targets []*Target) {
+	current := map[Target]struct{}{}
+	for _, target := range targets {
+		current[*target] = struct{}{}
+	}
+	for target := range t.breakers {
+		if _, ok := current[target]; !ok {
+			delete(t.breakers, target)
+		}
+	}
+}
+
+func (t *targetBreakers) start(target *Target) {
+	if t == nil {
+		return
+	}
+	b := t.get(target)
+	b.mu.Lock()
+	b.start()
+	b.mu.Unlock()
+}
+
+func (t *targetBreakers) report(target *Target, resp *http.Response, err error) {
+	if t == nil {
+		return
+	}
+	t.get(target).report(classifyResult(resp, err))
+}
+
+func classifyResult(resp *http.Response, err error) requestResult {
+	if err != nil {
+		cause := errors.Cause(err)
+		if cause == context.Canceled {
+			return resultIgnored
+		}
+		if cause == context.DeadlineExceeded {
+			return resultTimeout
+		}
+		if netErr, ok := cause.(net.Error); ok && netErr.Timeout() {
+			return resultTimeout
+		}
+		return resultFailure
+	}
+	if resp.StatusCode >= 500 {
+		return resultFailure
+	}
+	return resultSuccess
+}
+
+type circuitBreakerTripper struct {
+	backendName string
+
+	parent         http.RoundTripper
+	backendBreaker *circuitBreaker
+	targetBreakers *targetBreakers
+}
+
+// NewCircuitBreakerTripper returns a RoundTripper that fails fast when the backend is failing.
+//
+// It is meant to wrap the lbtransport tripper. It keeps a breaker for the whole backend and passes breakers for each
+// target to the lbtransport tripper, so it does not pick targets with open breakers. If there is no target with
+// closed (or half-open) breaker, the request fails fast as well.
+func NewCircuitBreakerTripper(backendName string, parent http.RoundTripper, opts CircuitBreakerOptions) http.RoundTripper {
+	return &circuitBreakerTripper{
+		backendName: backendName,
+		parent:      parent,
+		backendBreaker: newCircuitBreaker(opts, func() {
+			breakerTripsCounter.WithLabelValues(backendName, backendBreakerLabel).Inc()
+		}),
+		targetBreakers: &targetBreakers{
+			backendName: backendName,
+			opts:        opts,
+			breakers:    map[Target]*circuitBreaker{},
+		},
+	}
+}
+
+func (t *circuitBreakerTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !t.backendBreaker.allow() {
+		breakerRejectedCounter.WithLabelValues(t.backendName).Inc()
+		err := errors.Errorf("circuit breaker: backend %s is failing, try later", t.backendName)
+		reporter.Extract(r).ReportError(errtypes.CircuitBreakerOpen, err)
+		return nil, err
+	}
+
+	resp, err := t.parent.RoundTrip(r.WithContext(context.WithValue(r.Context(), targetBreakersCtxKey{}, t.targetBreakers)))
+	if err != nil && errors.Cause(err) == errAllBreakersOpen {
+		// Request was not sent at all, so it does not change the backend breaker state.
+		t.backendBreaker.report(resultIgnored)
+		breakerRejectedCounter.WithLabelValues(t.backendName).Inc()
+		return resp, err
+	}
+	t.backendBreaker.report(classifyResult(resp, err))
+	return resp, err
+}
@@ -0,0 +1,154 @@
+package lbtransport
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	trips := 0
+	b := newCircuitBreaker(CircuitBreakerOptions{
+		ConsecutiveFailures: 3,
+		Window:              10 * time.Second,
+		OpenDuration:        5 * time.Second,
+		HalfOpenRequests:    1,
+	}, func() { trips++ })
+	b.timeNow = func() time.Time { return now }
+
+	for _, r := range []requestResult{resultFailure, resultFailure, resultSuccess, resultFailure, resultFailure} {
+		assert.True(t, b.allow())
+		b.report(r)
+	}
+	assert.Equal(t, 0, trips, "success should reset consecutive failures")
+
+	assert.True(t, b.allow())
+	b.report(resultTimeout)
+	assert.Equal(t, 1, trips)
+	assert.False(t, b.allow(), "breaker should be open")
+
+	now = now.Add(5 * time.Second)
+	assert.True(t, b.allow(), "breaker should let a probe through after open duration")
+	assert.False(t, b.allow(), "only one probe is allowed in half-open state")
+	b.report(resultFailure)
+	assert.Equal(t, 2, trips, "failed probe should open breaker again")
+	assert.False(t, b.allow())
+
+	now = now.Add(5 * time.Second)
+	assert.True(t, b.allow())
+	b.report(resultSuccess)
+	assert.True(t, b.allow(), "successful probe should close breaker")
+	assert.True(t, b.allow())
+}
+
+func TestCircuitBreaker_Ratios(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(CircuitBreakerOptions{
+		FailureRatio:     0.5,
+		TimeoutRatio:     0.2,
+		MinRequests:      10,
+		Window:           10 * time.Second,
+		OpenDuration:     5 * time.Second,
+		HalfOpenRequests: 1,
+	}, nil)
+	b.timeNow = func() time.Time { return now }
+
+	// 5 failures out of 9 requests, but not enough requests to check ratio.
+	for i := 0; i < 9; i++ {
+		r := resultSuccess
+		if i%2 == 0 {
+			r = resultFailure
+		}
+		b.report(r)
+	}
+	assert.True(t, b.allow())
+	b.report(resultIgnored)
+	assert.True(t, b.allow(), "ignored results should not be counted")
+
+	// New window drops old results.
+	now = now.Add(11 * time.Second)
+	for i := 0; i < 9; i++ {
+		b.report(resultSuccess)
+	}
+	b.report(resultFailure)
+	assert.True(t, b.allow(), "1 failure out of 10 should not trip breaker")
+
+	now = now.Add(11 * time.Second)
+	for i := 0; i < 7; i++ {
+		b.report(resultSuccess)
+	}
+	for i := 0; i < 3; i++ {
+		b.report(resultTimeout)
+	}
+	assert.False(t, b.allow(), "3 timeouts out of 10 should trip breaker")
+}
+
+func (s *BalancedRRTransportSuite) TestCircuitBreakerSkipsFailingTarget() {
+	calls := make(chan string, 100*testBackendCount)
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		calls <- req.Header.Get("X-TEST-BACKEND-ID")
+		if req.Header.Get("X-TEST-BACKEND-ID") == "2" {
+			resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	})
+
+	client := &http.Client{
+		Transport: NewCircuitBreakerTripper("my-magic-srv", s.lbTrans, CircuitBreakerOptions{
+			ConsecutiveFailures: 2,
+			Window:              10 * time.Second,
+			OpenDuration:        time.Minute,
+			HalfOpenRequests:    1,
+		}),
+		Timeout: 10 * time.Second,
+	}
+	failing := 0
+	for i := 0; i < 10*testBackendCount; i++ {
+		resp, err := client.Get("http://my-magic-srv/something")
+		s.Require().NoError(err)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			failing++
+		}
+	}
+	s.Assert().Equal(2, failing, "failing target should be skipped after breaker trips")
+	close(calls)
+	var hits int
+	for id := range calls {
+		if id == "2" {
+			hits++
+		}
+	}
+	s.Assert().Equal(2, hits)
+}
+
+func (s *BalancedRRTransportSuite) TestCircuitBreakerFailsFast() {
+	calls := make(chan struct{}, 100*testBackendCount)
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		calls <- struct{}{}
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client := &http.Client{
+		Transport: NewCircuitBreakerTripper("my-magic-srv", s.lbTrans, CircuitBreakerOptions{
+			ConsecutiveFailures: testBackendCount + 1,
+			Window:              10 * time.Second,
+			OpenDuration:        time.Minute,
+			HalfOpenRequests:    1,
+		}),
+		Timeout: 10 * time.Second,
+	}
+	for i := 0; i < testBackendCount+1; i++ {
+		resp, err := client.Get("http://my-magic-srv/something")
+		s.Require().NoError(err)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://my-magic-srv/something")
+	s.Require().Error(err, "backend breaker should be open")
+	s.Assert().Contains(err.Error(), "circuit breaker")
+	s.Assert().Len(calls, testBackendCount+1)
+}
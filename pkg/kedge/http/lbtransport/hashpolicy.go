@@ -0,0 +1,139 @@
+package lbtransport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+)
+
+// HashKeyFunc returns the key used for consistent hashing of the request.
+type HashKeyFunc func(r *http.Request) string
+
+// HeaderHashKey hashes requests on the value of the given header.
+func HeaderHashKey(name string) HashKeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// CookieHashKey hashes requests on the value of the given cookie.
+func CookieHashKey(name string) HashKeyFunc {
+	return func(r *http.Request) string {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	}
+}
+
+// PathHashKey hashes requests on the URL path.
+func PathHashKey(r *http.Request) string {
+	return r.URL.Path
+}
+
+// hashPolicy picks the target using consistent hashing of the request key, so requests with the same key go to the
+// same target as long as it is available. Requests without key are balanced in round robin manner.
+// It blacklists failing targets in the same way as roundRobinPolicy.
+type hashPolicy struct {
+	*roundRobinPolicy
+
+	key     HashKeyFunc
+	newHash func(targets []string) common.ConsistentHash
+
+	mu         sync.Mutex
+	hashedAddr string
+	hash       common.ConsistentHash
+}
+
+func RingHashPolicyFromFlags(ctx context.Context, key HashKeyFunc) LBPolicy {
+	return RingHashPolicy(ctx, *flagBlacklistBackoff, key)
+}
+
+func RingHashPolicy(ctx context.Context, backoffDuration time.Duration, key HashKeyFunc) LBPolicy {
+	return newHashPolicy(ctx, backoffDuration, key, common.NewRingHash)
+}
+
+func MaglevPolicyFromFlags(ctx context.Context, key HashKeyFunc) LBPolicy {
+	return MaglevPolicy(ctx, *flagBlacklistBackoff, key)
+}
+
+func MaglevPolicy(ctx context.Context, backoffDuration time.Duration, key HashKeyFunc) LBPolicy {
+	return newHashPolicy(ctx, backoffDuration, key, common.NewMaglev)
+}
+
+func newHashPolicy(ctx context.Context, backoffDuration time.Duration, key HashKeyFunc, newHash func([]string) common.ConsistentHash) *hashPolicy {
+	return &hashPolicy{
+		roundRobinPolicy: RoundRobinPolicy(ctx, backoffDuration, 0).(*roundRobinPolicy),
+		key:              key,
+		newHash:          newHash,
+	}
+}
+
+// hashFor returns consistent hash for given targets. It is rebuilt only when targets change.
+func (h *hashPolicy) hashFor(targets []*Target) common.ConsistentHash {
+	addrs := make([]string, len(targets))
+	for i, t := range targets {
+		addrs[i] = t.DialAddr
+	}
+	joined := strings.Join(addrs, ",")
+
+	h.mu.Lock()
+	hash, hashedAddr := h.hash, h.hashedAddr
+	h.mu.Unlock()
+	if hash != nil && hashedAddr == joined {
+		return hash
+	}
+
+	// Building the hash (especially Maglev table) is expensive, do not block other requests meanwhile.
+	hash = h.newHash(addrs)
+	h.mu.Lock()
+	h.hash, h.hashedAddr = hash, joined
+	h.mu.Unlock()
+	return hash
+}
+
+func (h *hashPolicy) Picker() LBPolicyPicker {
+	return &hashPolicyPicker{
+		roundRobinPolicyPicker: h.roundRobinPolicy.Picker().(*roundRobinPolicyPicker),
+		policy:                 h,
+	}
+}
+
+type hashPolicyPicker struct {
+	// roundRobinPolicyPicker is used for blacklisting and for requests without key.
+	*roundRobinPolicyPicker
+
+	policy *hashPolicy
+}
+
+func (p *hashPolicyPicker) Pick(r *http.Request, currentTargets []*Target) (*Target, error) {
+	return p.PickFrom(r, currentTargets, currentTargets)
+}
+
+// PickFrom hashes the key over all resolved targets, so keys do not move when some targets are temporarily skipped
+// (e.g. because of open circuit breakers). Only candidates that are not blacklisted can be picked.
+func (p *hashPolicyPicker) PickFrom(r *http.Request, resolvedTargets []*Target, candidates []*Target) (*Target, error) {
+	key := p.policy.key(r)
+	if key == "" {
+		return p.roundRobinPolicyPicker.Pick(r, candidates)
+	}
+
+	available := map[Target]struct{}{}
+	for _, t := range p.available(candidates) {
+		available[*t] = struct{}{}
+	}
+	idx := p.policy.hashFor(resolvedTargets).Get(key, func(i int) bool {
+		_, ok := available[*resolvedTargets[i]]
+		return ok
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("All targets %v are failing, try later.", candidates)
+	}
+	return resolvedTargets[idx], nil
+}
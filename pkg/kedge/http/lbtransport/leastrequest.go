@@ -0,0 +1,85 @@
+package lbtransport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+)
+
+// leastRequestPolicy picks the target with the least outstanding requests. With powerOfTwo it compares only two
+// randomly chosen targets, which avoids herding on a single target when many pickers see the same counts.
+// It blacklists failing targets in the same way as roundRobinPolicy.
+type leastRequestPolicy struct {
+	*roundRobinPolicy
+
+	powerOfTwo bool
+
+	mu          sync.Mutex
+	outstanding map[Target]int64
+}
+
+func LeastRequestPolicyFromFlags(ctx context.Context, powerOfTwo bool) LBPolicy {
+	return LeastRequestPolicy(ctx, *flagBlacklistBackoff, powerOfTwo)
+}
+
+func LeastRequestPolicy(ctx context.Context, backoffDuration time.Duration, powerOfTwo bool) LBPolicy {
+	return &leastRequestPolicy{
+		roundRobinPolicy: RoundRobinPolicy(ctx, backoffDuration, 0).(*roundRobinPolicy),
+		powerOfTwo:       powerOfTwo,
+		outstanding:      make(map[Target]int64),
+	}
+}
+
+func (lr *leastRequestPolicy) Picker() LBPolicyPicker {
+	return &leastRequestPolicyPicker{
+		roundRobinPolicyPicker: lr.roundRobinPolicy.Picker().(*roundRobinPolicyPicker),
+		policy:                 lr,
+	}
+}
+
+func (lr *leastRequestPolicy) load(target *Target) int64 {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.outstanding[*target]
+}
+
+type leastRequestPolicyPicker struct {
+	// roundRobinPolicyPicker is used only for blacklisting.
+	*roundRobinPolicyPicker
+
+	policy *leastRequestPolicy
+}
+
+func (p *leastRequestPolicyPicker) Pick(r *http.Request, currentTargets []*Target) (*Target, error) {
+	available := p.available(currentTargets)
+	if len(available) == 0 {
+		return nil, fmt.Errorf("All targets %v are failing, try later.", currentTargets)
+	}
+	idx := common.PickLeastLoaded(len(available), func(i int) int64 {
+		return p.policy.load(available[i])
+	}, p.policy.powerOfTwo)
+	return available[idx], nil
+}
+
+func (p *leastRequestPolicyPicker) Started(target *Target) func() {
+	lr := p.policy
+	lr.mu.Lock()
+	lr.outstanding[*target]++
+	lr.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lr.mu.Lock()
+			defer lr.mu.Unlock()
+			lr.outstanding[*target]--
+			if lr.outstanding[*target] <= 0 {
+				delete(lr.outstanding, *target)
+			}
+		})
+	}
+}
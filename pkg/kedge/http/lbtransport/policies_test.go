@@ -0,0 +1,143 @@
+package lbtransport
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeastRequestPolicy_PicksLeastOutstanding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testTargets := []*Target{{DialAddr: "0"}, {DialAddr: "1"}, {DialAddr: "2"}}
+	policy := LeastRequestPolicy(ctx, testFailBlacklistDuration, false)
+	req := httptest.NewRequest("GET", "http://does-not-matter", nil)
+
+	var dones []func()
+	picked := map[Target]int{}
+	for i := 0; i < 3; i++ {
+		picker := policy.Picker()
+		target, err := picker.Pick(req, testTargets)
+		require.NoError(t, err)
+		picked[*target]++
+		dones = append(dones, picker.(requestTracker).Started(target))
+	}
+	assert.Len(t, picked, 3, "every target should get exactly one outstanding request")
+
+	// Finish request on one target, so it should be picked next.
+	picker := policy.Picker()
+	dones[1]()
+	dones[1]()
+	target, err := picker.Pick(req, testTargets)
+	require.NoError(t, err)
+	for t2, c := range picked {
+		if t2.DialAddr == target.DialAddr {
+			assert.Equal(t, 1, c)
+		}
+	}
+
+	picker.ExcludeTarget(target)
+	next, err := policy.Picker().Pick(req, testTargets)
+	require.NoError(t, err)
+	assert.NotEqual(t, target, next, "excluded target should not be picked")
+}
+
+func TestHashPolicy_StickyOnKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testTargets := []*Target{{DialAddr: "0"}, {DialAddr: "1"}, {DialAddr: "2"}, {DialAddr: "3"}}
+	for _, policy := range []LBPolicy{
+		RingHashPolicy(ctx, testFailBlacklistDuration, HeaderHashKey("X-User")),
+		MaglevPolicy(ctx, testFailBlacklistDuration, HeaderHashKey("X-User")),
+	} {
+		req := httptest.NewRequest("GET", "http://does-not-matter", nil)
+		req.Header.Set("X-User", "some-user")
+
+		first, err := policy.Picker().Pick(req, testTargets)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			target, err := policy.Picker().Pick(req, testTargets)
+			require.NoError(t, err)
+			assert.Equal(t, first, target, "requests with the same key should go to the same target")
+		}
+
+		picker := policy.Picker()
+		picker.ExcludeTarget(first)
+		target, err := picker.Pick(req, testTargets)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, target, "excluded target should not be picked")
+
+		// Requests without key are balanced in round robin manner.
+		req.Header.Del("X-User")
+		seen := map[string]struct{}{}
+		for i := 0; i < len(testTargets); i++ {
+			target, err := policy.Picker().Pick(req, testTargets)
+			require.NoError(t, err)
+			seen[target.DialAddr] = struct{}{}
+		}
+		assert.Len(t, seen, len(testTargets)-1)
+	}
+}
+
+func TestHashPolicy_KeysDoNotMoveWhenTargetsSkipped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testTargets := []*Target{{DialAddr: "0"}, {DialAddr: "1"}, {DialAddr: "2"}, {DialAddr: "3"}}
+	policy := MaglevPolicy(ctx, testFailBlacklistDuration, HeaderHashKey("X-User"))
+	req := httptest.NewRequest("GET", "http://does-not-matter", nil)
+
+	moved := 0
+	for i := 0; i < 100; i++ {
+		req.Header.Set("X-User", fmt.Sprintf("user-%d", i))
+		first, err := policy.Picker().Pick(req, testTargets)
+		require.NoError(t, err)
+
+		// Skip target other than the picked one, as the transport does e.g. for open circuit breakers.
+		var candidates []*Target
+		for _, target := range testTargets {
+			if target == first || target.DialAddr != "0" {
+				candidates = append(candidates, target)
+			}
+		}
+		target, err := policy.Picker().(resolvedTargetsPicker).PickFrom(req, testTargets, candidates)
+		require.NoError(t, err)
+		if target != first {
+			moved++
+		}
+	}
+	assert.Equal(t, 0, moved, "keys of not skipped targets should not move")
+}
+
+func TestWeightedRoundRobinPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testTargets := []*Target{{DialAddr: "0", Weight: 3}, {DialAddr: "1", Weight: 1}, {DialAddr: "2", Weight: 1}}
+	policy := WeightedRoundRobinPolicy(ctx, testFailBlacklistDuration)
+	req := httptest.NewRequest("GET", "http://does-not-matter", nil)
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		target, err := policy.Picker().Pick(req, testTargets)
+		require.NoError(t, err)
+		picked[target.DialAddr]++
+	}
+	assert.InDelta(t, 600, picked["0"], 20)
+	assert.InDelta(t, 200, picked["1"], 20)
+	assert.InDelta(t, 200, picked["2"], 20)
+
+	picker := policy.Picker()
+	picker.ExcludeTarget(testTargets[0])
+	for i := 0; i < 10; i++ {
+		target, err := picker.Pick(req, testTargets)
+		require.NoError(t, err)
+		assert.NotEqual(t, "0", target.DialAddr, "excluded target should not be picked")
+	}
+}
@@ -31,9 +31,33 @@ type LBPolicyPicker interface {
 	ExcludeTarget(*Target)
 }
 
-// Target represents the canonical address of a backend.
+// resolvedTargetsPicker is implemented by LBPolicyPicker that needs all resolved targets and not only the candidates
+// left after skipping targets (e.g. the ones already attempted or with open circuit breakers) to pick consistently.
+type resolvedTargetsPicker interface {
+	// PickFrom decides which of the candidates to use for the request. Candidates are a subset of resolvedTargets.
+	PickFrom(req *http.Request, resolvedTargets []*Target, candidates []*Target) (*Target, error)
+}
+
+// requestTracker is implemented by LBPolicyPicker that needs to know when the request sent to the picked target
+// finished (e.g. to count outstanding requests).
+type requestTracker interface {
+	// Started is called just before the request is sent to the target. Returned func is called when it finished.
+	Started(target *Target) (done func())
+}
+
+// targetBlacklist is implemented by LBPolicy that blacklists failing targets.
+type targetBlacklist interface {
+	isTargetBlacklisted(target *Target) bool
+}
+
+// Target represents the canonical address of a backend together with its resolved attributes. Targets are compared by
+// value, so a target with changed attributes is a new target.
 type Target struct {
 	DialAddr string
+	// Zone is the locality of the target. Empty if unknown.
+	Zone string
+	// Weight is the relative amount of traffic the target should receive with weighted policy.
+	Weight uint32
 }
 
 // roundRobinPolicy picks target using round robin behaviour.
@@ -162,6 +186,21 @@ func (rr *roundRobinPolicyPicker) Pick(r *http.Request, currentTargets []*Target
 	return nil, fmt.Errorf("All targets %v are failing, try later.", currentTargets)
 }
 
+// available returns targets that are not blacklisted (locally or globally).
+func (rr *roundRobinPolicyPicker) available(currentTargets []*Target) []*Target {
+	var available []*Target
+	for _, target := range currentTargets {
+		if rr.isTargetLocallyBlacklisted(target) {
+			continue
+		}
+		if !rr.base.isBlacklistDisabled() && rr.base.isTargetBlacklisted(target) {
+			continue
+		}
+		available = append(available, target)
+	}
+	return available
+}
+
 func (rr *roundRobinPolicyPicker) ExcludeTarget(target *Target) {
 	rr.base.blacklistTarget(target)
 	rr.localBlacklist[*target] = struct{}{}
@@ -0,0 +1,199 @@
+package lbtransport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/improbable-eng/kedge/pkg/reporter"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	retriesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "http_lbtransport",
+			Name:      "retries",
+			Help:      "Total number of retried requests by reason (response status code or transport error).",
+		},
+		[]string{"backend", "reason"},
+	)
+)
+
+type attemptedTargetsCtxKey struct{}
+
+// RetryOptions configures behaviour of the tripper returned by NewRetryTripper.
+type RetryOptions struct {
+	// RetryCount is the maximum number of retries made for a single request.
+	RetryCount int
+	// OnCodes is the list of response status codes that should be retried.
+	OnCodes []int
+	// BodyBufferLimitBytes is the maximum size of the request body that will be buffered to be replayed on retries.
+	// Requests with bigger bodies are not retried.
+	BodyBufferLimitBytes int64
+	// RetryNonIdempotent allows to retry requests with non-idempotent methods like POST or PATCH.
+	RetryNonIdempotent bool
+}
+
+type retryTripper struct {
+	backendName string
+
+	parent http.RoundTripper
+	opts   RetryOptions
+}
+
+// NewRetryTripper returns a RoundTripper that retries requests failed with one of the configured status codes or
+// with EOF error from the backend.
+//
+// It is meant to wrap the lbtransport tripper. Each retry will be sent to a target that was not used by any of the previous
+// attempts of the same request (unless all of them were already used).
+// Dial errors are not retried here, because lbtransport tripper already tries other targets in that case.
+func NewRetryTripper(backendName string, parent http.RoundTripper, opts RetryOptions) http.RoundTripper {
+	return &retryTripper{
+		backendName: backendName,
+		parent:      parent,
+		opts:        opts,
+	}
+}
+
+func (t *retryTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.opts.RetryCount <= 0 || (!t.opts.RetryNonIdempotent && !isIdempotent(r.Method)) {
+		return t.parent.RoundTrip(r)
+	}
+
+	body, ok, err := bufferBody(r, t.opts.BodyBufferLimitBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "retry: failed to read request body")
+	}
+	if !ok {
+		// Body is too big to be replayed.
+		return t.parent.RoundTrip(r)
+	}
+
+	ctx := context.WithValue(r.Context(), attemptedTargetsCtxKey{}, &attemptedTargets{targets: map[Target]struct{}{}})
+	tracker := reporter.Extract(r)
+	for attempt := 0; ; attempt++ {
+		// Each attempt has its own tracker, so we report only the error from the last one.
+		attemptTracker := &reporter.Tracker{}
+		attemptReq := reporter.ReqWrappedWithTracker(r.WithContext(ctx), attemptTracker)
+		if body != nil {
+			attemptReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := t.parent.RoundTrip(attemptReq)
+		if attempt < t.opts.RetryCount && ctx.Err() == nil {
+			if reason := t.retryReason(resp, err); reason != "" {
+				if resp != nil {
+					io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				retriesCounter.WithLabelValues(t.backendName, reason).Inc()
+				continue
+			}
+		}
+
+		if errType, attemptErr := attemptTracker.Error(); attemptErr != nil {
+			tracker.ReportError(errType, attemptErr)
+		}
+		return resp, err
+	}
+}
+
+// retryReason returns the reason of retry or empty string if response should not be retried.
+func (t *retryTripper) retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		cause := errors.Cause(err)
+		if cause == io.EOF || cause == io.ErrUnexpectedEOF {
+			return "eof"
+		}
+		return ""
+	}
+
+	for _, code := range t.opts.OnCodes {
+		if resp.StatusCode == code {
+			return strconv.Itoa(code)
+		}
+	}
+	return ""
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// bufferBody reads the request body if it is not bigger than limit. If it is bigger, false is returned and the request body
+// is left in a state as it was never read.
+func bufferBody(r *http.Request, limit int64) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+	if r.ContentLength > limit {
+		return nil, false, nil
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(buf)) > limit {
+		r.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(buf), r.Body), Closer: r.Body}
+		return nil, false, nil
+	}
+	r.Body.Close()
+	return buf, true, nil
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// attemptedTargets tracks targets picked for all attempts of a single request.
+type attemptedTargets struct {
+	mu      sync.Mutex
+	targets map[Target]struct{}
+}
+
+func attemptedTargetsFromCtx(ctx context.Context) *attemptedTargets {
+	a, _ := ctx.Value(attemptedTargetsCtxKey{}).(*attemptedTargets)
+	return a
+}
+
+func (a *attemptedTargets) add(target *Target) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.targets[*target] = struct{}{}
+}
+
+// notAttempted returns targets that were not attempted yet. If all were attempted, all targets are returned.
+func (a *attemptedTargets) notAttempted(targets []*Target) []*Target {
+	if a == nil {
+		return targets
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var notAttempted []*Target
+	for _, t := range targets {
+		if _, ok := a.targets[*t]; !ok {
+			notAttempted = append(notAttempted, t)
+		}
+	}
+	if len(notAttempted) == 0 {
+		return targets
+	}
+	return notAttempted
+}
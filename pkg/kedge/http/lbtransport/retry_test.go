@@ -0,0 +1,123 @@
+package lbtransport
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+func (s *BalancedRRTransportSuite) TestRetryOnCodesPicksDifferentTarget() {
+	// Only one backend out of testBackendCount is healthy.
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-TEST-BACKEND-ID") != "2" {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	})
+
+	client := &http.Client{
+		Transport: NewRetryTripper("my-magic-srv", s.lbTrans, RetryOptions{
+			RetryCount: testBackendCount - 1,
+			OnCodes:    []int{http.StatusServiceUnavailable},
+		}),
+		Timeout: 10 * time.Second,
+	}
+	for i := 0; i < 2*testBackendCount; i++ {
+		resp, err := client.Get("http://my-magic-srv/something")
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Assert().Equal(http.StatusOK, resp.StatusCode, "every request should eventually reach the healthy backend")
+	}
+}
+
+func (s *BalancedRRTransportSuite) TestRetryReplaysBody() {
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		s.Require().NoError(err)
+		s.Assert().Equal("some body", string(body))
+		if req.Header.Get("X-TEST-BACKEND-ID") != "2" {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	})
+
+	client := &http.Client{
+		Transport: NewRetryTripper("my-magic-srv", s.lbTrans, RetryOptions{
+			RetryCount:           testBackendCount - 1,
+			OnCodes:              []int{http.StatusServiceUnavailable},
+			BodyBufferLimitBytes: 1024,
+			RetryNonIdempotent:   true,
+		}),
+		Timeout: 10 * time.Second,
+	}
+	for i := 0; i < 2*testBackendCount; i++ {
+		resp, err := client.Post("http://my-magic-srv/something", "text/plain", bytes.NewBufferString("some body"))
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Assert().Equal(http.StatusOK, resp.StatusCode, "every request should eventually reach the healthy backend")
+	}
+}
+
+func (s *BalancedRRTransportSuite) TestRetryIgnoresNonIdempotentAndTooBigBodies() {
+	calls := make(chan struct{}, 2*testBackendCount)
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		calls <- struct{}{}
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for _, opts := range []RetryOptions{
+		{RetryCount: 3, OnCodes: []int{http.StatusServiceUnavailable}, BodyBufferLimitBytes: 1024},
+		{RetryCount: 3, OnCodes: []int{http.StatusServiceUnavailable}, BodyBufferLimitBytes: 4, RetryNonIdempotent: true},
+	} {
+		client := &http.Client{Transport: NewRetryTripper("my-magic-srv", s.lbTrans, opts), Timeout: 10 * time.Second}
+		resp, err := client.Post("http://my-magic-srv/something", "text/plain", bytes.NewBufferString("some body"))
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Assert().Equal(http.StatusServiceUnavailable, resp.StatusCode)
+		s.Assert().Len(calls, 1, "request should not be retried")
+		<-calls
+	}
+}
+
+func (s *BalancedRRTransportSuite) TestRetryWithCircuitBreakerTripsFailingTarget() {
+	calls := make(chan string, 100*testBackendCount)
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		calls <- req.Header.Get("X-TEST-BACKEND-ID")
+		if req.Header.Get("X-TEST-BACKEND-ID") == "2" {
+			resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	})
+
+	// Same order as in backendpool: retry is the outer middleware.
+	client := &http.Client{
+		Transport: NewRetryTripper("my-magic-srv", NewCircuitBreakerTripper("my-magic-srv", s.lbTrans, CircuitBreakerOptions{
+			ConsecutiveFailures: 2,
+			Window:              10 * time.Second,
+			OpenDuration:        time.Minute,
+			HalfOpenRequests:    1,
+		}), RetryOptions{
+			RetryCount: 1,
+			OnCodes:    []int{http.StatusInternalServerError},
+		}),
+		Timeout: 10 * time.Second,
+	}
+	for i := 0; i < 10*testBackendCount; i++ {
+		resp, err := client.Get("http://my-magic-srv/something")
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Assert().Equal(http.StatusOK, resp.StatusCode, "failed attempts should be retried on other targets")
+	}
+	close(calls)
+	var hits int
+	for id := range calls {
+		if id == "2" {
+			hits++
+		}
+	}
+	s.Assert().Equal(2, hits, "breaker of the failing target should trip despite retries")
+}
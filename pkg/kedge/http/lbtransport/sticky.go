@@ -0,0 +1,179 @@
+package lbtransport
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+)
+
+var (
+	flagStickySessionSecret = sharedflags.Set.String("http_sticky_session_secret", "",
+		"Secret used to sign sticky session cookies. It needs to be the same on all kedge replicas. If empty, random "+
+			"secret is generated on start, so sessions do not survive restarts.")
+
+	stickySessionSecretOnce sync.Once
+	stickySessionSecret     []byte
+)
+
+// StickySessionSecretFromFlags returns secret for signing sticky session cookies.
+func StickySessionSecretFromFlags() []byte {
+	stickySessionSecretOnce.Do(func() {
+		if *flagStickySessionSecret != "" {
+			stickySessionSecret = []byte(*flagStickySessionSecret)
+			return
+		}
+		stickySessionSecret = make([]byte, 32)
+		if _, err := rand.Read(stickySessionSecret); err != nil {
+			panic(err)
+		}
+	})
+	return stickySessionSecret
+}
+
+type stickySessionCtxKey struct{}
+
+// stickySession is passed to lbtransport tripper in the request context. It holds the target that should be used (if
+// possible) and records the target that was actually used.
+type stickySession struct {
+	// fromCookie is the target stored in the cookie.
+	fromCookie string
+	// preferred is the target to be tried first. It is cleared once used.
+	preferred string
+	used      string
+}
+
+func stickySessionFromCtx(ctx context.Context) *stickySession {
+	s, _ := ctx.Value(stickySessionCtxKey{}).(*stickySession)
+	return s
+}
+
+// pick returns the preferred target if it is still resolved and not blacklisted. The preferred target is returned only
+// once, so if it fails, the policy picks another one.
+func (s *stickySession) pick(targets []*Target, policy LBPolicy) *Target {
+	if s == nil || s.preferred == "" {
+		return nil
+	}
+	preferred := s.preferred
+	s.preferred = ""
+
+	for _, t := range targets {
+		if t.DialAddr != preferred {
+			continue
+		}
+		if bl, ok := policy.(targetBlacklist); ok && bl.isTargetBlacklisted(t) {
+			return nil
+		}
+		return t
+	}
+	return nil
+}
+
+func (s *stickySession) use(target *Target) {
+	if s == nil {
+		return
+	}
+	s.used = target.DialAddr
+}
+
+// StickySessionOptions configures behaviour of the tripper returned by NewStickySessionTripper.
+type StickySessionOptions struct {
+	// CookieName is the name of the cookie holding the session.
+	CookieName string
+	// MaxAge is the max age of the cookie. If 0, session cookie is used.
+	MaxAge time.Duration
+	// Secret is used to sign the cookie, so clients cannot choose arbitrary targets.
+	Secret []byte
+}
+
+type stickySessionTripper struct {
+	backendName string
+
+	parent http.RoundTripper
+	opts   StickySessionOptions
+}
+
+// NewStickySessionTripper returns a RoundTripper that keeps requests of a client on the same target.
+//
+// It is meant to wrap the lbtransport tripper. The target chosen for the first request is stored in a signed cookie
+// set on the response. Following requests with the cookie go to the same target as long as it is resolved and not
+// blacklisted. Otherwise another target is picked and the cookie is rewritten.
+// The cookie is never sent to the backend.
+func NewStickySessionTripper(backendName string, parent http.RoundTripper, opts StickySessionOptions) http.RoundTripper {
+	return &stickySessionTripper{
+		backendName: backendName,
+		parent:      parent,
+		opts:        opts,
+	}
+}
+
+func (t *stickySessionTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	session := &stickySession{}
+	if c, err := r.Cookie(t.opts.CookieName); err == nil {
+		session.fromCookie, _ = t.verify(c.Value)
+		session.preferred = session.fromCookie
+		removeCookie(r, t.opts.CookieName)
+	}
+
+	resp, err := t.parent.RoundTrip(r.WithContext(context.WithValue(r.Context(), stickySessionCtxKey{}, session)))
+	if err != nil || session.used == "" || session.used == session.fromCookie {
+		return resp, err
+	}
+
+	cookie := &http.Cookie{
+		Name:     t.opts.CookieName,
+		Value:    t.sign(session.used),
+		Path:     "/",
+		HttpOnly: true,
+		MaxAge:   int(t.opts.MaxAge / time.Second),
+	}
+	resp.Header.Add("Set-Cookie", cookie.String())
+	return resp, nil
+}
+
+func (t *stickySessionTripper) mac(addr string) []byte {
+	mac := hmac.New(sha256.New, t.opts.Secret)
+	mac.Write([]byte(t.backendName + "/" + addr))
+	return mac.Sum(nil)
+}
+
+func (t *stickySessionTripper) sign(addr string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(addr)) + "." + base64.RawURLEncoding.EncodeToString(t.mac(addr))
+}
+
+// verify returns the target address from the cookie value if it is correctly signed.
+func (t *stickySessionTripper) verify(value string) (string, bool) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	addr, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", false
+	}
+	if !hmac.Equal(sig, t.mac(string(addr))) {
+		return "", false
+	}
+	return string(addr), true
+}
+
+func removeCookie(r *http.Request, name string) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, c := range cookies {
+		if c.Name != name {
+			r.AddCookie(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package lbtransport
+
+import (
+	"net/http"
+	"time"
+)
+
+func (s *BalancedRRTransportSuite) TestStickySession() {
+	s.setBackendHandler(func(resp http.ResponseWriter, req *http.Request) {
+		if _, err := req.Cookie("session"); err == nil {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp.Header().Set("X-TEST-BACKEND-ID", req.Header.Get("X-TEST-BACKEND-ID"))
+		resp.WriteHeader(http.StatusOK)
+	})
+
+	client := &http.Client{
+		Transport: NewStickySessionTripper("my-magic-srv", s.lbTrans, StickySessionOptions{
+			CookieName: "session",
+			Secret:     []byte("secret"),
+		}),
+		Timeout: 10 * time.Second,
+	}
+
+	resp, err := client.Get("http://my-magic-srv/something")
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Len(resp.Cookies(), 1, "session cookie should be set on first response")
+	cookie := resp.Cookies()[0]
+	backendID := resp.Header.Get("X-TEST-BACKEND-ID")
+
+	for i := 0; i < 2*testBackendCount; i++ {
+		req, err := http.NewRequest("GET", "http://my-magic-srv/something", nil)
+		s.Require().NoError(err)
+		req.AddCookie(cookie)
+		resp, err := client.Do(req)
+		s.Require().NoError(err)
+		resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode, "session cookie should not be sent to the backend")
+		s.Assert().Equal(backendID, resp.Header.Get("X-TEST-BACKEND-ID"), "requests with the cookie should stick to one target")
+		s.Assert().Empty(resp.Cookies(), "cookie should not be rewritten when target did not change")
+	}
+
+	// Tampered cookie is ignored and rewritten.
+	req, err := http.NewRequest("GET", "http://my-magic-srv/something", nil)
+	s.Require().NoError(err)
+	req.AddCookie(&http.Cookie{Name: "session", Value: cookie.Value[:len(cookie.Value)-2] + "xx"})
+	resp, err = client.Do(req)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Len(resp.Cookies(), 1)
+	s.Assert().NotEqual(cookie.Value[:len(cookie.Value)-2]+"xx", resp.Cookies()[0].Value)
+}
@@ -2,17 +2,20 @@ package lbtransport
 
 import (
 	"context"
+	"io"
 	"net"
 	"net/http"
 	"sync"
 
 	"github.com/improbable-eng/go-httpwares/tags"
 	"github.com/improbable-eng/kedge/pkg/http/ctxtags"
+	"github.com/improbable-eng/kedge/pkg/metrics"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/improbable-eng/kedge/pkg/tracing"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
-	"google.golang.org/grpc/naming"
 )
 
 type tripper struct {
@@ -40,6 +43,9 @@ var (
 
 func init() {
 	prometheus.MustRegister(failedDialsCounter)
+	prometheus.MustRegister(retriesCounter)
+	prometheus.MustRegister(breakerTripsCounter)
+	prometheus.MustRegister(breakerRejectedCounter)
 }
 
 // New creates a new load-balanced Round Tripper for a single backend.
@@ -47,8 +53,8 @@ func init() {
 // This RoundTripper is meant to only dial a single backend, and will throw errors if the req.URL.Host
 // doesn't match the targetAddr.
 //
-// For resolving backend addresses it uses a grpc.naming.Resolver, allowing for generic use.
-func New(ctx context.Context, targetAddr string, parent http.RoundTripper, resolver naming.Resolver, policy LBPolicy) (*tripper, error) {
+// For resolving backend addresses it uses a resolvers.Resolver, allowing for generic use. Only ready addresses are used.
+func New(ctx context.Context, targetAddr string, parent http.RoundTripper, resolver resolvers.Resolver, policy LBPolicy) (*tripper, error) {
 	s := &tripper{
 		targetName:     targetAddr,
 		parent:         parent,
@@ -68,10 +74,9 @@ func New(ctx context.Context, targetAddr string, parent http.RoundTripper, resol
 	return s, nil
 }
 
-func (s *tripper) run(ctx context.Context, watcher naming.Watcher) {
-	var localCurrentTargets []*Target
+func (s *tripper) run(ctx context.Context, watcher resolvers.Watcher) {
 	for ctx.Err() == nil {
-		updates, err := watcher.Next() // blocking call until new updates are there
+		addrs, err := watcher.Next() // blocking call until new addresses are there
 		if err != nil {
 			// Watcher next errors are irrecoverable.
 			s.mu.Lock()
@@ -81,35 +86,24 @@ func (s *tripper) run(ctx context.Context, watcher naming.Watcher) {
 			return
 		}
 
-		for _, u := range updates {
-			if u.Op == naming.Add {
-				localCurrentTargets = append(localCurrentTargets, &Target{DialAddr: u.Addr})
-			} else if u.Op == naming.Delete {
-				var kept []*Target
-				for _, t := range localCurrentTargets {
-					if u.Addr != t.DialAddr {
-						kept = append(kept, t)
-					}
-				}
-				localCurrentTargets = kept
-			}
+		targets := []*Target{}
+		for _, a := range resolvers.Ready(addrs) {
+			targets = append(targets, &Target{DialAddr: a.Addr, Zone: a.Zone, Weight: a.Weight})
 		}
 		s.mu.Lock()
-		s.currentTargets = localCurrentTargets
+		s.currentTargets = targets
 		s.mu.Unlock()
 	}
 }
 
-func (s *tripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	tags := http_ctxtags.ExtractInbound(r)
-	tags.Set(ctxtags.TagForBackendTarget, s.targetName)
-
+// resolvedTargets returns currently resolved targets or reports an error if there are none.
+func (s *tripper) resolvedTargets(r *http.Request) ([]*Target, error) {
 	s.mu.RLock()
 	targetsRef := s.currentTargets
 	irrecoverableErr := s.irrecoverableErr
 	s.mu.RUnlock()
 	if irrecoverableErr != nil {
-		err := errors.Wrapf(irrecoverableErr, "lb: critical naming.Watcher error for target %s. Tripper is closed.", s.targetName)
+		err := errors.Wrapf(irrecoverableErr, "lb: critical resolver watcher error for target %s. Tripper is closed.", s.targetName)
 		reporter.Extract(r).ReportError(errtypes.IrrecoverableWatcherError, err)
 		return nil, err
 	}
@@ -119,6 +113,46 @@ func (s *tripper) RoundTrip(r *http.Request) (*http.Response, error) {
 		reporter.Extract(r).ReportError(errtypes.NoResolutionAvailable, err)
 		return nil, err
 	}
+	return targetsRef, nil
+}
+
+// TargetStatus is a currently resolved target with its state in the LB policy.
+type TargetStatus struct {
+	DialAddr    string
+	Blacklisted bool
+}
+
+// Targets returns the currently resolved targets. It is used for debugging.
+func (s *tripper) Targets() ([]TargetStatus, error) {
+	s.mu.RLock()
+	targetsRef := s.currentTargets
+	irrecoverableErr := s.irrecoverableErr
+	s.mu.RUnlock()
+	if irrecoverableErr != nil {
+		return nil, errors.Wrapf(irrecoverableErr, "lb: critical resolver watcher error for target %s", s.targetName)
+	}
+
+	bl, _ := s.policy.(targetBlacklist)
+	var statuses []TargetStatus
+	for _, t := range targetsRef {
+		statuses = append(statuses, TargetStatus{DialAddr: t.DialAddr, Blacklisted: bl != nil && bl.isTargetBlacklisted(t)})
+	}
+	return statuses, nil
+}
+
+// DialFunc dials the given address.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Dial dials a raw TCP connection to a target picked by the LB policy for the given request. It is used for CONNECT
+// tunnels, which are not sent using RoundTrip.
+func (s *tripper) Dial(r *http.Request, dial DialFunc) (net.Conn, error) {
+	tags := http_ctxtags.ExtractInbound(r)
+	tags.Set(ctxtags.TagForBackendTarget, s.targetName)
+
+	targetsRef, err := s.resolvedTargets(r)
+	if err != nil {
+		return nil, err
+	}
 
 	picker := s.policy.Picker()
 	for {
@@ -129,13 +163,86 @@ func (s *tripper) RoundTrip(r *http.Request) (*http.Response, error) {
 			return nil, err
 		}
 
+		tags.Set(ctxtags.TagForTargetAddress, target.DialAddr)
+		conn, err := dial(r.Context(), "tcp", target.DialAddr)
+		if err == nil {
+			return conn, nil
+		}
+
+		failedDialsCounter.WithLabelValues(s.targetName, target.DialAddr).Inc()
+		picker.ExcludeTarget(target)
+	}
+}
+
+func (s *tripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	tags := http_ctxtags.ExtractInbound(r)
+	tags.Set(ctxtags.TagForBackendTarget, s.targetName)
+
+	targetsRef, err := s.resolvedTargets(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedRef := targetsRef
+
+	// Skip targets with open circuit breakers (see NewCircuitBreakerTripper). Breakers need all resolved targets, as
+	// they forget breakers of targets that are not passed.
+	breakers := targetBreakersFromCtx(r.Context())
+	targetsRef = breakers.available(targetsRef)
+	if len(targetsRef) == 0 {
+		err := errors.Wrapf(errAllBreakersOpen, "lb: no backend is available for %s", s.targetName)
+		reporter.Extract(r).ReportError(errtypes.CircuitBreakerOpen, err)
+		return nil, err
+	}
+
+	// Prefer targets that were not yet used by previous attempts of the same request (see NewRetryTripper).
+	attempted := attemptedTargetsFromCtx(r.Context())
+	targetsRef = attempted.notAttempted(targetsRef)
+
+	// Use the target of the sticky session if possible (see NewStickySessionTripper).
+	session := stickySessionFromCtx(r.Context())
+
+	picker := s.policy.Picker()
+	for {
+		target := session.pick(targetsRef, s.policy)
+		if target == nil {
+			var err error
+			if p, ok := picker.(resolvedTargetsPicker); ok {
+				target, err = p.PickFrom(r, resolvedRef, targetsRef)
+			} else {
+				target, err = picker.Pick(r, targetsRef)
+			}
+			if err != nil {
+				err = errors.Wrapf(err, "lb: failed choosing valid target for %s", s.targetName)
+				reporter.Extract(r).ReportError(errtypes.NoConnToAllResolvedAddresses, err)
+				return nil, err
+			}
+		}
+
 		// Override the host for downstream Tripper, usually http.DefaultTransport.
 		// http.Default transport uses `URL.Host` for Dial(<host>) and relevant connection pooling.
 		// We override it to make sure it enters the appropriate dial method and the appropriate connection pool.
 		// See http.connectMethodKey.
 		r.URL.Host = target.DialAddr
 		tags.Set(ctxtags.TagForTargetAddress, target.DialAddr)
-		resp, err := s.parent.RoundTrip(r)
+		metrics.ProxyRequestFromContext(r.Context()).SetTarget(target.DialAddr)
+		attempted.add(target)
+		session.use(target)
+		breakers.start(target)
+		var done func()
+		if tracker, ok := picker.(requestTracker); ok {
+			done = tracker.Started(target)
+		}
+		resp, err := s.roundTripTarget(r, target)
+		breakers.report(target, resp, err)
+		if done != nil {
+			if err != nil {
+				done()
+			} else {
+				// Request is finished only when the whole response body was consumed.
+				resp.Body = wrapBodyWithDone(resp.Body, done)
+			}
+		}
 		if err == nil {
 			return resp, nil
 		}
@@ -153,6 +260,46 @@ func (s *tripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	}
 }
 
+// roundTripTarget sends the request to the picked target within a span of its own, so every attempt is visible in the
+// trace.
+func (s *tripper) roundTripTarget(r *http.Request, target *Target) (*http.Response, error) {
+	ctx, span := tracing.StartSpan(r.Context(), "kedge.lbtransport.pick", tracing.KindClient)
+	if span == nil {
+		// Not tracing, the request already carries the context of the caller.
+		return s.parent.RoundTrip(r)
+	}
+	defer span.End()
+	span.SetAttribute("kedge.backend.target", s.targetName)
+	span.SetAttribute("kedge.target.address", target.DialAddr)
+	resp, err := s.parent.RoundTrip(tracing.RequestWithSpan(r.WithContext(ctx)))
+	tracing.RecordHTTPResult(span, resp, err)
+	return resp, err
+}
+
+// doneReadCloser invokes done when closed.
+type doneReadCloser struct {
+	io.ReadCloser
+	done func()
+}
+
+func (d *doneReadCloser) Close() error {
+	d.done()
+	return d.ReadCloser.Close()
+}
+
+// doneReadWriteCloser is doneReadCloser for bodies of upgraded (101 Switching Protocols) responses, which are writable.
+type doneReadWriteCloser struct {
+	doneReadCloser
+	io.Writer
+}
+
+func wrapBodyWithDone(body io.ReadCloser, done func()) io.ReadCloser {
+	if rwc, ok := body.(io.ReadWriteCloser); ok {
+		return &doneReadWriteCloser{doneReadCloser: doneReadCloser{ReadCloser: body, done: done}, Writer: rwc}
+	}
+	return &doneReadCloser{ReadCloser: body, done: done}
+}
+
 func isDialError(err error) bool {
 	if opErr, ok := err.(*net.OpError); ok {
 		if opErr.Op == "dial" {
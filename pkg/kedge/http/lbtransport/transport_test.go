@@ -14,10 +14,10 @@ import (
 	"github.com/fortytw2/leaktest"
 	"github.com/improbable-eng/kedge/pkg/reporter"
 	"github.com/improbable-eng/kedge/pkg/reporter/errtypes"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	"google.golang.org/grpc/naming"
 )
 
 var (
@@ -48,8 +48,8 @@ func TestRRBalancedTransportSuite(t *testing.T) {
 	suite.Run(t, new(BalancedRRTransportSuite))
 }
 
-// implementation of the naming.Resolver returning mocked watcher.
-func (s *BalancedRRTransportSuite) Resolve(_ string) (naming.Watcher, error) {
+// implementation of the resolvers.Resolver returning mocked watcher.
+func (s *BalancedRRTransportSuite) Resolve(_ string) (resolvers.Watcher, error) {
 	return s.backendSRVWatcher, nil
 }
 
@@ -67,7 +67,7 @@ func (s *BalancedRRTransportSuite) SetupSuite() {
 	s.ctx, s.cancelFn = context.WithCancel(context.TODO())
 
 	s.backendSRVWatcher = &mockSRVWatcher{
-		backendAddrUpdatesCh: make(chan []*naming.Update),
+		backendAddrUpdatesCh: make(chan []resolvers.Address),
 	}
 	var err error
 	// add `testBackendCount` backends to which we will be sending requests.
@@ -81,7 +81,7 @@ func (s *BalancedRRTransportSuite) SetupSuite() {
 		s.ctx,
 		"my-magic-srv",
 		http.DefaultTransport,
-		s, // self implements naming.Resolver
+		s, // self implements resolvers.Resolver
 		s.policy,
 	)
 	require.NoError(s.T(), err, "cannot fail on initialization")
@@ -270,43 +270,21 @@ func (s *BalancedRRTransportSuite) TestSrvLbErrorsAllResolvedAddressesAreWrong()
 	s.Assert().Equal(errtypes.NoConnToAllResolvedAddresses, t.ErrType())
 }
 
-// mockSRVWatcher implements naming.Watcher that is used inside lbtransport to watch for SRV lookup changes.
+// mockSRVWatcher implements resolvers.Watcher that is used inside lbtransport to watch for SRV lookup changes.
 type mockSRVWatcher struct {
-	currentBackendsMap   map[string]struct{}
-	backendAddrUpdatesCh chan []*naming.Update
+	backendAddrUpdatesCh chan []resolvers.Address
 }
 
 // UpdateBackends update SRV targets.
 func (t *mockSRVWatcher) UpdateBackends(newBackends []*httptest.Server) {
-	var updates []*naming.Update
-
-	newBackendsMap := make(map[string]struct{})
+	var addrs []resolvers.Address
 	for _, nb := range newBackends {
-		newBackendsMap[nb.Listener.Addr().String()] = struct{}{}
-		if _, ok := t.currentBackendsMap[nb.Listener.Addr().String()]; ok {
-			continue
-		}
-		updates = append(updates, &naming.Update{
-			Addr: nb.Listener.Addr().String(),
-			Op:   naming.Add,
-		})
-	}
-
-	for cbAddr := range t.currentBackendsMap {
-		if _, ok := newBackendsMap[cbAddr]; ok {
-			continue
-		}
-		updates = append(updates, &naming.Update{
-			Addr: cbAddr,
-			Op:   naming.Delete,
-		})
+		addrs = append(addrs, resolvers.Address{Addr: nb.Listener.Addr().String(), Weight: 1, Ready: true})
 	}
-
-	t.backendAddrUpdatesCh <- updates
-	t.currentBackendsMap = newBackendsMap
+	t.backendAddrUpdatesCh <- addrs
 }
 
-func (t *mockSRVWatcher) Next() ([]*naming.Update, error) {
+func (t *mockSRVWatcher) Next() ([]resolvers.Address, error) {
 	return <-t.backendAddrUpdatesCh, nil
 }
 
@@ -0,0 +1,54 @@
+package lbtransport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+)
+
+// weightedRoundRobinPolicy picks targets in proportion to their resolved weights (e.g. weights of SRV records).
+// It blacklists failing targets in the same way as roundRobinPolicy.
+type weightedRoundRobinPolicy struct {
+	*roundRobinPolicy
+
+	atomicSeq uint64
+}
+
+func WeightedRoundRobinPolicyFromFlags(ctx context.Context) LBPolicy {
+	return WeightedRoundRobinPolicy(ctx, *flagBlacklistBackoff)
+}
+
+func WeightedRoundRobinPolicy(ctx context.Context, backoffDuration time.Duration) LBPolicy {
+	return &weightedRoundRobinPolicy{
+		roundRobinPolicy: RoundRobinPolicy(ctx, backoffDuration, 0).(*roundRobinPolicy),
+	}
+}
+
+func (w *weightedRoundRobinPolicy) Picker() LBPolicyPicker {
+	return &weightedRoundRobinPolicyPicker{
+		roundRobinPolicyPicker: w.roundRobinPolicy.Picker().(*roundRobinPolicyPicker),
+		policy:                 w,
+	}
+}
+
+type weightedRoundRobinPolicyPicker struct {
+	// roundRobinPolicyPicker is used only for blacklisting.
+	*roundRobinPolicyPicker
+
+	policy *weightedRoundRobinPolicy
+}
+
+func (p *weightedRoundRobinPolicyPicker) Pick(r *http.Request, currentTargets []*Target) (*Target, error) {
+	available := p.available(currentTargets)
+	if len(available) == 0 {
+		return nil, fmt.Errorf("All targets %v are failing, try later.", currentTargets)
+	}
+	seq := atomic.AddUint64(&p.policy.atomicSeq, 1)
+	return available[common.PickWeighted(len(available), seq, func(i int) uint32 {
+		return available[i].Weight
+	})], nil
+}
@@ -0,0 +1,151 @@
+package backendpool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/improbable-eng/kedge/pkg/resolvers/host"
+	"github.com/improbable-eng/kedge/pkg/resolvers/k8s"
+	"github.com/improbable-eng/kedge/pkg/resolvers/srv"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+	"github.com/mwitkow/go-conntrack"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// Top DialContext func with decreased Dial Timeout in comparison to DefaultDialer.
+	ParentDialFunc = (&net.Dialer{
+		Timeout:   1 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+)
+
+type backend struct {
+	mu sync.RWMutex
+
+	ctx    context.Context // life-time context.
+	cancel context.CancelFunc
+
+	config   *pb.Backend
+	dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+	watcher  resolvers.Watcher
+
+	targets          []string
+	next             int
+	irrecoverableErr error
+}
+
+// newBackend creates backend from given configuration and starts watching its targets.
+func newBackend(cnf *pb.Backend) (*backend, error) {
+	b := &backend{config: cnf}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+
+	target, resolver, err := chooseResolver(cnf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to construct resolver for backend %s", cnf.Name)
+	}
+	b.watcher, err = resolver.Resolve(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve target for backend %s", cnf.Name)
+	}
+
+	b.dialFunc = ParentDialFunc
+	if !cnf.DisableConntracking {
+		b.dialFunc = conntrack.NewDialContextFunc(
+			conntrack.DialWithName("tcp_backend_"+cnf.Name),
+			conntrack.DialWithDialContextFunc(b.dialFunc),
+			conntrack.DialWithTracing(),
+		)
+	}
+
+	go b.watchTargets()
+	return b, nil
+}
+
+func (b *backend) watchTargets() {
+	for {
+		addrs, err := b.watcher.Next()
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			// Watcher next errors are irrecoverable.
+			logrus.WithError(err).WithField("backend", b.config.Name).Error("tcp backend: failed to watch targets, backend is closed")
+			b.mu.Lock()
+			b.irrecoverableErr = err
+			b.targets = []string{}
+			b.mu.Unlock()
+			return
+		}
+
+		targets := []string{}
+		for _, a := range resolvers.Ready(addrs) {
+			targets = append(targets, a.Addr)
+		}
+		b.mu.Lock()
+		b.targets = targets
+		b.mu.Unlock()
+	}
+}
+
+// Dial dials targets in round robin manner until one of them accepts the connection.
+func (b *backend) Dial(ctx context.Context) (net.Conn, error) {
+	b.mu.Lock()
+	if b.ctx.Err() != nil {
+		b.mu.Unlock()
+		return nil, errors.Errorf("backend %s is closed", b.config.Name)
+	}
+	if b.irrecoverableErr != nil {
+		err := errors.Wrapf(b.irrecoverableErr, "critical resolver watcher error for backend %s. Backend is closed", b.config.Name)
+		b.mu.Unlock()
+		return nil, err
+	}
+	targets := make([]string, len(b.targets))
+	copy(targets, b.targets)
+	start := b.next
+	b.next++
+	b.mu.Unlock()
+
+	if len(targets) == 0 {
+		return nil, errors.Errorf("no target is available for backend %s. 0 resolved addresses", b.config.Name)
+	}
+
+	var lastErr error
+	for i := range targets {
+		addr := targets[(start+i)%len(targets)]
+		conn, err := b.dialFunc(ctx, "tcp", addr)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, errors.Wrapf(lastErr, "failed to dial all targets of backend %s", b.config.Name)
+}
+
+// Close is used when backend is removed from configuration dynamically.
+func (b *backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cancel()
+	b.watcher.Close()
+	return nil
+}
+
+func chooseResolver(cnf *pb.Backend) (string, resolvers.Resolver, error) {
+	if s := cnf.GetSrv(); s != nil {
+		return srvresolver.NewFromConfig(s)
+	}
+	if k := cnf.GetK8S(); k != nil {
+		rsv, err := k8sresolver.NewFromFlags(logrus.StandardLogger())
+		return k.GetDnsPortName(), rsv, err
+	}
+	if k := cnf.GetHost(); k != nil {
+		return hostresolver.NewFromConfig(k)
+	}
+	return "", nil, fmt.Errorf("unspecified resolver for %v", cnf.Name)
+}
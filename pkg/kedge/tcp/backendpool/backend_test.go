@@ -0,0 +1,66 @@
+package backendpool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend_DialSkipsUnreachableTargets(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	b := &backend{
+		config:   &pb.Backend{Name: "db"},
+		dialFunc: ParentDialFunc,
+		targets:  []string{closedAddr, listener.Addr().String()},
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	defer b.cancel()
+
+	for i := 0; i < 2; i++ {
+		conn, err := b.Dial(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, listener.Addr().String(), conn.RemoteAddr().String())
+		conn.Close()
+	}
+
+	b.targets = nil
+	_, err = b.Dial(context.Background())
+	assert.Error(t, err, "dial should fail without targets")
+}
+
+type failingWatcher struct{}
+
+func (failingWatcher) Next() ([]resolvers.Address, error) { return nil, errors.New("watch failed") }
+func (failingWatcher) Close()                             {}
+
+func TestBackend_WatcherErrorFailsDials(t *testing.T) {
+	b := &backend{
+		config:   &pb.Backend{Name: "db"},
+		dialFunc: ParentDialFunc,
+		watcher:  failingWatcher{},
+		targets:  []string{"127.0.0.1:1"},
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	defer b.cancel()
+
+	// Returns after the first error instead of retrying it in a loop.
+	b.watchTargets()
+
+	_, err := b.Dial(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "watch failed")
+}
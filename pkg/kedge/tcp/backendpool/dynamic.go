@@ -0,0 +1,100 @@
+package backendpool
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/improbable-eng/kedge/pkg/metrics"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+	"github.com/sirupsen/logrus"
+)
+
+// dynamic is a Pool to which you can update or remove backends.
+type dynamic struct {
+	mu sync.RWMutex
+
+	backends       map[string]*backend
+	backendFactory func(backend *pb.Backend) (*backend, error)
+	logger         logrus.FieldLogger
+}
+
+// NewDynamic creates a pool with a dynamic allocator
+func NewDynamic(logger logrus.FieldLogger) *dynamic {
+	return &dynamic{backends: make(map[string]*backend), backendFactory: newBackend, logger: logger}
+}
+
+func (s *dynamic) Dial(ctx context.Context, backendName string) (net.Conn, error) {
+	s.mu.RLock()
+	be, ok := s.backends[backendName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownBackend
+	}
+	return be.Dial(ctx)
+}
+
+func (s *dynamic) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, be := range s.backends {
+		be.Close()
+	}
+}
+
+// AddOrUpdate adds a new backend or replaces the existing one if its configuration has changed.
+func (s *dynamic) AddOrUpdate(config *pb.Backend) (changed bool, err error) {
+	s.mu.RLock()
+	existing, ok := s.backends[config.Name]
+	s.mu.RUnlock()
+	if ok && proto.Equal(existing.config, config) {
+		return false, nil
+	}
+
+	be, err := s.backendFactory(config)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.backends[config.Name] = be
+	s.mu.Unlock()
+
+	if !ok {
+		s.logger.Infof("Adding new tcp backend: %v", config.Name)
+		metrics.BackendTCPConfigurationCounter.WithLabelValues(config.Name, metrics.ConfiguationActionCreate).Inc()
+		return true, nil
+	}
+	// Make sure we clear up resources.
+	existing.Close()
+	s.logger.Infof("Updated tcp backend: %v", config.Name)
+	metrics.BackendTCPConfigurationCounter.WithLabelValues(config.Name, metrics.ConfiguationActionChange).Inc()
+	return true, nil
+}
+
+// Remove removes and shuts down a previously active backend.
+func (s *dynamic) Remove(backendName string) error {
+	s.mu.Lock()
+	existing, ok := s.backends[backendName]
+	delete(s.backends, backendName)
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownBackend
+	}
+	existing.Close()
+
+	s.logger.Infof("Removed tcp backend: %v", backendName)
+	metrics.BackendTCPConfigurationCounter.WithLabelValues(backendName, metrics.ConfiguationActionDelete).Inc()
+	return nil
+}
+
+// Configs returns a map of all active backends and their configuration.
+func (s *dynamic) Configs() map[string]*pb.Backend {
+	ret := make(map[string]*pb.Backend)
+	s.mu.RLock()
+	for k, v := range s.backends {
+		ret[k] = v.config
+	}
+	s.mu.RUnlock()
+	return ret
+}
@@ -0,0 +1,17 @@
+package backendpool
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+var (
+	ErrUnknownBackend = errors.New("unknown backend")
+)
+
+type Pool interface {
+	// Dial returns a raw TCP connection to one of the targets of the backend.
+	Dial(ctx context.Context, backendName string) (net.Conn, error)
+	Close()
+}
@@ -0,0 +1,137 @@
+package director
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/tcp/backendpool"
+	"github.com/improbable-eng/kedge/pkg/kedge/tcp/director/router"
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	flagClientHelloTimeout = sharedflags.Set.Duration("tcp_client_hello_timeout", 10*time.Second,
+		"Maximum time for reading TLS ClientHello of connections to the TLS passthrough listener.")
+	flagBackendDialTimeout = sharedflags.Set.Duration("tcp_backend_dial_timeout", 5*time.Second,
+		"Maximum time for dialing a TCP backend, including trying other targets on failures.")
+
+	connsOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "tcp",
+			Name:      "connections_open",
+			Help:      "Number of currently open connections forwarded to TCP backends.",
+		},
+		[]string{"backend_name"},
+	)
+	connsRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "tcp",
+			Name:      "connections_rejected_total",
+			Help:      "Total number of connections to the TLS passthrough listener that were not forwarded, by reason.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(connsOpen)
+	prometheus.MustRegister(connsRejected)
+}
+
+// Proxy forwards raw TLS connections to TCP backends chosen by the server name (SNI) from the ClientHello.
+// TLS is not terminated, so backends need to serve their own certificates.
+type Proxy struct {
+	pool     backendpool.Pool
+	router   router.Router
+	logEntry logrus.FieldLogger
+}
+
+// New creates a TLS passthrough proxy.
+func New(pool backendpool.Pool, router router.Router, logEntry logrus.FieldLogger) *Proxy {
+	return &Proxy{
+		pool:     pool,
+		router:   router,
+		logEntry: logEntry,
+	}
+}
+
+// Serve accepts connections on the listener and forwards them until the listener is closed.
+func (p *Proxy) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *Proxy) handle(conn net.Conn) {
+	defer conn.Close()
+	logEntry := p.logEntry.WithField("client_addr", conn.RemoteAddr().String())
+
+	conn.SetReadDeadline(time.Now().Add(*flagClientHelloTimeout))
+	serverName, hello, err := readClientHello(conn)
+	if err != nil {
+		logEntry.WithError(err).Debug("Rejected TCP connection")
+		connsRejected.WithLabelValues("bad_client_hello").Inc()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	logEntry = logEntry.WithField("sni", serverName)
+
+	backendName, err := p.router.Route(serverName)
+	if err != nil {
+		logEntry.WithError(err).Info("Rejected TCP connection")
+		connsRejected.WithLabelValues("no_route").Inc()
+		return
+	}
+	logEntry = logEntry.WithField("backend", backendName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flagBackendDialTimeout)
+	backendConn, err := p.pool.Dial(ctx, backendName)
+	cancel()
+	if err != nil {
+		logEntry.WithError(err).Warn("Failed to dial TCP backend")
+		connsRejected.WithLabelValues("backend_dial_failed").Inc()
+		return
+	}
+	defer backendConn.Close()
+
+	if _, err := backendConn.Write(hello); err != nil {
+		logEntry.WithError(err).Warn("Failed to forward ClientHello to TCP backend")
+		connsRejected.WithLabelValues("backend_write_failed").Inc()
+		return
+	}
+
+	connsOpen.WithLabelValues(backendName).Inc()
+	defer connsOpen.WithLabelValues(backendName).Dec()
+	pipe(conn, backendConn)
+}
+
+// pipe copies data in both directions until one of the sides closes the connection.
+func pipe(clientConn net.Conn, backendConn net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(backendConn, clientConn)
+		// Closing both sides unblocks the other copying goroutine.
+		backendConn.Close()
+		clientConn.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(clientConn, backendConn)
+		backendConn.Close()
+		clientConn.Close()
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package director
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/tcp/backendpool"
+	"github.com/improbable-eng/kedge/pkg/kedge/tcp/director/router"
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePool struct {
+	addrs map[string]string
+}
+
+func (p *fakePool) Dial(ctx context.Context, backendName string) (net.Conn, error) {
+	addr, ok := p.addrs[backendName]
+	if !ok {
+		return nil, backendpool.ErrUnknownBackend
+	}
+	return (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+}
+
+func (p *fakePool) Close() {}
+
+func startPassthroughProxy(t *testing.T) (backend *httptest.Server, listener net.Listener) {
+	backend = httptest.NewTLSServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte("served by backend for " + req.TLS.ServerName))
+	}))
+
+	r := router.NewStatic([]*pb.Route{
+		{
+			BackendName: "backend",
+			SniPattern:  &pb_common.StringMatcher{Match: &pb_common.StringMatcher_Suffix{Suffix: ".db.example.com"}},
+		},
+	})
+	pool := &fakePool{addrs: map[string]string{"backend": backend.Listener.Addr().String()}}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go New(pool, r, logrus.New()).Serve(listener)
+	return backend, listener
+}
+
+func TestProxy_ForwardsTLSWithoutTerminating(t *testing.T) {
+	backend, listener := startPassthroughProxy(t)
+	defer backend.Close()
+	defer listener.Close()
+
+	// Verify against the backend certificate, to make sure TLS is terminated by the backend.
+	roots := x509.NewCertPool()
+	roots.AddCert(backend.Certificate())
+	client := &http.Client{Transport: &http.Transport{
+		DialTLS: func(network, addr string) (net.Conn, error) {
+			return tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+				ServerName: "postgres.db.example.com",
+				// httptest certificate is issued for example.com only, so check just the issuer.
+				InsecureSkipVerify: true,
+				VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+					cert, err := x509.ParseCertificate(rawCerts[0])
+					if err != nil {
+						return err
+					}
+					_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+					return err
+				},
+			})
+		},
+	}}
+
+	resp, err := client.Get("https://postgres.db.example.com/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "served by backend for postgres.db.example.com", string(body))
+}
+
+func TestProxy_ClosesConnectionsWithUnknownSNI(t *testing.T) {
+	backend, listener := startPassthroughProxy(t)
+	defer backend.Close()
+	defer listener.Close()
+
+	_, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{ServerName: "web.example.com", InsecureSkipVerify: true})
+	assert.Error(t, err, "handshake should fail")
+}
+
+func TestReadClientHello_NoTLS(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		client.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+		client.Close()
+	}()
+	_, _, err := readClientHello(server)
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/improbable-eng/kedge/pkg/kedge/common"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrRouteNotFound = errors.New("unknown route to service")
+)
+
+type Router interface {
+	// Route returns a backend name for a connection with the given TLS server name (SNI), or an error.
+	Route(serverName string) (backendName string, err error)
+}
+
+type dynamic struct {
+	mu           sync.RWMutex
+	staticRouter *static
+}
+
+// NewDynamic creates a new dynamic router that can be have its routes updated.
+func NewDynamic() *dynamic {
+	return &dynamic{staticRouter: NewStatic([]*pb.Route{})}
+}
+
+func (d *dynamic) Route(serverName string) (backendName string, err error) {
+	d.mu.RLock()
+	staticRouter := d.staticRouter
+	d.mu.RUnlock()
+	return staticRouter.Route(serverName)
+}
+
+// Update sets the routing table to the provided set of routes.
+func (d *dynamic) Update(routes []*pb.Route) {
+	staticRouter := NewStatic(routes)
+	d.mu.Lock()
+	d.staticRouter = staticRouter
+	d.mu.Unlock()
+}
+
+type route struct {
+	*pb.Route
+
+	name       string
+	sniPattern *common.StringMatcher
+}
+
+func newRoute(idx int, cnf *pb.Route) (*route, error) {
+	r := &route{Route: cnf, name: cnf.Name}
+	if r.name == "" {
+		r.name = strconv.Itoa(idx)
+	}
+
+	var err error
+	if r.sniPattern, err = common.NewStringMatcher(cnf.SniPattern); err != nil {
+		return nil, pkgerrors.Wrapf(err, "route %v: invalid sni_pattern", r.name)
+	}
+	return r, nil
+}
+
+// ValidateRoutes returns an error if any of the routes cannot be used by the router, e.g. because of an invalid regex.
+func ValidateRoutes(routes []*pb.Route) error {
+	for i, r := range routes {
+		if _, err := newRoute(i, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type static struct {
+	routes []*route
+}
+
+// NewStatic creates a router with the given routes. Invalid routes are logged and skipped, so they need to be validated
+// using ValidateRoutes upfront.
+func NewStatic(routes []*pb.Route) *static {
+	s := &static{}
+	for i, r := range routes {
+		route, err := newRoute(i, r)
+		if err != nil {
+			logrus.WithError(err).Error("Skipping invalid TCP route.")
+			continue
+		}
+		s.routes = append(s.routes, route)
+	}
+	return s
+}
+
+func (r *static) Route(serverName string) (backendName string, err error) {
+	// Server names are case-insensitive.
+	serverName = strings.ToLower(serverName)
+	for _, route := range r.routes {
+		if route.SniPattern != nil && serverName == "" {
+			continue
+		}
+		if !route.sniPattern.Match(serverName) {
+			continue
+		}
+		return route.BackendName, nil
+	}
+	return "", ErrRouteNotFound
+}
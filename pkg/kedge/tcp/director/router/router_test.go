@@ -0,0 +1,55 @@
+package router
+
+import (
+	"testing"
+
+	pb_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_MatchesSNIPatterns(t *testing.T) {
+	routes := []*pb.Route{
+		{
+			BackendName: "postgres",
+			SniPattern:  &pb_common.StringMatcher{Match: &pb_common.StringMatcher_Exact{Exact: "postgres.db.example.com"}},
+		},
+		{
+			BackendName: "databases",
+			SniPattern:  &pb_common.StringMatcher{Match: &pb_common.StringMatcher_Wildcard{Wildcard: "*.db.example.com"}},
+		},
+	}
+	require.NoError(t, ValidateRoutes(routes))
+	r := NewStatic(routes)
+
+	for _, tcase := range []struct {
+		serverName      string
+		expectedBackend string
+		expectedErr     error
+	}{
+		{serverName: "postgres.db.example.com", expectedBackend: "postgres"},
+		{serverName: "Postgres.DB.example.com", expectedBackend: "postgres"},
+		{serverName: "mysql.db.example.com", expectedBackend: "databases"},
+		{serverName: "web.example.com", expectedErr: ErrRouteNotFound},
+		{serverName: "", expectedErr: ErrRouteNotFound},
+	} {
+		backend, err := r.Route(tcase.serverName)
+		assert.Equal(t, tcase.expectedErr, err, tcase.serverName)
+		assert.Equal(t, tcase.expectedBackend, backend, tcase.serverName)
+	}
+}
+
+func TestRouter_RouteWithoutPatternMatchesConnectionsWithoutSNI(t *testing.T) {
+	r := NewStatic([]*pb.Route{{BackendName: "default"}})
+	backend, err := r.Route("")
+	require.NoError(t, err)
+	assert.Equal(t, "default", backend)
+}
+
+func TestValidateRoutes_InvalidRegex(t *testing.T) {
+	err := ValidateRoutes([]*pb.Route{
+		{BackendName: "broken", SniPattern: &pb_common.StringMatcher{Match: &pb_common.StringMatcher_Regex{Regex: "("}}},
+	})
+	assert.Error(t, err)
+}
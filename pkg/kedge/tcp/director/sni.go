@@ -0,0 +1,45 @@
+package director
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errClientHelloRead = errors.New("tls: ClientHello read")
+
+// readClientHello reads the TLS ClientHello from conn without terminating TLS. It returns the server name (SNI) sent
+// by the client (empty if not sent) together with all bytes read from conn, which need to be replayed to the backend.
+func readClientHello(conn net.Conn) (serverName string, hello []byte, err error) {
+	buf := &bytes.Buffer{}
+	var helloInfo *tls.ClientHelloInfo
+	err = tls.Server(&readOnlyConn{reader: io.TeeReader(conn, buf)}, &tls.Config{
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			helloInfo = info
+			// Stop the handshake, we are only interested in the ClientHello.
+			return nil, errClientHelloRead
+		},
+	}).Handshake()
+	if helloInfo == nil {
+		return "", nil, errors.Wrap(err, "failed to read TLS ClientHello")
+	}
+	return helloInfo.ServerName, buf.Bytes(), nil
+}
+
+// readOnlyConn is a net.Conn which can be only read from. Writes (e.g. TLS alerts) are discarded.
+type readOnlyConn struct {
+	reader io.Reader
+}
+
+func (c *readOnlyConn) Read(p []byte) (int, error)         { return c.reader.Read(p) }
+func (c *readOnlyConn) Write(p []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (c *readOnlyConn) Close() error                       { return nil }
+func (c *readOnlyConn) LocalAddr() net.Addr                { return nil }
+func (c *readOnlyConn) RemoteAddr() net.Addr               { return nil }
+func (c *readOnlyConn) SetDeadline(t time.Time) error      { return nil }
+func (c *readOnlyConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *readOnlyConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -25,9 +25,17 @@ var (
 		},
 		[]string{"backend_name", "action"},
 	)
+	BackendTCPConfigurationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kedge_tcp_backend_configuration_changes_total",
+			Help: "Count of changes in TCP backend configuration, done by flagz flag change.",
+		},
+		[]string{"backend_name", "action"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(BackendHTTPConfigurationCounter)
 	prometheus.MustRegister(BackendGRPCConfigurationCounter)
+	prometheus.MustRegister(BackendTCPConfigurationCounter)
 }
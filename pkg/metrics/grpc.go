@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// UpstreamStreamClientInterceptor adds the time from opening the call to the upstream until it ends as upstream time
+// of the ProxyRequest of the call (if any).
+func UpstreamStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	r := ProxyRequestFromContext(ctx)
+	if r == nil {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	start := time.Now()
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		r.AddUpstream(time.Since(start))
+		return nil, err
+	}
+	return &upstreamTimedStream{ClientStream: cs, onFinish: func() { r.AddUpstream(time.Since(start)) }}, nil
+}
+
+// upstreamTimedStream calls onFinish once the call ends, i.e. when RecvMsg returns an error. The proxy receives until
+// then.
+type upstreamTimedStream struct {
+	grpc.ClientStream
+	once     sync.Once
+	onFinish func()
+}
+
+func (s *upstreamTimedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(s.onFinish)
+	}
+	return err
+}
@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ModeForward = "forward"
+	ModeReverse = "reverse"
+	ModeAdhoc   = "adhoc"
+
+	// AdhocBackendName is the backend_name label of requests sent to adhoc targets.
+	AdhocBackendName = "_adhoc"
+	// OverflowLabelValue replaces label values over the limit of distinct values.
+	OverflowLabelValue = "_other"
+)
+
+var (
+	flagMaxLabelValues = sharedflags.Set.Int("metrics_proxy_max_label_values", 500,
+		"Maximum number of distinct target label values of kedge_proxy_*_duration_seconds metrics for adhoc targets and "+
+			"for targets of each backend. Further values are reported as \"_other\".")
+
+	proxyLabels = []string{"protocol", "route", "backend_name", "target", "mode", "error_type"}
+
+	ProxyOverheadDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "kedge_proxy_overhead_duration_seconds",
+			Help: "Time spent by kedge on a proxied request or call other than waiting for the upstream, e.g. auth, routing, " +
+				"resolving and picking the target.",
+			Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		},
+		proxyLabels,
+	)
+	ProxyUpstreamDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "kedge_proxy_upstream_duration_seconds",
+			Help: "Time from sending a proxied request or call to the upstream until its response finished, summed over all " +
+				"attempts.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		proxyLabels,
+	)
+	ProxyLabelOverflows = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kedge_proxy_metrics_label_overflows_total",
+			Help: "Count of requests reported with \"_other\" label value, as the label reached its limit of distinct values.",
+		},
+		[]string{"label"},
+	)
+
+	// adhocTargetGuard limits adhoc targets, which come from requests. Targets of backends are limited per backend (see
+	// backendSeries), so adhoc targets cannot use up their budget.
+	adhocTargetGuard = &labelGuard{name: "target"}
+
+	backendsMu sync.Mutex
+	backends   = map[backendKey]*backendSeries{}
+)
+
+type backendKey struct {
+	protocol string
+	name     string
+}
+
+// backendSeries tracks series reported for a backend, so they can be forgotten when the backend is removed.
+type backendSeries struct {
+	targets *labelGuard
+	// labels are label values of the reported series.
+	labels map[[6]string]struct{}
+}
+
+// guardBackendTarget limits the target of the given label values of a backend's request (in the order of proxyLabels)
+// and records the series to be reported.
+func guardBackendTarget(lvs [6]string) [6]string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	k := backendKey{protocol: lvs[0], name: lvs[2]}
+	b, ok := backends[k]
+	if !ok {
+		b = &backendSeries{targets: &labelGuard{name: "target"}, labels: map[[6]string]struct{}{}}
+		backends[k] = b
+	}
+	lvs[3] = b.targets.value(lvs[3])
+	b.labels[lvs] = struct{}{}
+	return lvs
+}
+
+// ForgetProxyBackend deletes series of the removed backend of the given protocol ("http" or "grpc"), so targets of
+// removed backends are not reported forever.
+func ForgetProxyBackend(protocol string, backend string) {
+	backendsMu.Lock()
+	k := backendKey{protocol: protocol, name: backend}
+	b, ok := backends[k]
+	delete(backends, k)
+	backendsMu.Unlock()
+	if !ok {
+		return
+	}
+	for lvs := range b.labels {
+		ProxyOverheadDuration.DeleteLabelValues(lvs[:]...)
+		ProxyUpstreamDuration.DeleteLabelValues(lvs[:]...)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(ProxyOverheadDuration)
+	prometheus.MustRegister(ProxyUpstreamDuration)
+	prometheus.MustRegister(ProxyLabelOverflows)
+}
+
+// labelGuard limits the number of distinct values of a label, so values coming from requests (adhoc targets) or
+// changing over time (e.g. pod IPs) cannot create an unbounded number of series.
+type labelGuard struct {
+	name string
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func (g *labelGuard) value(v string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.seen[v]; ok {
+		return v
+	}
+	if len(g.seen) >= *flagMaxLabelValues {
+		ProxyLabelOverflows.WithLabelValues(g.name).Inc()
+		return OverflowLabelValue
+	}
+	if g.seen == nil {
+		g.seen = map[string]struct{}{}
+	}
+	g.seen[v] = struct{}{}
+	return v
+}
+
+// ProxyRequest collects labels and upstream time of a single proxied HTTP request or gRPC call, reported in the
+// kedge_proxy_*_duration_seconds histograms once it is finished. It is passed in the request's context; all methods are
+// safe to call on nil.
+type ProxyRequest struct {
+	protocol string
+	start    time.Time
+
+	mu                sync.Mutex
+	mode              string
+	route             string
+	backend           string
+	target            string
+	errType           string
+	upstream          time.Duration
+	upstreamAttempted bool
+	discarded         bool
+}
+
+// NewProxyRequest starts measuring a request of the given protocol ("http" or "grpc") and proxy mode.
+func NewProxyRequest(protocol string, mode string) *ProxyRequest {
+	return &ProxyRequest{protocol: protocol, mode: mode, start: time.Now()}
+}
+
+type proxyRequestKey struct{}
+
+// ContextWithProxyRequest returns a context carrying the request.
+func ContextWithProxyRequest(ctx context.Context, r *ProxyRequest) context.Context {
+	return context.WithValue(ctx, proxyRequestKey{}, r)
+}
+
+// ProxyRequestFromContext returns the request of the context, or nil.
+func ProxyRequestFromContext(ctx context.Context) *ProxyRequest {
+	r, _ := ctx.Value(proxyRequestKey{}).(*ProxyRequest)
+	return r
+}
+
+// SetRoute sets the name of the route the request matched and the backend chosen by it.
+func (r *ProxyRequest) SetRoute(route string, backend string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.route = route
+	r.backend = backend
+}
+
+// SetAdhoc marks the request as sent to the given adhoc target.
+func (r *ProxyRequest) SetAdhoc(target string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mode = ModeAdhoc
+	r.backend = AdhocBackendName
+	r.target = target
+}
+
+// SetTarget sets the resolved address the request was sent to.
+func (r *ProxyRequest) SetTarget(target string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.target = target
+}
+
+// SetErrorType sets the type of the error that failed the request within kedge (see reporter/errtypes).
+func (r *ProxyRequest) SetErrorType(errType string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errType = errType
+}
+
+// AddUpstream adds the duration of an attempt to send the request upstream.
+func (r *ProxyRequest) AddUpstream(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upstream += d
+	r.upstreamAttempted = true
+}
+
+// Discard prevents the request from being reported, e.g. for tunnels whose duration is not a latency.
+func (r *ProxyRequest) Discard() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.discarded = true
+}
+
+// Finish reports the request. The upstream time is not reported if the request was not sent upstream at all.
+func (r *ProxyRequest) Finish() {
+	if r == nil {
+		return
+	}
+	total := time.Since(r.start)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.discarded {
+		return
+	}
+	errType := r.errType
+	if errType == "" {
+		errType = "none"
+	}
+	// Label values in the order of proxyLabels.
+	lvs := [6]string{r.protocol, r.route, r.backend, r.target, r.mode, errType}
+	switch r.backend {
+	case AdhocBackendName:
+		lvs[3] = adhocTargetGuard.value(r.target)
+	case "":
+		// No route matched, so there is no target.
+	default:
+		lvs = guardBackendTarget(lvs)
+	}
+	overhead := total - r.upstream
+	if overhead < 0 {
+		overhead = 0
+	}
+	ProxyOverheadDuration.WithLabelValues(lvs[:]...).Observe(overhead.Seconds())
+	if r.upstreamAttempted {
+		ProxyUpstreamDuration.WithLabelValues(lvs[:]...).Observe(r.upstream.Seconds())
+	}
+}
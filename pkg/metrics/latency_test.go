@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleCount(t *testing.T, h interface {
+	Write(*dto.Metric) error
+}) uint64 {
+	m := &dto.Metric{}
+	require.NoError(t, h.Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestLabelGuard_LimitsDistinctValues(t *testing.T) {
+	defer func(old int) { *flagMaxLabelValues = old }(*flagMaxLabelValues)
+	*flagMaxLabelValues = 2
+
+	g := &labelGuard{name: "target"}
+	assert.Equal(t, "a", g.value("a"))
+	assert.Equal(t, "b", g.value("b"))
+	assert.Equal(t, OverflowLabelValue, g.value("c"))
+	assert.Equal(t, "a", g.value("a"), "known values should be kept")
+}
+
+func TestProxyRequest_ReportsOverheadAndUpstream(t *testing.T) {
+	ctx := ContextWithProxyRequest(context.Background(), NewProxyRequest("http", ModeReverse))
+	r := ProxyRequestFromContext(ctx)
+	require.NotNil(t, r)
+	r.SetRoute("route_a", "backend_a")
+	r.SetTarget("10.0.0.1:80")
+	r.AddUpstream(20 * time.Millisecond)
+	r.Finish()
+
+	labels := []string{"http", "route_a", "backend_a", "10.0.0.1:80", ModeReverse, "none"}
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels...)))
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyUpstreamDuration.WithLabelValues(labels...)))
+}
+
+func TestProxyRequest_AdhocWithoutUpstream(t *testing.T) {
+	target := fmt.Sprintf("adhoc-%d:80", time.Now().UnixNano())
+	r := NewProxyRequest("grpc", ModeReverse)
+	r.SetAdhoc(target)
+	r.SetErrorType("no-route")
+	r.Finish()
+
+	labels := []string{"grpc", "", AdhocBackendName, target, ModeAdhoc, "no-route"}
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels...)))
+	assert.Equal(t, uint64(0), sampleCount(t, ProxyUpstreamDuration.WithLabelValues(labels...)),
+		"upstream time should not be reported when nothing was sent upstream")
+}
+
+func TestProxyRequest_DiscardedAndNil(t *testing.T) {
+	r := NewProxyRequest("http", ModeForward)
+	r.SetRoute("discarded_route", "backend_a")
+	r.Discard()
+	r.Finish()
+	assert.Equal(t, uint64(0), sampleCount(t, ProxyOverheadDuration.WithLabelValues("http", "discarded_route", "backend_a", "", ModeForward, "none")))
+
+	var nilReq *ProxyRequest
+	nilReq.SetRoute("a", "b")
+	nilReq.AddUpstream(time.Second)
+	nilReq.Finish()
+	assert.Nil(t, ProxyRequestFromContext(context.Background()))
+}
+
+func TestProxyRequest_TargetsGuardedPerBackend(t *testing.T) {
+	defer func(old int) { *flagMaxLabelValues = old }(*flagMaxLabelValues)
+	*flagMaxLabelValues = 1
+
+	report := func(backend string, target string) {
+		r := NewProxyRequest("http", ModeReverse)
+		r.SetRoute("guarded_route", backend)
+		r.SetTarget(target)
+		r.Finish()
+	}
+	report("guarded_a", "10.0.0.1:80")
+	report("guarded_a", "10.0.0.2:80")
+	report("guarded_b", "10.0.1.1:80")
+	for i := 0; i < 3; i++ {
+		r := NewProxyRequest("http", ModeReverse)
+		r.SetAdhoc(fmt.Sprintf("guarded-adhoc-%d-%d:80", time.Now().UnixNano(), i))
+		r.Finish()
+	}
+
+	labels := func(backend string, target string) []string {
+		return []string{"http", "guarded_route", backend, target, ModeReverse, "none"}
+	}
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels("guarded_a", OverflowLabelValue)...)))
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels("guarded_b", "10.0.1.1:80")...)),
+		"targets of other backends and adhoc targets should not use up the limit of a backend")
+
+	ForgetProxyBackend("http", "guarded_a")
+	assert.Equal(t, uint64(0), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels("guarded_a", "10.0.0.1:80")...)),
+		"series of removed backend should be deleted")
+	report("guarded_a", "10.0.0.3:80")
+	assert.Equal(t, uint64(1), sampleCount(t, ProxyOverheadDuration.WithLabelValues(labels("guarded_a", "10.0.0.3:80")...)),
+		"limit of removed backend should be reset")
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	RouteHTTPRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kedge_http_route_requests_total",
+			Help: "Count of HTTP requests matched by a route, by the backend chosen for the request.",
+		},
+		[]string{"route", "backend_name"},
+	)
+	RouteGRPCRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kedge_grpc_route_requests_total",
+			Help: "Count of gRPC requests matched by a route, by the backend chosen for the request.",
+		},
+		[]string{"route", "backend_name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(RouteHTTPRequestsCounter)
+	prometheus.MustRegister(RouteGRPCRequestsCounter)
+}
@@ -15,6 +15,9 @@ const (
 	// RouteUnknownError is an error returned by p.router.Route(req) indicating some unknown error than no route.
 	RouteUnknownError Type = "unknown-route-error"
 
+	// RateLimited is an error returned when the request exceeds the rate limit of the route it matched.
+	RateLimited Type = "rate-limited"
+
 	// NoBackend is the only error that can be returned by backendpool.Tripper (ErrUnknownBackend)
 	// It can happen on bug or wrong configuration (routing exists for not existing backend) or race in configuration.
 	NoBackend Type = "no-backend"
@@ -39,6 +42,10 @@ const (
 	// other winch internal error.
 	TransportUnknownError Type = "transport-unknown-error"
 
-	// IrrecoverableWatcherError indicates unlikely irrecoverable error from resolver's naming.Watcher.
+	// CircuitBreakerOpen is an error returned by lbtransport when the circuit breaker of the backend or breakers of all
+	// its targets are open, so the request fails fast without being sent.
+	CircuitBreakerOpen Type = "circuit-breaker-open"
+
+	// IrrecoverableWatcherError indicates unlikely irrecoverable error from resolver's watcher.
 	IrrecoverableWatcherError Type = "resolver-watcher-irrecoverable"
 )
@@ -0,0 +1,465 @@
+package healthresolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var (
+	targetHealthyGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kedge",
+			Subsystem: "backend",
+			Name:      "target_healthy",
+			Help:      "Health of the backend target according to active health checks. 1 if healthy, 0 otherwise.",
+		},
+		[]string{"backend", "target"},
+	)
+	checksCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "backend",
+			Name:      "health_checks_total",
+			Help:      "Total number of active health checks of backend targets by result.",
+		},
+		[]string{"backend", "result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(targetHealthyGauge)
+	prometheus.MustRegister(checksCounter)
+}
+
+// Prober checks health of targets.
+type Prober interface {
+	// Probe checks health of a single target. It returns nil if target is healthy.
+	Probe(ctx context.Context, addr string) error
+	// Forget releases resources kept for the target once it is no longer checked.
+	Forget(addr string)
+}
+
+// ProbeFunc is a Prober that keeps no resources for targets.
+type ProbeFunc func(ctx context.Context, addr string) error
+
+func (f ProbeFunc) Probe(ctx context.Context, addr string) error {
+	return f(ctx, addr)
+}
+
+func (f ProbeFunc) Forget(string) {}
+
+// Options configures how targets are checked.
+type Options struct {
+	Interval           time.Duration
+	Timeout            time.Duration
+	HealthyThreshold   int
+	UnhealthyThreshold int
+}
+
+// OptionsFromConfig returns options from health check config with defaults applied.
+func OptionsFromConfig(cnf *pb.HealthCheck) Options {
+	opts := Options{
+		Interval:           5 * time.Second,
+		Timeout:            1 * time.Second,
+		HealthyThreshold:   1,
+		UnhealthyThreshold: 3,
+	}
+	if cnf.GetIntervalMs() > 0 {
+		opts.Interval = time.Duration(cnf.GetIntervalMs()) * time.Millisecond
+	}
+	if cnf.GetTimeoutMs() > 0 {
+		opts.Timeout = time.Duration(cnf.GetTimeoutMs()) * time.Millisecond
+	}
+	if cnf.GetHealthyThreshold() > 0 {
+		opts.HealthyThreshold = int(cnf.GetHealthyThreshold())
+	}
+	if cnf.GetUnhealthyThreshold() > 0 {
+		opts.UnhealthyThreshold = int(cnf.GetUnhealthyThreshold())
+	}
+	return opts
+}
+
+// NewHTTPProbe returns ProbeFunc that sends GET request with the given path to the target using given transport.
+func NewHTTPProbe(transport http.RoundTripper, scheme string, cnf *pb.HealthCheck_Http) ProbeFunc {
+	expected := int(cnf.GetExpectedStatus())
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+	client := &http.Client{Transport: transport}
+	return func(ctx context.Context, addr string) error {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s://%s%s", scheme, addr, cnf.GetPath()), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			return errors.Errorf("health check returned status %d, expected %d", resp.StatusCode, expected)
+		}
+		return nil
+	}
+}
+
+type grpcProbe struct {
+	cnf      *pb.HealthCheck_Grpc
+	dialOpts []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewGRPCProbe returns Prober that calls grpc.health.v1.Health/Check on the target. A single connection, dialed with the
+// given dial options, is kept for every target as long as the target is checked.
+func NewGRPCProbe(cnf *pb.HealthCheck_Grpc, dialOpts ...grpc.DialOption) Prober {
+	return &grpcProbe{
+		cnf:      cnf,
+		dialOpts: dialOpts,
+		conns:    map[string]*grpc.ClientConn{},
+	}
+}
+
+func (p *grpcProbe) Probe(ctx context.Context, addr string) error {
+	cc, err := p.conn(ctx, addr)
+	if err != nil {
+		return err
+	}
+	resp, err := grpc_health_v1.NewHealthClient(cc).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: p.cnf.GetService()}, grpc.FailFast(false))
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return errors.Errorf("health check returned %s status", resp.Status)
+	}
+	return nil
+}
+
+func (p *grpcProbe) conn(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ctx.Err() != nil {
+		// Do not dial for targets that are already forgotten.
+		return nil, ctx.Err()
+	}
+	if cc, ok := p.conns[addr]; ok {
+		return cc, nil
+	}
+	// Dial does not block, the connection is established by the first check.
+	cc, err := grpc.Dial(addr, p.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[addr] = cc
+	return cc, nil
+}
+
+func (p *grpcProbe) Forget(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cc, ok := p.conns[addr]; ok {
+		cc.Close()
+		delete(p.conns, addr)
+	}
+}
+
+type resolver struct {
+	backendName string
+	parent      resolvers.Resolver
+	probe       Prober
+	opts        Options
+}
+
+// New returns resolvers.Resolver that actively checks health of every address resolved by parent.
+// Watchers returned by it mark unhealthy addresses as not ready, so load balancers (lbtransport and gRPC balancer)
+// never pick them. Newly resolved addresses are assumed healthy until proven otherwise.
+//
+// Health of targets is shared by all watchers of the same backend, so a backend re-created on config change keeps the
+// health of its targets.
+func New(backendName string, parent resolvers.Resolver, probe Prober, opts Options) resolvers.Resolver {
+	return &resolver{
+		backendName: backendName,
+		parent:      parent,
+		probe:       probe,
+		opts:        opts,
+	}
+}
+
+func (r *resolver) Resolve(target string) (resolvers.Watcher, error) {
+	parentWatcher, err := r.parent.Resolve(target)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		resolver: r,
+		parent:   parentWatcher,
+		ctx:      ctx,
+		cancel:   cancel,
+		updatesC: make(chan []resolvers.Address, 1),
+		errC:     make(chan error, 1),
+		checks:   map[string]*check{},
+	}
+	w.health = acquireBackendHealth(r.backendName, w)
+	go w.run()
+	return w, nil
+}
+
+var (
+	backendHealthsMu sync.Mutex
+	backendHealths   = map[string]*backendHealth{}
+)
+
+// backendHealth is the health of targets of a single backend, shared by all its watchers. Its lock guards the state of
+// the watchers as well.
+type backendHealth struct {
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+	targets  map[string]*targetHealth
+}
+
+type targetHealth struct {
+	healthy   bool
+	successes int
+	failures  int
+	// checkers is the number of watchers checking the target.
+	checkers int
+}
+
+func acquireBackendHealth(backendName string, w *watcher) *backendHealth {
+	backendHealthsMu.Lock()
+	defer backendHealthsMu.Unlock()
+	h, ok := backendHealths[backendName]
+	if !ok {
+		h = &backendHealth{watchers: map[*watcher]struct{}{}, targets: map[string]*targetHealth{}}
+		backendHealths[backendName] = h
+	}
+	h.mu.Lock()
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+	return h
+}
+
+// releaseBackendHealth forgets the watcher and health of targets no longer checked by any watcher of the backend.
+func releaseBackendHealth(backendName string, w *watcher) {
+	backendHealthsMu.Lock()
+	defer backendHealthsMu.Unlock()
+	h := backendHealths[backendName]
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for addr, c := range w.checks {
+		c.cancel()
+		w.probe.Forget(addr)
+		h.targets[addr].checkers--
+	}
+	w.checks = map[string]*check{}
+	delete(h.watchers, w)
+	h.forgetUnchecked(backendName)
+	if len(h.watchers) == 0 {
+		delete(backendHealths, backendName)
+	}
+}
+
+// forgetUnchecked forgets health of targets that are not checked by any watcher. It waits until all watchers resolved
+// their targets, so a watcher that replaces another one finds the health of the targets it is going to check. Must be
+// called with the lock held.
+func (h *backendHealth) forgetUnchecked(backendName string) {
+	for w := range h.watchers {
+		if !w.resolved {
+			return
+		}
+	}
+	for addr, t := range h.targets {
+		if t.checkers == 0 {
+			delete(h.targets, addr)
+			targetHealthyGauge.DeleteLabelValues(backendName, addr)
+		}
+	}
+}
+
+type check struct {
+	cancel context.CancelFunc
+}
+
+type watcher struct {
+	*resolver
+
+	parent   resolvers.Watcher
+	ctx      context.Context
+	cancel   context.CancelFunc
+	updatesC chan []resolvers.Address
+	errC     chan error
+
+	health *backendHealth
+	// Guarded by health.mu.
+	resolved bool
+	addrs    []resolvers.Address
+	checks   map[string]*check
+}
+
+func (w *watcher) Next() ([]resolvers.Address, error) {
+	select {
+	case addrs := <-w.updatesC:
+		return addrs, nil
+	case err := <-w.errC:
+		return nil, err
+	case <-w.ctx.Done():
+		return nil, errors.New("health watcher closed")
+	}
+}
+
+func (w *watcher) Close() {
+	w.cancel()
+	w.parent.Close()
+}
+
+func (w *watcher) run() {
+	defer releaseBackendHealth(w.backendName, w)
+
+	for w.ctx.Err() == nil {
+		addrs, err := w.parent.Next()
+		if err != nil {
+			w.errC <- err
+			return
+		}
+		w.update(addrs)
+	}
+}
+
+// update starts checking newly resolved addresses and stops checking the ones that are gone.
+func (w *watcher) update(addrs []resolvers.Address) {
+	h := w.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resolved := map[string]struct{}{}
+	for _, a := range addrs {
+		resolved[a.Addr] = struct{}{}
+		if _, ok := w.checks[a.Addr]; ok {
+			continue
+		}
+		t, ok := h.targets[a.Addr]
+		if !ok {
+			t = &targetHealth{healthy: true}
+			h.targets[a.Addr] = t
+			targetHealthyGauge.WithLabelValues(w.backendName, a.Addr).Set(1)
+		}
+		t.checkers++
+		ctx, cancel := context.WithCancel(w.ctx)
+		c := &check{cancel: cancel}
+		w.checks[a.Addr] = c
+		go w.check(ctx, a.Addr, c)
+	}
+	for addr, c := range w.checks {
+		if _, ok := resolved[addr]; ok {
+			continue
+		}
+		c.cancel()
+		w.probe.Forget(addr)
+		delete(w.checks, addr)
+		h.targets[addr].checkers--
+	}
+	w.resolved = true
+	h.forgetUnchecked(w.backendName)
+	w.addrs = addrs
+	w.publish()
+}
+
+// publish passes the current addresses with their health applied to Next. Only the newest addresses are kept if Next
+// is not called in the meantime. It is called under lock to keep the order of updates.
+func (w *watcher) publish() {
+	addrs := make([]resolvers.Address, len(w.addrs))
+	for i, a := range w.addrs {
+		addrs[i] = a
+		if t, ok := w.health.targets[a.Addr]; ok && !t.healthy {
+			addrs[i].Ready = false
+		}
+	}
+	select {
+	case <-w.updatesC:
+	default:
+	}
+	w.updatesC <- addrs
+}
+
+// check probes the target until ctx is canceled and reports changes of its health.
+func (w *watcher) check(ctx context.Context, addr string, c *check) {
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, w.opts.Timeout)
+		err := w.probe.Probe(probeCtx, addr)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		checksCounter.WithLabelValues(w.backendName, result).Inc()
+
+		w.record(addr, c, err == nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.Interval):
+		}
+	}
+}
+
+// record records the result of a single check and publishes addresses of all watchers of the backend if health of the
+// target changed.
+func (w *watcher) record(addr string, c *check, success bool) {
+	h := w.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if w.checks[addr] != c {
+		// Target was removed in the meantime.
+		return
+	}
+
+	t := h.targets[addr]
+	if success {
+		t.successes++
+		t.failures = 0
+	} else {
+		t.failures++
+		t.successes = 0
+	}
+
+	changed := false
+	if t.healthy && t.failures >= w.opts.UnhealthyThreshold {
+		t.healthy, changed = false, true
+	} else if !t.healthy && t.successes >= w.opts.HealthyThreshold {
+		t.healthy, changed = true, true
+	}
+
+	// Gauge is set on every result, so it is never left missing for a checked target.
+	if t.healthy {
+		targetHealthyGauge.WithLabelValues(w.backendName, addr).Set(1)
+	} else {
+		targetHealthyGauge.WithLabelValues(w.backendName, addr).Set(0)
+	}
+	if changed {
+		for other := range h.watchers {
+			other.publish()
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package healthresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakeResolver struct {
+	updatesC  chan []resolvers.Address
+	closeOnce sync.Once
+	closedC   chan struct{}
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{updatesC: make(chan []resolvers.Address, 1), closedC: make(chan struct{})}
+}
+
+func (r *fakeResolver) Resolve(target string) (resolvers.Watcher, error) {
+	return r, nil
+}
+
+func (r *fakeResolver) Next() ([]resolvers.Address, error) {
+	select {
+	case u := <-r.updatesC:
+		return u, nil
+	case <-r.closedC:
+		return nil, errors.New("closed")
+	}
+}
+
+func (r *fakeResolver) Close() {
+	r.closeOnce.Do(func() { close(r.closedC) })
+}
+
+type fakeProbe struct {
+	mu        sync.Mutex
+	unhealthy map[string]bool
+}
+
+func (p *fakeProbe) setUnhealthy(addr string, unhealthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unhealthy[addr] = unhealthy
+}
+
+func (p *fakeProbe) probe(_ context.Context, addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unhealthy[addr] {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+func nextWithTimeout(t *testing.T, w resolvers.Watcher) []resolvers.Address {
+	resC := make(chan []resolvers.Address, 1)
+	go func() {
+		u, err := w.Next()
+		require.NoError(t, err)
+		resC <- u
+	}()
+	select {
+	case u := <-resC:
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for update")
+		return nil
+	}
+}
+
+func addrs(readiness ...bool) []resolvers.Address {
+	var ret []resolvers.Address
+	for i, ready := range readiness {
+		ret = append(ret, resolvers.Address{Addr: fmt.Sprintf("1.1.1.%d:80", i+1), Weight: 1, Ready: ready})
+	}
+	return ret
+}
+
+func TestHealthResolver_MarksUnhealthyTargetsNotReady(t *testing.T) {
+	parent := newFakeResolver()
+	probe := &fakeProbe{unhealthy: map[string]bool{"1.1.1.2:80": true}}
+	r := New("backend", parent, ProbeFunc(probe.probe), Options{
+		Interval:           50 * time.Millisecond,
+		Timeout:            time.Second,
+		HealthyThreshold:   2,
+		UnhealthyThreshold: 2,
+	})
+
+	w, err := r.Resolve("target")
+	require.NoError(t, err)
+	defer w.Close()
+
+	parent.updatesC <- addrs(true, true)
+	assert.Equal(t, addrs(true, true), nextWithTimeout(t, w), "new targets should be assumed healthy")
+	assert.Equal(t, addrs(true, false), nextWithTimeout(t, w))
+
+	probe.setUnhealthy("1.1.1.2:80", false)
+	assert.Equal(t, addrs(true, true), nextWithTimeout(t, w))
+
+	// Targets not ready according to the parent stay not ready.
+	parent.updatesC <- addrs(false, true)
+	assert.Equal(t, addrs(false, true), nextWithTimeout(t, w))
+
+	parent.updatesC <- addrs(true)
+	assert.Equal(t, addrs(true), nextWithTimeout(t, w))
+}
+
+// healthyGauge returns the value of the target health gauge and whether it exists.
+func healthyGauge(backendName string, addr string) (float64, bool) {
+	ch := make(chan prometheus.Metric, 100)
+	targetHealthyGauge.Collect(ch)
+	close(ch)
+	for metric := range ch {
+		m := &dto.Metric{}
+		metric.Write(m)
+		labels := map[string]string{}
+		for _, l := range m.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["backend"] == backendName && labels["target"] == addr {
+			return m.GetGauge().GetValue(), true
+		}
+	}
+	return 0, false
+}
+
+func waitForWatchers(t *testing.T, backendName string, count int) {
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		backendHealthsMu.Lock()
+		n := 0
+		if h, ok := backendHealths[backendName]; ok {
+			h.mu.Lock()
+			n = len(h.watchers)
+			h.mu.Unlock()
+		}
+		backendHealthsMu.Unlock()
+		if n == count {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %d watchers of %s", count, backendName)
+}
+
+func TestHealthResolver_KeepsHealthWhenBackendIsRecreated(t *testing.T) {
+	probe := &fakeProbe{unhealthy: map[string]bool{"1.1.1.1:80": true}}
+	opts := Options{
+		Interval:           50 * time.Millisecond,
+		Timeout:            time.Second,
+		HealthyThreshold:   1,
+		UnhealthyThreshold: 2,
+	}
+
+	oldParent := newFakeResolver()
+	oldWatcher, err := New("recreated", oldParent, ProbeFunc(probe.probe), opts).Resolve("target")
+	require.NoError(t, err)
+	oldParent.updatesC <- addrs(true)
+	assert.Equal(t, addrs(true), nextWithTimeout(t, oldWatcher))
+	assert.Equal(t, addrs(false), nextWithTimeout(t, oldWatcher))
+
+	// Backend pools build the new backend before closing the old one.
+	newParent := newFakeResolver()
+	newWatcher, err := New("recreated", newParent, ProbeFunc(probe.probe), opts).Resolve("target")
+	require.NoError(t, err)
+	oldWatcher.Close()
+	waitForWatchers(t, "recreated", 1)
+
+	value, ok := healthyGauge("recreated", "1.1.1.1:80")
+	require.True(t, ok, "gauge should survive closing the old backend")
+	assert.Equal(t, 0.0, value)
+
+	newParent.updatesC <- addrs(true)
+	assert.Equal(t, addrs(false), nextWithTimeout(t, newWatcher), "target known to be unhealthy should stay not ready")
+
+	probe.setUnhealthy("1.1.1.1:80", false)
+	assert.Equal(t, addrs(true), nextWithTimeout(t, newWatcher))
+	value, ok = healthyGauge("recreated", "1.1.1.1:80")
+	require.True(t, ok)
+	assert.Equal(t, 1.0, value)
+
+	newWatcher.Close()
+	waitForWatchers(t, "recreated", 0)
+	_, ok = healthyGauge("recreated", "1.1.1.1:80")
+	assert.False(t, ok, "gauge should be removed with the last watcher of the backend")
+}
+
+func TestGRPCProbe_KeepsConnectionPerTarget(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, healthSrv)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	var mu sync.Mutex
+	dials := 0
+	probe := NewGRPCProbe(&pb.HealthCheck_Grpc{}, grpc.WithInsecure(), grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+		mu.Lock()
+		dials++
+		mu.Unlock()
+		return net.DialTimeout("tcp", addr, timeout)
+	}))
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		require.NoError(t, probe.Probe(ctx, lis.Addr().String()))
+		cancel()
+	}
+	mu.Lock()
+	assert.Equal(t, 1, dials, "checks of the same target should reuse the connection")
+	mu.Unlock()
+
+	probe.Forget(lis.Addr().String())
+	assert.Empty(t, probe.(*grpcProbe).conns)
+}
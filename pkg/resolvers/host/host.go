@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"net"
 
-	"github.com/improbable-eng/go-srvlb/grpc"
 	"github.com/improbable-eng/go-srvlb/srv"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/improbable-eng/kedge/pkg/resolvers/srv"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
-	"google.golang.org/grpc/naming"
 )
 
 type hostResolverFn func(host string) (addrs []string, err error)
@@ -16,9 +16,11 @@ var (
 	ParentHostResolver hostResolverFn = net.LookupHost
 )
 
-func NewFromConfig(conf *pb.HostResolver) (target string, namer naming.Resolver, err error) {
+// NewFromConfig returns resolver looking up A records of the host. Records carry no TTL, so they are looked up every
+// srvresolver.MinimumRefreshInterval.
+func NewFromConfig(conf *pb.HostResolver) (target string, r resolvers.Resolver, err error) {
 	parent := ParentHostResolver
-	return conf.GetDnsName(), grpcsrvlb.New(newHostResolver(conf.Port, parent)), nil
+	return conf.GetDnsName(), srvresolver.New(newHostResolver(conf.Port, parent)), nil
 }
 
 type hostResolver struct {
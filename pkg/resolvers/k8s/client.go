@@ -2,12 +2,15 @@ package k8sresolver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/improbable-eng/kedge/pkg/k8s"
 	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
 )
 
 type endpointClient interface {
@@ -31,6 +34,27 @@ func (c *client) StartChangeStream(ctx context.Context, t targetEntry) (io.ReadC
 	return c.startGET(ctx, epWatchURL)
 }
 
+// NodeLabels returns labels of the node.
+// See https://kubernetes.io/docs/api-reference/v1.7/#read-100
+func (c *client) NodeLabels(ctx context.Context, nodeName string) (map[string]string, error) {
+	nodeURL := fmt.Sprintf("%s/api/v1/nodes/%s", c.k8sClient.Address, nodeName)
+
+	body, err := c.startGET(ctx, nodeURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = ioutil.ReadAll(body)
+		body.Close()
+	}()
+
+	var node v1.Node
+	if err := json.NewDecoder(body).Decode(&node); err != nil {
+		return nil, errors.Wrapf(err, "Failed to decode node %s", nodeName)
+	}
+	return node.Labels, nil
+}
+
 // NOTE: It is caller responsibility to read body through and close it.
 func (c *client) startGET(ctx context.Context, url string) (io.ReadCloser, error) {
 	req, err := http.NewRequest("GET", url, nil)
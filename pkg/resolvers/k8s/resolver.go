@@ -8,10 +8,10 @@ import (
 	"strings"
 
 	"github.com/improbable-eng/kedge/pkg/k8s"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/grpc/naming"
 )
 
 const (
@@ -51,9 +51,11 @@ func init() {
 type resolver struct {
 	cl     *client
 	logger logrus.FieldLogger
+	// zones is nil if zones of nodes are not looked up.
+	zones *nodeZones
 }
 
-func NewFromFlags(logger logrus.FieldLogger) (name naming.Resolver, err error) {
+func NewFromFlags(logger logrus.FieldLogger) (r resolvers.Resolver, err error) {
 	apiClient, err := k8s.NewFromFlags()
 	if err != nil {
 		return nil, err
@@ -62,13 +64,17 @@ func NewFromFlags(logger logrus.FieldLogger) (name naming.Resolver, err error) {
 }
 
 // NewWithClient returns a new Kubernetes resolver using given k8s.APIClient configured to be used against kube-apiserver.
-func NewWithClient(logger logrus.FieldLogger, apiClient *k8s.APIClient) naming.Resolver {
-	return &resolver{
+func NewWithClient(logger logrus.FieldLogger, apiClient *k8s.APIClient) resolvers.Resolver {
+	r := &resolver{
 		cl: &client{
 			k8sClient: apiClient,
 		},
 		logger: logger,
 	}
+	if *flagNodeZonesEnabled {
+		r.zones = newNodeZones(r.cl, logger)
+	}
+	return r
 }
 
 type targetPort struct {
@@ -132,7 +138,7 @@ func hasSchema(targetName string) bool {
 
 // Resolve creates a Kubernetes watcher for the targetEntry.
 // It expects targetEntry in a form of usual k8s DNS entry. See const 'ExpectedTargetFmt'.
-func (r *resolver) Resolve(target string) (naming.Watcher, error) {
+func (r *resolver) Resolve(target string) (resolvers.Watcher, error) {
 	t, err := parseTarget(target)
 	if err != nil {
 		return nil, err
@@ -143,6 +149,7 @@ func (r *resolver) Resolve(target string) (naming.Watcher, error) {
 		r.logger,
 		t,
 		r.cl,
+		r.zones,
 		resolvedAddrs.WithLabelValues(target),
 		watcherErrs.WithLabelValues(target),
 		watcherGotChanges.WithLabelValues(target),
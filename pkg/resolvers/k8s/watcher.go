@@ -3,18 +3,17 @@ package k8sresolver
 import (
 	"context"
 	"fmt"
-	"net"
-
 	"io"
-
+	"net"
+	"sort"
 	"time"
 
+	"github.com/improbable-eng/kedge/pkg/resolvers"
 	"github.com/improbable-eng/kedge/pkg/sharedflags"
 	"github.com/jpillora/backoff"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/grpc/naming"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/watch"
 )
@@ -30,7 +29,8 @@ var (
 
 // A Watcher provides name resolution updates by watching endpoints API.
 // It works by watching endpoint Watch API (retries if connection broke). Returned events with
-// changes inside endpoints are translated to resolution naming.Updates.
+// changes inside endpoints are translated to the current set of addresses. Not ready endpoints are included with
+// Ready set to false.
 type watcher struct {
 	logger logrus.FieldLogger
 
@@ -38,8 +38,11 @@ type watcher struct {
 	cancel   context.CancelFunc
 	target   targetEntry
 	epClient endpointClient
+	zones    *nodeZones
 
-	addrsState map[string]struct{}
+	addrsState map[string]resolvers.Address
+	// resolved is true once the initial set of addresses was returned.
+	resolved bool
 
 	resolvedAddrs     prometheus.Gauge
 	watcherErrs       prometheus.Counter
@@ -53,6 +56,7 @@ func startNewWatcher(
 	logger logrus.FieldLogger,
 	target targetEntry,
 	epClient endpointClient,
+	zones *nodeZones,
 	resolvedAddrs prometheus.Gauge,
 	watcherErrs prometheus.Counter,
 	watcherGotChanges prometheus.Counter,
@@ -64,7 +68,8 @@ func startNewWatcher(
 		cancel:            cancel,
 		target:            target,
 		epClient:          epClient,
-		addrsState:        map[string]struct{}{},
+		zones:             zones,
+		addrsState:        map[string]resolvers.Address{},
 		resolvedAddrs:     resolvedAddrs,
 		watcherErrs:       watcherErrs,
 		watcherGotChanges: watcherGotChanges,
@@ -111,7 +116,7 @@ func (w *watcher) startNewStreamerWithRetry(ctx context.Context) *streamer {
 
 // Next updates the endpoints for the targetEntry being watched.
 // As from Watcher interface: It should return an error if and only if Watcher cannot recover.
-func (w *watcher) Next() ([]*naming.Update, error) {
+func (w *watcher) Next() ([]resolvers.Address, error) {
 	if w.ctx.Err() != nil {
 		// We already stopped.
 		return nil, errors.Wrap(w.ctx.Err(), "k8sresolver: watcher.Next already stopped or Next returned error already. "+
@@ -124,11 +129,11 @@ func (w *watcher) Next() ([]*naming.Update, error) {
 			w.streamer = w.startNewStreamerWithRetry(w.ctx)
 		}
 
-		u, err := w.next(w.ctx)
+		addrs, err := w.next(w.ctx)
 		if err == nil {
 			// No error.
-			w.resolvedAddrs.Set(float64(len(w.addrsState)))
-			return u, nil
+			w.resolvedAddrs.Set(float64(len(resolvers.Ready(addrs))))
+			return addrs, nil
 		}
 
 		if w.ctx.Err() != nil {
@@ -148,19 +153,18 @@ func (w *watcher) Next() ([]*naming.Update, error) {
 	return nil, w.ctx.Err()
 }
 
-// next gathers kube api endpoint watch changes and translates them to naming.Update set.
-// The main complexity is fact that naming.Update can be either Add or Delete. However kube events can be Add,
-// Delete or Modified. As a result we are required to maintain state.
-// If the state is malformed we immediately return error which will resync for our streamer.
-func (w *watcher) next(ctx context.Context) ([]*naming.Update, error) {
+// next gathers kube api endpoint watch changes and returns the new set of addresses once it differs from the
+// previous one (or for the first change).
+// We watch strictly for single service, thus we assume single "endpoints" object all the time. This way we can
+// safely treat the object for Added and Modified as new state. Deleted event gives state before deletion, which needs
+// to match the tracked state. If the state is malformed we immediately return error which will resync for our streamer.
+func (w *watcher) next(ctx context.Context) ([]resolvers.Address, error) {
 	var (
-		updates         []*naming.Update
 		change          change
 		changeCh, errCh = w.streamer.ResultChans()
-		newAddrsState   = map[string]struct{}{}
 	)
 
-	for len(updates) == 0 {
+	for {
 		select {
 		case <-ctx.Done():
 			// We already stopped.
@@ -176,9 +180,10 @@ func (w *watcher) next(ctx context.Context) ([]*naming.Update, error) {
 		}
 		w.watcherGotChanges.Inc()
 
+		newAddrsState := map[string]resolvers.Address{}
 		for _, subset := range change.Subsets {
 			var err error
-			newAddrsState, err = subsetToAddresses(w.target, subset)
+			newAddrsState, err = subsetToAddresses(w.target, subset, w.zones)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to convert k8s endpoint subset to update Addr")
 			}
@@ -191,80 +196,73 @@ func (w *watcher) next(ctx context.Context) ([]*naming.Update, error) {
 			// Target port not found yet. Maybe other subsets includes target one?
 		}
 
-		// We watch strictly for single service, thus we assume single "endpoints" object all the time.
-		// This way we can safely treat the object for Added and Modified as new state.
-		// Deleted event gives state before deletion, but we don't care. The whole object was deleted.
 		switch change.typ {
 		case watch.Modified, watch.Added:
-			for addr := range newAddrsState {
-				_, ok := w.addrsState[addr]
-				if ok {
-					// Address already exists in old state, nothing to do.
-					continue
-				}
-
-				// Address does not exists in old state, let's add it.
-				updates = append(updates, w.addAddr(addr))
-			}
-
-			for addr := range w.addrsState {
-				_, ok := newAddrsState[addr]
-				if ok {
-					// Address exists in new state, nothing to do.
-					continue
-				}
-
-				// Address does not exists in new state, let's remove it.
-				updates = append(updates, w.delAddr(addr))
-			}
 		case watch.Deleted:
-			if len(w.addrsState) != len(newAddrsState) {
+			if !sameAddrs(w.addrsState, newAddrsState) {
 				return nil, errors.Errorf("malformed internal state for addresses for target %v. "+
 					"We got delete event type with state before deletion and it does not match with that we tracked %v. "+
-					"State before deletion %v. Doing resync...", w.target, w.addrsState, newAddrsState)
-			}
-
-			for addr := range w.addrsState {
-				_, ok := newAddrsState[addr]
-				if !ok {
-					return nil, errors.Errorf("malformed internal state for addresses for target %v. "+
-						"We got delete event type with state before deletion and it does not match with that we tracked %v. "+
-						"State before deletion %v. Doing resync...", w.target, w.addrsState, newAddrsState)
-				}
-
-				updates = append(updates, w.delAddr(addr))
+					"State before deletion %v. Doing resync...", w.target, sortedAddrs(w.addrsState), sortedAddrs(newAddrsState))
 			}
+			newAddrsState = map[string]resolvers.Address{}
 		default:
 			return nil, errors.Errorf("unexpected change type %v", change.typ)
 		}
-	}
 
-	return updates, nil
+		if w.resolved && sameAddrs(w.addrsState, newAddrsState) {
+			// No change.
+			continue
+		}
+		w.resolved = true
+		w.addrsState = newAddrsState
+
+		addrs := make([]resolvers.Address, 0, len(newAddrsState))
+		for _, addr := range sortedAddrs(newAddrsState) {
+			addrs = append(addrs, newAddrsState[addr])
+		}
+		return addrs, nil
+	}
 }
 
-func (w *watcher) addAddr(addr string) *naming.Update {
-	w.addrsState[addr] = struct{}{}
-	return &naming.Update{Op: naming.Add, Addr: addr}
+func sameAddrs(a map[string]resolvers.Address, b map[string]resolvers.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for addr, attrs := range a {
+		if other, ok := b[addr]; !ok || other != attrs {
+			return false
+		}
+	}
+	return true
 }
 
-func (w *watcher) delAddr(addr string) *naming.Update {
-	delete(w.addrsState, addr)
-	return &naming.Update{Op: naming.Delete, Addr: addr}
+func sortedAddrs(state map[string]resolvers.Address) []string {
+	addrs := make([]string, 0, len(state))
+	for addr := range state {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
 }
 
-func subsetToAddresses(t targetEntry, sub v1.EndpointSubset) (map[string]struct{}, error) {
+func subsetToAddresses(t targetEntry, sub v1.EndpointSubset, zones *nodeZones) (map[string]resolvers.Address, error) {
 	port, found, err := matchTargetPort(t.port, sub.Ports)
 	if err != nil {
 		return nil, err
 	}
 
-	addrs := map[string]struct{}{}
+	addrs := map[string]resolvers.Address{}
 	if !found {
 		return nil, nil
 	}
 
 	for _, address := range sub.Addresses {
-		addrs[net.JoinHostPort(address.IP, port)] = struct{}{}
+		addr := net.JoinHostPort(address.IP, port)
+		addrs[addr] = resolvers.Address{Addr: addr, Zone: zones.zone(address.NodeName), Weight: 1, Ready: true}
+	}
+	for _, address := range sub.NotReadyAddresses {
+		addr := net.JoinHostPort(address.IP, port)
+		addrs[addr] = resolvers.Address{Addr: addr, Zone: zones.zone(address.NodeName), Weight: 1, Ready: false}
 	}
 	return addrs, nil
 }
@@ -2,15 +2,13 @@ package k8sresolver
 
 import (
 	"context"
-	"sort"
-	"strings"
 	"testing"
 	"time"
 
 	"github.com/fortytw2/leaktest"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
-	"google.golang.org/grpc/naming"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/watch"
 )
@@ -69,6 +67,24 @@ var (
 			},
 		},
 	}
+	notReadyAddrSubset = v1.EndpointSubset{
+		Ports: []v1.EndpointPort{
+			{
+				Port: 8080,
+				Name: "someName",
+			},
+		},
+		Addresses: []v1.EndpointAddress{
+			{
+				IP: "1.2.3.4",
+			},
+		},
+		NotReadyAddresses: []v1.EndpointAddress{
+			{
+				IP: "1.2.3.6",
+			},
+		},
+	}
 	modifiedMultipleAddrSubset = v1.EndpointSubset{
 		Ports: []v1.EndpointPort{
 			{
@@ -88,70 +104,68 @@ var (
 	}
 )
 
+func ready(addrs ...string) []resolvers.Address {
+	ret := []resolvers.Address{}
+	for _, addr := range addrs {
+		ret = append(ret, resolvers.Address{Addr: addr, Weight: 1, Ready: true})
+	}
+	return ret
+}
+
 func TestWatcher_Next_OK(t *testing.T) {
 	for _, tcase := range []struct {
 		watchedTargetPort targetPort
 		changes           []change
 		err               error
-		expectedUpdates   [][]*naming.Update
-		expectedErrs      []error
+		// nil means no change is expected.
+		expectedAddrs [][]resolvers.Address
+		expectedErrs  []error
 	}{
 		// Tests for subsetToAddresses function.
 		{
 			watchedTargetPort: targetPort{},
 			changes:           []change{newTestChange(watch.Added, testAddr1)},
+			expectedAddrs:     [][]resolvers.Address{{}},
 			expectedErrs: []error{errors.New("failed to convert k8s endpoint subset to update Addr: we got " +
 				"[{someName 8080 } {someName1 8081 } {someName2 8082 }] ports and target port is not specified. Don't know what to choose")},
 		},
 		{
 			watchedTargetPort: targetPort{},
 			changes:           []change{newTestChange(watch.Added, modifiedAddr1)},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.5:8080",
-						Op:   naming.Add,
-					},
-				},
-			},
+			expectedAddrs:     [][]resolvers.Address{ready("1.2.3.5:8080")},
 		},
 		{
 			watchedTargetPort: targetPort{isNamed: true, value: "someName2"},
 			changes:           []change{newTestChange(watch.Added, testAddr1)},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8082",
-						Op:   naming.Add,
-					},
-				},
-			},
+			expectedAddrs:     [][]resolvers.Address{ready("1.2.3.4:8082")},
 		},
 		{
 			watchedTargetPort: targetPort{value: "8081"},
 			changes:           []change{newTestChange(watch.Added, testAddr1)},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8081",
-						Op:   naming.Add,
-					},
-				},
-			},
+			expectedAddrs:     [][]resolvers.Address{ready("1.2.3.4:8081")},
 		},
 		{
 			// Non existing port just return no IPs. This makes configuration bit harder to debug, but we cannot assume
 			// port is always in any subset.
 			watchedTargetPort: targetPort{value: "no-such-number"},
 			changes:           []change{newTestChange(watch.Added, testAddr1)},
-			expectedUpdates:   [][]*naming.Update{nil},
+			expectedAddrs:     [][]resolvers.Address{{}},
 		},
 		{
 			// Non existing named port just return no IPs. This makes configuration bit harder to debug, but we cannot assume
 			// port is always in any subset.
 			watchedTargetPort: targetPort{isNamed: true, value: "non-existing-port-name"},
 			changes:           []change{newTestChange(watch.Added, testAddr1)},
-			expectedUpdates:   [][]*naming.Update{nil},
+			expectedAddrs:     [][]resolvers.Address{{}},
+		},
+		{
+			// Not ready addresses are returned, but marked as such.
+			watchedTargetPort: targetPort{isNamed: true, value: "someName"},
+			changes:           []change{newTestChange(watch.Added, notReadyAddrSubset)},
+			expectedAddrs: [][]resolvers.Address{{
+				{Addr: "1.2.3.4:8080", Weight: 1, Ready: true},
+				{Addr: "1.2.3.6:8080", Weight: 1, Ready: false},
+			}},
 		},
 		// Watcher next() tests:
 		{
@@ -160,23 +174,9 @@ func TestWatcher_Next_OK(t *testing.T) {
 				newTestChange(watch.Added, testAddr1),
 				newTestChange(watch.Modified, modifiedAddr1),
 			},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Add,
-					},
-				},
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Delete,
-					},
-					{
-						Addr: "1.2.3.5:8080",
-						Op:   naming.Add,
-					},
-				},
+			expectedAddrs: [][]resolvers.Address{
+				ready("1.2.3.4:8080"),
+				ready("1.2.3.5:8080"),
 			},
 		},
 		{
@@ -187,46 +187,11 @@ func TestWatcher_Next_OK(t *testing.T) {
 				newTestChange(watch.Modified, modifiedMultipleAddrSubset),
 				newTestChange(watch.Deleted, modifiedMultipleAddrSubset),
 			},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.3:8080",
-						Op:   naming.Add,
-					},
-					{
-						Addr: "1.2.4.4:8080",
-						Op:   naming.Add,
-					},
-					{
-						Addr: "1.2.5.5:8080",
-						Op:   naming.Add,
-					},
-				},
+			expectedAddrs: [][]resolvers.Address{
+				ready("1.2.3.3:8080", "1.2.4.4:8080", "1.2.5.5:8080"),
 				nil, // No change.
-				{
-					{
-						Addr: "1.2.4.4:8080",
-						Op:   naming.Delete,
-					},
-					{
-						Addr: "1.2.4.5:8080",
-						Op:   naming.Add,
-					},
-					{
-						Addr: "1.2.5.5:8080",
-						Op:   naming.Delete,
-					},
-				},
-				{
-					{
-						Addr: "1.2.3.3:8080",
-						Op:   naming.Delete,
-					},
-					{
-						Addr: "1.2.4.5:8080",
-						Op:   naming.Delete,
-					},
-				},
+				ready("1.2.3.3:8080", "1.2.4.5:8080"),
+				ready(),
 			},
 		},
 		{
@@ -237,25 +202,10 @@ func TestWatcher_Next_OK(t *testing.T) {
 				newTestChange(watch.Deleted, testAddr1),
 				newTestChange(watch.Added, testAddr1),
 			},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Add,
-					},
-				},
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Delete,
-					},
-				},
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Add,
-					},
-				},
+			expectedAddrs: [][]resolvers.Address{
+				ready("1.2.3.4:8080"),
+				ready(),
+				ready("1.2.3.4:8080"),
 			},
 		},
 		{
@@ -264,14 +214,7 @@ func TestWatcher_Next_OK(t *testing.T) {
 				newTestChange(watch.Modified, testAddr1),
 			},
 			// It's hard to detect this case, so we just add the thing.
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Add,
-					},
-				},
-			},
+			expectedAddrs: [][]resolvers.Address{ready("1.2.3.4:8080")},
 		},
 		// Malformed state cases. We assume this order of events will never happen:
 		{
@@ -279,9 +222,10 @@ func TestWatcher_Next_OK(t *testing.T) {
 			changes: []change{
 				newTestChange(watch.Deleted, testAddr1),
 			},
+			expectedAddrs: [][]resolvers.Address{{}},
 			expectedErrs: []error{errors.New("malformed internal state for addresses for target {  " +
 				"{true someName}}. We got delete event type with state before deletion and it does not match with that " +
-				"we tracked map[]. State before deletion map[1.2.3.4:8080:{}]. Doing resync...")},
+				"we tracked []. State before deletion [1.2.3.4:8080]. Doing resync...")},
 		},
 		{
 			// This can happen (two adds) when we do resync.
@@ -290,13 +234,8 @@ func TestWatcher_Next_OK(t *testing.T) {
 				newTestChange(watch.Added, testAddr1),
 				newTestChange(watch.Added, testAddr1),
 			},
-			expectedUpdates: [][]*naming.Update{
-				{
-					{
-						Addr: "1.2.3.4:8080",
-						Op:   naming.Add,
-					},
-				},
+			expectedAddrs: [][]resolvers.Address{
+				ready("1.2.3.4:8080"),
 				nil,
 			},
 		},
@@ -317,7 +256,7 @@ func TestWatcher_Next_OK(t *testing.T) {
 					errCh:    errCh,
 					cancel:   func() {},
 				},
-				addrsState: map[string]struct{}{},
+				addrsState: map[string]resolvers.Address{},
 
 				resolvedAddrs:     resolvedAddrs.WithLabelValues(""),
 				watcherErrs:       watcherErrs.WithLabelValues(""),
@@ -326,23 +265,19 @@ func TestWatcher_Next_OK(t *testing.T) {
 
 			for i, change := range tcase.changes {
 				changeCh <- change
-				if len(tcase.expectedUpdates) > i && len(tcase.expectedUpdates[i]) == 0 {
-					// if 0 updates, then next will not give us anything.
+				if tcase.expectedAddrs[i] == nil {
+					// No change, so next will not give us anything.
 					continue
 				}
 
-				u, err := w.next(ctx)
+				addrs, err := w.next(ctx)
 				if len(tcase.expectedErrs) > i && tcase.expectedErrs[i] != nil {
 					require.Error(t, err)
 					require.Equal(t, tcase.expectedErrs[i].Error(), err.Error())
 					continue
 				}
 				require.NoError(t, err)
-
-				sort.Slice(u, func(i, j int) bool {
-					return strings.Compare(u[i].Addr, u[j].Addr) < 0
-				})
-				require.Equal(t, tcase.expectedUpdates[i], u, "case %d is wrong", i)
+				require.Equal(t, tcase.expectedAddrs[i], addrs, "case %d is wrong", i)
 			}
 		})
 		if !ok {
@@ -0,0 +1,85 @@
+package k8sresolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	flagNodeZonesEnabled = sharedflags.Set.Bool("k8sresolver_node_zones_enabled", false,
+		"If enabled, resolved addresses get the zone of the node hosting the endpoint (from topology.kubernetes.io/zone "+
+			"or failure-domain.beta.kubernetes.io/zone node label). Requires permission to get nodes.")
+
+	// nodeZoneRetryInterval is the time after which zone of the node is looked up again if the lookup failed.
+	nodeZoneRetryInterval = 1 * time.Minute
+	nodeZoneTimeout       = 5 * time.Second
+)
+
+var zoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+type nodeClient interface {
+	NodeLabels(ctx context.Context, nodeName string) (map[string]string, error)
+}
+
+// nodeZones looks up zones of nodes hosting endpoints. Zone of a node never changes, so zones are cached. Failed lookups
+// are repeated after nodeZoneRetryInterval.
+type nodeZones struct {
+	client nodeClient
+	logger logrus.FieldLogger
+
+	mu       sync.Mutex
+	zones    map[string]string
+	failedAt map[string]time.Time
+
+	// For testing purposes.
+	timeNow func() time.Time
+}
+
+func newNodeZones(client nodeClient, logger logrus.FieldLogger) *nodeZones {
+	return &nodeZones{
+		client:   client,
+		logger:   logger,
+		zones:    map[string]string{},
+		failedAt: map[string]time.Time{},
+		timeNow:  time.Now,
+	}
+}
+
+// zone returns the zone of the node or empty string if it is not known. It is safe to call on nil nodeZones.
+func (z *nodeZones) zone(nodeName *string) string {
+	if z == nil || nodeName == nil || *nodeName == "" {
+		return ""
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if zone, ok := z.zones[*nodeName]; ok {
+		return zone
+	}
+	if failedAt, ok := z.failedAt[*nodeName]; ok && z.timeNow().Sub(failedAt) < nodeZoneRetryInterval {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeZoneTimeout)
+	defer cancel()
+	labels, err := z.client.NodeLabels(ctx, *nodeName)
+	if err != nil {
+		z.logger.WithError(err).Warnf("k8sresolver: failed to get zone of node %s", *nodeName)
+		z.failedAt[*nodeName] = z.timeNow()
+		return ""
+	}
+	delete(z.failedAt, *nodeName)
+
+	var zone string
+	for _, label := range zoneLabels {
+		if zone = labels[label]; zone != "" {
+			break
+		}
+	}
+	z.zones[*nodeName] = zone
+	return zone
+}
@@ -0,0 +1,53 @@
+package k8sresolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type nodeClientMock struct {
+	labels map[string]map[string]string
+	err    error
+	calls  int
+}
+
+func (m *nodeClientMock) NodeLabels(_ context.Context, nodeName string) (map[string]string, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.labels[nodeName], nil
+}
+
+func TestNodeZones(t *testing.T) {
+	now := time.Now()
+	client := &nodeClientMock{labels: map[string]map[string]string{
+		"node-a": {"topology.kubernetes.io/zone": "zone-a"},
+		"node-b": {"failure-domain.beta.kubernetes.io/zone": "zone-b"},
+	}}
+	zones := newNodeZones(client, logrus.New())
+	zones.timeNow = func() time.Time { return now }
+	name := func(n string) *string { return &n }
+
+	assert.Equal(t, "zone-a", zones.zone(name("node-a")))
+	assert.Equal(t, "zone-b", zones.zone(name("node-b")))
+	assert.Equal(t, "zone-a", zones.zone(name("node-a")))
+	assert.Equal(t, 2, client.calls, "zones should be cached")
+	assert.Equal(t, "", zones.zone(nil))
+	assert.Equal(t, "", (*nodeZones)(nil).zone(name("node-a")))
+
+	client.err = errors.New("forbidden")
+	assert.Equal(t, "", zones.zone(name("node-c")))
+	assert.Equal(t, "", zones.zone(name("node-c")))
+	assert.Equal(t, 3, client.calls, "failed lookup should not be repeated immediately")
+
+	client.err = nil
+	client.labels["node-c"] = map[string]string{"topology.kubernetes.io/zone": "zone-c"}
+	now = now.Add(nodeZoneRetryInterval)
+	assert.Equal(t, "zone-c", zones.zone(name("node-c")))
+}
@@ -0,0 +1,59 @@
+// Package resolvers defines how backend targets are resolved into addresses. Resolvers (srv, k8s, host) and
+// resolver wrappers (health) live in subpackages. HTTP backends (lbtransport) and TCP backends consume them directly,
+// gRPC backends through the adapter in the grpc subpackage.
+package resolvers
+
+// Address is a single resolved address of a target together with its attributes.
+type Address struct {
+	// Addr is the ip:port to dial.
+	Addr string
+	// Zone is the locality (e.g. availability zone) of the address. Empty if unknown.
+	Zone string
+	// Weight is the relative amount of traffic the address should receive. Resolvers that do not know it use 1.
+	Weight uint32
+	// Ready is false for addresses that exist but should not receive traffic, e.g. not ready Kubernetes endpoints or
+	// addresses failing active health checks.
+	Ready bool
+}
+
+// Watcher watches addresses of a single target.
+type Watcher interface {
+	// Next blocks until the addresses of the target change and returns all of them, including not ready ones.
+	// The first call returns the initial resolution. Errors are irrecoverable.
+	Next() ([]Address, error)
+	// Close stops the watcher.
+	Close()
+}
+
+// Resolver creates watchers for targets.
+type Resolver interface {
+	Resolve(target string) (Watcher, error)
+}
+
+// Ready returns only ready addresses from the given ones.
+func Ready(addrs []Address) []Address {
+	var ready []Address
+	for _, a := range addrs {
+		if a.Ready {
+			ready = append(ready, a)
+		}
+	}
+	return ready
+}
+
+// Equal returns true if both sets of addresses contain the same addresses with the same attributes, in any order.
+func Equal(a []Address, b []Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byAddr := make(map[string]Address, len(a))
+	for _, addr := range a {
+		byAddr[addr.Addr] = addr
+	}
+	for _, addr := range b {
+		if existing, ok := byAddr[addr.Addr]; !ok || existing != addr {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package srvresolver
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/improbable-eng/go-srvlb/srv"
+	"github.com/pkg/errors"
+)
+
+// NewGoResolver returns srv.Resolver using net-package LookupSRV like srv.NewGoResolver, that also knows weights of the
+// SRV records. net-package does not expose TTL, so all targets have the given dummy TTL.
+func NewGoResolver(dummyTtl time.Duration) srv.Resolver {
+	return &goResolver{ttl: dummyTtl}
+}
+
+type goResolver struct {
+	ttl time.Duration
+}
+
+func (r *goResolver) Lookup(domainName string) ([]*srv.Target, error) {
+	weighted, err := r.LookupWeighted(domainName)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]*srv.Target, 0, len(weighted))
+	for _, t := range weighted {
+		targets = append(targets, t.Target)
+	}
+	return targets, nil
+}
+
+// LookupWeighted looks up SRV records and their hosts. Records with zero weight get weight 1, the smallest one.
+func (r *goResolver) LookupWeighted(domainName string) ([]*WeightedTarget, error) {
+	_, srvs, err := net.LookupSRV("", "", domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]*WeightedTarget, len(srvs))
+	var wg sync.WaitGroup
+	for i, s := range srvs {
+		wg.Add(1)
+		go func(i int, s *net.SRV) {
+			defer wg.Done()
+			addrs, err := net.LookupHost(s.Target)
+			if err != nil || len(addrs) == 0 {
+				return
+			}
+			weight := uint32(s.Weight)
+			if weight == 0 {
+				weight = 1
+			}
+			resolved[i] = &WeightedTarget{
+				Target: &srv.Target{Ttl: r.ttl, DialAddr: net.JoinHostPort(addrs[0], fmt.Sprintf("%d", s.Port))},
+				Weight: weight,
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	var targets []*WeightedTarget
+	for _, t := range resolved {
+		if t != nil {
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("failed resolving hostnames for SRV entries")
+	}
+	return targets, nil
+}
@@ -1,29 +1,162 @@
 package srvresolver
 
 import (
-	"time"
-
+	"context"
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
-	"github.com/improbable-eng/go-srvlb/grpc"
 	"github.com/improbable-eng/go-srvlb/srv"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
 	pb "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
-	"google.golang.org/grpc/naming"
+	"github.com/jonboulle/clockwork"
+	"github.com/jpillora/backoff"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
 )
 
 var (
-	ParentSrvResolver = srv.NewGoResolver(5 * time.Second)
+	ParentSrvResolver srv.Resolver = NewGoResolver(5 * time.Second)
+
+	// MinimumRefreshInterval is the maximum time between lookups, otherwise controlled by the TTL of records.
+	// Records without TTL are looked up with this interval.
+	MinimumRefreshInterval = 5 * time.Second
+
+	lookupErrs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kedge",
+		Name:      "srvresolver_lookup_errors_total",
+		Help:      "Count of failed lookups. Failed lookups are retried with backoff.",
+	}, []string{"target"})
 )
 
-func NewFromConfig(conf *pb.SrvResolver) (target string, namer naming.Resolver, err error) {
+func init() {
+	prometheus.MustRegister(lookupErrs)
+}
+
+// WeightedTarget is a target together with the weight of its SRV record.
+type WeightedTarget struct {
+	*srv.Target
+	Weight uint32
+}
+
+// WeightedResolver is implemented by srv.Resolver that know weights of the targets. Targets of other resolvers have
+// weight 1.
+type WeightedResolver interface {
+	LookupWeighted(domainName string) ([]*WeightedTarget, error)
+}
+
+func lookupWeighted(lookup srv.Resolver, domainName string) ([]*WeightedTarget, error) {
+	if wr, ok := lookup.(WeightedResolver); ok {
+		return wr.LookupWeighted(domainName)
+	}
+	targets, err := lookup.Lookup(domainName)
+	if err != nil {
+		return nil, err
+	}
+	weighted := make([]*WeightedTarget, 0, len(targets))
+	for _, t := range targets {
+		weighted = append(weighted, &WeightedTarget{Target: t, Weight: 1})
+	}
+	return weighted, nil
+}
+
+func NewFromConfig(conf *pb.SrvResolver) (target string, r resolvers.Resolver, err error) {
 	parent := ParentSrvResolver
 	if conf.PortOverride != 0 {
 		parent = newPortOverrideSRVResolver(conf.PortOverride, parent)
 	}
 
-	return conf.GetDnsName(), grpcsrvlb.New(parent), nil
+	return conf.GetDnsName(), New(parent), nil
+}
+
+// New returns resolvers.Resolver that periodically looks up targets using the given srv.Resolver. Lookup errors are
+// logged and retried with backoff, so watchers return errors only when closed. Addresses get weights of the targets if
+// the srv.Resolver is a WeightedResolver.
+func New(lookup srv.Resolver) resolvers.Resolver {
+	return &resolver{lookup: lookup, clock: clockwork.NewRealClock()}
+}
+
+type resolver struct {
+	lookup srv.Resolver
+	clock  clockwork.Clock
+}
+
+func (r *resolver) Resolve(target string) (resolvers.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{
+		domainName:   target,
+		lookup:       r.lookup,
+		clock:        r.clock,
+		ctx:          ctx,
+		cancel:       cancel,
+		retryBackoff: &backoff.Backoff{Max: MinimumRefreshInterval},
+	}, nil
+}
+
+type watcher struct {
+	domainName   string
+	lookup       srv.Resolver
+	clock        clockwork.Clock
+	ctx          context.Context
+	cancel       context.CancelFunc
+	retryBackoff *backoff.Backoff
+
+	resolved  bool
+	addrs     []resolvers.Address
+	nextFetch time.Time
+}
+
+// Next looks up the targets when their TTL expires and returns them once they differ from the previous result.
+func (w *watcher) Next() ([]resolvers.Address, error) {
+	for {
+		if d := w.nextFetch.Sub(w.clock.Now()); d > 0 {
+			select {
+			case <-w.clock.After(d):
+			case <-w.ctx.Done():
+			}
+		}
+		if w.ctx.Err() != nil {
+			return nil, errors.Wrapf(w.ctx.Err(), "srvresolver: watcher for %s closed", w.domainName)
+		}
+
+		targets, err := lookupWeighted(w.lookup, w.domainName)
+		if err != nil {
+			retryIn := w.retryBackoff.Duration()
+			lookupErrs.WithLabelValues(w.domainName).Inc()
+			logrus.WithError(err).WithField("target", w.domainName).Warnf("srvresolver: lookup failed, retrying in %v", retryIn)
+			w.nextFetch = w.clock.Now().Add(retryIn)
+			continue
+		}
+		w.retryBackoff.Reset()
+		w.nextFetch = w.clock.Now().Add(refreshInterval(targets))
+
+		addrs := make([]resolvers.Address, 0, len(targets))
+		for _, t := range targets {
+			addrs = append(addrs, resolvers.Address{Addr: t.DialAddr, Weight: t.Weight, Ready: true})
+		}
+		if w.resolved && resolvers.Equal(w.addrs, addrs) {
+			continue
+		}
+		w.resolved = true
+		w.addrs = addrs
+		return addrs, nil
+	}
+}
+
+func (w *watcher) Close() {
+	w.cancel()
+}
+
+func refreshInterval(targets []*WeightedTarget) time.Duration {
+	ret := MinimumRefreshInterval
+	for _, t := range targets {
+		if t.Ttl > 0 && t.Ttl < ret {
+			ret = t.Ttl
+		}
+	}
+	return ret
 }
 
 // newPortOverrideSRVResolver uses results from parent resolver, but ignores port totally and specifies our own.
@@ -46,11 +179,28 @@ func (r *portOverrideSRVResolver) Lookup(domainName string) ([]*srv.Target, erro
 	}
 
 	for _, target := range targets {
-		splitted := strings.Split(target.DialAddr, ":")
+		r.overridePort(target)
+	}
+
+	return targets, nil
+}
+
+func (r *portOverrideSRVResolver) LookupWeighted(domainName string) ([]*WeightedTarget, error) {
+	targets, err := lookupWeighted(r.parent, domainName)
+	if err != nil {
+		return targets, err
+	}
 
-		// Ignore port from SRV and use specified one.
-		target.DialAddr = net.JoinHostPort(splitted[0], fmt.Sprintf("%d", r.port))
+	for _, target := range targets {
+		r.overridePort(target.Target)
 	}
 
 	return targets, nil
 }
+
+func (r *portOverrideSRVResolver) overridePort(target *srv.Target) {
+	splitted := strings.Split(target.DialAddr, ":")
+
+	// Ignore port from SRV and use specified one.
+	target.DialAddr = net.JoinHostPort(splitted[0], fmt.Sprintf("%d", r.port))
+}
@@ -1,10 +1,14 @@
 package srvresolver
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/improbable-eng/go-srvlb/srv"
+	"github.com/improbable-eng/kedge/pkg/resolvers"
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,3 +49,141 @@ func TestPortOverrideSRVResolver_Lookup(t *testing.T) {
 	assert.Equal(t, "1.1.1.2:99", targets[1].DialAddr)
 	assert.Equal(t, "1.1.1.10:99", targets[2].DialAddr)
 }
+
+type fakeLookup struct {
+	mu      sync.Mutex
+	targets []*srv.Target
+	err     error
+	lookups int
+}
+
+func (l *fakeLookup) set(targets []*srv.Target, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.targets, l.err = targets, err
+}
+
+func (l *fakeLookup) Lookup(_ string) ([]*srv.Target, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lookups++
+	return l.targets, l.err
+}
+
+func newTestWatcher(t *testing.T, l *fakeLookup) (resolvers.Watcher, clockwork.FakeClock) {
+	clock := clockwork.NewFakeClock()
+	w, err := (&resolver{lookup: l, clock: clock}).Resolve(testDomain)
+	require.NoError(t, err)
+	return w, clock
+}
+
+func nextAsync(w resolvers.Watcher) chan []resolvers.Address {
+	resC := make(chan []resolvers.Address, 1)
+	go func() {
+		addrs, err := w.Next()
+		if err != nil {
+			close(resC)
+			return
+		}
+		resC <- addrs
+	}()
+	return resC
+}
+
+func TestWatcher_ReturnsOnlyChangedTargetsAfterTTL(t *testing.T) {
+	l := &fakeLookup{targets: []*srv.Target{{DialAddr: "1.1.1.1:80", Ttl: 2 * time.Second}}}
+	w, clock := newTestWatcher(t, l)
+	defer w.Close()
+
+	addrs, err := w.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []resolvers.Address{{Addr: "1.1.1.1:80", Weight: 1, Ready: true}}, addrs)
+
+	resC := nextAsync(w)
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Second)
+	// Same targets are looked up again, but not returned.
+	clock.BlockUntil(1)
+	l.mu.Lock()
+	require.Equal(t, 2, l.lookups)
+	l.mu.Unlock()
+
+	l.set([]*srv.Target{{DialAddr: "1.1.1.1:80", Ttl: 2 * time.Second}, {DialAddr: "1.1.1.2:80", Ttl: 2 * time.Second}}, nil)
+	clock.Advance(2 * time.Second)
+	select {
+	case addrs := <-resC:
+		assert.Len(t, addrs, 2)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for targets")
+	}
+}
+
+func TestWatcher_RetriesLookupErrors(t *testing.T) {
+	l := &fakeLookup{err: errors.New("dns failure")}
+	w, clock := newTestWatcher(t, l)
+	defer w.Close()
+
+	resC := nextAsync(w)
+	clock.BlockUntil(1)
+	l.set([]*srv.Target{{DialAddr: "1.1.1.1:80"}}, nil)
+	clock.Advance(MinimumRefreshInterval)
+	select {
+	case addrs := <-resC:
+		assert.Equal(t, []resolvers.Address{{Addr: "1.1.1.1:80", Weight: 1, Ready: true}}, addrs)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for targets")
+	}
+}
+
+func TestWatcher_NextFailsAfterClose(t *testing.T) {
+	l := &fakeLookup{targets: []*srv.Target{{DialAddr: "1.1.1.1:80"}}}
+	w, clock := newTestWatcher(t, l)
+
+	_, err := w.Next()
+	require.NoError(t, err)
+
+	resC := nextAsync(w)
+	clock.BlockUntil(1)
+	w.Close()
+	select {
+	case _, ok := <-resC:
+		assert.False(t, ok, "Next should fail after Close")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Next to fail")
+	}
+}
+
+type weightedLookup struct {
+	fakeLookup
+	weights map[string]uint32
+}
+
+func (l *weightedLookup) LookupWeighted(domainName string) ([]*WeightedTarget, error) {
+	targets, err := l.Lookup(domainName)
+	if err != nil {
+		return nil, err
+	}
+	var weighted []*WeightedTarget
+	for _, t := range targets {
+		weighted = append(weighted, &WeightedTarget{Target: t, Weight: l.weights[t.DialAddr]})
+	}
+	return weighted, nil
+}
+
+func TestWatcher_UsesWeightsOfRecords(t *testing.T) {
+	l := &weightedLookup{
+		fakeLookup: fakeLookup{targets: []*srv.Target{{DialAddr: "1.1.1.1:80"}, {DialAddr: "1.1.1.2:80"}}},
+		weights:    map[string]uint32{"1.1.1.1:80": 10, "1.1.1.2:80": 30},
+	}
+	// Weights are kept when the port is overridden.
+	w, err := New(newPortOverrideSRVResolver(99, l)).Resolve(testDomain)
+	require.NoError(t, err)
+	defer w.Close()
+
+	addrs, err := w.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []resolvers.Address{
+		{Addr: "1.1.1.1:99", Weight: 10, Ready: true},
+		{Addr: "1.1.1.2:99", Weight: 30, Ready: true},
+	}, addrs)
+}
@@ -0,0 +1,85 @@
+package kedge_tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
+	"github.com/pkg/errors"
+)
+
+// BackendSecurity is implemented by Security messages of both HTTP and gRPC backends.
+type BackendSecurity interface {
+	GetInsecureSkipVerify() bool
+	GetConfigName() string
+}
+
+// BuildBackendTLSConfig creates TLS config for connections to a backend with given security settings.
+// tlsServerConfigs are all named TlsServerConfigs the security settings can refer to.
+func BuildBackendTLSConfig(sec BackendSecurity, tlsServerConfigs []*pb_config.TlsServerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if name := sec.GetConfigName(); name != "" {
+		cnf := FindTLSServerConfig(name, tlsServerConfigs)
+		if cnf == nil {
+			return nil, errors.Errorf("unknown TLS server config %q", name)
+		}
+
+		var err error
+		tlsConfig, err = tlsConfigFromServerConfig(cnf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed building TLS server config %q", name)
+		}
+	}
+	tlsConfig.InsecureSkipVerify = sec.GetInsecureSkipVerify()
+	return tlsConfig, nil
+}
+
+// FindTLSServerConfig returns TlsServerConfig of the given name or nil if it does not exist.
+func FindTLSServerConfig(name string, tlsServerConfigs []*pb_config.TlsServerConfig) *pb_config.TlsServerConfig {
+	for _, cnf := range tlsServerConfigs {
+		if cnf.GetName() == name {
+			return cnf
+		}
+	}
+	return nil
+}
+
+func tlsConfigFromServerConfig(cnf *pb_config.TlsServerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cnf.GetServerName(),
+	}
+
+	switch cnf.GetMinVersion() {
+	case pb_config.TlsServerConfig_TLS_1_0:
+		tlsConfig.MinVersion = tls.VersionTLS10
+	case pb_config.TlsServerConfig_TLS_1_1:
+		tlsConfig.MinVersion = tls.VersionTLS11
+	case pb_config.TlsServerConfig_TLS_1_3:
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if cnf.GetCertFile() != "" || cnf.GetKeyFile() != "" {
+		cert, err := tls.LoadX509KeyPair(cnf.GetCertFile(), cnf.GetKeyFile())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading TLS client keys")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cnf.GetCaFiles()) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, path := range cnf.GetCaFiles() {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed reading root CA file %v", path)
+			}
+			if ok := tlsConfig.RootCAs.AppendCertsFromPEM(data); !ok {
+				return nil, errors.Errorf("failed processing root CA file %v", path)
+			}
+		}
+	}
+	return tlsConfig, nil
+}
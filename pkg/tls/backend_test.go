@@ -0,0 +1,199 @@
+package kedge_tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb_config "github.com/improbable-eng/kedge/protogen/kedge/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSecurity struct {
+	insecureSkipVerify bool
+	configName         string
+}
+
+func (s testSecurity) GetInsecureSkipVerify() bool { return s.insecureSkipVerify }
+func (s testSecurity) GetConfigName() string       { return s.configName }
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	// file is the path of the PEM-encoded CA certificate.
+	file string
+}
+
+var serial int64
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newTemplate(cn string) *x509.Certificate {
+	serial++
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+}
+
+func writePEM(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+func newTestCA(t *testing.T, dir string, name string) *testCA {
+	key := newKey(t)
+	tmpl := newTemplate(name)
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, file: writePEM(t, dir, name+".crt", "CERTIFICATE", der)}
+}
+
+// issue returns certificate signed by the CA together with paths of its PEM-encoded cert and key files.
+func (ca *testCA) issue(t *testing.T, dir string, name string, usage x509.ExtKeyUsage, dnsNames []string, ips []net.IP) (tls.Certificate, string, string) {
+	key := newKey(t)
+	tmpl := newTemplate(name)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+	tmpl.DNSNames = dnsNames
+	tmpl.IPAddresses = ips
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile := writePEM(t, dir, name+".crt", "CERTIFICATE", der)
+	keyFile := writePEM(t, dir, name+".key", "EC PRIVATE KEY", keyDer)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	return cert, certFile, keyFile
+}
+
+func startTLSServer(t *testing.T, serverTLS *tls.Config) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = serverTLS
+	srv.StartTLS()
+	return srv
+}
+
+func get(srv *httptest.Server, clientTLS *tls.Config) error {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}, Timeout: 5 * time.Second}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestBuildBackendTLSConfig_Handshakes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kedge_tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t, dir, "ca")
+	otherCA := newTestCA(t, dir, "other_ca")
+	serverCert, _, _ := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth, []string{"backend.example.com"}, []net.IP{net.ParseIP("127.0.0.1")})
+	namedServerCert, _, _ := ca.issue(t, dir, "named_server", x509.ExtKeyUsageServerAuth, []string{"backend.example.com"}, nil)
+	_, clientCertFile, clientKeyFile := ca.issue(t, dir, "client", x509.ExtKeyUsageClientAuth, nil, nil)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	srv := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+	defer srv.Close()
+	mtlsSrv := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs})
+	defer mtlsSrv.Close()
+	namedSrv := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{namedServerCert}})
+	defer namedSrv.Close()
+	tls12Srv := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{serverCert}, MaxVersion: tls.VersionTLS12})
+	defer tls12Srv.Close()
+
+	configs := []*pb_config.TlsServerConfig{
+		{Name: "trusted", CaFiles: []string{ca.file}},
+		{Name: "wrong_ca", CaFiles: []string{otherCA.file}},
+		{Name: "both_cas", CaFiles: []string{otherCA.file, ca.file}},
+		{Name: "mtls", CaFiles: []string{ca.file}, CertFile: clientCertFile, KeyFile: clientKeyFile},
+		{Name: "server_name", CaFiles: []string{ca.file}, ServerName: "backend.example.com"},
+		{Name: "wrong_server_name", CaFiles: []string{ca.file}, ServerName: "other.example.com"},
+		{Name: "tls13", CaFiles: []string{ca.file}, MinVersion: pb_config.TlsServerConfig_TLS_1_3},
+	}
+
+	for _, tcase := range []struct {
+		name    string
+		sec     testSecurity
+		srv     *httptest.Server
+		success bool
+	}{
+		{name: "TrustedCA", sec: testSecurity{configName: "trusted"}, srv: srv, success: true},
+		{name: "WrongCARejected", sec: testSecurity{configName: "wrong_ca"}, srv: srv},
+		{name: "AnyOfCAFiles", sec: testSecurity{configName: "both_cas"}, srv: srv, success: true},
+		{name: "InsecureSkipVerifyWithWrongCA", sec: testSecurity{configName: "wrong_ca", insecureSkipVerify: true}, srv: srv, success: true},
+		{name: "ClientCertRequiredButMissing", sec: testSecurity{configName: "trusted"}, srv: mtlsSrv},
+		{name: "ClientCertPresented", sec: testSecurity{configName: "mtls"}, srv: mtlsSrv, success: true},
+		{name: "ServerNameMissing", sec: testSecurity{configName: "trusted"}, srv: namedSrv},
+		{name: "ServerNameMatches", sec: testSecurity{configName: "server_name"}, srv: namedSrv, success: true},
+		{name: "ServerNameMismatch", sec: testSecurity{configName: "wrong_server_name"}, srv: namedSrv},
+		{name: "MinVersionAboveServerMax", sec: testSecurity{configName: "tls13"}, srv: tls12Srv},
+		{name: "MinVersionSupported", sec: testSecurity{configName: "tls13"}, srv: srv, success: true},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			tlsConfig, err := BuildBackendTLSConfig(tcase.sec, configs)
+			require.NoError(t, err)
+			err = get(tcase.srv, tlsConfig)
+			if tcase.success {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBuildBackendTLSConfig_Errors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kedge_tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	notPEM := filepath.Join(dir, "not_pem")
+	require.NoError(t, ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	tlsConfig, err := BuildBackendTLSConfig(testSecurity{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion, "TLS 1.2 should be the default minimum")
+
+	configs := []*pb_config.TlsServerConfig{
+		{Name: "missing_ca", CaFiles: []string{filepath.Join(dir, "missing.crt")}},
+		{Name: "invalid_ca", CaFiles: []string{notPEM}},
+		{Name: "missing_key", CertFile: notPEM},
+	}
+	for _, name := range []string{"unknown", "missing_ca", "invalid_ca", "missing_key"} {
+		_, err := BuildBackendTLSConfig(testSecurity{configName: name}, configs)
+		assert.Error(t, err, name)
+	}
+}
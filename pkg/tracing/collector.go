@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// CollectedSpan is a span received by the Collector.
+type CollectedSpan struct {
+	ServiceName  string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         Kind
+	Attributes   map[string]interface{}
+	Error        string
+}
+
+// Collector is an in-process OTLP/HTTP (JSON) trace collector. It stands in for a real collector in tests and local
+// debugging.
+type Collector struct {
+	mu    sync.Mutex
+	spans []CollectedSpan
+}
+
+// NewCollector creates an empty collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// ServeHTTP accepts an OTLP export request.
+func (c *Collector) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(resp, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	var export otlpRequest
+	if err := json.NewDecoder(req.Body).Decode(&export); err != nil {
+		http.Error(resp, "invalid OTLP JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var spans []CollectedSpan
+	for _, rs := range export.ResourceSpans {
+		var serviceName string
+		for _, kv := range rs.Resource.Attributes {
+			if kv.Key == "service.name" && kv.Value.StringValue != nil {
+				serviceName = *kv.Value.StringValue
+			}
+		}
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				span := CollectedSpan{
+					ServiceName:  serviceName,
+					TraceID:      s.TraceID,
+					SpanID:       s.SpanID,
+					ParentSpanID: s.ParentSpanID,
+					Name:         s.Name,
+					Kind:         Kind(s.Kind),
+					Attributes:   map[string]interface{}{},
+					Error:        s.Status.Message,
+				}
+				for _, kv := range s.Attributes {
+					span.Attributes[kv.Key] = kv.Value.value()
+				}
+				spans = append(spans, span)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.spans = append(c.spans, spans...)
+	c.mu.Unlock()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write([]byte("{}"))
+}
+
+// Spans returns all spans received so far.
+func (c *Collector) Spans() []CollectedSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CollectedSpan(nil), c.spans...)
+}
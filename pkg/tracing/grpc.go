@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor creates a server span with the given name for every call, continuing the trace from the
+// incoming metadata.
+func UnaryServerInterceptor(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startServerSpan(ctx, name, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endServerSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor creates a server span with the given name for every call, continuing the trace from the
+// incoming metadata.
+func StreamServerInterceptor(name string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		var span *Span
+		wrapped.WrappedContext, span = startServerSpan(stream.Context(), name, info.FullMethod)
+		err := handler(srv, wrapped)
+		endServerSpan(span, err)
+		return err
+	}
+}
+
+func startServerSpan(ctx context.Context, name string, fullMethod string) (context.Context, *Span) {
+	if sc, ok := Extract(MetadataCarrier(metautils.ExtractIncoming(ctx))); ok {
+		ctx = ContextWithRemoteParent(ctx, sc)
+	}
+	ctx, span := StartSpan(ctx, name, KindServer)
+	span.SetAttribute("grpc.method", fullMethod)
+	return ctx, span
+}
+
+func endServerSpan(span *Span, err error) {
+	span.SetAttribute("grpc.code", grpc.Code(err).String())
+	span.SetError(err)
+	span.End()
+}
+
+// OutgoingContext writes the context of the current span (or the remote parent) of ctx to the outgoing metadata.
+func OutgoingContext(ctx context.Context) context.Context {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+	md := metautils.ExtractOutgoing(ctx).Clone()
+	Inject(sc, MetadataCarrier(metadata.MD(md)))
+	return md.ToOutgoing(ctx)
+}
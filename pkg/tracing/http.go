@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+// Tripper returns a RoundTripper creating a client span with the given name for every request. The span context is
+// propagated in the headers of the request sent by parent.
+func Tripper(name string, parent http.RoundTripper) http.RoundTripper {
+	return &tracingTripper{name: name, parent: parent}
+}
+
+type tracingTripper struct {
+	name   string
+	parent http.RoundTripper
+}
+
+func (t *tracingTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := StartSpan(req.Context(), t.name, KindClient)
+	defer span.End()
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.host", req.URL.Host)
+
+	resp, err := t.parent.RoundTrip(RequestWithSpan(req.WithContext(ctx)))
+	RecordHTTPResult(span, resp, err)
+	return resp, err
+}
+
+// RequestWithSpan returns a copy of the request with the headers carrying the context of the current span of the
+// request's context.
+func RequestWithSpan(req *http.Request) *http.Request {
+	sc := SpanContextFromContext(req.Context())
+	if !sc.IsValid() {
+		return req
+	}
+	reqCopy := req.WithContext(req.Context())
+	reqCopy.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		reqCopy.Header[k] = v
+	}
+	Inject(sc, HTTPCarrier(reqCopy.Header))
+	return reqCopy
+}
+
+// RecordHTTPResult sets the status code of the response (or the error if there is none) on the span.
+func RecordHTTPResult(span *Span, resp *http.Response, err error) {
+	if err != nil {
+		span.SetError(err)
+		return
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= 500 {
+		span.SetError(errorStatus(resp.Status))
+	}
+}
+
+type errorStatus string
+
+func (e errorStatus) Error() string { return string(e) }
@@ -0,0 +1,281 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/improbable-eng/kedge/pkg/sharedflags"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxBatchSize  = 512
+	maxQueuedSpan = 4096
+	scopeName     = "github.com/improbable-eng/kedge/pkg/tracing"
+)
+
+var (
+	flagOTLPEndpoint = sharedflags.Set.String("tracing_otlp_endpoint", "",
+		"OTLP/HTTP (JSON) endpoint of the trace collector, e.g. http://localhost:4318/v1/traces. If empty, spans are not "+
+			"recorded, but the trace context of incoming requests is still propagated.")
+	flagSampleRatio = sharedflags.Set.Float64("tracing_sample_ratio", 1.0,
+		"Ratio (0 to 1) of new traces that are sampled. Traces continued from incoming requests keep their decision.")
+	flagFlushInterval = sharedflags.Set.Duration("tracing_flush_interval", 1*time.Second,
+		"Maximum time finished spans wait before being sent to the trace collector.")
+
+	exportedSpans = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kedge",
+			Subsystem: "tracing",
+			Name:      "spans_total",
+			Help:      "Total number of finished spans, by the result of export (exported, dropped or failed).",
+		},
+		[]string{"result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(exportedSpans)
+}
+
+// InitFromFlags sets up the global tracer exporting spans of the given service according to the flags. The returned
+// func flushes the remaining spans.
+func InitFromFlags(serviceName string, logger logrus.FieldLogger) func() {
+	if *flagOTLPEndpoint == "" {
+		return func() {}
+	}
+	exporter := NewOTLPExporter(*flagOTLPEndpoint, serviceName, *flagFlushInterval, logger)
+	SetGlobal(NewTracer(exporter, *flagSampleRatio))
+	return exporter.Close
+}
+
+// OTLPExporter sends spans in batches to an OTLP/HTTP collector using the JSON encoding.
+type OTLPExporter struct {
+	endpoint      string
+	serviceName   string
+	flushInterval time.Duration
+	client        *http.Client
+	logger        logrus.FieldLogger
+
+	spansC chan *Span
+	stopC  chan struct{}
+	doneC  chan struct{}
+}
+
+// NewOTLPExporter creates an exporter and starts sending spans. It needs to be closed.
+func NewOTLPExporter(endpoint string, serviceName string, flushInterval time.Duration, logger logrus.FieldLogger) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint:      endpoint,
+		serviceName:   serviceName,
+		flushInterval: flushInterval,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		logger:        logger,
+		spansC:        make(chan *Span, maxQueuedSpan),
+		stopC:         make(chan struct{}),
+		doneC:         make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues the span. Spans are dropped if the queue is full, e.g. when the collector is down.
+func (e *OTLPExporter) Export(s *Span) {
+	select {
+	case e.spansC <- s:
+	default:
+		exportedSpans.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Close sends the queued spans and stops the exporter.
+func (e *OTLPExporter) Close() {
+	close(e.stopC)
+	<-e.doneC
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.doneC)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	for {
+		select {
+		case s := <-e.spansC:
+			batch = append(batch, s)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.stopC:
+			for {
+				select {
+				case s := <-e.spansC:
+					batch = append(batch, s)
+				default:
+					e.send(batch)
+					return
+				}
+			}
+		}
+		e.send(batch)
+		batch = nil
+	}
+}
+
+func (e *OTLPExporter) send(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := e.post(batch); err != nil {
+		exportedSpans.WithLabelValues("failed").Add(float64(len(batch)))
+		e.logger.WithError(err).Warn("Failed to export spans.")
+		return
+	}
+	exportedSpans.WithLabelValues("exported").Add(float64(len(batch)))
+}
+
+func (e *OTLPExporter) post(batch []*Span) error {
+	body, err := json.Marshal(encodeSpans(e.serviceName, batch))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spans")
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to send spans")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON messages (opentelemetry/proto/collector/trace/v1), limited to the fields we use.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	// Code is 0 (unset) or 2 (error).
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (v otlpAnyValue) value() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		i, _ := strconv.ParseInt(*v.IntValue, 10, 64)
+		return i
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	}
+	return nil
+}
+
+func encodeValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &val}
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case int:
+		s := strconv.Itoa(val)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &val}
+	default:
+		s := fmt.Sprintf("%v", val)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func encodeSpans(serviceName string, spans []*Span) *otlpRequest {
+	scope := otlpScopeSpans{}
+	scope.Scope.Name = scopeName
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           s.ctx.TraceID.String(),
+			SpanID:            s.ctx.SpanID.String(),
+			Name:              s.name,
+			Kind:              int(s.kind),
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != (SpanID{}) {
+			span.ParentSpanID = s.parentID.String()
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+		keys := make([]string, 0, len(s.attributes))
+		for k := range s.attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			span.Attributes = append(span.Attributes, otlpKeyValue{Key: k, Value: encodeValue(s.attributes[k])})
+		}
+		s.mu.Unlock()
+		scope.Spans = append(scope.Spans, span)
+	}
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpKeyValue{{Key: "service.name", Value: encodeValue(serviceName)}}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	headerTraceParent  = "traceparent"
+	headerB3           = "b3"
+	headerB3TraceID    = "x-b3-traceid"
+	headerB3SpanID     = "x-b3-spanid"
+	headerB3ParentSpan = "x-b3-parentspanid"
+	headerB3Sampled    = "x-b3-sampled"
+)
+
+// Carrier is a set of headers (or gRPC metadata) carrying the span context.
+type Carrier interface {
+	Get(key string) string
+	Set(key string, value string)
+	Del(key string)
+}
+
+// HTTPCarrier adapts http.Header to a Carrier.
+type HTTPCarrier http.Header
+
+func (c HTTPCarrier) Get(key string) string        { return http.Header(c).Get(key) }
+func (c HTTPCarrier) Set(key string, value string) { http.Header(c).Set(key, value) }
+func (c HTTPCarrier) Del(key string)               { http.Header(c).Del(key) }
+
+// MetadataCarrier adapts gRPC metadata to a Carrier.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	if vals := c[strings.ToLower(key)]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func (c MetadataCarrier) Set(key string, value string) {
+	c[strings.ToLower(key)] = []string{value}
+}
+
+func (c MetadataCarrier) Del(key string) {
+	delete(c, strings.ToLower(key))
+}
+
+// Inject writes the span context in both W3C trace-context and B3 formats, replacing the ones already present.
+// Invalid span context is not written.
+func Inject(sc SpanContext, carrier Carrier) {
+	if !sc.IsValid() {
+		return
+	}
+	flags, b3Sampled := "00", "0"
+	if sc.Sampled {
+		flags, b3Sampled = "01", "1"
+	}
+	carrier.Set(headerTraceParent, fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags))
+	carrier.Set(headerB3TraceID, sc.TraceID.String())
+	carrier.Set(headerB3SpanID, sc.SpanID.String())
+	carrier.Set(headerB3Sampled, b3Sampled)
+	if carrier.Get(headerB3) != "" {
+		carrier.Set(headerB3, fmt.Sprintf("%s-%s-%s", sc.TraceID, sc.SpanID, b3Sampled))
+	}
+	// The parent is not known here and a stale one would be wrong.
+	carrier.Del(headerB3ParentSpan)
+}
+
+// Extract reads the span context from W3C trace-context or, if not present, B3 (single or multi header) format.
+func Extract(carrier Carrier) (SpanContext, bool) {
+	if sc, ok := parseTraceParent(carrier.Get(headerTraceParent)); ok {
+		return sc, true
+	}
+	if b3 := carrier.Get(headerB3); b3 != "" {
+		parts := strings.Split(b3, "-")
+		if len(parts) >= 2 {
+			sampled := "1"
+			if len(parts) >= 3 {
+				sampled = parts[2]
+			}
+			return parseB3(parts[0], parts[1], sampled)
+		}
+	}
+	return parseB3(carrier.Get(headerB3TraceID), carrier.Get(headerB3SpanID), carrier.Get(headerB3Sampled))
+}
+
+// ExtractHTTP returns a context continuing the trace of the incoming HTTP request, if it carries one.
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	if sc, ok := Extract(HTTPCarrier(header)); ok {
+		return ContextWithRemoteParent(ctx, sc)
+	}
+	return ctx
+}
+
+// InjectHTTP writes the context of the current span (or the remote parent) of ctx to the outgoing HTTP headers.
+func InjectHTTP(ctx context.Context, header http.Header) {
+	Inject(SpanContextFromContext(ctx), HTTPCarrier(header))
+}
+
+func parseTraceParent(v string) (SpanContext, bool) {
+	// version-traceid-spanid-flags, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if !decodeHex(parts[1], sc.TraceID[:]) || !decodeHex(parts[2], sc.SpanID[:]) {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+func parseB3(traceID string, spanID string, sampled string) (SpanContext, bool) {
+	var sc SpanContext
+	if len(traceID) == 16 {
+		// 64-bit trace IDs are left-padded.
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if !decodeHex(traceID, sc.TraceID[:]) || !decodeHex(spanID, sc.SpanID[:]) {
+		return SpanContext{}, false
+	}
+	// Missing sampling decision is deferred to us, we sample it.
+	sc.Sampled = sampled != "0" && sampled != "false"
+	return sc, sc.IsValid()
+}
+
+func decodeHex(s string, dst []byte) bool {
+	if len(s) != 2*len(dst) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
@@ -0,0 +1,218 @@
+// Package tracing creates spans of requests passing through winch and kedge, propagates their context to the next hop
+// (W3C trace-context and B3 headers) and exports finished spans to an OTLP collector.
+//
+// Spans are created with StartSpan, which continues the span found in the context or the remote parent extracted from
+// the incoming request. When tracing is disabled spans are not recorded, but the incoming trace context is still
+// propagated, so kedge does not break traces of its clients.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	mathrand "math/rand"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// SpanContext is the part of a span propagated to other processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns true if both IDs are set.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != TraceID{} && c.SpanID != SpanID{}
+}
+
+// Kind describes the relationship of the span to the request, as in OTLP.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Span is a single operation within a trace. All methods are safe to call on a nil Span.
+type Span struct {
+	tracer   *Tracer
+	name     string
+	kind     Kind
+	ctx      SpanContext
+	parentID SpanID
+	start    time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes map[string]interface{}
+	errMsg     string
+	ended      bool
+}
+
+// Context returns the span's context, used to propagate the span to the next hop.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.ctx
+}
+
+// SetAttribute sets an attribute of the span. Values should be strings, bools, ints or floats.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attributes == nil {
+		s.attributes = map[string]interface{}{}
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed with the given error. Nil error is ignored.
+func (s *Span) SetError(err error) {
+	if err == nil || !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End finishes the span and queues it for export. Only the first call has an effect.
+func (s *Span) End() {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.exporter.Export(s)
+}
+
+func (s *Span) recording() bool {
+	return s != nil && s.tracer != nil && s.ctx.Sampled
+}
+
+// Exporter receives finished spans.
+type Exporter interface {
+	Export(span *Span)
+}
+
+// Tracer creates spans. Tracer with nil exporter does not record spans.
+type Tracer struct {
+	exporter    Exporter
+	sampleRatio float64
+
+	mu  sync.Mutex
+	rnd *mathrand.Rand
+}
+
+// NewTracer creates a tracer exporting spans to the exporter. New traces are sampled with the given ratio (0 to 1),
+// continued traces keep the decision of the parent.
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	var seed int64
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err == nil {
+		seed = int64(binary.LittleEndian.Uint64(b[:]))
+	} else {
+		seed = time.Now().UnixNano()
+	}
+	return &Tracer{
+		exporter:    exporter,
+		sampleRatio: sampleRatio,
+		rnd:         mathrand.New(mathrand.NewSource(seed)),
+	}
+}
+
+var (
+	globalMu     sync.RWMutex
+	globalTracer = NewTracer(nil, 0)
+)
+
+// SetGlobal sets the tracer used by StartSpan.
+func SetGlobal(t *Tracer) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalTracer = t
+}
+
+func global() *Tracer {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalTracer
+}
+
+type spanKey struct{}
+type remoteParentKey struct{}
+
+// ContextWithRemoteParent returns a context whose next span continues the given span of another process.
+func ContextWithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	if !parent.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, parent)
+}
+
+// SpanFromContext returns the current span, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SpanContextFromContext returns the context of the current span or, if there is none, of the remote parent.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.Context()
+	}
+	parent, _ := ctx.Value(remoteParentKey{}).(SpanContext)
+	return parent
+}
+
+// StartSpan starts a span using the global tracer. It is a child of the current span or of the remote parent (see
+// ContextWithRemoteParent). The returned context holds the new span. The span needs to be ended.
+func StartSpan(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	return global().StartSpan(ctx, name, kind)
+}
+
+// StartSpan starts a span. See the package-level StartSpan.
+func (t *Tracer) StartSpan(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	if t.exporter == nil {
+		// Not recording, the remote parent (if any) is propagated unchanged.
+		return ctx, nil
+	}
+
+	s := &Span{tracer: t, name: name, kind: kind, start: time.Now()}
+	t.mu.Lock()
+	if parent.IsValid() {
+		s.ctx.TraceID = parent.TraceID
+		s.ctx.Sampled = parent.Sampled
+		s.parentID = parent.SpanID
+	} else {
+		t.rnd.Read(s.ctx.TraceID[:])
+		s.ctx.Sampled = t.rnd.Float64() < t.sampleRatio
+	}
+	t.rnd.Read(s.ctx.SpanID[:])
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract_TraceParent(t *testing.T) {
+	h := http.Header{}
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	sc, ok := Extract(HTTPCarrier(h))
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+}
+
+func TestExtract_B3(t *testing.T) {
+	for _, tcase := range []struct {
+		name    string
+		headers map[string]string
+		traceID string
+		sampled bool
+	}{
+		{
+			name:    "single",
+			headers: map[string]string{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0"},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: false,
+		},
+		{
+			name: "multi",
+			headers: map[string]string{
+				"X-B3-TraceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+				"X-B3-Sampled": "1",
+			},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: true,
+		},
+		{
+			name: "multi with 64-bit trace ID",
+			headers: map[string]string{
+				"X-B3-TraceId": "a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+			},
+			traceID: "0000000000000000a3ce929d0e0e4736",
+			sampled: true,
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tcase.headers {
+				h.Set(k, v)
+			}
+			sc, ok := Extract(HTTPCarrier(h))
+			require.True(t, ok)
+			assert.Equal(t, tcase.traceID, sc.TraceID.String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			assert.Equal(t, tcase.sampled, sc.Sampled)
+		})
+	}
+}
+
+func TestExtract_Invalid(t *testing.T) {
+	h := http.Header{}
+	h.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	h.Set("X-B3-TraceId", "not-hex")
+	_, ok := Extract(HTTPCarrier(h))
+	assert.False(t, ok)
+}
+
+func TestInject_RoundTrip(t *testing.T) {
+	h := http.Header{}
+	h.Set("b3", "stale")
+	h.Set("X-B3-ParentSpanId", "00f067aa0ba902b7")
+	sc := SpanContext{Sampled: true}
+	copy(sc.TraceID[:], []byte("0123456789abcdef"))
+	copy(sc.SpanID[:], []byte("01234567"))
+	Inject(sc, HTTPCarrier(h))
+
+	assert.Empty(t, h.Get("X-B3-ParentSpanId"))
+	for _, only := range []string{"traceparent", "b3", "X-B3-TraceId"} {
+		single := http.Header{}
+		single.Set(only, h.Get(only))
+		if only == "X-B3-TraceId" {
+			single.Set("X-B3-SpanId", h.Get("X-B3-SpanId"))
+			single.Set("X-B3-Sampled", h.Get("X-B3-Sampled"))
+		}
+		got, ok := Extract(HTTPCarrier(single))
+		require.True(t, ok, only)
+		assert.Equal(t, sc, got, only)
+	}
+}
+
+func TestStartSpan_NotRecordingPropagatesParent(t *testing.T) {
+	tracer := NewTracer(nil, 1)
+	parent := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true}
+	ctx, span := tracer.StartSpan(ContextWithRemoteParent(context.Background(), parent), "op", KindServer)
+	assert.Nil(t, span)
+	span.SetAttribute("ignored", true)
+	span.End()
+	assert.Equal(t, parent, SpanContextFromContext(ctx))
+}
+
+func TestOTLPExporter_SendsSpansToCollector(t *testing.T) {
+	collector := NewCollector()
+	collectorSrv := httptest.NewServer(collector)
+	defer collectorSrv.Close()
+
+	exporter := NewOTLPExporter(collectorSrv.URL, "kedge", time.Hour, logrus.New())
+	tracer := NewTracer(exporter, 1)
+
+	// Backend receiving the proxied request, as a child of the client span.
+	var backendParent SpanContext
+	backend := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		backendParent, _ = Extract(HTTPCarrier(req.Header))
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	ctx, server := tracer.StartSpan(context.Background(), "kedge.http", KindServer)
+	server.SetAttribute("kedge.backend", "backend_a")
+	clientCtx, client := tracer.StartSpan(ctx, "kedge.lbtransport.pick", KindClient)
+	req, err := http.NewRequest("GET", backend.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultTransport.RoundTrip(RequestWithSpan(req.WithContext(clientCtx)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	RecordHTTPResult(client, resp, nil)
+	client.End()
+	server.End()
+	exporter.Close()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 2)
+	pick, proxy := spans[0], spans[1]
+	assert.Equal(t, "kedge", proxy.ServiceName)
+	assert.Equal(t, "kedge.http", proxy.Name)
+	assert.Equal(t, KindServer, proxy.Kind)
+	assert.Equal(t, "backend_a", proxy.Attributes["kedge.backend"])
+	assert.Empty(t, proxy.ParentSpanID)
+
+	assert.Equal(t, "kedge.lbtransport.pick", pick.Name)
+	assert.Equal(t, proxy.TraceID, pick.TraceID)
+	assert.Equal(t, proxy.SpanID, pick.ParentSpanID)
+	assert.Equal(t, int64(http.StatusServiceUnavailable), pick.Attributes["http.status_code"])
+	assert.NotEmpty(t, pick.Error)
+
+	assert.Equal(t, pick.TraceID, backendParent.TraceID.String())
+	assert.Equal(t, pick.SpanID, backendParent.SpanID.String())
+}
@@ -35,10 +35,11 @@ type winchMapper interface {
 
 func New(mapper winchMapper, config *tls.Config, logEntry *logrus.Entry, mux *http.ServeMux, debugMode bool) *Proxy {
 	// Prepare chain of trippers for winch logic. (The last wrapped will be first in the chain of tripperwares)
-	// 5) Last, default transport for communication with our kedges.
-	// 4) Kedge auth tipper - injects auth for kedge based on route.
-	// 3) Backend auth tripper - injects auth for backend based on route.
-	// 2) Routing tripper - redirects to kedge if specified based on route.
+	// 6) Last, default transport for communication with our kedges.
+	// 5) Kedge auth tipper - injects auth for kedge based on route.
+	// 4) Backend auth tripper - injects auth for backend based on route.
+	// 3) Routing tripper - redirects to kedge if specified based on route.
+	// 2) Tracing tripper - creates span and propagates its context to kedge.
 	// 1) First, mapping tripper - maps dns to route and puts it to request context for rest of the tripperwares.
 
 	parentTransport := tripperware.Default(config)
@@ -49,6 +50,7 @@ func New(mapper winchMapper, config *tls.Config, logEntry *logrus.Entry, mux *ht
 	parentTransport = tripperware.WrapForProxyAuth(parentTransport)
 	parentTransport = tripperware.WrapForBackendAuth(parentTransport)
 	parentTransport = tripperware.WrapForRouting(parentTransport)
+	parentTransport = tripperware.WrapForTracing(parentTransport)
 	parentTransport = tripperware.WrapForMapping(mapper, parentTransport)
 	parentTransport = tripperware.WrapForRequestID("winch-", parentTransport)
 	parentTransport = reverseProxyErrHandler(parentTransport, logEntry)
@@ -0,0 +1,889 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/admin/admin.proto
+
+/*
+Package kedge_admin is a generated protocol buffer package.
+
+It is generated from these files:
+	kedge/admin/admin.proto
+
+It has these top-level messages:
+	Resource
+	ListRequest
+	ListResponse
+	GetRequest
+	GetResponse
+	AddRequest
+	UpdateRequest
+	DeleteRequest
+	ChangeResponse
+*/
+package kedge_admin
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import kedge_config_grpc_backends "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+import kedge_config_grpc_routes "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+import kedge_config_http_backends "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
+import kedge_config_http_routes "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+import kedge_config_tcp_backends "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+import kedge_config_tcp_routes "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// / Kind is the kind of an editable resource.
+type Kind int32
+
+const (
+	Kind_UNKNOWN         Kind = 0
+	Kind_GRPC_ROUTE      Kind = 1
+	Kind_HTTP_ROUTE      Kind = 2
+	Kind_TCP_ROUTE       Kind = 3
+	Kind_GRPC_ADHOC_RULE Kind = 4
+	Kind_HTTP_ADHOC_RULE Kind = 5
+	Kind_GRPC_BACKEND    Kind = 6
+	Kind_HTTP_BACKEND    Kind = 7
+	Kind_TCP_BACKEND     Kind = 8
+)
+
+var Kind_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "GRPC_ROUTE",
+	2: "HTTP_ROUTE",
+	3: "TCP_ROUTE",
+	4: "GRPC_ADHOC_RULE",
+	5: "HTTP_ADHOC_RULE",
+	6: "GRPC_BACKEND",
+	7: "HTTP_BACKEND",
+	8: "TCP_BACKEND",
+}
+var Kind_value = map[string]int32{
+	"UNKNOWN":         0,
+	"GRPC_ROUTE":      1,
+	"HTTP_ROUTE":      2,
+	"TCP_ROUTE":       3,
+	"GRPC_ADHOC_RULE": 4,
+	"HTTP_ADHOC_RULE": 5,
+	"GRPC_BACKEND":    6,
+	"HTTP_BACKEND":    7,
+	"TCP_BACKEND":     8,
+}
+
+func (x Kind) String() string {
+	return proto.EnumName(Kind_name, int32(x))
+}
+func (Kind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// / Resource is a single route, adhoc rule or backend.
+type Resource struct {
+	// / name identifies the resource. Backends are identified by their name, routes by their name or (if not named)
+	// / by their position, the same way as in metrics, and adhoc rules by their position. Positions start at 0.
+	// / It is set by kedge and ignored in requests.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Types that are valid to be assigned to Resource:
+	//	*Resource_GrpcRoute
+	//	*Resource_HttpRoute
+	//	*Resource_TcpRoute
+	//	*Resource_GrpcAdhocRule
+	//	*Resource_HttpAdhocRule
+	//	*Resource_GrpcBackend
+	//	*Resource_HttpBackend
+	//	*Resource_TcpBackend
+	Resource isResource_Resource `protobuf_oneof:"resource"`
+}
+
+func (m *Resource) Reset()                    { *m = Resource{} }
+func (m *Resource) String() string            { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()               {}
+func (*Resource) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type isResource_Resource interface {
+	isResource_Resource()
+}
+
+type Resource_GrpcRoute struct {
+	GrpcRoute *kedge_config_grpc_routes.Route `protobuf:"bytes,2,opt,name=grpc_route,json=grpcRoute,oneof"`
+}
+type Resource_HttpRoute struct {
+	HttpRoute *kedge_config_http_routes.Route `protobuf:"bytes,3,opt,name=http_route,json=httpRoute,oneof"`
+}
+type Resource_TcpRoute struct {
+	TcpRoute *kedge_config_tcp_routes.Route `protobuf:"bytes,4,opt,name=tcp_route,json=tcpRoute,oneof"`
+}
+type Resource_GrpcAdhocRule struct {
+	GrpcAdhocRule *kedge_config_common.Adhoc `protobuf:"bytes,5,opt,name=grpc_adhoc_rule,json=grpcAdhocRule,oneof"`
+}
+type Resource_HttpAdhocRule struct {
+	HttpAdhocRule *kedge_config_common.Adhoc `protobuf:"bytes,6,opt,name=http_adhoc_rule,json=httpAdhocRule,oneof"`
+}
+type Resource_GrpcBackend struct {
+	GrpcBackend *kedge_config_grpc_backends.Backend `protobuf:"bytes,7,opt,name=grpc_backend,json=grpcBackend,oneof"`
+}
+type Resource_HttpBackend struct {
+	HttpBackend *kedge_config_http_backends.Backend `protobuf:"bytes,8,opt,name=http_backend,json=httpBackend,oneof"`
+}
+type Resource_TcpBackend struct {
+	TcpBackend *kedge_config_tcp_backends.Backend `protobuf:"bytes,9,opt,name=tcp_backend,json=tcpBackend,oneof"`
+}
+
+func (*Resource_GrpcRoute) isResource_Resource()     {}
+func (*Resource_HttpRoute) isResource_Resource()     {}
+func (*Resource_TcpRoute) isResource_Resource()      {}
+func (*Resource_GrpcAdhocRule) isResource_Resource() {}
+func (*Resource_HttpAdhocRule) isResource_Resource() {}
+func (*Resource_GrpcBackend) isResource_Resource()   {}
+func (*Resource_HttpBackend) isResource_Resource()   {}
+func (*Resource_TcpBackend) isResource_Resource()    {}
+
+func (m *Resource) GetResource() isResource_Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *Resource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Resource) GetGrpcRoute() *kedge_config_grpc_routes.Route {
+	if x, ok := m.GetResource().(*Resource_GrpcRoute); ok {
+		return x.GrpcRoute
+	}
+	return nil
+}
+
+func (m *Resource) GetHttpRoute() *kedge_config_http_routes.Route {
+	if x, ok := m.GetResource().(*Resource_HttpRoute); ok {
+		return x.HttpRoute
+	}
+	return nil
+}
+
+func (m *Resource) GetTcpRoute() *kedge_config_tcp_routes.Route {
+	if x, ok := m.GetResource().(*Resource_TcpRoute); ok {
+		return x.TcpRoute
+	}
+	return nil
+}
+
+func (m *Resource) GetGrpcAdhocRule() *kedge_config_common.Adhoc {
+	if x, ok := m.GetResource().(*Resource_GrpcAdhocRule); ok {
+		return x.GrpcAdhocRule
+	}
+	return nil
+}
+
+func (m *Resource) GetHttpAdhocRule() *kedge_config_common.Adhoc {
+	if x, ok := m.GetResource().(*Resource_HttpAdhocRule); ok {
+		return x.HttpAdhocRule
+	}
+	return nil
+}
+
+func (m *Resource) GetGrpcBackend() *kedge_config_grpc_backends.Backend {
+	if x, ok := m.GetResource().(*Resource_GrpcBackend); ok {
+		return x.GrpcBackend
+	}
+	return nil
+}
+
+func (m *Resource) GetHttpBackend() *kedge_config_http_backends.Backend {
+	if x, ok := m.GetResource().(*Resource_HttpBackend); ok {
+		return x.HttpBackend
+	}
+	return nil
+}
+
+func (m *Resource) GetTcpBackend() *kedge_config_tcp_backends.Backend {
+	if x, ok := m.GetResource().(*Resource_TcpBackend); ok {
+		return x.TcpBackend
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Resource) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Resource_OneofMarshaler, _Resource_OneofUnmarshaler, _Resource_OneofSizer, []interface{}{
+		(*Resource_GrpcRoute)(nil),
+		(*Resource_HttpRoute)(nil),
+		(*Resource_TcpRoute)(nil),
+		(*Resource_GrpcAdhocRule)(nil),
+		(*Resource_HttpAdhocRule)(nil),
+		(*Resource_GrpcBackend)(nil),
+		(*Resource_HttpBackend)(nil),
+		(*Resource_TcpBackend)(nil),
+	}
+}
+
+func _Resource_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Resource)
+	// resource
+	switch x := m.Resource.(type) {
+	case *Resource_GrpcRoute:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GrpcRoute); err != nil {
+			return err
+		}
+	case *Resource_HttpRoute:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HttpRoute); err != nil {
+			return err
+		}
+	case *Resource_TcpRoute:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TcpRoute); err != nil {
+			return err
+		}
+	case *Resource_GrpcAdhocRule:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GrpcAdhocRule); err != nil {
+			return err
+		}
+	case *Resource_HttpAdhocRule:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HttpAdhocRule); err != nil {
+			return err
+		}
+	case *Resource_GrpcBackend:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GrpcBackend); err != nil {
+			return err
+		}
+	case *Resource_HttpBackend:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HttpBackend); err != nil {
+			return err
+		}
+	case *Resource_TcpBackend:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TcpBackend); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Resource.Resource has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Resource_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Resource)
+	switch tag {
+	case 2: // resource.grpc_route
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_grpc_routes.Route)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_GrpcRoute{msg}
+		return true, err
+	case 3: // resource.http_route
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_http_routes.Route)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_HttpRoute{msg}
+		return true, err
+	case 4: // resource.tcp_route
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_tcp_routes.Route)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_TcpRoute{msg}
+		return true, err
+	case 5: // resource.grpc_adhoc_rule
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_common.Adhoc)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_GrpcAdhocRule{msg}
+		return true, err
+	case 6: // resource.http_adhoc_rule
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_common.Adhoc)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_HttpAdhocRule{msg}
+		return true, err
+	case 7: // resource.grpc_backend
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_grpc_backends.Backend)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_GrpcBackend{msg}
+		return true, err
+	case 8: // resource.http_backend
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_http_backends.Backend)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_HttpBackend{msg}
+		return true, err
+	case 9: // resource.tcp_backend
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_tcp_backends.Backend)
+		err := b.DecodeMessage(msg)
+		m.Resource = &Resource_TcpBackend{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Resource_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Resource)
+	// resource
+	switch x := m.Resource.(type) {
+	case *Resource_GrpcRoute:
+		s := proto.Size(x.GrpcRoute)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_HttpRoute:
+		s := proto.Size(x.HttpRoute)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_TcpRoute:
+		s := proto.Size(x.TcpRoute)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_GrpcAdhocRule:
+		s := proto.Size(x.GrpcAdhocRule)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_HttpAdhocRule:
+		s := proto.Size(x.HttpAdhocRule)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_GrpcBackend:
+		s := proto.Size(x.GrpcBackend)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_HttpBackend:
+		s := proto.Size(x.HttpBackend)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Resource_TcpBackend:
+		s := proto.Size(x.TcpBackend)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ListRequest struct {
+	Kind Kind `protobuf:"varint,1,opt,name=kind,enum=kedge.admin.Kind" json:"kind,omitempty"`
+}
+
+func (m *ListRequest) Reset()                    { *m = ListRequest{} }
+func (m *ListRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()               {}
+func (*ListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ListRequest) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_UNKNOWN
+}
+
+type ListResponse struct {
+	// / resources are in the order they are matched in (routes and adhoc rules).
+	Resources []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	Version   uint64      `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *ListResponse) Reset()                    { *m = ListResponse{} }
+func (m *ListResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()               {}
+func (*ListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ListResponse) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *ListResponse) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type GetRequest struct {
+	Kind Kind   `protobuf:"varint,1,opt,name=kind,enum=kedge.admin.Kind" json:"kind,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *GetRequest) Reset()                    { *m = GetRequest{} }
+func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()               {}
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *GetRequest) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_UNKNOWN
+}
+
+func (m *GetRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type GetResponse struct {
+	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	Version  uint64    `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *GetResponse) Reset()                    { *m = GetResponse{} }
+func (m *GetResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()               {}
+func (*GetResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetResponse) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *GetResponse) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type AddRequest struct {
+	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	// / before is the name of the resource the new one is inserted before. If empty, it is appended.
+	// / It is meaningful only for routes and adhoc rules, whose order matters.
+	Before  string `protobuf:"bytes,2,opt,name=before" json:"before,omitempty"`
+	Version uint64 `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *AddRequest) Reset()                    { *m = AddRequest{} }
+func (m *AddRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddRequest) ProtoMessage()               {}
+func (*AddRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *AddRequest) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *AddRequest) GetBefore() string {
+	if m != nil {
+		return m.Before
+	}
+	return ""
+}
+
+func (m *AddRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// / resource replaces the one with the name. It needs to be of the same kind.
+	Resource *Resource `protobuf:"bytes,2,opt,name=resource" json:"resource,omitempty"`
+	Version  uint64    `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *UpdateRequest) Reset()                    { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()               {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *UpdateRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *UpdateRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	Kind    Kind   `protobuf:"varint,1,opt,name=kind,enum=kedge.admin.Kind" json:"kind,omitempty"`
+	Name    string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Version uint64 `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()               {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *DeleteRequest) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_UNKNOWN
+}
+
+func (m *DeleteRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type ChangeResponse struct {
+	// / version is the version of the config after the change.
+	Version uint64 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *ChangeResponse) Reset()                    { *m = ChangeResponse{} }
+func (m *ChangeResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChangeResponse) ProtoMessage()               {}
+func (*ChangeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *ChangeResponse) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Resource)(nil), "kedge.admin.Resource")
+	proto.RegisterType((*ListRequest)(nil), "kedge.admin.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "kedge.admin.ListResponse")
+	proto.RegisterType((*GetRequest)(nil), "kedge.admin.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "kedge.admin.GetResponse")
+	proto.RegisterType((*AddRequest)(nil), "kedge.admin.AddRequest")
+	proto.RegisterType((*UpdateRequest)(nil), "kedge.admin.UpdateRequest")
+	proto.RegisterType((*DeleteRequest)(nil), "kedge.admin.DeleteRequest")
+	proto.RegisterType((*ChangeResponse)(nil), "kedge.admin.ChangeResponse")
+	proto.RegisterEnum("kedge.admin.Kind", Kind_name, Kind_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Admin service
+
+type AdminClient interface {
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := grpc.Invoke(ctx, "/kedge.admin.Admin/List", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := grpc.Invoke(ctx, "/kedge.admin.Admin/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	out := new(ChangeResponse)
+	err := grpc.Invoke(ctx, "/kedge.admin.Admin/Add", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	out := new(ChangeResponse)
+	err := grpc.Invoke(ctx, "/kedge.admin.Admin/Update", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	out := new(ChangeResponse)
+	err := grpc.Invoke(ctx, "/kedge.admin.Admin/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Admin service
+
+type AdminServer interface {
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Add(context.Context, *AddRequest) (*ChangeResponse, error)
+	Update(context.Context, *UpdateRequest) (*ChangeResponse, error)
+	Delete(context.Context, *DeleteRequest) (*ChangeResponse, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kedge.admin.Admin/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kedge.admin.Admin/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kedge.admin.Admin/Add",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kedge.admin.Admin/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kedge.admin.Admin/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "kedge.admin.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _Admin_List_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Admin_Get_Handler,
+		},
+		{
+			MethodName: "Add",
+			Handler:    _Admin_Add_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Admin_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Admin_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kedge/admin/admin.proto",
+}
+
+func init() { proto.RegisterFile("kedge/admin/admin.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 785 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x95, 0x6f, 0x6b, 0xda, 0x5e,
+	0x14, 0xc7, 0x8d, 0xa6, 0xfe, 0x39, 0xa9, 0xad, 0xbf, 0xfb, 0x63, 0x6b, 0x96, 0xc1, 0x2a, 0xd9,
+	0xca, 0xa4, 0xd0, 0xc8, 0xec, 0x18, 0x6c, 0x50, 0x98, 0xff, 0x50, 0x68, 0xd1, 0x12, 0x94, 0x8d,
+	0xc1, 0x10, 0x4d, 0x6e, 0x35, 0xa8, 0x89, 0x4b, 0xae, 0x2d, 0x7b, 0xb6, 0xb7, 0xb2, 0xd7, 0xb3,
+	0xa7, 0x7b, 0x3e, 0xd8, 0x2b, 0x19, 0xf7, 0x26, 0xd1, 0xdc, 0x9a, 0x76, 0x16, 0xf6, 0xa4, 0xb9,
+	0xe7, 0x9c, 0xef, 0xf9, 0xdc, 0x73, 0xcf, 0x3d, 0xb7, 0xc2, 0xc1, 0x14, 0x9b, 0x63, 0x5c, 0x1e,
+	0x9a, 0x73, 0xcb, 0xf6, 0xff, 0x6a, 0x0b, 0xd7, 0x21, 0x0e, 0x92, 0x58, 0x40, 0x63, 0x2e, 0xe5,
+	0xcd, 0xd8, 0x22, 0x93, 0xe5, 0x48, 0x33, 0x9c, 0x79, 0x79, 0x7e, 0x63, 0x91, 0xa9, 0x73, 0x53,
+	0x1e, 0x3b, 0x27, 0x4c, 0x79, 0x72, 0x3d, 0x9c, 0x59, 0xe6, 0x90, 0x38, 0xae, 0x57, 0x5e, 0x2d,
+	0x7d, 0x88, 0x72, 0xe8, 0xd3, 0x0d, 0xc7, 0xbe, 0xb2, 0xc6, 0x65, 0xc3, 0x99, 0xcf, 0x1d, 0xba,
+	0xcb, 0xc4, 0x31, 0x02, 0x41, 0x89, 0x13, 0x8c, 0xdd, 0x85, 0x51, 0x1e, 0x0d, 0x8d, 0x29, 0xb6,
+	0x4d, 0x2f, 0x5c, 0x04, 0xca, 0xa3, 0x4d, 0xa5, 0xeb, 0x2c, 0x09, 0xf6, 0x82, 0x4f, 0x2c, 0x70,
+	0x42, 0xc8, 0x62, 0x3b, 0x20, 0x53, 0xc6, 0x01, 0x5f, 0x72, 0x32, 0x62, 0xdc, 0xc9, 0x7b, 0xb1,
+	0x21, 0x8c, 0xc1, 0xa9, 0x3f, 0x45, 0xc8, 0xea, 0xd8, 0x73, 0x96, 0xae, 0x81, 0x11, 0x02, 0xd1,
+	0x1e, 0xce, 0xb1, 0x2c, 0x14, 0x85, 0x52, 0x4e, 0x67, 0x6b, 0xf4, 0x1e, 0x80, 0x1e, 0x6e, 0xc0,
+	0xb2, 0xe4, 0x64, 0x51, 0x28, 0x49, 0x95, 0x43, 0xcd, 0xbf, 0x0c, 0x9f, 0xad, 0xd1, 0xb8, 0x16,
+	0x50, 0x75, 0xfa, 0x69, 0x27, 0xf4, 0x1c, 0x75, 0x32, 0x83, 0x12, 0xe8, 0x69, 0x02, 0x42, 0x2a,
+	0x8e, 0x40, 0xe3, 0x1b, 0x04, 0xea, 0xf4, 0x09, 0x67, 0x90, 0x23, 0x46, 0x08, 0x10, 0x19, 0xe0,
+	0x19, 0x0f, 0x20, 0xc6, 0x46, 0x7e, 0x96, 0x18, 0x41, 0x7a, 0x03, 0xf6, 0xd9, 0x11, 0xd8, 0x45,
+	0x0f, 0xdc, 0xe5, 0x0c, 0xcb, 0x3b, 0x0c, 0xa2, 0xf0, 0x10, 0x7f, 0x1e, 0xb4, 0x2a, 0x95, 0xb5,
+	0x13, 0x7a, 0x9e, 0x26, 0x31, 0x43, 0x5f, 0xce, 0x18, 0x85, 0x1d, 0x23, 0x42, 0x49, 0x6f, 0x43,
+	0xa1, 0x49, 0x6b, 0x4a, 0x1b, 0x76, 0x59, 0x2d, 0xc1, 0x5d, 0xc9, 0x19, 0x86, 0x78, 0x1e, 0xd3,
+	0xd0, 0xf0, 0x5a, 0xb5, 0x9a, 0xbf, 0x68, 0x27, 0x74, 0x89, 0x06, 0x02, 0x93, 0x92, 0x58, 0x3d,
+	0x21, 0x29, 0x1b, 0x47, 0x62, 0x8d, 0x8d, 0x23, 0xd1, 0x40, 0x48, 0x6a, 0x82, 0x44, 0xdb, 0x1b,
+	0x82, 0x72, 0x0c, 0xa4, 0x6e, 0x36, 0x38, 0x86, 0x03, 0xc4, 0x08, 0x31, 0x35, 0x80, 0xac, 0x1b,
+	0x4c, 0x92, 0xfa, 0x1a, 0xa4, 0x0b, 0xcb, 0x23, 0x3a, 0xfe, 0xb2, 0xc4, 0x1e, 0x41, 0x47, 0x20,
+	0x4e, 0x2d, 0xdb, 0x64, 0x83, 0xb5, 0x57, 0xf9, 0x4f, 0x8b, 0xbc, 0x65, 0xed, 0xdc, 0xb2, 0x4d,
+	0x9d, 0x85, 0xd5, 0xcf, 0xb0, 0xeb, 0x67, 0x79, 0x0b, 0xc7, 0xf6, 0x30, 0x3a, 0x85, 0x5c, 0x48,
+	0xf4, 0x64, 0xa1, 0x98, 0x2a, 0x49, 0x95, 0x47, 0x5c, 0x6e, 0x38, 0xb9, 0xfa, 0x5a, 0x87, 0x64,
+	0xc8, 0x5c, 0x63, 0xd7, 0xb3, 0x1c, 0x9b, 0x4d, 0xab, 0xa8, 0x87, 0xa6, 0xda, 0x05, 0x68, 0xe1,
+	0x07, 0xd6, 0x84, 0x94, 0xe0, 0x4d, 0x50, 0x56, 0xae, 0x96, 0xfe, 0xfd, 0xeb, 0x30, 0xf9, 0x51,
+	0xf0, 0xdf, 0x86, 0xfa, 0x09, 0xa4, 0x16, 0x5e, 0x97, 0xfb, 0x6a, 0xdd, 0x00, 0x46, 0xbd, 0xb3,
+	0xda, 0x95, 0xec, 0x9e, 0x62, 0xbf, 0x02, 0x54, 0x4d, 0x33, 0x2c, 0xf6, 0xed, 0x96, 0x68, 0xbf,
+	0xc0, 0xa2, 0x10, 0xd9, 0xe2, 0x31, 0xa4, 0x47, 0xf8, 0xca, 0x71, 0x83, 0x23, 0xe8, 0x81, 0x15,
+	0xdd, 0x3a, 0xc5, 0x6f, 0xfd, 0x4d, 0x80, 0x7c, 0x7f, 0x61, 0x0e, 0x09, 0x0e, 0xb7, 0x57, 0xa2,
+	0xff, 0x18, 0xf8, 0x26, 0x70, 0xa5, 0x25, 0x1f, 0x56, 0xda, 0xdd, 0x25, 0xcc, 0x20, 0xdf, 0xc0,
+	0x33, 0x4c, 0xf0, 0xbf, 0xbb, 0xad, 0x7b, 0x76, 0x3b, 0x86, 0xbd, 0xfa, 0x64, 0x68, 0x8f, 0xf1,
+	0xea, 0x2a, 0x23, 0x5a, 0x81, 0xd3, 0x1e, 0x7f, 0x17, 0x40, 0xa4, 0x1b, 0x22, 0x09, 0x32, 0xfd,
+	0xce, 0x79, 0xa7, 0xfb, 0xa1, 0x53, 0x48, 0xa0, 0x3d, 0x80, 0x96, 0x7e, 0x59, 0x1f, 0xe8, 0xdd,
+	0x7e, 0xaf, 0x59, 0x10, 0xa8, 0xdd, 0xee, 0xf5, 0x2e, 0x03, 0x3b, 0x89, 0xf2, 0x90, 0xeb, 0xd5,
+	0x43, 0x33, 0x85, 0xfe, 0x87, 0x7d, 0x26, 0xaf, 0x36, 0xda, 0xdd, 0xfa, 0x40, 0xef, 0x5f, 0x34,
+	0x0b, 0x22, 0x75, 0xb2, 0x9c, 0x88, 0x73, 0x07, 0x15, 0x60, 0x97, 0x29, 0x6b, 0xd5, 0xfa, 0x79,
+	0xb3, 0xd3, 0x28, 0xa4, 0xa9, 0x87, 0xc9, 0x42, 0x4f, 0x06, 0xed, 0x83, 0xd4, 0xab, 0xaf, 0x1d,
+	0xd9, 0xca, 0x8f, 0x24, 0xec, 0x54, 0x69, 0x6b, 0xd0, 0x19, 0x88, 0xf4, 0x45, 0x21, 0x99, 0x6b,
+	0x58, 0xe4, 0x69, 0x2a, 0x4f, 0x62, 0x22, 0x7e, 0x13, 0xd4, 0x04, 0x7a, 0x07, 0xa9, 0x16, 0x26,
+	0xe8, 0x80, 0xd3, 0xac, 0xdf, 0x90, 0x22, 0x6f, 0x06, 0x56, 0xb9, 0x67, 0x90, 0xaa, 0x9a, 0xe6,
+	0xad, 0xdc, 0xf5, 0x48, 0x2b, 0x4f, 0xb9, 0x00, 0xdf, 0x7f, 0x35, 0x81, 0xea, 0x90, 0xf6, 0x67,
+	0x10, 0x29, 0x9c, 0x90, 0x1b, 0xcc, 0x2d, 0x20, 0xfe, 0x18, 0xdd, 0x82, 0x70, 0xb3, 0xf5, 0x17,
+	0xc8, 0x28, 0xcd, 0x7e, 0x29, 0x4f, 0xff, 0x0c, 0x00, 0x16, 0x81, 0x2c, 0xa8, 0x9b, 0x08, 0x00,
+	0x00,
+}
@@ -0,0 +1,160 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: kedge/admin/admin.proto
+
+/*
+Package kedge_admin is a generated protocol buffer package.
+
+It is generated from these files:
+	kedge/admin/admin.proto
+
+It has these top-level messages:
+	Resource
+	ListRequest
+	ListResponse
+	GetRequest
+	GetResponse
+	AddRequest
+	UpdateRequest
+	DeleteRequest
+	ChangeResponse
+*/
+package kedge_admin
+
+import fmt "fmt"
+import go_proto_validators "github.com/mwitkow/go-proto-validators"
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+func (this *Resource) Validate() error {
+	if oneOfNester, ok := this.GetResource().(*Resource_GrpcRoute); ok {
+		if oneOfNester.GrpcRoute != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.GrpcRoute); err != nil {
+				return go_proto_validators.FieldError("GrpcRoute", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_HttpRoute); ok {
+		if oneOfNester.HttpRoute != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.HttpRoute); err != nil {
+				return go_proto_validators.FieldError("HttpRoute", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_TcpRoute); ok {
+		if oneOfNester.TcpRoute != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.TcpRoute); err != nil {
+				return go_proto_validators.FieldError("TcpRoute", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_GrpcAdhocRule); ok {
+		if oneOfNester.GrpcAdhocRule != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.GrpcAdhocRule); err != nil {
+				return go_proto_validators.FieldError("GrpcAdhocRule", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_HttpAdhocRule); ok {
+		if oneOfNester.HttpAdhocRule != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.HttpAdhocRule); err != nil {
+				return go_proto_validators.FieldError("HttpAdhocRule", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_GrpcBackend); ok {
+		if oneOfNester.GrpcBackend != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.GrpcBackend); err != nil {
+				return go_proto_validators.FieldError("GrpcBackend", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_HttpBackend); ok {
+		if oneOfNester.HttpBackend != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.HttpBackend); err != nil {
+				return go_proto_validators.FieldError("HttpBackend", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetResource().(*Resource_TcpBackend); ok {
+		if oneOfNester.TcpBackend != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.TcpBackend); err != nil {
+				return go_proto_validators.FieldError("TcpBackend", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *ListRequest) Validate() error {
+	return nil
+}
+func (this *ListResponse) Validate() error {
+	for _, item := range this.Resources {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("Resources", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *GetRequest) Validate() error {
+	if this.Name == "" {
+		return go_proto_validators.FieldError("Name", fmt.Errorf(`value '%v' must not be an empty string`, this.Name))
+	}
+	return nil
+}
+func (this *GetResponse) Validate() error {
+	if this.Resource != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Resource); err != nil {
+			return go_proto_validators.FieldError("Resource", err)
+		}
+	}
+	return nil
+}
+func (this *AddRequest) Validate() error {
+	if nil == this.Resource {
+		return go_proto_validators.FieldError("Resource", fmt.Errorf("message must exist"))
+	}
+	if this.Resource != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Resource); err != nil {
+			return go_proto_validators.FieldError("Resource", err)
+		}
+	}
+	return nil
+}
+func (this *UpdateRequest) Validate() error {
+	if this.Name == "" {
+		return go_proto_validators.FieldError("Name", fmt.Errorf(`value '%v' must not be an empty string`, this.Name))
+	}
+	if nil == this.Resource {
+		return go_proto_validators.FieldError("Resource", fmt.Errorf("message must exist"))
+	}
+	if this.Resource != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Resource); err != nil {
+			return go_proto_validators.FieldError("Resource", err)
+		}
+	}
+	return nil
+}
+func (this *DeleteRequest) Validate() error {
+	if this.Name == "" {
+		return go_proto_validators.FieldError("Name", fmt.Errorf(`value '%v' must not be an empty string`, this.Name))
+	}
+	return nil
+}
+func (this *ChangeResponse) Validate() error {
+	return nil
+}
@@ -21,6 +21,7 @@ import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import kedge_config_grpc_backends "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 import kedge_config_http_backends "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
+import kedge_config_tcp_backends "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -33,11 +34,42 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type TlsServerConfig_Version int32
+
+const (
+	TlsServerConfig_TLS_DEFAULT TlsServerConfig_Version = 0
+	TlsServerConfig_TLS_1_0     TlsServerConfig_Version = 1
+	TlsServerConfig_TLS_1_1     TlsServerConfig_Version = 2
+	TlsServerConfig_TLS_1_2     TlsServerConfig_Version = 3
+	TlsServerConfig_TLS_1_3     TlsServerConfig_Version = 4
+)
+
+var TlsServerConfig_Version_name = map[int32]string{
+	0: "TLS_DEFAULT",
+	1: "TLS_1_0",
+	2: "TLS_1_1",
+	3: "TLS_1_2",
+	4: "TLS_1_3",
+}
+var TlsServerConfig_Version_value = map[string]int32{
+	"TLS_DEFAULT": 0,
+	"TLS_1_0":     1,
+	"TLS_1_1":     2,
+	"TLS_1_2":     3,
+	"TLS_1_3":     4,
+}
+
+func (x TlsServerConfig_Version) String() string {
+	return proto.EnumName(TlsServerConfig_Version_name, int32(x))
+}
+func (TlsServerConfig_Version) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1, 0} }
+
 // / Config is the top level configuration message for a backend pool.
 type BackendPoolConfig struct {
 	TlsServerConfigs []*TlsServerConfig      `protobuf:"bytes,1,rep,name=tls_server_configs,json=tlsServerConfigs" json:"tls_server_configs,omitempty"`
 	Grpc             *BackendPoolConfig_Grpc `protobuf:"bytes,2,opt,name=grpc" json:"grpc,omitempty"`
 	Http             *BackendPoolConfig_Http `protobuf:"bytes,3,opt,name=http" json:"http,omitempty"`
+	Tcp              *BackendPoolConfig_Tcp  `protobuf:"bytes,4,opt,name=tcp" json:"tcp,omitempty"`
 }
 
 func (m *BackendPoolConfig) Reset()                    { *m = BackendPoolConfig{} }
@@ -66,6 +98,13 @@ func (m *BackendPoolConfig) GetHttp() *BackendPoolConfig_Http {
 	return nil
 }
 
+func (m *BackendPoolConfig) GetTcp() *BackendPoolConfig_Tcp {
+	if m != nil {
+		return m.Tcp
+	}
+	return nil
+}
+
 type BackendPoolConfig_Grpc struct {
 	Backends []*kedge_config_grpc_backends.Backend `protobuf:"bytes,1,rep,name=backends" json:"backends,omitempty"`
 }
@@ -98,8 +137,40 @@ func (m *BackendPoolConfig_Http) GetBackends() []*kedge_config_http_backends.Bac
 	return nil
 }
 
+type BackendPoolConfig_Tcp struct {
+	Backends []*kedge_config_tcp_backends.Backend `protobuf:"bytes,1,rep,name=backends" json:"backends,omitempty"`
+}
+
+func (m *BackendPoolConfig_Tcp) Reset()                    { *m = BackendPoolConfig_Tcp{} }
+func (m *BackendPoolConfig_Tcp) String() string            { return proto.CompactTextString(m) }
+func (*BackendPoolConfig_Tcp) ProtoMessage()               {}
+func (*BackendPoolConfig_Tcp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 2} }
+
+func (m *BackendPoolConfig_Tcp) GetBackends() []*kedge_config_tcp_backends.Backend {
+	if m != nil {
+		return m.Backends
+	}
+	return nil
+}
+
+// / TlsServerConfig is a named TLS configuration used for connections to backend servers.
+// / Backends refer to it using Security.config_name.
 type TlsServerConfig struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// / ca_files is a list of paths to PEM-encoded CA certificate chains used to verify the backend's server certificate.
+	// / If empty, root CAs are fetched from host.
+	CaFiles []string `protobuf:"bytes,2,rep,name=ca_files,json=caFiles" json:"ca_files,omitempty"`
+	// / cert_file is a path to the PEM-encoded client certificate presented to the backend (mutual TLS).
+	// / If present, key_file needs to be specified as well.
+	CertFile string `protobuf:"bytes,3,opt,name=cert_file,json=certFile" json:"cert_file,omitempty"`
+	// / key_file is a path to the PEM-encoded key for the cert_file.
+	KeyFile string `protobuf:"bytes,4,opt,name=key_file,json=keyFile" json:"key_file,omitempty"`
+	// / server_name overrides the name used to verify the backend's server certificate. It is also sent as SNI.
+	// / NOTE: HTTP backends are dialed using resolved IP addresses, so this is required if the backend's certificate
+	// / does not contain them.
+	ServerName string `protobuf:"bytes,5,opt,name=server_name,json=serverName" json:"server_name,omitempty"`
+	// / min_version is the minimum TLS version accepted for the connection. Defaults to TLS 1.2.
+	MinVersion TlsServerConfig_Version `protobuf:"varint,6,opt,name=min_version,json=minVersion,enum=kedge.config.TlsServerConfig_Version" json:"min_version,omitempty"`
 }
 
 func (m *TlsServerConfig) Reset()                    { *m = TlsServerConfig{} }
@@ -114,35 +185,84 @@ func (m *TlsServerConfig) GetName() string {
 	return ""
 }
 
+func (m *TlsServerConfig) GetCaFiles() []string {
+	if m != nil {
+		return m.CaFiles
+	}
+	return nil
+}
+
+func (m *TlsServerConfig) GetCertFile() string {
+	if m != nil {
+		return m.CertFile
+	}
+	return ""
+}
+
+func (m *TlsServerConfig) GetKeyFile() string {
+	if m != nil {
+		return m.KeyFile
+	}
+	return ""
+}
+
+func (m *TlsServerConfig) GetServerName() string {
+	if m != nil {
+		return m.ServerName
+	}
+	return ""
+}
+
+func (m *TlsServerConfig) GetMinVersion() TlsServerConfig_Version {
+	if m != nil {
+		return m.MinVersion
+	}
+	return TlsServerConfig_TLS_DEFAULT
+}
+
 func init() {
 	proto.RegisterType((*BackendPoolConfig)(nil), "kedge.config.BackendPoolConfig")
 	proto.RegisterType((*BackendPoolConfig_Grpc)(nil), "kedge.config.BackendPoolConfig.Grpc")
 	proto.RegisterType((*BackendPoolConfig_Http)(nil), "kedge.config.BackendPoolConfig.Http")
+	proto.RegisterType((*BackendPoolConfig_Tcp)(nil), "kedge.config.BackendPoolConfig.Tcp")
 	proto.RegisterType((*TlsServerConfig)(nil), "kedge.config.TlsServerConfig")
+	proto.RegisterEnum("kedge.config.TlsServerConfig_Version", TlsServerConfig_Version_name, TlsServerConfig_Version_value)
 }
 
 func init() { proto.RegisterFile("kedge/config/backendpool.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 318 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0x4d, 0x4b, 0xc3, 0x30,
-	0x18, 0xc7, 0xe9, 0x56, 0x44, 0x33, 0x61, 0x2e, 0x20, 0x94, 0x82, 0x5a, 0xe6, 0x0e, 0x15, 0x6c,
-	0x0a, 0x55, 0x86, 0x07, 0x41, 0x98, 0x87, 0x09, 0x5e, 0xa4, 0x7a, 0x13, 0x2d, 0x7d, 0x89, 0x5d,
-	0xe9, 0x4b, 0x42, 0x12, 0x37, 0x50, 0xfc, 0xac, 0x82, 0x07, 0x3f, 0x87, 0x24, 0xb5, 0xc3, 0xfa,
-	0x7e, 0x0b, 0xc9, 0xff, 0xf7, 0xfb, 0x3f, 0x4f, 0x0b, 0xb6, 0x73, 0x9c, 0xa4, 0xd8, 0x8d, 0x49,
-	0x75, 0x97, 0xa5, 0x6e, 0x14, 0xc6, 0x39, 0xae, 0x12, 0x4a, 0x48, 0x81, 0x28, 0x23, 0x82, 0xc0,
-	0x75, 0xf5, 0x8e, 0xea, 0x77, 0x73, 0x9c, 0x66, 0x62, 0x76, 0x1f, 0xa1, 0x98, 0x94, 0x6e, 0xb9,
-	0xc8, 0x44, 0x4e, 0x16, 0x6e, 0x4a, 0x1c, 0x15, 0x75, 0xe6, 0x61, 0x91, 0x25, 0xa1, 0x20, 0x8c,
-	0xbb, 0xcb, 0x63, 0x6d, 0x31, 0xed, 0x56, 0x4b, 0xca, 0x68, 0xdc, 0x54, 0xf1, 0xe6, 0xf0, 0x6d,
-	0x72, 0x26, 0x04, 0xfd, 0x21, 0x39, 0x7c, 0xed, 0x80, 0xc1, 0xa4, 0xbe, 0xb9, 0x20, 0xa4, 0x38,
-	0x55, 0x04, 0x3c, 0x07, 0x50, 0x14, 0x3c, 0xe0, 0x98, 0xcd, 0x31, 0x0b, 0x6a, 0x0d, 0x37, 0x34,
-	0xab, 0x6b, 0xf7, 0xbc, 0x2d, 0xf4, 0x71, 0x19, 0x74, 0x55, 0xf0, 0x4b, 0x15, 0xab, 0x51, 0x7f,
-	0x43, 0xb4, 0x2f, 0x38, 0x3c, 0x02, 0xba, 0x9c, 0xd5, 0xe8, 0x58, 0x9a, 0xdd, 0xf3, 0x46, 0x6d,
-	0xfc, 0x4b, 0x37, 0x9a, 0x32, 0x1a, 0xfb, 0x8a, 0x90, 0xa4, 0x9c, 0xdd, 0xe8, 0xfe, 0x8f, 0x3c,
-	0x13, 0x82, 0xfa, 0x8a, 0x30, 0xa7, 0x40, 0x97, 0x1e, 0x78, 0x02, 0x56, 0x9b, 0xc5, 0xdf, 0xc7,
-	0xdf, 0x6d, 0x5b, 0x64, 0x0f, 0x6a, 0x22, 0x8d, 0xd3, 0x5f, 0x42, 0x52, 0x24, 0xb5, 0x7f, 0x8b,
-	0x64, 0xed, 0x2f, 0xa2, 0xe1, 0x31, 0xe8, 0x7f, 0xfa, 0x54, 0x70, 0x0f, 0xe8, 0x55, 0x58, 0x62,
-	0x43, 0xb3, 0x34, 0x7b, 0x6d, 0xb2, 0xf9, 0xf2, 0xbc, 0x33, 0x00, 0xfd, 0xdb, 0xeb, 0xd0, 0x79,
-	0x08, 0xd0, 0xcd, 0xa3, 0xb7, 0x3f, 0x3e, 0x7c, 0x1a, 0xf9, 0x2a, 0x12, 0xad, 0xa8, 0xbf, 0x75,
-	0xf0, 0x16, 0x00, 0x00, 0xff, 0xff, 0x85, 0x9f, 0x6a, 0xa2, 0x69, 0x02, 0x00, 0x00,
+	// 497 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0xef, 0x6a, 0xd4, 0x40,
+	0x10, 0x37, 0x97, 0xd8, 0xbb, 0x9b, 0x88, 0x97, 0x2e, 0x08, 0xf1, 0x44, 0x7b, 0x9c, 0x15, 0x23,
+	0x78, 0x89, 0x4d, 0xb5, 0xf8, 0x49, 0xb1, 0xda, 0xab, 0x60, 0x29, 0x92, 0x46, 0xbf, 0x88, 0x86,
+	0xdc, 0xde, 0x36, 0x0d, 0xf9, 0xb3, 0x21, 0xbb, 0x5e, 0xa9, 0xe2, 0xfb, 0xf9, 0x16, 0x82, 0xf8,
+	0x20, 0xb2, 0x9b, 0x4b, 0xcf, 0xb4, 0xf6, 0xda, 0x6f, 0x33, 0xf3, 0xfb, 0x33, 0x33, 0xd9, 0x09,
+	0xdc, 0x4b, 0xc8, 0x34, 0x22, 0x0e, 0xa6, 0xf9, 0x61, 0x1c, 0x39, 0x93, 0x10, 0x27, 0x24, 0x9f,
+	0x16, 0x94, 0xa6, 0x76, 0x51, 0x52, 0x4e, 0xd1, 0x0d, 0x89, 0xdb, 0x15, 0xde, 0xdf, 0x8a, 0x62,
+	0x7e, 0xf4, 0x75, 0x62, 0x63, 0x9a, 0x39, 0xd9, 0x71, 0xcc, 0x13, 0x7a, 0xec, 0x44, 0x74, 0x24,
+	0xa9, 0xa3, 0x59, 0x98, 0xc6, 0xd3, 0x90, 0xd3, 0x92, 0x39, 0xa7, 0x61, 0xe5, 0xd2, 0xb7, 0x1a,
+	0x5d, 0xa2, 0xb2, 0xc0, 0x75, 0x2b, 0x56, 0x07, 0xff, 0x65, 0x1e, 0x71, 0x5e, 0x5c, 0xc4, 0x7c,
+	0xd8, 0x60, 0x72, 0x7c, 0x11, 0x71, 0xf8, 0x47, 0x85, 0xd5, 0xed, 0xaa, 0xf2, 0x9e, 0xd2, 0xf4,
+	0xb5, 0x14, 0xa0, 0x77, 0x80, 0x78, 0xca, 0x02, 0x46, 0xca, 0x19, 0x29, 0x83, 0xca, 0x85, 0x99,
+	0xca, 0x40, 0xb5, 0x74, 0xf7, 0xae, 0xfd, 0xef, 0xd6, 0xb6, 0x9f, 0xb2, 0x03, 0x49, 0xab, 0xa4,
+	0x9e, 0xc1, 0x9b, 0x05, 0x86, 0x9e, 0x83, 0x26, 0x96, 0x32, 0x5b, 0x03, 0xc5, 0xd2, 0xdd, 0xf5,
+	0xa6, 0xfc, 0x5c, 0x6f, 0x7b, 0xb7, 0x2c, 0xb0, 0x27, 0x15, 0x42, 0x29, 0x96, 0x34, 0xd5, 0xab,
+	0x29, 0xdf, 0x72, 0x5e, 0x78, 0x52, 0x81, 0x9e, 0x81, 0xca, 0x71, 0x61, 0x6a, 0x52, 0x78, 0xff,
+	0x32, 0xa1, 0x8f, 0x0b, 0x4f, 0xf0, 0xfb, 0xbb, 0xa0, 0x89, 0xf6, 0xe8, 0x25, 0x74, 0xea, 0xef,
+	0x35, 0xdf, 0xfa, 0x8c, 0x87, 0x18, 0xcf, 0xae, 0x29, 0xb5, 0xa3, 0x77, 0x2a, 0x12, 0x46, 0x62,
+	0x9a, 0xcb, 0x8d, 0xc4, 0xb4, 0xcb, 0x8c, 0x76, 0x40, 0xf5, 0x71, 0x81, 0x5e, 0x9c, 0xf3, 0x19,
+	0x36, 0x7d, 0x38, 0x5e, 0x66, 0x33, 0xfc, 0xd9, 0x82, 0xde, 0x99, 0x97, 0x42, 0x8f, 0x40, 0xcb,
+	0xc3, 0x8c, 0x98, 0xca, 0x40, 0xb1, 0xba, 0xdb, 0xb7, 0x7e, 0xff, 0x5a, 0x5b, 0x85, 0xde, 0x97,
+	0x4f, 0xe1, 0xe8, 0x5b, 0x60, 0x7f, 0xfe, 0xee, 0x3e, 0xde, 0x7a, 0xfa, 0x63, 0xdd, 0x93, 0x14,
+	0x74, 0x1b, 0x3a, 0x38, 0x0c, 0x0e, 0xe3, 0x94, 0x30, 0xb3, 0x35, 0x50, 0xad, 0xae, 0xd7, 0xc6,
+	0xe1, 0x58, 0xa4, 0xe8, 0x0e, 0x74, 0x31, 0x29, 0xb9, 0x04, 0xe5, 0x43, 0x75, 0xbd, 0x8e, 0x28,
+	0x08, 0x54, 0xe8, 0x12, 0x72, 0x52, 0x61, 0x9a, 0xc4, 0xda, 0x09, 0x39, 0x91, 0xd0, 0x1a, 0xe8,
+	0xf3, 0xf3, 0x92, 0x43, 0x5c, 0x97, 0x28, 0x54, 0xa5, 0x7d, 0xd1, 0x73, 0x0c, 0x7a, 0x16, 0xe7,
+	0xc1, 0x8c, 0x94, 0x2c, 0xa6, 0xb9, 0xb9, 0x32, 0x50, 0xac, 0x9b, 0xee, 0x83, 0xa5, 0xc7, 0x67,
+	0x7f, 0xac, 0xc8, 0x1e, 0x64, 0x71, 0x3e, 0x8f, 0x87, 0xfb, 0xd0, 0x9e, 0x87, 0xa8, 0x07, 0xba,
+	0xbf, 0x77, 0x10, 0xbc, 0xd9, 0x19, 0xbf, 0xfa, 0xb0, 0xe7, 0x1b, 0xd7, 0x90, 0x0e, 0x6d, 0x51,
+	0xd8, 0x08, 0x9e, 0x18, 0xca, 0x22, 0xd9, 0x30, 0x5a, 0x8b, 0xc4, 0x35, 0xd4, 0x45, 0xb2, 0x69,
+	0x68, 0x93, 0x15, 0xf9, 0xe3, 0x6c, 0xfe, 0x1d, 0x00, 0xfa, 0x23, 0x46, 0xcd, 0x1d, 0x04, 0x00,
+	0x00,
 }
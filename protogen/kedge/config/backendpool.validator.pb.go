@@ -23,6 +23,7 @@ import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/http/backends"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/backends"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -47,6 +48,11 @@ func (this *BackendPoolConfig) Validate() error {
 			return go_proto_validators.FieldError("Http", err)
 		}
 	}
+	if this.Tcp != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Tcp); err != nil {
+			return go_proto_validators.FieldError("Tcp", err)
+		}
+	}
 	return nil
 }
 func (this *BackendPoolConfig_Grpc) Validate() error {
@@ -69,6 +75,16 @@ func (this *BackendPoolConfig_Http) Validate() error {
 	}
 	return nil
 }
+func (this *BackendPoolConfig_Tcp) Validate() error {
+	for _, item := range this.Backends {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("Backends", err)
+			}
+		}
+	}
+	return nil
+}
 
 var _regex_TlsServerConfig_Name = regexp.MustCompile(`^[a-z_.]{2,64}$`)
 
@@ -6,9 +6,15 @@ Package kedge_config_common is a generated protocol buffer package.
 
 It is generated from these files:
 	kedge/config/common/adhoc.proto
+	kedge/config/common/healthcheck.proto
+	kedge/config/common/matcher.proto
+	kedge/config/common/ratelimit.proto
 
 It has these top-level messages:
 	Adhoc
+	HealthCheck
+	StringMatcher
+	RateLimit
 */
 package kedge_config_common
 
@@ -47,6 +53,9 @@ type Adhoc struct {
 	// / you want this abc service/pod to be accessible as 'abc.default.svc.cluster1.example.com'. In this case you want
 	// / to set dns_name_replace.pattern "cluster1.example.com" , dns_name_replace.substitution="cluster.local"
 	DnsNameReplace *Adhoc_Replace `protobuf:"bytes,3,opt,name=dns_name_replace,json=dnsNameReplace" json:"dns_name_replace,omitempty"`
+	// / tls enables TLS for connections to the adhoc target. If not set, the target is dialed in plain text.
+	// / Currently it is honoured only by gRPC adhoc rules.
+	Tls *Adhoc_Tls `protobuf:"bytes,4,opt,name=tls" json:"tls,omitempty"`
 }
 
 func (m *Adhoc) Reset()                    { *m = Adhoc{} }
@@ -75,6 +84,13 @@ func (m *Adhoc) GetDnsNameReplace() *Adhoc_Replace {
 	return nil
 }
 
+func (m *Adhoc) GetTls() *Adhoc_Tls {
+	if m != nil {
+		return m.Tls
+	}
+	return nil
+}
+
 // / Port controls how the :port part of the URI is processed.
 type Adhoc_Port struct {
 	// / default is the default port used if no entry is present.
@@ -139,8 +155,7 @@ func (m *Adhoc_Port_Range) GetTo() uint32 {
 }
 
 type Adhoc_Replace struct {
-	// pattern specified pattern to substitute the hostname with. If not pattern is not found, nothing is substituted
-	// with warning.
+	// pattern specified pattern to substitute the hostname with. If not pattern is not found error is returned (!).
 	Pattern      string `protobuf:"bytes,1,opt,name=pattern" json:"pattern,omitempty"`
 	Substitution string `protobuf:"bytes,2,opt,name=substitution" json:"substitution,omitempty"`
 }
@@ -164,37 +179,70 @@ func (m *Adhoc_Replace) GetSubstitution() string {
 	return ""
 }
 
+// / Tls controls how connections to the adhoc target are secured.
+type Adhoc_Tls struct {
+	// / insecure_skip_verify disables verification of the target's certificate chain and host name.
+	InsecureSkipVerify bool `protobuf:"varint,1,opt,name=insecure_skip_verify,json=insecureSkipVerify" json:"insecure_skip_verify,omitempty"`
+	// / server_name is used to verify the target's certificate. Defaults to the requested host name.
+	ServerName string `protobuf:"bytes,2,opt,name=server_name,json=serverName" json:"server_name,omitempty"`
+}
+
+func (m *Adhoc_Tls) Reset()                    { *m = Adhoc_Tls{} }
+func (m *Adhoc_Tls) String() string            { return proto.CompactTextString(m) }
+func (*Adhoc_Tls) ProtoMessage()               {}
+func (*Adhoc_Tls) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 2} }
+
+func (m *Adhoc_Tls) GetInsecureSkipVerify() bool {
+	if m != nil {
+		return m.InsecureSkipVerify
+	}
+	return false
+}
+
+func (m *Adhoc_Tls) GetServerName() string {
+	if m != nil {
+		return m.ServerName
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Adhoc)(nil), "kedge.config.common.Adhoc")
 	proto.RegisterType((*Adhoc_Port)(nil), "kedge.config.common.Adhoc.Port")
 	proto.RegisterType((*Adhoc_Port_Range)(nil), "kedge.config.common.Adhoc.Port.Range")
 	proto.RegisterType((*Adhoc_Replace)(nil), "kedge.config.common.Adhoc.Replace")
+	proto.RegisterType((*Adhoc_Tls)(nil), "kedge.config.common.Adhoc.Tls")
 }
 
 func init() { proto.RegisterFile("kedge/config/common/adhoc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 351 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x51, 0x4d, 0x4b, 0xeb, 0x40,
-	0x14, 0x25, 0x1f, 0x6d, 0x79, 0xd3, 0xd7, 0xf2, 0x98, 0xb7, 0x09, 0xd9, 0x34, 0x14, 0x84, 0xa2,
-	0x34, 0x91, 0x0a, 0x6e, 0x5c, 0xe9, 0xba, 0x8a, 0x64, 0xe3, 0xb2, 0x4c, 0x33, 0xd3, 0x34, 0x34,
-	0x33, 0x37, 0xcc, 0xdc, 0xd8, 0x5f, 0xe6, 0x4f, 0x71, 0x2d, 0xf8, 0x4b, 0x24, 0x93, 0xb4, 0x7e,
-	0x20, 0xba, 0x3b, 0x27, 0xe7, 0xdc, 0x33, 0xe7, 0xde, 0x90, 0xc9, 0x4e, 0xf0, 0x5c, 0x24, 0x19,
-	0xa8, 0x4d, 0x91, 0x27, 0x19, 0x48, 0x09, 0x2a, 0x61, 0x7c, 0x0b, 0x59, 0x5c, 0x69, 0x40, 0xa0,
-	0xff, 0xad, 0x21, 0x6e, 0x0d, 0x71, 0x6b, 0x08, 0x2f, 0xf3, 0x02, 0xb7, 0xf5, 0xba, 0xa1, 0x89,
-	0xdc, 0x17, 0xb8, 0x83, 0x7d, 0x92, 0xc3, 0xdc, 0x4e, 0xcc, 0x1f, 0x59, 0x59, 0x70, 0x86, 0xa0,
-	0x4d, 0x72, 0x84, 0x6d, 0xd8, 0xf4, 0xd9, 0x23, 0xbd, 0xeb, 0x26, 0x9c, 0x9e, 0x93, 0x7f, 0x5c,
-	0x99, 0x95, 0x62, 0x52, 0xac, 0x24, 0xc3, 0x6c, 0x2b, 0x74, 0xe0, 0x44, 0xce, 0xec, 0xcf, 0x4d,
-	0xff, 0xf5, 0x65, 0xe2, 0x46, 0x4e, 0x3a, 0xe6, 0xca, 0xdc, 0x31, 0x29, 0x6e, 0x5b, 0x95, 0x5e,
-	0x11, 0xbf, 0x02, 0x8d, 0x81, 0x1b, 0x39, 0xb3, 0xe1, 0x62, 0x12, 0x7f, 0xd3, 0x2b, 0xb6, 0xd9,
-	0xf1, 0x3d, 0x68, 0x3c, 0xc6, 0xd8, 0x21, 0xba, 0xfc, 0xf0, 0x9c, 0x16, 0x55, 0xc9, 0x32, 0x11,
-	0x78, 0x36, 0x68, 0xfa, 0x43, 0x50, 0xda, 0x3a, 0x8f, 0x55, 0x3a, 0x1e, 0x3e, 0x39, 0xc4, 0x6f,
-	0x1e, 0xa1, 0x01, 0x19, 0x70, 0xb1, 0x61, 0x75, 0x89, 0xb6, 0xfc, 0x28, 0x3d, 0xd0, 0x46, 0x61,
-	0x65, 0x09, 0x7b, 0xc1, 0x03, 0x2f, 0xf2, 0x1a, 0xa5, 0xa3, 0x74, 0x49, 0xc6, 0x1d, 0x5c, 0x69,
-	0xa6, 0x72, 0x61, 0x02, 0x3f, 0xf2, 0x66, 0xc3, 0xc5, 0xc9, 0x2f, 0x1b, 0xc5, 0x69, 0xe3, 0x4e,
-	0x47, 0xdd, 0xb0, 0x65, 0x26, 0x3c, 0x23, 0x3d, 0x8b, 0x28, 0x25, 0xfe, 0x46, 0x83, 0xec, 0x7a,
-	0x58, 0x4c, 0xc7, 0xc4, 0x45, 0xb0, 0x07, 0x1b, 0xa5, 0x2e, 0x42, 0xf8, 0x40, 0x06, 0xdd, 0x0a,
-	0x34, 0x22, 0x83, 0x8a, 0x21, 0x0a, 0xad, 0xbe, 0x9c, 0xfd, 0xf0, 0x99, 0x9e, 0x92, 0xbf, 0xa6,
-	0x5e, 0x1b, 0x2c, 0xb0, 0xc6, 0x02, 0x94, 0x8d, 0x79, 0xb7, 0x7d, 0xd2, 0xd6, 0x7d, 0xfb, 0x7b,
-	0x2f, 0xde, 0x02, 0x00, 0x00, 0xff, 0xff, 0xda, 0x8c, 0x20, 0x5e, 0x4e, 0x02, 0x00, 0x00,
+	// 417 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0x4d, 0x8f, 0xd3, 0x30,
+	0x10, 0x55, 0x3e, 0x76, 0x0b, 0x53, 0x5a, 0x21, 0xc3, 0x21, 0xca, 0x81, 0x46, 0x2b, 0x21, 0x55,
+	0xa0, 0x4d, 0xaa, 0x45, 0xe2, 0xc2, 0x09, 0xce, 0x0b, 0x42, 0x66, 0x05, 0xdc, 0x22, 0x37, 0x71,
+	0x53, 0xab, 0x8e, 0x1d, 0xd9, 0x93, 0x56, 0xfc, 0x30, 0x7e, 0x0f, 0x82, 0x5f, 0x82, 0xec, 0xa4,
+	0x15, 0x8b, 0x50, 0xb9, 0xcd, 0xf3, 0x7b, 0xf3, 0xe6, 0xd9, 0x63, 0x58, 0xec, 0x78, 0xdd, 0xf0,
+	0xa2, 0xd2, 0x6a, 0x23, 0x9a, 0xa2, 0xd2, 0x6d, 0xab, 0x55, 0xc1, 0xea, 0xad, 0xae, 0xf2, 0xce,
+	0x68, 0xd4, 0xe4, 0x89, 0x17, 0xe4, 0x83, 0x20, 0x1f, 0x04, 0xe9, 0xeb, 0x46, 0xe0, 0xb6, 0x5f,
+	0x3b, 0x58, 0xb4, 0x07, 0x81, 0x3b, 0x7d, 0x28, 0x1a, 0x7d, 0xed, 0x3b, 0xae, 0xf7, 0x4c, 0x8a,
+	0x9a, 0xa1, 0x36, 0xb6, 0x38, 0x95, 0x83, 0xd9, 0xd5, 0xcf, 0x18, 0x2e, 0xde, 0x3a, 0x73, 0xb2,
+	0x82, 0xc7, 0xb5, 0xb2, 0xa5, 0x62, 0x2d, 0x2f, 0x5b, 0x86, 0xd5, 0x96, 0x9b, 0x24, 0xc8, 0x82,
+	0xe5, 0xc3, 0x77, 0x97, 0xbf, 0x7e, 0x2c, 0xc2, 0x2c, 0xa0, 0xf3, 0x5a, 0xd9, 0x0f, 0xac, 0xe5,
+	0xef, 0x07, 0x96, 0xbc, 0x81, 0xb8, 0xd3, 0x06, 0x93, 0x30, 0x0b, 0x96, 0xd3, 0x9b, 0x45, 0xfe,
+	0x8f, 0x5c, 0xb9, 0xf7, 0xce, 0x3f, 0x6a, 0x83, 0x27, 0x1b, 0xdf, 0x44, 0x6e, 0xff, 0x18, 0x67,
+	0x78, 0x27, 0x59, 0xc5, 0x93, 0xc8, 0x1b, 0x5d, 0x9d, 0x31, 0xa2, 0x83, 0xf2, 0x14, 0x65, 0xc4,
+	0x64, 0x05, 0x11, 0x4a, 0x9b, 0xc4, 0xde, 0xe0, 0xd9, 0x19, 0x83, 0x3b, 0x69, 0xa9, 0x93, 0xa6,
+	0xdf, 0x03, 0x88, 0x5d, 0x2c, 0x92, 0xc0, 0xa4, 0xe6, 0x1b, 0xd6, 0x4b, 0xf4, 0xd7, 0x9d, 0xd1,
+	0x23, 0x74, 0x0c, 0x93, 0x52, 0x1f, 0x78, 0x9d, 0x44, 0x59, 0xe4, 0x98, 0x11, 0x92, 0x5b, 0x98,
+	0x8f, 0x65, 0x69, 0x98, 0x6a, 0xb8, 0x9b, 0x1c, 0x2d, 0xa7, 0x37, 0xcf, 0xff, 0xf3, 0x06, 0x39,
+	0x75, 0x6a, 0x3a, 0x1b, 0x9b, 0x3d, 0xb2, 0xe9, 0x4b, 0xb8, 0xf0, 0x15, 0x21, 0x10, 0x6f, 0x8c,
+	0x6e, 0xc7, 0x1c, 0xbe, 0x26, 0x73, 0x08, 0x51, 0xfb, 0x27, 0x9e, 0xd1, 0x10, 0x75, 0xfa, 0x05,
+	0x26, 0xc7, 0x4b, 0x67, 0x30, 0xe9, 0x18, 0x22, 0x37, 0xea, 0xaf, 0x45, 0x1d, 0x8f, 0xc9, 0x0b,
+	0x78, 0x64, 0xfb, 0xb5, 0x45, 0x81, 0x3d, 0x0a, 0xad, 0x92, 0xf0, 0x9e, 0xec, 0x1e, 0x97, 0x7e,
+	0x85, 0xe8, 0x4e, 0x5a, 0xb2, 0x82, 0xa7, 0x42, 0x59, 0x5e, 0xf5, 0x86, 0x97, 0x76, 0x27, 0xba,
+	0x72, 0xcf, 0x8d, 0xd8, 0x7c, 0xf3, 0x13, 0x1e, 0x50, 0x72, 0xe4, 0x3e, 0xed, 0x44, 0xf7, 0xd9,
+	0x33, 0x64, 0x01, 0x53, 0xcb, 0xcd, 0x9e, 0x1b, 0xbf, 0xcc, 0x61, 0x06, 0x85, 0xe1, 0xc8, 0xed,
+	0x68, 0x7d, 0xe9, 0xbf, 0xda, 0xab, 0xdf, 0x03, 0x00, 0x87, 0xdc, 0x29, 0x3b, 0xda, 0x02, 0x00,
+	0x00,
 }
@@ -6,9 +6,15 @@ Package kedge_config_common is a generated protocol buffer package.
 
 It is generated from these files:
 	kedge/config/common/adhoc.proto
+	kedge/config/common/healthcheck.proto
+	kedge/config/common/matcher.proto
+	kedge/config/common/ratelimit.proto
 
 It has these top-level messages:
 	Adhoc
+	HealthCheck
+	StringMatcher
+	RateLimit
 */
 package kedge_config_common
 
@@ -37,6 +43,11 @@ func (this *Adhoc) Validate() error {
 			return go_proto_validators.FieldError("DnsNameReplace", err)
 		}
 	}
+	if this.Tls != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Tls); err != nil {
+			return go_proto_validators.FieldError("Tls", err)
+		}
+	}
 	return nil
 }
 func (this *Adhoc_Port) Validate() error {
@@ -55,3 +66,6 @@ func (this *Adhoc_Port_Range) Validate() error {
 func (this *Adhoc_Replace) Validate() error {
 	return nil
 }
+func (this *Adhoc_Tls) Validate() error {
+	return nil
+}
@@ -0,0 +1,254 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/config/common/healthcheck.proto
+
+package kedge_config_common
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// / HealthCheck configures active health checking of every target resolved for a backend.
+// / Targets are considered healthy when resolved. Unhealthy targets are not used until they become healthy again.
+type HealthCheck struct {
+	// Types that are valid to be assigned to Check:
+	//	*HealthCheck_Http_
+	//	*HealthCheck_Grpc_
+	Check isHealthCheck_Check `protobuf_oneof:"check"`
+	// / interval_ms is the time between checks of a single target. Defaults to 5s.
+	IntervalMs uint32 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs" json:"interval_ms,omitempty"`
+	// / timeout_ms is the timeout of a single check. Defaults to 1s.
+	TimeoutMs uint32 `protobuf:"varint,4,opt,name=timeout_ms,json=timeoutMs" json:"timeout_ms,omitempty"`
+	// / healthy_threshold is the number of consecutive successful checks after which an unhealthy target becomes healthy.
+	// / Defaults to 1.
+	HealthyThreshold uint32 `protobuf:"varint,5,opt,name=healthy_threshold,json=healthyThreshold" json:"healthy_threshold,omitempty"`
+	// / unhealthy_threshold is the number of consecutive failed checks after which a healthy target becomes unhealthy.
+	// / Defaults to 3.
+	UnhealthyThreshold uint32 `protobuf:"varint,6,opt,name=unhealthy_threshold,json=unhealthyThreshold" json:"unhealthy_threshold,omitempty"`
+}
+
+func (m *HealthCheck) Reset()                    { *m = HealthCheck{} }
+func (m *HealthCheck) String() string            { return proto.CompactTextString(m) }
+func (*HealthCheck) ProtoMessage()               {}
+func (*HealthCheck) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+type isHealthCheck_Check interface {
+	isHealthCheck_Check()
+}
+
+type HealthCheck_Http_ struct {
+	Http *HealthCheck_Http `protobuf:"bytes,1,opt,name=http,oneof"`
+}
+type HealthCheck_Grpc_ struct {
+	Grpc *HealthCheck_Grpc `protobuf:"bytes,2,opt,name=grpc,oneof"`
+}
+
+func (*HealthCheck_Http_) isHealthCheck_Check() {}
+func (*HealthCheck_Grpc_) isHealthCheck_Check() {}
+
+func (m *HealthCheck) GetCheck() isHealthCheck_Check {
+	if m != nil {
+		return m.Check
+	}
+	return nil
+}
+
+func (m *HealthCheck) GetHttp() *HealthCheck_Http {
+	if x, ok := m.GetCheck().(*HealthCheck_Http_); ok {
+		return x.Http
+	}
+	return nil
+}
+
+func (m *HealthCheck) GetGrpc() *HealthCheck_Grpc {
+	if x, ok := m.GetCheck().(*HealthCheck_Grpc_); ok {
+		return x.Grpc
+	}
+	return nil
+}
+
+func (m *HealthCheck) GetIntervalMs() uint32 {
+	if m != nil {
+		return m.IntervalMs
+	}
+	return 0
+}
+
+func (m *HealthCheck) GetTimeoutMs() uint32 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+func (m *HealthCheck) GetHealthyThreshold() uint32 {
+	if m != nil {
+		return m.HealthyThreshold
+	}
+	return 0
+}
+
+func (m *HealthCheck) GetUnhealthyThreshold() uint32 {
+	if m != nil {
+		return m.UnhealthyThreshold
+	}
+	return 0
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*HealthCheck) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _HealthCheck_OneofMarshaler, _HealthCheck_OneofUnmarshaler, _HealthCheck_OneofSizer, []interface{}{
+		(*HealthCheck_Http_)(nil),
+		(*HealthCheck_Grpc_)(nil),
+	}
+}
+
+func _HealthCheck_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*HealthCheck)
+	// check
+	switch x := m.Check.(type) {
+	case *HealthCheck_Http_:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Http); err != nil {
+			return err
+		}
+	case *HealthCheck_Grpc_:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Grpc); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("HealthCheck.Check has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _HealthCheck_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*HealthCheck)
+	switch tag {
+	case 1: // check.http
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HealthCheck_Http)
+		err := b.DecodeMessage(msg)
+		m.Check = &HealthCheck_Http_{msg}
+		return true, err
+	case 2: // check.grpc
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HealthCheck_Grpc)
+		err := b.DecodeMessage(msg)
+		m.Check = &HealthCheck_Grpc_{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _HealthCheck_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*HealthCheck)
+	// check
+	switch x := m.Check.(type) {
+	case *HealthCheck_Http_:
+		s := proto.Size(x.Http)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *HealthCheck_Grpc_:
+		s := proto.Size(x.Grpc)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// / Http checks the target with a GET request. Only valid for HTTP backends.
+type HealthCheck_Http struct {
+	// / path is the path of the GET request, e.g. /healthz.
+	Path string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	// / expected_status is the status code of a healthy response. Defaults to 200.
+	ExpectedStatus uint32 `protobuf:"varint,2,opt,name=expected_status,json=expectedStatus" json:"expected_status,omitempty"`
+}
+
+func (m *HealthCheck_Http) Reset()                    { *m = HealthCheck_Http{} }
+func (m *HealthCheck_Http) String() string            { return proto.CompactTextString(m) }
+func (*HealthCheck_Http) ProtoMessage()               {}
+func (*HealthCheck_Http) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0, 0} }
+
+func (m *HealthCheck_Http) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *HealthCheck_Http) GetExpectedStatus() uint32 {
+	if m != nil {
+		return m.ExpectedStatus
+	}
+	return 0
+}
+
+// / Grpc checks the target using the standard grpc.health.v1.Health/Check call. Only valid for gRPC backends.
+type HealthCheck_Grpc struct {
+	// / service is the service name sent in the check request. Empty means the overall server health.
+	Service string `protobuf:"bytes,1,opt,name=service" json:"service,omitempty"`
+}
+
+func (m *HealthCheck_Grpc) Reset()                    { *m = HealthCheck_Grpc{} }
+func (m *HealthCheck_Grpc) String() string            { return proto.CompactTextString(m) }
+func (*HealthCheck_Grpc) ProtoMessage()               {}
+func (*HealthCheck_Grpc) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0, 1} }
+
+func (m *HealthCheck_Grpc) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*HealthCheck)(nil), "kedge.config.common.HealthCheck")
+	proto.RegisterType((*HealthCheck_Http)(nil), "kedge.config.common.HealthCheck.Http")
+	proto.RegisterType((*HealthCheck_Grpc)(nil), "kedge.config.common.HealthCheck.Grpc")
+}
+
+func init() { proto.RegisterFile("kedge/config/common/healthcheck.proto", fileDescriptor1) }
+
+var fileDescriptor1 = []byte{
+	// 341 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0x4f, 0x4b, 0xf3, 0x40,
+	0x10, 0xc6, 0xdf, 0xbe, 0x4d, 0x5b, 0x3a, 0xa1, 0xfe, 0xd9, 0x5e, 0x42, 0x41, 0x5a, 0xc4, 0x62,
+	0x51, 0x9a, 0x05, 0x05, 0x2f, 0xde, 0xea, 0xc1, 0x5e, 0x0a, 0x12, 0x3d, 0x5b, 0xd2, 0xcd, 0x9a,
+	0x0d, 0x4d, 0xb2, 0x61, 0x77, 0xd2, 0xea, 0xd7, 0xf1, 0x8b, 0x09, 0x7e, 0x12, 0xd9, 0x4d, 0x23,
+	0xa2, 0x1e, 0xbc, 0xed, 0x3e, 0xcf, 0xf3, 0x9b, 0x61, 0x66, 0x60, 0xbc, 0xe6, 0x51, 0xcc, 0x29,
+	0x93, 0xf9, 0x53, 0x12, 0x53, 0x26, 0xb3, 0x4c, 0xe6, 0x54, 0xf0, 0x30, 0x45, 0xc1, 0x04, 0x67,
+	0x6b, 0xbf, 0x50, 0x12, 0x25, 0xe9, 0xdb, 0x98, 0x5f, 0xc5, 0xfc, 0x2a, 0x36, 0xb8, 0x8a, 0x13,
+	0x14, 0xe5, 0xca, 0x7c, 0x69, 0xb6, 0x4d, 0x70, 0x2d, 0xb7, 0x34, 0x96, 0x53, 0x4b, 0x4c, 0x37,
+	0x61, 0x9a, 0x44, 0x21, 0x4a, 0xa5, 0xe9, 0xe7, 0xb3, 0x2a, 0x76, 0xfc, 0xda, 0x04, 0x77, 0x6e,
+	0x5b, 0xdc, 0x98, 0x16, 0xe4, 0x1a, 0x1c, 0x81, 0x58, 0x78, 0x8d, 0x51, 0x63, 0xe2, 0x5e, 0x8c,
+	0xfd, 0x5f, 0x7a, 0xf9, 0x5f, 0xf2, 0xfe, 0x1c, 0xb1, 0x98, 0xff, 0x0b, 0x2c, 0x64, 0xe0, 0x58,
+	0x15, 0xcc, 0xfb, 0xff, 0x47, 0xf8, 0x56, 0x15, 0xcc, 0xc0, 0x06, 0x22, 0x43, 0x70, 0x93, 0x1c,
+	0xb9, 0xda, 0x84, 0xe9, 0x32, 0xd3, 0x5e, 0x73, 0xd4, 0x98, 0xf4, 0x02, 0xa8, 0xa5, 0x85, 0x26,
+	0x47, 0x00, 0x98, 0x64, 0x5c, 0x96, 0x68, 0x7c, 0xc7, 0xfa, 0xdd, 0x9d, 0xb2, 0xd0, 0xe4, 0x1c,
+	0x0e, 0xab, 0x5d, 0xbd, 0x2c, 0x51, 0x28, 0xae, 0x85, 0x4c, 0x23, 0xaf, 0x65, 0x53, 0x07, 0x3b,
+	0xe3, 0xa1, 0xd6, 0x09, 0x85, 0x7e, 0x99, 0xff, 0x8c, 0xb7, 0x6d, 0x9c, 0x94, 0xf9, 0x77, 0x60,
+	0x70, 0x07, 0x8e, 0x19, 0x95, 0x0c, 0xc1, 0x29, 0x42, 0x14, 0x76, 0x3f, 0xdd, 0x99, 0xfb, 0xfe,
+	0x36, 0xec, 0x40, 0xeb, 0x91, 0xfa, 0x67, 0x27, 0x81, 0x35, 0xc8, 0x29, 0xec, 0xf3, 0xe7, 0x82,
+	0x33, 0xe4, 0xd1, 0x52, 0x63, 0x88, 0xa5, 0xb6, 0xeb, 0xe8, 0x05, 0x7b, 0xb5, 0x7c, 0x6f, 0xd5,
+	0xc1, 0x08, 0x1c, 0x33, 0x3f, 0xf1, 0xa0, 0xa3, 0xb9, 0xda, 0x24, 0x8c, 0x57, 0x45, 0x83, 0xfa,
+	0x3b, 0xeb, 0x40, 0xcb, 0xde, 0x7d, 0xd5, 0xb6, 0xb7, 0xba, 0xfc, 0x18, 0x00, 0x8a, 0xa7, 0x34,
+	0x87, 0x21, 0x02, 0x00, 0x00,
+}
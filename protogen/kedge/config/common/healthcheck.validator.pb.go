@@ -0,0 +1,46 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: kedge/config/common/healthcheck.proto
+
+package kedge_config_common
+
+import regexp "regexp"
+import fmt "fmt"
+import go_proto_validators "github.com/mwitkow/go-proto-validators"
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+func (this *HealthCheck) Validate() error {
+	if oneOfNester, ok := this.GetCheck().(*HealthCheck_Http_); ok {
+		if oneOfNester.Http != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Http); err != nil {
+				return go_proto_validators.FieldError("Http", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetCheck().(*HealthCheck_Grpc_); ok {
+		if oneOfNester.Grpc != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Grpc); err != nil {
+				return go_proto_validators.FieldError("Grpc", err)
+			}
+		}
+	}
+	return nil
+}
+
+var _regex_HealthCheck_Http_Path = regexp.MustCompile(`^/.*$`)
+
+func (this *HealthCheck_Http) Validate() error {
+	if !_regex_HealthCheck_Http_Path.MatchString(this.Path) {
+		return go_proto_validators.FieldError("Path", fmt.Errorf(`value '%v' must be a string conforming to regex "^/.*$"`, this.Path))
+	}
+	return nil
+}
+func (this *HealthCheck_Grpc) Validate() error {
+	return nil
+}
@@ -0,0 +1,229 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/config/common/matcher.proto
+
+package kedge_config_common
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// / StringMatcher matches a string value, e.g. host, path, header or metadata value.
+type StringMatcher struct {
+	// Types that are valid to be assigned to Match:
+	//	*StringMatcher_Exact
+	//	*StringMatcher_Prefix
+	//	*StringMatcher_Suffix
+	//	*StringMatcher_Wildcard
+	//	*StringMatcher_Regex
+	Match isStringMatcher_Match `protobuf_oneof:"match"`
+}
+
+func (m *StringMatcher) Reset()                    { *m = StringMatcher{} }
+func (m *StringMatcher) String() string            { return proto.CompactTextString(m) }
+func (*StringMatcher) ProtoMessage()               {}
+func (*StringMatcher) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+
+type isStringMatcher_Match interface {
+	isStringMatcher_Match()
+}
+
+type StringMatcher_Exact struct {
+	Exact string `protobuf:"bytes,1,opt,name=exact,oneof"`
+}
+type StringMatcher_Prefix struct {
+	Prefix string `protobuf:"bytes,2,opt,name=prefix,oneof"`
+}
+type StringMatcher_Suffix struct {
+	Suffix string `protobuf:"bytes,3,opt,name=suffix,oneof"`
+}
+type StringMatcher_Wildcard struct {
+	Wildcard string `protobuf:"bytes,4,opt,name=wildcard,oneof"`
+}
+type StringMatcher_Regex struct {
+	Regex string `protobuf:"bytes,5,opt,name=regex,oneof"`
+}
+
+func (*StringMatcher_Exact) isStringMatcher_Match()    {}
+func (*StringMatcher_Prefix) isStringMatcher_Match()   {}
+func (*StringMatcher_Suffix) isStringMatcher_Match()   {}
+func (*StringMatcher_Wildcard) isStringMatcher_Match() {}
+func (*StringMatcher_Regex) isStringMatcher_Match()    {}
+
+func (m *StringMatcher) GetMatch() isStringMatcher_Match {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
+func (m *StringMatcher) GetExact() string {
+	if x, ok := m.GetMatch().(*StringMatcher_Exact); ok {
+		return x.Exact
+	}
+	return ""
+}
+
+func (m *StringMatcher) GetPrefix() string {
+	if x, ok := m.GetMatch().(*StringMatcher_Prefix); ok {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (m *StringMatcher) GetSuffix() string {
+	if x, ok := m.GetMatch().(*StringMatcher_Suffix); ok {
+		return x.Suffix
+	}
+	return ""
+}
+
+func (m *StringMatcher) GetWildcard() string {
+	if x, ok := m.GetMatch().(*StringMatcher_Wildcard); ok {
+		return x.Wildcard
+	}
+	return ""
+}
+
+func (m *StringMatcher) GetRegex() string {
+	if x, ok := m.GetMatch().(*StringMatcher_Regex); ok {
+		return x.Regex
+	}
+	return ""
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*StringMatcher) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _StringMatcher_OneofMarshaler, _StringMatcher_OneofUnmarshaler, _StringMatcher_OneofSizer, []interface{}{
+		(*StringMatcher_Exact)(nil),
+		(*StringMatcher_Prefix)(nil),
+		(*StringMatcher_Suffix)(nil),
+		(*StringMatcher_Wildcard)(nil),
+		(*StringMatcher_Regex)(nil),
+	}
+}
+
+func _StringMatcher_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*StringMatcher)
+	// match
+	switch x := m.Match.(type) {
+	case *StringMatcher_Exact:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Exact)
+	case *StringMatcher_Prefix:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Prefix)
+	case *StringMatcher_Suffix:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Suffix)
+	case *StringMatcher_Wildcard:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Wildcard)
+	case *StringMatcher_Regex:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Regex)
+	case nil:
+	default:
+		return fmt.Errorf("StringMatcher.Match has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _StringMatcher_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*StringMatcher)
+	switch tag {
+	case 1: // match.exact
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &StringMatcher_Exact{x}
+		return true, err
+	case 2: // match.prefix
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &StringMatcher_Prefix{x}
+		return true, err
+	case 3: // match.suffix
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &StringMatcher_Suffix{x}
+		return true, err
+	case 4: // match.wildcard
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &StringMatcher_Wildcard{x}
+		return true, err
+	case 5: // match.regex
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &StringMatcher_Regex{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _StringMatcher_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*StringMatcher)
+	// match
+	switch x := m.Match.(type) {
+	case *StringMatcher_Exact:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Exact)))
+		n += len(x.Exact)
+	case *StringMatcher_Prefix:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Prefix)))
+		n += len(x.Prefix)
+	case *StringMatcher_Suffix:
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Suffix)))
+		n += len(x.Suffix)
+	case *StringMatcher_Wildcard:
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Wildcard)))
+		n += len(x.Wildcard)
+	case *StringMatcher_Regex:
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Regex)))
+		n += len(x.Regex)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+func init() {
+	proto.RegisterType((*StringMatcher)(nil), "kedge.config.common.StringMatcher")
+}
+
+func init() { proto.RegisterFile("kedge/config/common/matcher.proto", fileDescriptor2) }
+
+var fileDescriptor2 = []byte{
+	// 169 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0xcc, 0x4e, 0x4d, 0x49,
+	0x4f, 0xd5, 0x4f, 0xce, 0xcf, 0x4b, 0xcb, 0x4c, 0xd7, 0x4f, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0xd3,
+	0xcf, 0x4d, 0x2c, 0x49, 0xce, 0x48, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x06,
+	0x2b, 0xd1, 0x83, 0x28, 0xd1, 0x83, 0x28, 0x51, 0x9a, 0xc5, 0xc8, 0xc5, 0x1b, 0x5c, 0x52, 0x94,
+	0x99, 0x97, 0xee, 0x0b, 0x51, 0x2c, 0x24, 0xc6, 0xc5, 0x9a, 0x5a, 0x91, 0x98, 0x5c, 0x22, 0xc1,
+	0xa8, 0xc0, 0xa8, 0xc1, 0xe9, 0xc1, 0x10, 0x04, 0xe1, 0x0a, 0x49, 0x70, 0xb1, 0x15, 0x14, 0xa5,
+	0xa6, 0x65, 0x56, 0x48, 0x30, 0x41, 0x25, 0xa0, 0x7c, 0x90, 0x4c, 0x71, 0x69, 0x1a, 0x48, 0x86,
+	0x19, 0x26, 0x03, 0xe1, 0x0b, 0xc9, 0x70, 0x71, 0x94, 0x67, 0xe6, 0xa4, 0x24, 0x27, 0x16, 0xa5,
+	0x48, 0xb0, 0x40, 0xe5, 0xe0, 0x22, 0x20, 0x9b, 0x8a, 0x52, 0xd3, 0x53, 0x2b, 0x24, 0x58, 0x61,
+	0x36, 0x81, 0xb9, 0x4e, 0xec, 0x5c, 0xac, 0x60, 0x97, 0x27, 0xb1, 0x81, 0x1d, 0x6e, 0x0c, 0x18,
+	0x00, 0x03, 0xc3, 0x74, 0x50, 0xdd, 0x00, 0x00, 0x00,
+}
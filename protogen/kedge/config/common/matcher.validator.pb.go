@@ -0,0 +1,17 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: kedge/config/common/matcher.proto
+
+package kedge_config_common
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+func (this *StringMatcher) Validate() error {
+	return nil
+}
@@ -0,0 +1,122 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/config/common/ratelimit.proto
+
+package kedge_config_common
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type RateLimit_Key int32
+
+const (
+	// / SOURCE_IP identifies clients by the IP address of the connection.
+	RateLimit_SOURCE_IP RateLimit_Key = 0
+	// / OIDC_SUBJECT identifies clients by the subject of the OIDC token used for proxy auth.
+	RateLimit_OIDC_SUBJECT RateLimit_Key = 1
+	// / CLIENT_CERT_CN identifies clients by the common name of their client certificate.
+	RateLimit_CLIENT_CERT_CN RateLimit_Key = 2
+	// / HEADER identifies clients by the value of the header (HTTP) or metadata (gRPC) specified in 'header'.
+	RateLimit_HEADER RateLimit_Key = 3
+)
+
+var RateLimit_Key_name = map[int32]string{
+	0: "SOURCE_IP",
+	1: "OIDC_SUBJECT",
+	2: "CLIENT_CERT_CN",
+	3: "HEADER",
+}
+var RateLimit_Key_value = map[string]int32{
+	"SOURCE_IP":      0,
+	"OIDC_SUBJECT":   1,
+	"CLIENT_CERT_CN": 2,
+	"HEADER":         3,
+}
+
+func (x RateLimit_Key) String() string {
+	return proto.EnumName(RateLimit_Key_name, int32(x))
+}
+func (RateLimit_Key) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{0, 0} }
+
+// / RateLimit is a token bucket rate limit applied separately to each client.
+type RateLimit struct {
+	// / requests_per_second is the rate at which the bucket of a single client is refilled.
+	RequestsPerSecond float64 `protobuf:"fixed64,1,opt,name=requests_per_second,json=requestsPerSecond" json:"requests_per_second,omitempty"`
+	// / burst is the size of the bucket, so the maximum number of requests a client can make at once.
+	// / If 0, it defaults to requests_per_second rounded up.
+	Burst uint32 `protobuf:"varint,2,opt,name=burst" json:"burst,omitempty"`
+	// / key specifies how clients are identified. Requests without the identity (e.g. without the header) share a single
+	// / bucket.
+	Key RateLimit_Key `protobuf:"varint,3,opt,name=key,enum=kedge.config.common.RateLimit_Key" json:"key,omitempty"`
+	// / header is the name of the header or metadata used for the HEADER key.
+	Header string `protobuf:"bytes,4,opt,name=header" json:"header,omitempty"`
+}
+
+func (m *RateLimit) Reset()                    { *m = RateLimit{} }
+func (m *RateLimit) String() string            { return proto.CompactTextString(m) }
+func (*RateLimit) ProtoMessage()               {}
+func (*RateLimit) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+
+func (m *RateLimit) GetRequestsPerSecond() float64 {
+	if m != nil {
+		return m.RequestsPerSecond
+	}
+	return 0
+}
+
+func (m *RateLimit) GetBurst() uint32 {
+	if m != nil {
+		return m.Burst
+	}
+	return 0
+}
+
+func (m *RateLimit) GetKey() RateLimit_Key {
+	if m != nil {
+		return m.Key
+	}
+	return RateLimit_SOURCE_IP
+}
+
+func (m *RateLimit) GetHeader() string {
+	if m != nil {
+		return m.Header
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*RateLimit)(nil), "kedge.config.common.RateLimit")
+	proto.RegisterEnum("kedge.config.common.RateLimit_Key", RateLimit_Key_name, RateLimit_Key_value)
+}
+
+func init() { proto.RegisterFile("kedge/config/common/ratelimit.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 301 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x8f, 0xcf, 0x4e, 0xc2, 0x40,
+	0x10, 0x87, 0x59, 0xaa, 0x24, 0x9d, 0x08, 0xa9, 0x8b, 0x31, 0x8d, 0x17, 0x1b, 0xbc, 0xf4, 0x42,
+	0x1b, 0xff, 0xc4, 0x9b, 0x07, 0x29, 0x35, 0x22, 0x04, 0xc8, 0x02, 0xe7, 0x4d, 0xa1, 0x63, 0xd9,
+	0x40, 0x59, 0xdc, 0x2e, 0x12, 0x9e, 0xd6, 0xc4, 0x87, 0xf0, 0x6c, 0x68, 0x91, 0x93, 0x73, 0x9a,
+	0x2f, 0xf9, 0x66, 0xe6, 0x37, 0x70, 0xb3, 0xc0, 0x38, 0x41, 0x7f, 0x26, 0x57, 0xef, 0x22, 0xf1,
+	0x67, 0x32, 0x4d, 0xe5, 0xca, 0x57, 0x91, 0xc6, 0xa5, 0x48, 0x85, 0xf6, 0xd6, 0x4a, 0x6a, 0x49,
+	0xeb, 0xb9, 0xe4, 0x15, 0x92, 0x57, 0x48, 0x57, 0x8f, 0x89, 0xd0, 0xf3, 0xcd, 0x74, 0x8f, 0x7e,
+	0xba, 0x15, 0x7a, 0x21, 0xb7, 0x7e, 0x22, 0x9b, 0xf9, 0x44, 0xf3, 0x33, 0x5a, 0x8a, 0x38, 0xd2,
+	0x52, 0x65, 0xfe, 0xb1, 0x2d, 0x96, 0x35, 0x7e, 0x08, 0x98, 0x2c, 0xd2, 0xd8, 0xdb, 0x1f, 0xa0,
+	0x4f, 0x50, 0x57, 0xf8, 0xb1, 0xc1, 0x4c, 0x67, 0x7c, 0x8d, 0x8a, 0x67, 0x38, 0x93, 0xab, 0xd8,
+	0x26, 0x0e, 0x71, 0x49, 0xab, 0xfa, 0xfd, 0x75, 0x6d, 0xde, 0x96, 0x0e, 0xc5, 0xce, 0xff, 0xcc,
+	0x21, 0xaa, 0x51, 0xee, 0xd1, 0x0b, 0x38, 0x9d, 0x6e, 0x54, 0xa6, 0xed, 0xb2, 0x43, 0xdc, 0x2a,
+	0x2b, 0x80, 0x3e, 0x80, 0xb1, 0xc0, 0x9d, 0x6d, 0x38, 0xc4, 0xad, 0xdd, 0x35, 0xbc, 0x7f, 0xd2,
+	0x7b, 0xc7, 0x04, 0x5e, 0x17, 0x77, 0x6c, 0xaf, 0xd3, 0x4b, 0xa8, 0xcc, 0x31, 0x8a, 0x51, 0xd9,
+	0x27, 0x0e, 0x71, 0x4d, 0x76, 0xa0, 0xc6, 0x0b, 0x18, 0x5d, 0xdc, 0xd1, 0x2a, 0x98, 0xa3, 0xc1,
+	0x84, 0x05, 0x21, 0xef, 0x0c, 0xad, 0x12, 0xb5, 0xe0, 0x6c, 0xd0, 0x69, 0x07, 0x7c, 0x34, 0x69,
+	0xbd, 0x85, 0xc1, 0xd8, 0x22, 0x94, 0x42, 0x2d, 0xe8, 0x75, 0xc2, 0xfe, 0x98, 0x07, 0x21, 0x1b,
+	0xf3, 0xa0, 0x6f, 0x95, 0x29, 0x40, 0xe5, 0x35, 0x7c, 0x6e, 0x87, 0xcc, 0x32, 0xa6, 0x95, 0xfc,
+	0xff, 0xfb, 0xdf, 0x01, 0x00, 0xc8, 0x82, 0x1f, 0x7d, 0x73, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,22 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: kedge/config/common/ratelimit.proto
+
+package kedge_config_common
+
+import fmt "fmt"
+import go_proto_validators "github.com/mwitkow/go-proto-validators"
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+func (this *RateLimit) Validate() error {
+	if !(this.RequestsPerSecond > 0) {
+		return go_proto_validators.FieldError("RequestsPerSecond", fmt.Errorf(`value '%v' must be strictly greater than '0'`, this.RequestsPerSecond))
+	}
+	return nil
+}
@@ -7,9 +7,10 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
-import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import kedge_config_common1 "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 import kedge_config_grpc_routes "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
 import kedge_config_http_routes "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+import kedge_config_tcp_routes "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -20,6 +21,8 @@ var _ = math.Inf
 type DirectorConfig struct {
 	Grpc *DirectorConfig_Grpc `protobuf:"bytes,1,opt,name=grpc" json:"grpc,omitempty"`
 	Http *DirectorConfig_Http `protobuf:"bytes,2,opt,name=http" json:"http,omitempty"`
+	// / tcp routes are used by the TLS passthrough listener. Optional.
+	Tcp *DirectorConfig_Tcp `protobuf:"bytes,3,opt,name=tcp" json:"tcp,omitempty"`
 }
 
 func (m *DirectorConfig) Reset()                    { *m = DirectorConfig{} }
@@ -41,9 +44,16 @@ func (m *DirectorConfig) GetHttp() *DirectorConfig_Http {
 	return nil
 }
 
+func (m *DirectorConfig) GetTcp() *DirectorConfig_Tcp {
+	if m != nil {
+		return m.Tcp
+	}
+	return nil
+}
+
 type DirectorConfig_Grpc struct {
 	Routes     []*kedge_config_grpc_routes.Route `protobuf:"bytes,1,rep,name=routes" json:"routes,omitempty"`
-	AdhocRules []*kedge_config_common.Adhoc      `protobuf:"bytes,2,rep,name=adhoc_rules,json=adhocRules" json:"adhoc_rules,omitempty"`
+	AdhocRules []*kedge_config_common1.Adhoc     `protobuf:"bytes,2,rep,name=adhoc_rules,json=adhocRules" json:"adhoc_rules,omitempty"`
 }
 
 func (m *DirectorConfig_Grpc) Reset()                    { *m = DirectorConfig_Grpc{} }
@@ -58,7 +68,7 @@ func (m *DirectorConfig_Grpc) GetRoutes() []*kedge_config_grpc_routes.Route {
 	return nil
 }
 
-func (m *DirectorConfig_Grpc) GetAdhocRules() []*kedge_config_common.Adhoc {
+func (m *DirectorConfig_Grpc) GetAdhocRules() []*kedge_config_common1.Adhoc {
 	if m != nil {
 		return m.AdhocRules
 	}
@@ -67,7 +77,7 @@ func (m *DirectorConfig_Grpc) GetAdhocRules() []*kedge_config_common.Adhoc {
 
 type DirectorConfig_Http struct {
 	Routes     []*kedge_config_http_routes.Route `protobuf:"bytes,1,rep,name=routes" json:"routes,omitempty"`
-	AdhocRules []*kedge_config_common.Adhoc      `protobuf:"bytes,2,rep,name=adhoc_rules,json=adhocRules" json:"adhoc_rules,omitempty"`
+	AdhocRules []*kedge_config_common1.Adhoc     `protobuf:"bytes,2,rep,name=adhoc_rules,json=adhocRules" json:"adhoc_rules,omitempty"`
 }
 
 func (m *DirectorConfig_Http) Reset()                    { *m = DirectorConfig_Http{} }
@@ -82,40 +92,59 @@ func (m *DirectorConfig_Http) GetRoutes() []*kedge_config_http_routes.Route {
 	return nil
 }
 
-func (m *DirectorConfig_Http) GetAdhocRules() []*kedge_config_common.Adhoc {
+func (m *DirectorConfig_Http) GetAdhocRules() []*kedge_config_common1.Adhoc {
 	if m != nil {
 		return m.AdhocRules
 	}
 	return nil
 }
 
+type DirectorConfig_Tcp struct {
+	Routes []*kedge_config_tcp_routes.Route `protobuf:"bytes,1,rep,name=routes" json:"routes,omitempty"`
+}
+
+func (m *DirectorConfig_Tcp) Reset()                    { *m = DirectorConfig_Tcp{} }
+func (m *DirectorConfig_Tcp) String() string            { return proto.CompactTextString(m) }
+func (*DirectorConfig_Tcp) ProtoMessage()               {}
+func (*DirectorConfig_Tcp) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0, 2} }
+
+func (m *DirectorConfig_Tcp) GetRoutes() []*kedge_config_tcp_routes.Route {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*DirectorConfig)(nil), "kedge.config.DirectorConfig")
 	proto.RegisterType((*DirectorConfig_Grpc)(nil), "kedge.config.DirectorConfig.Grpc")
 	proto.RegisterType((*DirectorConfig_Http)(nil), "kedge.config.DirectorConfig.Http")
+	proto.RegisterType((*DirectorConfig_Tcp)(nil), "kedge.config.DirectorConfig.Tcp")
 }
 
 func init() { proto.RegisterFile("kedge/config/director.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 290 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x91, 0x4f, 0x4b, 0xf4, 0x30,
-	0x10, 0xc6, 0x69, 0x77, 0xe9, 0x21, 0x7d, 0x79, 0x0f, 0x3d, 0x95, 0x7a, 0x68, 0x15, 0x84, 0xbd,
-	0x6c, 0x02, 0x2b, 0xe8, 0x41, 0x3c, 0xf8, 0x07, 0xf4, 0xdc, 0x2f, 0x20, 0xdd, 0xb4, 0xb6, 0x61,
-	0xb7, 0x3b, 0x21, 0x9d, 0xba, 0x17, 0x3f, 0x81, 0x5f, 0x52, 0xf0, 0x93, 0xc8, 0xa4, 0x41, 0x0d,
-	0x2c, 0xe8, 0xc1, 0xd3, 0x04, 0xe6, 0xf7, 0x3c, 0xcf, 0xcc, 0x84, 0x1d, 0x6d, 0x9a, 0xba, 0x6d,
-	0x84, 0x84, 0xdd, 0x93, 0x6a, 0x45, 0xad, 0x4c, 0x23, 0x11, 0x0c, 0xd7, 0x06, 0x10, 0x92, 0x7f,
-	0xb6, 0xc9, 0xa7, 0x66, 0x76, 0xde, 0x2a, 0xec, 0xc6, 0x35, 0x97, 0xd0, 0x8b, 0x7e, 0xaf, 0x70,
-	0x03, 0x7b, 0xd1, 0xc2, 0xd2, 0xa2, 0xcb, 0xe7, 0x6a, 0xab, 0xea, 0x0a, 0xc1, 0x0c, 0xe2, 0xf3,
-	0x39, 0xb9, 0x64, 0xb9, 0x17, 0x21, 0xa1, 0xef, 0x61, 0x27, 0xaa, 0xba, 0x03, 0xe9, 0x80, 0x53,
-	0x0f, 0x68, 0x8d, 0x96, 0xc2, 0xc0, 0x88, 0xcd, 0xe0, 0xca, 0x41, 0xac, 0x43, 0xd4, 0x87, 0xb0,
-	0x93, 0xd7, 0x19, 0xfb, 0x7f, 0xe7, 0xf6, 0xb8, 0xb5, 0x6c, 0x72, 0xc5, 0xe6, 0xe4, 0x9a, 0x06,
-	0x45, 0xb0, 0x88, 0x57, 0xc7, 0xfc, 0xfb, 0x5a, 0xdc, 0x67, 0xf9, 0xbd, 0xd1, 0xf2, 0x26, 0x7a,
-	0x7f, 0xcb, 0xc3, 0x22, 0x28, 0xad, 0x8c, 0xe4, 0x94, 0x96, 0x86, 0xbf, 0x90, 0x3f, 0x20, 0xea,
-	0x2f, 0x39, 0xc9, 0xb2, 0x17, 0x36, 0x27, 0xd3, 0xe4, 0x82, 0x45, 0xd3, 0xa0, 0x69, 0x50, 0xcc,
-	0x16, 0xf1, 0x2a, 0xf7, 0x8d, 0x28, 0x8a, 0xbb, 0x4d, 0x4a, 0x2a, 0xa5, 0xc3, 0x93, 0x4b, 0x16,
-	0xdb, 0x73, 0x3d, 0x9a, 0x71, 0xdb, 0x0c, 0x69, 0x68, 0xd5, 0x99, 0xaf, 0x9e, 0xce, 0xca, 0xaf,
-	0x89, 0x2b, 0x99, 0xc5, 0x4b, 0xa2, 0x29, 0x9d, 0x66, 0xfa, 0x29, 0x9d, 0x26, 0xfd, 0xfb, 0xf4,
-	0x75, 0x64, 0xff, 0xe4, 0xec, 0x23, 0x00, 0x00, 0xff, 0xff, 0xcf, 0x15, 0x5c, 0x51, 0x67, 0x02,
-	0x00, 0x00,
+	// 321 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x92, 0x4f, 0x4b, 0xf3, 0x40,
+	0x10, 0xc6, 0x49, 0x53, 0x7a, 0xd8, 0xbe, 0xbc, 0x87, 0x9c, 0x42, 0x04, 0x1b, 0x45, 0xa1, 0x97,
+	0xee, 0x42, 0x85, 0x7a, 0x90, 0x1e, 0xfc, 0x03, 0x7a, 0x5e, 0x7a, 0x97, 0x76, 0x12, 0xd3, 0xd0,
+	0xa6, 0xb3, 0x6c, 0xa7, 0xf6, 0xe2, 0x77, 0x15, 0xfc, 0x1e, 0x82, 0xcc, 0x26, 0xa8, 0x2b, 0x41,
+	0xbd, 0x78, 0xda, 0xc0, 0xfe, 0x7e, 0x79, 0xe6, 0x61, 0x56, 0x1c, 0xac, 0xf2, 0xac, 0xc8, 0x15,
+	0xe0, 0xe6, 0xa1, 0x2c, 0x54, 0x56, 0xda, 0x1c, 0x08, 0xad, 0x34, 0x16, 0x09, 0xa3, 0x7f, 0xee,
+	0x52, 0xd6, 0x97, 0xc9, 0xa4, 0x28, 0x69, 0xb9, 0x5b, 0x48, 0xc0, 0x4a, 0x55, 0xfb, 0x92, 0x56,
+	0xb8, 0x57, 0x05, 0x8e, 0x1c, 0x3a, 0x7a, 0x9c, 0xaf, 0xcb, 0x6c, 0x4e, 0x68, 0xb7, 0xea, 0xfd,
+	0xb3, 0xfe, 0x4b, 0x32, 0xf0, 0x22, 0x00, 0xab, 0x0a, 0x37, 0x6a, 0x9e, 0x2d, 0x11, 0x1a, 0xe0,
+	0xd4, 0x03, 0x0a, 0x6b, 0x40, 0x59, 0xdc, 0x51, 0xbe, 0x6d, 0x8e, 0x56, 0x6c, 0x49, 0x64, 0x5a,
+	0xb1, 0x13, 0x0f, 0x23, 0x68, 0xa5, 0x8e, 0x5f, 0x43, 0xf1, 0xff, 0xa6, 0x69, 0x7b, 0xed, 0xd0,
+	0x68, 0x2a, 0xba, 0x9c, 0x1d, 0x07, 0x69, 0x30, 0xec, 0x8f, 0x8f, 0xe4, 0xe7, 0xf2, 0xd2, 0x67,
+	0xe5, 0xad, 0x35, 0x70, 0xd5, 0x7b, 0x79, 0x1e, 0x74, 0xd2, 0x40, 0x3b, 0x8d, 0x75, 0x9e, 0x29,
+	0xee, 0xfc, 0x42, 0xbf, 0x23, 0x32, 0x1f, 0x3a, 0x6b, 0xd1, 0x58, 0x84, 0x04, 0x26, 0x0e, 0x9d,
+	0x9d, 0x7e, 0x6b, 0xcf, 0xc0, 0x68, 0x86, 0x93, 0x27, 0xd1, 0xe5, 0x41, 0xa2, 0x73, 0xd1, 0xab,
+	0xcb, 0xc5, 0x41, 0x1a, 0x0e, 0xfb, 0xe3, 0x81, 0xaf, 0xf3, 0x78, 0xb2, 0x69, 0xaf, 0xf9, 0xd0,
+	0x0d, 0x1e, 0x5d, 0x88, 0xbe, 0x5b, 0xc4, 0xbd, 0xdd, 0xad, 0xf3, 0x6d, 0xdc, 0x71, 0x76, 0xe2,
+	0xdb, 0xf5, 0xc2, 0xe4, 0x25, 0x73, 0x5a, 0x38, 0x5c, 0x33, 0xcd, 0xe9, 0xdc, 0xe3, 0xa7, 0x74,
+	0x6e, 0xf7, 0x07, 0xe9, 0x53, 0x11, 0xce, 0xc0, 0x44, 0x93, 0x2f, 0xe1, 0x87, 0xbe, 0x4e, 0xd0,
+	0x9e, 0xbd, 0xe8, 0xb9, 0x67, 0x70, 0xf6, 0x36, 0x00, 0xfd, 0x4f, 0x53, 0xe2, 0x00, 0x03, 0x00,
+	0x00,
 }
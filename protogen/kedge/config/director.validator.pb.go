@@ -11,6 +11,7 @@ import _ "github.com/mwitkow/go-proto-validators"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/routes"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/http/routes"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/tcp/routes"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -34,6 +35,11 @@ func (this *DirectorConfig) Validate() error {
 			return go_proto_validators.FieldError("Http", err)
 		}
 	}
+	if this.Tcp != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Tcp); err != nil {
+			return go_proto_validators.FieldError("Tcp", err)
+		}
+	}
 	return nil
 }
 func (this *DirectorConfig_Grpc) Validate() error {
@@ -70,3 +76,13 @@ func (this *DirectorConfig_Http) Validate() error {
 	}
 	return nil
 }
+func (this *DirectorConfig_Tcp) Validate() error {
+	for _, item := range this.Routes {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("Routes", err)
+			}
+		}
+	}
+	return nil
+}
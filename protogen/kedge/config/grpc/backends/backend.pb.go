@@ -9,7 +9,11 @@ It is generated from these files:
 
 It has these top-level messages:
 	Backend
+	HashPolicy
 	Interceptor
+	MetadataInjection
+	MessageLimits
+	RetryPolicy
 	Security
 */
 package kedge_config_grpc_backends
@@ -19,6 +23,7 @@ import fmt "fmt"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import kedge_config_common_resolvers "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -37,13 +42,34 @@ type Balancer int32
 const (
 	// ROUND_ROBIN is the simpliest and default load balancing policy
 	Balancer_ROUND_ROBIN Balancer = 0
+	// / LEAST_REQUEST picks the target with the least outstanding RPCs.
+	Balancer_LEAST_REQUEST Balancer = 1
+	// / POWER_OF_TWO_CHOICES picks the target with less outstanding RPCs out of two randomly chosen ones.
+	Balancer_POWER_OF_TWO_CHOICES Balancer = 2
+	// / RING_HASH picks the target using consistent hashing (hash ring) of the key specified in hash_policy.
+	Balancer_RING_HASH Balancer = 3
+	// / MAGLEV picks the target using consistent hashing (Maglev) of the key specified in hash_policy.
+	Balancer_MAGLEV Balancer = 4
+	// / WEIGHTED_ROUND_ROBIN spreads RPCs over targets in proportion to their resolved weights (e.g. weights of SRV
+	// / records). Resolvers without weights give every target weight 1.
+	Balancer_WEIGHTED_ROUND_ROBIN Balancer = 5
 )
 
 var Balancer_name = map[int32]string{
 	0: "ROUND_ROBIN",
+	1: "LEAST_REQUEST",
+	2: "POWER_OF_TWO_CHOICES",
+	3: "RING_HASH",
+	4: "MAGLEV",
+	5: "WEIGHTED_ROUND_ROBIN",
 }
 var Balancer_value = map[string]int32{
-	"ROUND_ROBIN": 0,
+	"ROUND_ROBIN":          0,
+	"LEAST_REQUEST":        1,
+	"POWER_OF_TWO_CHOICES": 2,
+	"RING_HASH":            3,
+	"MAGLEV":               4,
+	"WEIGHTED_ROUND_ROBIN": 5,
 }
 
 func (x Balancer) String() string {
@@ -63,6 +89,10 @@ type Backend struct {
 	Security *Security `protobuf:"bytes,4,opt,name=security" json:"security,omitempty"`
 	// / interceptors controls what interceptors will be enabled for this backend.
 	Interceptors []*Interceptor `protobuf:"bytes,5,rep,name=interceptors" json:"interceptors,omitempty"`
+	// / health_check enables active health checking (using the grpc check) of every target of this backend.
+	HealthCheck *kedge_config_common.HealthCheck `protobuf:"bytes,7,opt,name=health_check,json=healthCheck" json:"health_check,omitempty"`
+	// / hash_policy specifies the key for RING_HASH and MAGLEV balancers.
+	HashPolicy *HashPolicy `protobuf:"bytes,8,opt,name=hash_policy,json=hashPolicy" json:"hash_policy,omitempty"`
 	// Types that are valid to be assigned to Resolver:
 	//	*Backend_Srv
 	//	*Backend_K8S
@@ -136,6 +166,20 @@ func (m *Backend) GetInterceptors() []*Interceptor {
 	return nil
 }
 
+func (m *Backend) GetHealthCheck() *kedge_config_common.HealthCheck {
+	if m != nil {
+		return m.HealthCheck
+	}
+	return nil
+}
+
+func (m *Backend) GetHashPolicy() *HashPolicy {
+	if m != nil {
+		return m.HashPolicy
+	}
+	return nil
+}
+
 func (m *Backend) GetSrv() *kedge_config_common_resolvers.SrvResolver {
 	if x, ok := m.GetResolver().(*Backend_Srv); ok {
 		return x.Srv
@@ -257,16 +301,40 @@ func _Backend_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// / HashPolicy specifies the key used by consistent hashing balancers. RPCs without the key are balanced in round
+// / robin manner.
+type HashPolicy struct {
+	// / metadata_key hashes RPCs on the value of this metadata.
+	MetadataKey string `protobuf:"bytes,1,opt,name=metadata_key,json=metadataKey" json:"metadata_key,omitempty"`
+}
+
+func (m *HashPolicy) Reset()                    { *m = HashPolicy{} }
+func (m *HashPolicy) String() string            { return proto.CompactTextString(m) }
+func (*HashPolicy) ProtoMessage()               {}
+func (*HashPolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *HashPolicy) GetMetadataKey() string {
+	if m != nil {
+		return m.MetadataKey
+	}
+	return ""
+}
+
 type Interceptor struct {
 	// Types that are valid to be assigned to Interceptor:
 	//	*Interceptor_Prometheus
+	//	*Interceptor_Retry
+	//	*Interceptor_Logging
+	//	*Interceptor_Tracing
+	//	*Interceptor_Metadata
+	//	*Interceptor_MessageLimits
 	Interceptor isInterceptor_Interceptor `protobuf_oneof:"interceptor"`
 }
 
 func (m *Interceptor) Reset()                    { *m = Interceptor{} }
 func (m *Interceptor) String() string            { return proto.CompactTextString(m) }
 func (*Interceptor) ProtoMessage()               {}
-func (*Interceptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*Interceptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 type isInterceptor_Interceptor interface {
 	isInterceptor_Interceptor()
@@ -275,8 +343,28 @@ type isInterceptor_Interceptor interface {
 type Interceptor_Prometheus struct {
 	Prometheus bool `protobuf:"varint,1,opt,name=prometheus,oneof"`
 }
+type Interceptor_Retry struct {
+	Retry *RetryPolicy `protobuf:"bytes,2,opt,name=retry,oneof"`
+}
+type Interceptor_Logging struct {
+	Logging bool `protobuf:"varint,3,opt,name=logging,oneof"`
+}
+type Interceptor_Tracing struct {
+	Tracing bool `protobuf:"varint,4,opt,name=tracing,oneof"`
+}
+type Interceptor_Metadata struct {
+	Metadata *MetadataInjection `protobuf:"bytes,5,opt,name=metadata,oneof"`
+}
+type Interceptor_MessageLimits struct {
+	MessageLimits *MessageLimits `protobuf:"bytes,6,opt,name=message_limits,json=messageLimits,oneof"`
+}
 
-func (*Interceptor_Prometheus) isInterceptor_Interceptor() {}
+func (*Interceptor_Prometheus) isInterceptor_Interceptor()    {}
+func (*Interceptor_Retry) isInterceptor_Interceptor()         {}
+func (*Interceptor_Logging) isInterceptor_Interceptor()       {}
+func (*Interceptor_Tracing) isInterceptor_Interceptor()       {}
+func (*Interceptor_Metadata) isInterceptor_Interceptor()      {}
+func (*Interceptor_MessageLimits) isInterceptor_Interceptor() {}
 
 func (m *Interceptor) GetInterceptor() isInterceptor_Interceptor {
 	if m != nil {
@@ -292,10 +380,50 @@ func (m *Interceptor) GetPrometheus() bool {
 	return false
 }
 
+func (m *Interceptor) GetRetry() *RetryPolicy {
+	if x, ok := m.GetInterceptor().(*Interceptor_Retry); ok {
+		return x.Retry
+	}
+	return nil
+}
+
+func (m *Interceptor) GetLogging() bool {
+	if x, ok := m.GetInterceptor().(*Interceptor_Logging); ok {
+		return x.Logging
+	}
+	return false
+}
+
+func (m *Interceptor) GetTracing() bool {
+	if x, ok := m.GetInterceptor().(*Interceptor_Tracing); ok {
+		return x.Tracing
+	}
+	return false
+}
+
+func (m *Interceptor) GetMetadata() *MetadataInjection {
+	if x, ok := m.GetInterceptor().(*Interceptor_Metadata); ok {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (m *Interceptor) GetMessageLimits() *MessageLimits {
+	if x, ok := m.GetInterceptor().(*Interceptor_MessageLimits); ok {
+		return x.MessageLimits
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Interceptor) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Interceptor_OneofMarshaler, _Interceptor_OneofUnmarshaler, _Interceptor_OneofSizer, []interface{}{
 		(*Interceptor_Prometheus)(nil),
+		(*Interceptor_Retry)(nil),
+		(*Interceptor_Logging)(nil),
+		(*Interceptor_Tracing)(nil),
+		(*Interceptor_Metadata)(nil),
+		(*Interceptor_MessageLimits)(nil),
 	}
 }
 
@@ -310,6 +438,35 @@ func _Interceptor_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		}
 		b.EncodeVarint(1<<3 | proto.WireVarint)
 		b.EncodeVarint(t)
+	case *Interceptor_Retry:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Retry); err != nil {
+			return err
+		}
+	case *Interceptor_Logging:
+		t := uint64(0)
+		if x.Logging {
+			t = 1
+		}
+		b.EncodeVarint(3<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case *Interceptor_Tracing:
+		t := uint64(0)
+		if x.Tracing {
+			t = 1
+		}
+		b.EncodeVarint(4<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case *Interceptor_Metadata:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Metadata); err != nil {
+			return err
+		}
+	case *Interceptor_MessageLimits:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.MessageLimits); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Interceptor.Interceptor has unexpected type %T", x)
@@ -327,6 +484,44 @@ func _Interceptor_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Bu
 		x, err := b.DecodeVarint()
 		m.Interceptor = &Interceptor_Prometheus{x != 0}
 		return true, err
+	case 2: // interceptor.retry
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RetryPolicy)
+		err := b.DecodeMessage(msg)
+		m.Interceptor = &Interceptor_Retry{msg}
+		return true, err
+	case 3: // interceptor.logging
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Interceptor = &Interceptor_Logging{x != 0}
+		return true, err
+	case 4: // interceptor.tracing
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Interceptor = &Interceptor_Tracing{x != 0}
+		return true, err
+	case 5: // interceptor.metadata
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MetadataInjection)
+		err := b.DecodeMessage(msg)
+		m.Interceptor = &Interceptor_Metadata{msg}
+		return true, err
+	case 6: // interceptor.message_limits
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MessageLimits)
+		err := b.DecodeMessage(msg)
+		m.Interceptor = &Interceptor_MessageLimits{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -339,6 +534,27 @@ func _Interceptor_OneofSizer(msg proto.Message) (n int) {
 	case *Interceptor_Prometheus:
 		n += proto.SizeVarint(1<<3 | proto.WireVarint)
 		n += 1
+	case *Interceptor_Retry:
+		s := proto.Size(x.Retry)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Interceptor_Logging:
+		n += proto.SizeVarint(3<<3 | proto.WireVarint)
+		n += 1
+	case *Interceptor_Tracing:
+		n += proto.SizeVarint(4<<3 | proto.WireVarint)
+		n += 1
+	case *Interceptor_Metadata:
+		s := proto.Size(x.Metadata)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Interceptor_MessageLimits:
+		s := proto.Size(x.MessageLimits)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -346,19 +562,134 @@ func _Interceptor_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// / MetadataInjection sets metadata of outgoing calls, overriding the metadata sent by the client.
+type MetadataInjection struct {
+	// / values maps metadata keys to values. Values are Go templates that can use environment variables prefixed with
+	// / KEDGE_MD_ and the incoming metadata, e.g. '{{env "KEDGE_MD_BACKEND_API_KEY"}}' or '{{.Metadata.Get "x-user"}}'.
+	Values map[string]string `protobuf:"bytes,1,rep,name=values" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *MetadataInjection) Reset()                    { *m = MetadataInjection{} }
+func (m *MetadataInjection) String() string            { return proto.CompactTextString(m) }
+func (*MetadataInjection) ProtoMessage()               {}
+func (*MetadataInjection) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *MetadataInjection) GetValues() map[string]string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// / MessageLimits limits the size of messages exchanged with a backend.
+type MessageLimits struct {
+	// / max_send_bytes is the maximum size of a message sent to the backend. If not present, it is not limited.
+	MaxSendBytes uint32 `protobuf:"varint,1,opt,name=max_send_bytes,json=maxSendBytes" json:"max_send_bytes,omitempty"`
+	// / max_receive_bytes is the maximum size of a message received from the backend. If not present, 4MiB is used.
+	MaxReceiveBytes uint32 `protobuf:"varint,2,opt,name=max_receive_bytes,json=maxReceiveBytes" json:"max_receive_bytes,omitempty"`
+	// / compression compresses messages sent to the backend. Only 'gzip' is supported. If not present, messages are not
+	// / compressed. Compressed responses are accepted in both cases.
+	Compression string `protobuf:"bytes,3,opt,name=compression" json:"compression,omitempty"`
+}
+
+func (m *MessageLimits) Reset()                    { *m = MessageLimits{} }
+func (m *MessageLimits) String() string            { return proto.CompactTextString(m) }
+func (*MessageLimits) ProtoMessage()               {}
+func (*MessageLimits) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *MessageLimits) GetMaxSendBytes() uint32 {
+	if m != nil {
+		return m.MaxSendBytes
+	}
+	return 0
+}
+
+func (m *MessageLimits) GetMaxReceiveBytes() uint32 {
+	if m != nil {
+		return m.MaxReceiveBytes
+	}
+	return 0
+}
+
+func (m *MessageLimits) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
+// / RetryPolicy controls retries and hedging of calls. Only calls whose request is a single message are retried, as the
+// / request needs to be buffered to be sent again; client streaming calls fall back to a single attempt.
+type RetryPolicy struct {
+	// / max_attempts is the maximum number of attempts of a call, including the first one. Values below 2 disable retries.
+	MaxAttempts uint32 `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts" json:"max_attempts,omitempty"`
+	// / retryable_codes are the gRPC status codes (e.g. 'UNAVAILABLE') on which calls are retried. If not present, only
+	// / UNAVAILABLE is retried.
+	RetryableCodes []string `protobuf:"bytes,2,rep,name=retryable_codes,json=retryableCodes" json:"retryable_codes,omitempty"`
+	// / backoff_ms is the delay between a failed attempt and the next one.
+	BackoffMs uint32 `protobuf:"varint,3,opt,name=backoff_ms,json=backoffMs" json:"backoff_ms,omitempty"`
+	// / hedging_delay_ms, if set, starts another attempt when the previous one did not respond within this delay.
+	// / The first attempt to respond wins and the others are cancelled.
+	HedgingDelayMs uint32 `protobuf:"varint,4,opt,name=hedging_delay_ms,json=hedgingDelayMs" json:"hedging_delay_ms,omitempty"`
+	// / max_buffer_bytes is the maximum size of the request message buffered for retries. Calls with bigger requests are
+	// / not retried. If not present, 64KiB is used.
+	MaxBufferBytes uint32 `protobuf:"varint,5,opt,name=max_buffer_bytes,json=maxBufferBytes" json:"max_buffer_bytes,omitempty"`
+}
+
+func (m *RetryPolicy) Reset()                    { *m = RetryPolicy{} }
+func (m *RetryPolicy) String() string            { return proto.CompactTextString(m) }
+func (*RetryPolicy) ProtoMessage()               {}
+func (*RetryPolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *RetryPolicy) GetMaxAttempts() uint32 {
+	if m != nil {
+		return m.MaxAttempts
+	}
+	return 0
+}
+
+func (m *RetryPolicy) GetRetryableCodes() []string {
+	if m != nil {
+		return m.RetryableCodes
+	}
+	return nil
+}
+
+func (m *RetryPolicy) GetBackoffMs() uint32 {
+	if m != nil {
+		return m.BackoffMs
+	}
+	return 0
+}
+
+func (m *RetryPolicy) GetHedgingDelayMs() uint32 {
+	if m != nil {
+		return m.HedgingDelayMs
+	}
+	return 0
+}
+
+func (m *RetryPolicy) GetMaxBufferBytes() uint32 {
+	if m != nil {
+		return m.MaxBufferBytes
+	}
+	return 0
+}
+
 // / Security settings for a backend.
 type Security struct {
 	// / insecure_skip_verify skips the server certificate verification completely.
-	// / No TLS config (for testclient or server) will be used. This should *not* be used in production software.
+	// / Client certificate from config_name is still presented if specified. This should *not* be used in production software.
 	InsecureSkipVerify bool `protobuf:"varint,1,opt,name=insecure_skip_verify,json=insecureSkipVerify" json:"insecure_skip_verify,omitempty"`
-	// / config_name indicates the TlsServerConfig to be used for this connection.
+	// / config_name indicates the TlsServerConfig (declared in BackendPoolConfig.tls_server_configs) to be used for this connection.
+	// / If empty, the backend's server certificate is verified against root CAs fetched from host.
 	ConfigName string `protobuf:"bytes,2,opt,name=config_name,json=configName" json:"config_name,omitempty"`
 }
 
 func (m *Security) Reset()                    { *m = Security{} }
 func (m *Security) String() string            { return proto.CompactTextString(m) }
 func (*Security) ProtoMessage()               {}
-func (*Security) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*Security) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *Security) GetInsecureSkipVerify() bool {
 	if m != nil {
@@ -376,7 +707,11 @@ func (m *Security) GetConfigName() string {
 
 func init() {
 	proto.RegisterType((*Backend)(nil), "kedge.config.grpc.backends.Backend")
+	proto.RegisterType((*HashPolicy)(nil), "kedge.config.grpc.backends.HashPolicy")
 	proto.RegisterType((*Interceptor)(nil), "kedge.config.grpc.backends.Interceptor")
+	proto.RegisterType((*MetadataInjection)(nil), "kedge.config.grpc.backends.MetadataInjection")
+	proto.RegisterType((*MessageLimits)(nil), "kedge.config.grpc.backends.MessageLimits")
+	proto.RegisterType((*RetryPolicy)(nil), "kedge.config.grpc.backends.RetryPolicy")
 	proto.RegisterType((*Security)(nil), "kedge.config.grpc.backends.Security")
 	proto.RegisterEnum("kedge.config.grpc.backends.Balancer", Balancer_name, Balancer_value)
 }
@@ -384,37 +719,70 @@ func init() {
 func init() { proto.RegisterFile("kedge/config/grpc/backends/backend.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 503 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0x6d, 0x68, 0x28, 0xee, 0xb8, 0x05, 0xb4, 0xe4, 0x60, 0x85, 0x43, 0xad, 0x28, 0x12, 0x56,
-	0x69, 0xec, 0x12, 0x50, 0x15, 0x2e, 0x15, 0x18, 0x0e, 0xa9, 0x2a, 0xa5, 0xd2, 0x46, 0x70, 0x41,
-	0xc5, 0xda, 0x38, 0x5b, 0x67, 0xe5, 0x78, 0xd7, 0xda, 0xdd, 0xa4, 0x2a, 0x88, 0x7f, 0xe2, 0x8f,
-	0x90, 0xf8, 0x12, 0xe4, 0x75, 0x9c, 0x38, 0x07, 0x0a, 0xb7, 0xf1, 0xcc, 0x7b, 0x6f, 0xbc, 0x6f,
-	0x1e, 0x78, 0x29, 0x9d, 0x26, 0x34, 0x88, 0x05, 0xbf, 0x61, 0x49, 0x90, 0xc8, 0x3c, 0x0e, 0x26,
-	0x24, 0x4e, 0x29, 0x9f, 0xaa, 0xaa, 0xf0, 0x73, 0x29, 0xb4, 0x40, 0x6d, 0x83, 0xf4, 0x4b, 0xa4,
-	0x5f, 0x20, 0xfd, 0x0a, 0xd9, 0x3e, 0x4b, 0x98, 0x9e, 0x2d, 0x26, 0x7e, 0x2c, 0xb2, 0x20, 0xbb,
-	0x65, 0x3a, 0x15, 0xb7, 0x41, 0x22, 0x7a, 0x86, 0xd8, 0x5b, 0x92, 0x39, 0x9b, 0x12, 0x2d, 0xa4,
-	0x0a, 0xd6, 0x65, 0xa9, 0xd9, 0xee, 0x6d, 0x6d, 0x8f, 0x45, 0x96, 0x09, 0x1e, 0x48, 0xaa, 0xc4,
-	0x7c, 0x49, 0xa5, 0xda, 0x54, 0x25, 0xbc, 0xf3, 0xb3, 0x09, 0x8f, 0xc2, 0x72, 0x27, 0x3a, 0x81,
-	0x26, 0x27, 0x19, 0x75, 0x1a, 0x6e, 0xc3, 0xdb, 0x0f, 0x9d, 0xdf, 0xbf, 0x8e, 0x5a, 0x80, 0xbe,
-	0x7e, 0x21, 0xbd, 0x6f, 0xd1, 0x69, 0xef, 0xad, 0x7f, 0xfd, 0xbd, 0x7f, 0x72, 0xf6, 0xe6, 0x47,
-	0x17, 0x1b, 0x14, 0x7a, 0x07, 0xd6, 0x84, 0xcc, 0x09, 0x8f, 0xa9, 0x74, 0x1e, 0xb8, 0x0d, 0xef,
-	0x71, 0xbf, 0xeb, 0xff, 0xfd, 0x3d, 0x7e, 0xb8, 0xc2, 0xe2, 0x35, 0x0b, 0xbd, 0x82, 0xd6, 0x94,
-	0x29, 0x32, 0x99, 0xd3, 0x28, 0x16, 0x9c, 0x6b, 0x49, 0xe2, 0x94, 0xf1, 0xc4, 0xd9, 0x75, 0x1b,
-	0x9e, 0x85, 0x9f, 0xad, 0x66, 0x1f, 0x6a, 0xa3, 0x62, 0xa9, 0xa2, 0xf1, 0x42, 0x32, 0x7d, 0xe7,
-	0x34, 0xdd, 0x86, 0x67, 0xdf, 0xbf, 0x74, 0xbc, 0xc2, 0xe2, 0x35, 0x0b, 0x5d, 0xc2, 0x01, 0xe3,
-	0x9a, 0xca, 0x98, 0xe6, 0x85, 0x7f, 0xce, 0x43, 0x77, 0xd7, 0xb3, 0xfb, 0x2f, 0xee, 0x53, 0xb9,
-	0xd8, 0xe0, 0xf1, 0x16, 0x19, 0x9d, 0xc3, 0xae, 0x92, 0x4b, 0x07, 0xcc, 0x9f, 0x1c, 0x6f, 0x6b,
-	0x94, 0xd6, 0xfb, 0x1b, 0xc3, 0xc7, 0x72, 0x89, 0x57, 0x1f, 0xc3, 0x1d, 0x5c, 0x10, 0x0b, 0x7e,
-	0x3a, 0x50, 0x8e, 0xfd, 0x5f, 0xfc, 0xcb, 0x81, 0xaa, 0xf3, 0xd3, 0x81, 0x42, 0xef, 0xa1, 0x39,
-	0x13, 0x4a, 0x3b, 0x07, 0x46, 0xe0, 0xe5, 0x3f, 0x04, 0x86, 0x42, 0xe9, 0x9a, 0x82, 0xa1, 0xa2,
-	0x2e, 0x1c, 0x92, 0x85, 0x16, 0x09, 0xe5, 0x54, 0x12, 0x4d, 0xa7, 0xce, 0x9e, 0x71, 0x7f, 0xbb,
-	0x19, 0x02, 0x58, 0x95, 0x4e, 0xe7, 0x1c, 0xec, 0x9a, 0x23, 0xc8, 0x05, 0xc8, 0xa5, 0xc8, 0xa8,
-	0x9e, 0xd1, 0x85, 0x32, 0xd9, 0xb1, 0x86, 0x3b, 0xb8, 0xd6, 0x0b, 0x0f, 0xc1, 0xae, 0xb9, 0xd6,
-	0xb9, 0x06, 0xab, 0xba, 0x0b, 0x3a, 0x85, 0x16, 0xe3, 0xe6, 0x36, 0x34, 0x52, 0x29, 0xcb, 0xa3,
-	0x25, 0x95, 0xec, 0xe6, 0xae, 0x94, 0xc1, 0xa8, 0x9a, 0x8d, 0x53, 0x96, 0x7f, 0x36, 0x13, 0x74,
-	0x04, 0x76, 0xf9, 0xbe, 0xc8, 0x64, 0xb5, 0x48, 0xde, 0x3e, 0x86, 0xb2, 0x35, 0x22, 0x19, 0x3d,
-	0x7e, 0x0e, 0x56, 0x95, 0x35, 0xf4, 0x04, 0x6c, 0x7c, 0xf5, 0x69, 0xf4, 0x31, 0xc2, 0x57, 0xe1,
-	0xc5, 0xe8, 0xe9, 0xce, 0x64, 0xcf, 0xa4, 0xfe, 0xf5, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x19,
-	0xe3, 0xcd, 0x6e, 0xa4, 0x03, 0x00, 0x00,
+	// 1027 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x95, 0xdf, 0x72, 0xda, 0x46,
+	0x14, 0xc6, 0xc1, 0x80, 0x0d, 0x47, 0xc6, 0xc6, 0x5b, 0x5f, 0x68, 0x3c, 0xd3, 0x31, 0x65, 0xdc,
+	0x86, 0xb8, 0x01, 0x12, 0xb7, 0x93, 0xb1, 0x7b, 0xd1, 0xd4, 0x60, 0x6a, 0x31, 0xfe, 0x43, 0xb2,
+	0x38, 0xf6, 0x45, 0x27, 0xd1, 0x2c, 0xd2, 0x22, 0xa9, 0x20, 0x89, 0xd1, 0x2e, 0x04, 0xd2, 0xc9,
+	0x5b, 0xf4, 0x05, 0xfa, 0x22, 0x7d, 0x82, 0x3e, 0x40, 0xef, 0x32, 0x93, 0x27, 0xe9, 0xec, 0x4a,
+	0x02, 0x79, 0x9a, 0x92, 0xe6, 0x6e, 0xf5, 0x9d, 0xdf, 0x77, 0xf6, 0xe8, 0xec, 0x91, 0x16, 0xaa,
+	0x43, 0x6a, 0x5a, 0xb4, 0x61, 0xf8, 0xde, 0xc0, 0xb1, 0x1a, 0x56, 0x30, 0x36, 0x1a, 0x7d, 0x62,
+	0x0c, 0xa9, 0x67, 0xb2, 0x78, 0x51, 0x1f, 0x07, 0x3e, 0xf7, 0xd1, 0x9e, 0x24, 0xeb, 0x21, 0x59,
+	0x17, 0x64, 0x3d, 0x26, 0xf7, 0x9e, 0x5a, 0x0e, 0xb7, 0x27, 0xfd, 0xba, 0xe1, 0xbb, 0x0d, 0xf7,
+	0x8d, 0xc3, 0x87, 0xfe, 0x9b, 0x86, 0xe5, 0xd7, 0xa4, 0xb1, 0x36, 0x25, 0x23, 0xc7, 0x24, 0xdc,
+	0x0f, 0x58, 0x63, 0xb1, 0x0c, 0x73, 0xee, 0xd5, 0xee, 0xed, 0x6e, 0xf8, 0xae, 0xeb, 0x7b, 0x8d,
+	0x80, 0x32, 0x7f, 0x34, 0xa5, 0x01, 0x5b, 0xae, 0x22, 0xfc, 0xeb, 0x8f, 0xe1, 0x36, 0x25, 0x23,
+	0x6e, 0x1b, 0x36, 0x35, 0x86, 0x21, 0x56, 0xf9, 0x33, 0x07, 0x1b, 0xcd, 0xb0, 0x34, 0xf4, 0x08,
+	0xb2, 0x1e, 0x71, 0xa9, 0x9a, 0x2e, 0xa7, 0xab, 0x85, 0xa6, 0xfa, 0xe1, 0xfd, 0xfe, 0x2e, 0xa0,
+	0xd7, 0xbf, 0x90, 0xda, 0x5b, 0xfd, 0x71, 0xed, 0xa4, 0xfe, 0xea, 0xb7, 0xa3, 0x47, 0x4f, 0xbf,
+	0x7f, 0x77, 0x80, 0x25, 0x85, 0x7e, 0x82, 0x7c, 0x9f, 0x8c, 0x88, 0x67, 0xd0, 0x40, 0x5d, 0x2b,
+	0xa7, 0xab, 0x5b, 0x47, 0x07, 0xf5, 0xff, 0x7e, 0xed, 0x7a, 0x33, 0x62, 0xf1, 0xc2, 0x85, 0x9e,
+	0xc0, 0xae, 0xe9, 0x30, 0xd2, 0x1f, 0x51, 0xdd, 0xf0, 0x3d, 0x8f, 0x07, 0xc4, 0x18, 0x3a, 0x9e,
+	0xa5, 0x66, 0xca, 0xe9, 0x6a, 0x1e, 0x7f, 0x11, 0xc5, 0x5a, 0x89, 0x90, 0xd8, 0x94, 0x51, 0x63,
+	0x12, 0x38, 0x7c, 0xae, 0x66, 0xcb, 0xe9, 0xaa, 0xb2, 0x7a, 0xd3, 0x5e, 0xc4, 0xe2, 0x85, 0x0b,
+	0x5d, 0xc0, 0xa6, 0xe3, 0x71, 0x1a, 0x18, 0x74, 0x2c, 0xda, 0xac, 0xe6, 0xca, 0x99, 0xaa, 0x72,
+	0xf4, 0x60, 0x55, 0x96, 0xce, 0x92, 0xc7, 0xf7, 0xcc, 0xa8, 0x05, 0x9b, 0x61, 0x4b, 0x75, 0xd9,
+	0x53, 0x75, 0x43, 0x96, 0x54, 0xbe, 0x9f, 0x2c, 0xec, 0x7d, 0x5d, 0x93, 0x60, 0x4b, 0x70, 0x58,
+	0xb1, 0x97, 0x0f, 0xe8, 0x1c, 0x14, 0x9b, 0x30, 0x5b, 0x1f, 0xfb, 0x23, 0xc7, 0x98, 0xab, 0x79,
+	0x99, 0xe3, 0x9b, 0x55, 0x05, 0x69, 0x84, 0xd9, 0xcf, 0x25, 0x8d, 0xc1, 0x5e, 0xac, 0xd1, 0x8f,
+	0x90, 0x61, 0xc1, 0x54, 0x05, 0x99, 0xe0, 0xf0, 0xa3, 0x45, 0x2c, 0xa7, 0xa4, 0x17, 0x4c, 0x71,
+	0xf4, 0xa0, 0xa5, 0xb0, 0x30, 0x0a, 0xff, 0xf0, 0x98, 0xa9, 0xca, 0xff, 0xf2, 0x5f, 0x1c, 0xb3,
+	0xa4, 0x7f, 0x78, 0xcc, 0xd0, 0x29, 0x64, 0x6d, 0x9f, 0x71, 0x75, 0x53, 0x26, 0xf8, 0xf6, 0x13,
+	0x09, 0x34, 0x9f, 0xf1, 0x44, 0x06, 0x69, 0x45, 0x07, 0x50, 0x24, 0x13, 0xee, 0x5b, 0xd4, 0xa3,
+	0x01, 0xe1, 0xd4, 0x54, 0xd7, 0xe5, 0x2c, 0xdc, 0x17, 0x9b, 0x00, 0xf9, 0x38, 0x4f, 0xa5, 0x01,
+	0xb0, 0x6c, 0x07, 0xfa, 0x0a, 0x36, 0x5d, 0xca, 0x89, 0x49, 0x38, 0xd1, 0x87, 0x74, 0x1e, 0x8e,
+	0x32, 0x56, 0x62, 0xed, 0x82, 0xce, 0x2b, 0x7f, 0xaf, 0x81, 0x92, 0x38, 0x51, 0x54, 0x06, 0x18,
+	0x07, 0xbe, 0x4b, 0xb9, 0x4d, 0x27, 0x4c, 0x1a, 0xf2, 0x5a, 0x0a, 0x27, 0x34, 0xf4, 0x0c, 0x72,
+	0x01, 0xe5, 0xc1, 0x5c, 0x8e, 0xf9, 0x27, 0x66, 0x05, 0x0b, 0x30, 0x2c, 0x46, 0x4b, 0xe1, 0xd0,
+	0x87, 0xf6, 0x60, 0x63, 0xe4, 0x5b, 0xd6, 0x62, 0xb6, 0xb5, 0x14, 0x8e, 0x05, 0x11, 0x13, 0xd3,
+	0x2d, 0x62, 0xd9, 0x38, 0x16, 0x09, 0xe8, 0x02, 0xf2, 0x71, 0xe5, 0x6a, 0x4e, 0xee, 0x5d, 0x5b,
+	0xb5, 0xf7, 0x55, 0xc4, 0x76, 0xbc, 0x5f, 0xa9, 0xc1, 0x1d, 0xdf, 0xd3, 0x52, 0x78, 0x91, 0x00,
+	0x61, 0xd8, 0x72, 0x29, 0x63, 0xc4, 0xa2, 0xfa, 0xc8, 0x71, 0x1d, 0xce, 0x64, 0x6f, 0x95, 0xa3,
+	0x87, 0xab, 0x53, 0x4a, 0xc7, 0xa5, 0x34, 0x68, 0x29, 0x5c, 0x74, 0x93, 0x42, 0xb3, 0x08, 0x4a,
+	0xe2, 0x7b, 0xa8, 0xfc, 0x91, 0x86, 0x9d, 0x7f, 0x15, 0x81, 0x5e, 0xc0, 0xfa, 0x94, 0x8c, 0x26,
+	0x54, 0x34, 0x57, 0x7c, 0x6b, 0x27, 0x9f, 0xf5, 0x0e, 0xf5, 0x5b, 0xe9, 0x6d, 0x7b, 0x3c, 0x98,
+	0xe3, 0x28, 0xd1, 0xde, 0x09, 0x28, 0x09, 0x19, 0x95, 0x20, 0xb3, 0x3c, 0x6c, 0xb1, 0x44, 0xbb,
+	0x90, 0x93, 0xa8, 0x3c, 0xb2, 0x02, 0x0e, 0x1f, 0x7e, 0x58, 0x3b, 0x4e, 0x57, 0x7e, 0x4f, 0x43,
+	0xf1, 0xde, 0x5b, 0xa1, 0x03, 0xd8, 0x72, 0xc9, 0x4c, 0x67, 0xd4, 0x33, 0xf5, 0xfe, 0x9c, 0xd3,
+	0x70, 0x08, 0x8a, 0x78, 0xd3, 0x25, 0xb3, 0x1e, 0xf5, 0xcc, 0xa6, 0xd0, 0xd0, 0x21, 0xec, 0x08,
+	0x2a, 0xa0, 0x06, 0x75, 0xa6, 0x34, 0x02, 0xd7, 0x24, 0xb8, 0xed, 0x92, 0x19, 0x0e, 0xf5, 0x90,
+	0x7d, 0x02, 0x8a, 0xe1, 0xbb, 0xe3, 0x80, 0x32, 0xe6, 0xf8, 0x9e, 0x3c, 0xf3, 0x42, 0x73, 0xfb,
+	0xc3, 0xfb, 0x7d, 0x05, 0x0a, 0xaf, 0xab, 0xd6, 0x5b, 0x67, 0xfc, 0xf0, 0xd9, 0x01, 0x4e, 0x32,
+	0x95, 0xbf, 0xd2, 0xa0, 0x24, 0x66, 0x47, 0x0e, 0x32, 0x99, 0xe9, 0x84, 0x73, 0xea, 0x8e, 0x79,
+	0x5c, 0x92, 0xe2, 0x92, 0xd9, 0x69, 0x24, 0xa1, 0x07, 0xb0, 0x2d, 0xc7, 0x2b, 0xfa, 0x81, 0x9a,
+	0xb2, 0x9e, 0x4c, 0xb5, 0x80, 0xb7, 0x16, 0x72, 0x4b, 0xa8, 0xe8, 0x4b, 0x00, 0xd1, 0x5f, 0x7f,
+	0x30, 0xd0, 0x5d, 0x26, 0xab, 0x29, 0xe2, 0x42, 0xa4, 0x5c, 0x31, 0x54, 0x85, 0x92, 0x4d, 0x4d,
+	0x31, 0x8c, 0xba, 0x49, 0x47, 0x64, 0x2e, 0xa0, 0xac, 0x84, 0xb6, 0x22, 0xfd, 0x4c, 0xc8, 0x21,
+	0x29, 0x8a, 0xea, 0x4f, 0x06, 0x03, 0x1a, 0x44, 0x2d, 0xc8, 0x85, 0xa4, 0x4b, 0x66, 0x4d, 0x29,
+	0xcb, 0x0e, 0x54, 0x5e, 0x41, 0x3e, 0xfe, 0xf7, 0xa2, 0xc7, 0xb0, 0xeb, 0x78, 0xf2, 0xff, 0x4b,
+	0x75, 0x36, 0x74, 0xc6, 0xfa, 0x94, 0x06, 0xce, 0x20, 0x3c, 0xae, 0x3c, 0x46, 0x71, 0xac, 0x37,
+	0x74, 0xc6, 0xb7, 0x32, 0x82, 0xf6, 0x41, 0x09, 0x87, 0x43, 0x97, 0xf7, 0x51, 0x78, 0x86, 0x10,
+	0x4a, 0xd7, 0xc4, 0xa5, 0x87, 0xef, 0x20, 0x1f, 0xdf, 0x27, 0x68, 0x1b, 0x14, 0xdc, 0x7d, 0x79,
+	0x7d, 0xa6, 0xe3, 0x6e, 0xb3, 0x73, 0x5d, 0x4a, 0xa1, 0x1d, 0x28, 0x5e, 0xb6, 0x4f, 0x7b, 0x37,
+	0x3a, 0x6e, 0xbf, 0x78, 0xd9, 0xee, 0xdd, 0x94, 0xd2, 0x48, 0x85, 0xdd, 0xe7, 0xdd, 0xbb, 0x36,
+	0xd6, 0xbb, 0x3f, 0xeb, 0x37, 0x77, 0x5d, 0xbd, 0xa5, 0x75, 0x3b, 0xad, 0x76, 0xaf, 0xb4, 0x86,
+	0x8a, 0x50, 0xc0, 0x9d, 0xeb, 0x73, 0x5d, 0x3b, 0xed, 0x69, 0xa5, 0x0c, 0x02, 0x58, 0xbf, 0x3a,
+	0x3d, 0xbf, 0x6c, 0xdf, 0x96, 0xb2, 0xc2, 0x74, 0xd7, 0xee, 0x9c, 0x6b, 0x37, 0xed, 0x33, 0x3d,
+	0xb9, 0x43, 0xae, 0xbf, 0x2e, 0xef, 0xce, 0xef, 0xfe, 0x19, 0x00, 0x2d, 0x08, 0x8b, 0x5b, 0x11,
+	0x08, 0x00, 0x00,
 }
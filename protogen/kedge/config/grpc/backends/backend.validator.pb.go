@@ -9,7 +9,11 @@ It is generated from these files:
 
 It has these top-level messages:
 	Backend
+	HashPolicy
 	Interceptor
+	MetadataInjection
+	MessageLimits
+	RetryPolicy
 	Security
 */
 package kedge_config_grpc_backends
@@ -21,6 +25,7 @@ import proto "github.com/golang/protobuf/proto"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -45,6 +50,16 @@ func (this *Backend) Validate() error {
 			}
 		}
 	}
+	if this.HealthCheck != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.HealthCheck); err != nil {
+			return go_proto_validators.FieldError("HealthCheck", err)
+		}
+	}
+	if this.HashPolicy != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.HashPolicy); err != nil {
+			return go_proto_validators.FieldError("HashPolicy", err)
+		}
+	}
 	if oneOfNester, ok := this.GetResolver().(*Backend_Srv); ok {
 		if oneOfNester.Srv != nil {
 			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Srv); err != nil {
@@ -68,7 +83,47 @@ func (this *Backend) Validate() error {
 	}
 	return nil
 }
+func (this *HashPolicy) Validate() error {
+	return nil
+}
 func (this *Interceptor) Validate() error {
+	if oneOfNester, ok := this.GetInterceptor().(*Interceptor_Retry); ok {
+		if oneOfNester.Retry != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Retry); err != nil {
+				return go_proto_validators.FieldError("Retry", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetInterceptor().(*Interceptor_Metadata); ok {
+		if oneOfNester.Metadata != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Metadata); err != nil {
+				return go_proto_validators.FieldError("Metadata", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetInterceptor().(*Interceptor_MessageLimits); ok {
+		if oneOfNester.MessageLimits != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.MessageLimits); err != nil {
+				return go_proto_validators.FieldError("MessageLimits", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *MetadataInjection) Validate() error {
+	// Validation of proto3 map<> fields is unsupported.
+	return nil
+}
+
+var _regex_MessageLimits_Compression = regexp.MustCompile(`^(gzip)?$`)
+
+func (this *MessageLimits) Validate() error {
+	if !_regex_MessageLimits_Compression.MatchString(this.Compression) {
+		return go_proto_validators.FieldError("Compression", fmt.Errorf(`value '%v' must be a string conforming to regex "^(gzip)?$"`, this.Compression))
+	}
+	return nil
+}
+func (this *RetryPolicy) Validate() error {
 	return nil
 }
 func (this *Security) Validate() error {
@@ -9,6 +9,9 @@ It is generated from these files:
 
 It has these top-level messages:
 	Route
+	Timeouts
+	Cors
+	WeightedBackend
 */
 package kedge_config_grpc_routes
 
@@ -16,6 +19,9 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import kedge_config_common1 "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import kedge_config_grpc_backends "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -31,6 +37,7 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 // / Route is a mapping between invoked gRPC requests and backends that should serve it.
 type Route struct {
 	// / backend_name is the string identifying the backend to send data to.
+	// / Either backend_name or weighted_backends needs to be specified.
 	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
 	// / service_name_matcher is a globbing expression that matches a full gRPC service name.
 	// / For example a method call to 'com.example.MyService/Create' would be matched by:
@@ -54,6 +61,38 @@ type Route struct {
 	// If 0 route will ignore port.
 	AuthorityPortMatcher uint32 `protobuf:"varint,5,opt,name=authority_port_matcher,json=authorityPortMatcher" json:"authority_port_matcher,omitempty"`
 	Autogenerated        bool   `protobuf:"varint,6,opt,name=autogenerated" json:"autogenerated,omitempty"`
+	// / weighted_backends splits the traffic of this route between multiple backends proportionally to their weights.
+	// / If present, backend_name is ignored.
+	WeightedBackends []*WeightedBackend `protobuf:"bytes,7,rep,name=weighted_backends,json=weightedBackends" json:"weighted_backends,omitempty"`
+	// / sticky_metadata_key makes the choice between weighted_backends sticky, so requests with the same value of this
+	// / metadata key are always sent to the same backend (as long as weights do not change).
+	// / If not present (or the request does not have this metadata), the backend is chosen randomly.
+	StickyMetadataKey string `protobuf:"bytes,8,opt,name=sticky_metadata_key,json=stickyMetadataKey" json:"sticky_metadata_key,omitempty"`
+	// / name is an optional name of the route used in metrics. If not present, the position of the route is used.
+	Name string `protobuf:"bytes,9,opt,name=name" json:"name,omitempty"`
+	// / service_name_pattern matches the full gRPC service name. It is checked in addition to service_name_matcher.
+	ServiceNamePattern *kedge_config_common.StringMatcher `protobuf:"bytes,10,opt,name=service_name_pattern,json=serviceNamePattern" json:"service_name_pattern,omitempty"`
+	// / authority_host_pattern matches the host part of the ':authority' header, e.g. {"wildcard": "*.svc.example.com"}.
+	// / It is checked in addition to authority_host_matcher.
+	AuthorityHostPattern *kedge_config_common.StringMatcher `protobuf:"bytes,11,opt,name=authority_host_pattern,json=authorityHostPattern" json:"authority_host_pattern,omitempty"`
+	// / metadata_patterns match any gRPC inbound request metadata. Each key provided must find a match.
+	// / If a given metadata entry has more than one string value, at least one of them needs to match.
+	// / They are checked in addition to metadata_matcher.
+	MetadataPatterns map[string]*kedge_config_common.StringMatcher `protobuf:"bytes,12,rep,name=metadata_patterns,json=metadataPatterns" json:"metadata_patterns,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// / rate_limit limits the rate of requests matched by this route per client. Requests over the limit are rejected
+	// / with RESOURCE_EXHAUSTED.
+	RateLimit *kedge_config_common1.RateLimit `protobuf:"bytes,13,opt,name=rate_limit,json=rateLimit" json:"rate_limit,omitempty"`
+	// / cors enables CORS for gRPC-Web requests matched by this route. Without it, gRPC-Web requests are served only for
+	// / same-origin (or non-browser) clients.
+	Cors *Cors `protobuf:"bytes,14,opt,name=cors" json:"cors,omitempty"`
+	// / method_pattern matches the service and method of the call, e.g. {"wildcard": "com.example.MyService/Get*"}.
+	// / It is checked in addition to the service name matchers.
+	MethodPattern *kedge_config_common.StringMatcher `protobuf:"bytes,15,opt,name=method_pattern,json=methodPattern" json:"method_pattern,omitempty"`
+	// / timeouts limit the duration of calls matched by this route.
+	Timeouts *Timeouts `protobuf:"bytes,16,opt,name=timeouts" json:"timeouts,omitempty"`
+	// / retry overrides the retry policy of the backend (see the backend's retry interceptor) for calls matched by this
+	// / route, e.g. to retry only idempotent methods. {"max_attempts": 1} disables retries.
+	Retry *kedge_config_grpc_backends.RetryPolicy `protobuf:"bytes,17,opt,name=retry" json:"retry,omitempty"`
 }
 
 func (m *Route) Reset()                    { *m = Route{} }
@@ -103,35 +142,266 @@ func (m *Route) GetAutogenerated() bool {
 	return false
 }
 
+func (m *Route) GetWeightedBackends() []*WeightedBackend {
+	if m != nil {
+		return m.WeightedBackends
+	}
+	return nil
+}
+
+func (m *Route) GetStickyMetadataKey() string {
+	if m != nil {
+		return m.StickyMetadataKey
+	}
+	return ""
+}
+
+func (m *Route) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Route) GetServiceNamePattern() *kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.ServiceNamePattern
+	}
+	return nil
+}
+
+func (m *Route) GetAuthorityHostPattern() *kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.AuthorityHostPattern
+	}
+	return nil
+}
+
+func (m *Route) GetMetadataPatterns() map[string]*kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.MetadataPatterns
+	}
+	return nil
+}
+
+func (m *Route) GetRateLimit() *kedge_config_common1.RateLimit {
+	if m != nil {
+		return m.RateLimit
+	}
+	return nil
+}
+
+func (m *Route) GetCors() *Cors {
+	if m != nil {
+		return m.Cors
+	}
+	return nil
+}
+
+func (m *Route) GetMethodPattern() *kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.MethodPattern
+	}
+	return nil
+}
+
+func (m *Route) GetTimeouts() *Timeouts {
+	if m != nil {
+		return m.Timeouts
+	}
+	return nil
+}
+
+func (m *Route) GetRetry() *kedge_config_grpc_backends.RetryPolicy {
+	if m != nil {
+		return m.Retry
+	}
+	return nil
+}
+
+// / Timeouts limit the duration of gRPC calls. Zero values disable the given limit.
+type Timeouts struct {
+	// / default_deadline_ms is the deadline applied to calls that were sent without one. It is propagated to the backend.
+	DefaultDeadlineMs uint32 `protobuf:"varint,1,opt,name=default_deadline_ms,json=defaultDeadlineMs" json:"default_deadline_ms,omitempty"`
+	// / max_deadline_ms caps the deadline of calls. Calls without a deadline, or with a later one, get this one instead.
+	// / It is propagated to the backend.
+	MaxDeadlineMs uint32 `protobuf:"varint,2,opt,name=max_deadline_ms,json=maxDeadlineMs" json:"max_deadline_ms,omitempty"`
+	// / max_stream_duration_ms ends calls (typically long-lived streams) with DEADLINE_EXCEEDED after this duration.
+	// / Unlike the deadlines, it is enforced by kedge only and is not propagated to the backend.
+	MaxStreamDurationMs uint32 `protobuf:"varint,3,opt,name=max_stream_duration_ms,json=maxStreamDurationMs" json:"max_stream_duration_ms,omitempty"`
+}
+
+func (m *Timeouts) Reset()                    { *m = Timeouts{} }
+func (m *Timeouts) String() string            { return proto.CompactTextString(m) }
+func (*Timeouts) ProtoMessage()               {}
+func (*Timeouts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Timeouts) GetDefaultDeadlineMs() uint32 {
+	if m != nil {
+		return m.DefaultDeadlineMs
+	}
+	return 0
+}
+
+func (m *Timeouts) GetMaxDeadlineMs() uint32 {
+	if m != nil {
+		return m.MaxDeadlineMs
+	}
+	return 0
+}
+
+func (m *Timeouts) GetMaxStreamDurationMs() uint32 {
+	if m != nil {
+		return m.MaxStreamDurationMs
+	}
+	return 0
+}
+
+// / Cors controls Cross-Origin Resource Sharing of gRPC-Web requests.
+type Cors struct {
+	// / allowed_origins is a list of origins allowed to call the route, e.g. 'https://app.example.com'. '*' allows all.
+	AllowedOrigins []string `protobuf:"bytes,1,rep,name=allowed_origins,json=allowedOrigins" json:"allowed_origins,omitempty"`
+	// / allowed_headers is a list of request headers (metadata) allowed in addition to the ones required by gRPC-Web.
+	AllowedHeaders []string `protobuf:"bytes,2,rep,name=allowed_headers,json=allowedHeaders" json:"allowed_headers,omitempty"`
+	// / exposed_headers is a list of response headers (metadata) exposed in addition to the ones required by gRPC-Web.
+	ExposedHeaders []string `protobuf:"bytes,3,rep,name=exposed_headers,json=exposedHeaders" json:"exposed_headers,omitempty"`
+	// / allow_credentials allows requests with credentials (cookies, HTTP authentication).
+	AllowCredentials bool `protobuf:"varint,4,opt,name=allow_credentials,json=allowCredentials" json:"allow_credentials,omitempty"`
+	// / max_age_s is how long the result of a preflight request can be cached by the browser.
+	MaxAgeS uint32 `protobuf:"varint,5,opt,name=max_age_s,json=maxAgeS" json:"max_age_s,omitempty"`
+}
+
+func (m *Cors) Reset()                    { *m = Cors{} }
+func (m *Cors) String() string            { return proto.CompactTextString(m) }
+func (*Cors) ProtoMessage()               {}
+func (*Cors) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Cors) GetAllowedOrigins() []string {
+	if m != nil {
+		return m.AllowedOrigins
+	}
+	return nil
+}
+
+func (m *Cors) GetAllowedHeaders() []string {
+	if m != nil {
+		return m.AllowedHeaders
+	}
+	return nil
+}
+
+func (m *Cors) GetExposedHeaders() []string {
+	if m != nil {
+		return m.ExposedHeaders
+	}
+	return nil
+}
+
+func (m *Cors) GetAllowCredentials() bool {
+	if m != nil {
+		return m.AllowCredentials
+	}
+	return false
+}
+
+func (m *Cors) GetMaxAgeS() uint32 {
+	if m != nil {
+		return m.MaxAgeS
+	}
+	return 0
+}
+
+// / WeightedBackend is a backend that receives a share of the route's traffic.
+type WeightedBackend struct {
+	// / backend_name is the string identifying the backend to send data to.
+	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
+	// / weight is the relative share of the traffic sent to this backend. Backends with 0 weight receive no traffic.
+	Weight uint32 `protobuf:"varint,2,opt,name=weight" json:"weight,omitempty"`
+}
+
+func (m *WeightedBackend) Reset()                    { *m = WeightedBackend{} }
+func (m *WeightedBackend) String() string            { return proto.CompactTextString(m) }
+func (*WeightedBackend) ProtoMessage()               {}
+func (*WeightedBackend) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *WeightedBackend) GetBackendName() string {
+	if m != nil {
+		return m.BackendName
+	}
+	return ""
+}
+
+func (m *WeightedBackend) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Route)(nil), "kedge.config.grpc.routes.Route")
+	proto.RegisterType((*Timeouts)(nil), "kedge.config.grpc.routes.Timeouts")
+	proto.RegisterType((*Cors)(nil), "kedge.config.grpc.routes.Cors")
+	proto.RegisterType((*WeightedBackend)(nil), "kedge.config.grpc.routes.WeightedBackend")
 }
 
 func init() { proto.RegisterFile("kedge/config/grpc/routes/routes.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 353 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x51, 0xcb, 0x4a, 0xf3, 0x40,
-	0x14, 0x26, 0xcd, 0xdf, 0xf2, 0x3b, 0xb5, 0x58, 0x86, 0x20, 0xa1, 0x1b, 0x83, 0x54, 0xc8, 0xc2,
-	0x4c, 0x4a, 0x2d, 0xc5, 0xcb, 0xae, 0x20, 0xb8, 0xa9, 0x48, 0xb6, 0xa2, 0x61, 0x9a, 0x1c, 0x93,
-	0x90, 0x26, 0x53, 0x26, 0x27, 0x2d, 0x55, 0x7c, 0x24, 0x9f, 0x49, 0xf0, 0x49, 0x24, 0x17, 0xa3,
-	0x05, 0x5d, 0xcd, 0x99, 0xf3, 0x5d, 0xf8, 0xe6, 0x1b, 0x72, 0x12, 0x83, 0x1f, 0x80, 0xed, 0x89,
-	0xf4, 0x29, 0x0a, 0xec, 0x40, 0xae, 0x3c, 0x5b, 0x8a, 0x1c, 0x21, 0xab, 0x0f, 0xb6, 0x92, 0x02,
-	0x05, 0xd5, 0x4b, 0x1a, 0xab, 0x68, 0xac, 0xa0, 0xb1, 0x0a, 0x1f, 0x4c, 0x83, 0x08, 0xc3, 0x7c,
-	0xc1, 0x3c, 0x91, 0xd8, 0xc9, 0x26, 0xc2, 0x58, 0x6c, 0xec, 0x40, 0x58, 0xa5, 0xcc, 0x5a, 0xf3,
-	0x65, 0xe4, 0x73, 0x14, 0x32, 0xb3, 0x9b, 0xb1, 0x72, 0x3c, 0x7e, 0x53, 0x49, 0xdb, 0x29, 0x2c,
-	0xe8, 0x15, 0xd9, 0x5f, 0x70, 0x2f, 0x86, 0xd4, 0x77, 0x53, 0x9e, 0x80, 0xae, 0x18, 0x8a, 0xb9,
-	0x37, 0xd3, 0x3f, 0xde, 0x8f, 0x34, 0x42, 0x1f, 0xef, 0xb9, 0xf5, 0xec, 0x8e, 0xac, 0x0b, 0xf6,
-	0xf0, 0x32, 0x3e, 0x9d, 0x4e, 0x5e, 0x87, 0x4e, 0xb7, 0x66, 0xdf, 0xf2, 0x04, 0xe8, 0x88, 0x68,
-	0x19, 0xc8, 0x75, 0xe4, 0x41, 0x29, 0x76, 0x13, 0x8e, 0x5e, 0x08, 0x52, 0x6f, 0x15, 0x26, 0x0e,
-	0xad, 0xb1, 0x82, 0x3a, 0xaf, 0x10, 0x3a, 0x21, 0x87, 0x3c, 0xc7, 0x50, 0xc8, 0x08, 0xb7, 0x6e,
-	0x28, 0x32, 0x6c, 0x34, 0x6a, 0xa9, 0xd1, 0x1a, 0xf4, 0x46, 0x64, 0xf8, 0xa5, 0x72, 0x49, 0x3f,
-	0x01, 0xe4, 0x3e, 0x47, 0xde, 0xf0, 0xff, 0x19, 0xaa, 0xd9, 0x1d, 0x4f, 0xd8, 0x5f, 0xdd, 0xb0,
-	0xf2, 0x7d, 0x6c, 0x5e, 0xeb, 0x6a, 0xab, 0xeb, 0x14, 0xe5, 0xd6, 0x39, 0x48, 0x76, 0xb7, 0xbb,
-	0xb1, 0x56, 0x42, 0x7e, 0xc7, 0x6a, 0x1b, 0x8a, 0xd9, 0xfb, 0x11, 0xeb, 0x4e, 0xc8, 0x26, 0xd6,
-	0x90, 0xf4, 0x78, 0x8e, 0x22, 0x80, 0x14, 0x24, 0x47, 0xf0, 0xf5, 0x8e, 0xa1, 0x98, 0xff, 0x9d,
-	0xdd, 0xe5, 0x60, 0x46, 0xb4, 0xdf, 0x42, 0xd0, 0x3e, 0x51, 0x63, 0xd8, 0x56, 0x85, 0x3b, 0xc5,
-	0x48, 0x35, 0xd2, 0x5e, 0xf3, 0x65, 0x0e, 0x75, 0x7f, 0xd5, 0xe5, 0xb2, 0x75, 0xae, 0x2c, 0x3a,
-	0xe5, 0xb7, 0x9d, 0x7d, 0x06, 0x00, 0x00, 0xff, 0xff, 0x94, 0xa3, 0x62, 0xc8, 0x31, 0x02, 0x00,
-	0x00,
+	// 866 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x95, 0x7f, 0x6f, 0xdb, 0x44,
+	0x18, 0xc7, 0x95, 0xa6, 0xed, 0x9a, 0xeb, 0xd2, 0x24, 0xb7, 0xac, 0x58, 0x41, 0x62, 0x21, 0x0c,
+	0x96, 0x09, 0xea, 0x4c, 0x5d, 0x99, 0x06, 0x68, 0x43, 0x74, 0x43, 0x1a, 0x82, 0x42, 0xe5, 0x4e,
+	0x80, 0x84, 0x98, 0x75, 0xb5, 0x9f, 0x3a, 0xa7, 0xf8, 0x7c, 0xd1, 0xdd, 0xa5, 0x49, 0x40, 0xfc,
+	0xcb, 0x5b, 0xe0, 0x3d, 0xf1, 0x26, 0x26, 0xed, 0x95, 0x4c, 0xf7, 0xc3, 0x6e, 0x9c, 0x79, 0x52,
+	0xff, 0xca, 0xe5, 0x79, 0x3e, 0xdf, 0xc7, 0xdf, 0x7b, 0x9e, 0xf3, 0x19, 0x7d, 0x3a, 0x81, 0x38,
+	0x81, 0x51, 0xc4, 0xb3, 0x0b, 0x9a, 0x8c, 0x12, 0x31, 0x8d, 0x46, 0x82, 0xcf, 0x14, 0x48, 0xf7,
+	0xe3, 0x4f, 0x05, 0x57, 0x1c, 0x7b, 0x06, 0xf3, 0x2d, 0xe6, 0x6b, 0xcc, 0xb7, 0xf9, 0xde, 0xa3,
+	0x84, 0xaa, 0xf1, 0xec, 0xdc, 0x8f, 0x38, 0x1b, 0xb1, 0x39, 0x55, 0x13, 0x3e, 0x1f, 0x25, 0xfc,
+	0xc0, 0xc8, 0x0e, 0x2e, 0x49, 0x4a, 0x63, 0xa2, 0xb8, 0x90, 0xa3, 0x62, 0x69, 0x2b, 0xf6, 0x3e,
+	0x2e, 0x3d, 0x38, 0xe2, 0x8c, 0xf1, 0x6c, 0xc4, 0x88, 0x8a, 0xc6, 0x90, 0x23, 0x9f, 0x54, 0x21,
+	0x82, 0x28, 0x48, 0x29, 0xa3, 0xca, 0x41, 0xc3, 0x77, 0x37, 0x70, 0x4e, 0xa2, 0x09, 0x64, 0xb1,
+	0xcc, 0x17, 0x96, 0x1c, 0xfc, 0x8b, 0xd0, 0x56, 0xa0, 0x4d, 0xe3, 0xa7, 0xe8, 0xa6, 0x4b, 0x85,
+	0x19, 0x61, 0xe0, 0xd5, 0xfa, 0xb5, 0x61, 0xe3, 0xf8, 0xc3, 0x37, 0xaf, 0xef, 0x7c, 0x80, 0x6e,
+	0xbf, 0x1a, 0xfe, 0x41, 0x0e, 0xfe, 0x0a, 0x1f, 0x1c, 0x7c, 0xe5, 0xff, 0xf9, 0xf7, 0xe1, 0x17,
+	0x8f, 0x8e, 0xfe, 0xb9, 0xff, 0xed, 0xdd, 0x60, 0xd7, 0x09, 0x7e, 0x26, 0x0c, 0xf0, 0x03, 0xd4,
+	0x95, 0x20, 0x2e, 0x69, 0x04, 0x46, 0x1f, 0x3a, 0xdb, 0xde, 0x86, 0xae, 0x13, 0x60, 0x97, 0xd3,
+	0xe8, 0x89, 0xcd, 0xe0, 0x23, 0xb4, 0x4f, 0x66, 0x6a, 0xcc, 0x05, 0x55, 0xcb, 0x70, 0xcc, 0xa5,
+	0x2a, 0x34, 0x75, 0xa3, 0xe9, 0x16, 0xd9, 0x17, 0x5c, 0xaa, 0x5c, 0x15, 0xa2, 0x36, 0x03, 0x45,
+	0x62, 0xa2, 0x48, 0xc1, 0x6f, 0xf6, 0xeb, 0xc3, 0xdd, 0xc3, 0x23, 0xff, 0x7d, 0x03, 0xf1, 0xcd,
+	0x16, 0xfd, 0x13, 0xa7, 0x73, 0xa5, 0xbe, 0xcf, 0x94, 0x58, 0x06, 0x2d, 0x56, 0x8e, 0x96, 0x6d,
+	0x4d, 0xb9, 0xb8, 0xb2, 0xb5, 0xd5, 0xaf, 0x0d, 0x9b, 0x2b, 0xb6, 0x4e, 0xb9, 0x28, 0x6c, 0xdd,
+	0x45, 0x4d, 0x32, 0x53, 0x3c, 0x81, 0x0c, 0xf4, 0x34, 0x62, 0x6f, 0xbb, 0x5f, 0x1b, 0xee, 0x04,
+	0xe5, 0x20, 0xfe, 0x15, 0x75, 0xe6, 0x40, 0x93, 0xb1, 0x82, 0x38, 0xcc, 0x27, 0xe2, 0xdd, 0x30,
+	0xee, 0xef, 0xbf, 0xdf, 0xfd, 0x6f, 0x4e, 0x72, 0x6c, 0x15, 0x41, 0x7b, 0x5e, 0x0e, 0x48, 0xec,
+	0xa3, 0x5b, 0x52, 0xd1, 0x68, 0xb2, 0x0c, 0x8b, 0xde, 0x4c, 0x60, 0xe9, 0xed, 0x98, 0x3e, 0x76,
+	0x6c, 0x2a, 0xdf, 0xfd, 0x8f, 0xb0, 0xc4, 0x18, 0x6d, 0x9a, 0x21, 0x37, 0x0c, 0x60, 0xd6, 0xf8,
+	0xe5, 0xda, 0x00, 0xa7, 0x44, 0x29, 0x10, 0x99, 0x87, 0xfa, 0xb5, 0xe1, 0xee, 0xe1, 0xa0, 0x6c,
+	0xcf, 0x1e, 0x3c, 0xff, 0x4c, 0x09, 0x9a, 0x25, 0xae, 0x07, 0xa5, 0x21, 0x9f, 0x5a, 0x35, 0xfe,
+	0xfd, 0x9d, 0x21, 0xe7, 0x75, 0x77, 0xaf, 0x5d, 0xb7, 0x7c, 0x10, 0xf2, 0xca, 0xe7, 0xa8, 0x53,
+	0x6c, 0xd6, 0xd5, 0x94, 0xde, 0x4d, 0xd3, 0xcb, 0x2f, 0xaf, 0x7b, 0x12, 0x5c, 0x2d, 0x69, 0x8f,
+	0x42, 0x9b, 0xad, 0x85, 0xf1, 0x13, 0x84, 0xf4, 0xe0, 0x42, 0xf3, 0x72, 0x79, 0x4d, 0xe3, 0xf8,
+	0xa3, 0x4a, 0xc7, 0x01, 0x51, 0xf0, 0x93, 0xa6, 0x82, 0x86, 0xc8, 0x97, 0xf8, 0x10, 0x6d, 0x46,
+	0x5c, 0x48, 0x6f, 0xaf, 0x4a, 0xb8, 0xea, 0xea, 0x19, 0x17, 0x32, 0x30, 0x2c, 0xfe, 0x01, 0xed,
+	0x31, 0x50, 0x63, 0x1e, 0x17, 0x8d, 0x6a, 0x5d, 0xbb, 0x51, 0x4d, 0xab, 0xcc, 0x3b, 0xf4, 0x14,
+	0xed, 0x28, 0xca, 0x80, 0xcf, 0x94, 0xf4, 0xda, 0x55, 0x45, 0x56, 0x2d, 0xbc, 0x74, 0x64, 0x50,
+	0x68, 0xf0, 0x13, 0xb4, 0x25, 0x40, 0x89, 0xa5, 0xd7, 0x31, 0xe2, 0x7b, 0x15, 0xe2, 0xfc, 0x10,
+	0xfb, 0x81, 0x06, 0x4f, 0x79, 0x4a, 0xa3, 0x65, 0x60, 0x55, 0xbd, 0x63, 0xd4, 0xad, 0x7a, 0xe3,
+	0x70, 0x1b, 0xd5, 0xf5, 0xe1, 0x34, 0x17, 0x4c, 0xa0, 0x97, 0xb8, 0x8b, 0xb6, 0x2e, 0x49, 0x3a,
+	0x03, 0x77, 0x59, 0xd8, 0x3f, 0x5f, 0x6f, 0x3c, 0xae, 0xf5, 0x12, 0x74, 0xbb, 0x72, 0x56, 0x15,
+	0x45, 0x1e, 0xaf, 0x16, 0xb9, 0x5e, 0xbf, 0xae, 0x1e, 0x34, 0xf8, 0xaf, 0x86, 0x76, 0xf2, 0x16,
+	0xe8, 0xd7, 0x29, 0x86, 0x0b, 0x32, 0x4b, 0x55, 0x18, 0x03, 0x89, 0x53, 0x9a, 0x41, 0xc8, 0xa4,
+	0x79, 0x58, 0x33, 0xe8, 0xb8, 0xd4, 0x73, 0x97, 0x39, 0x91, 0xf8, 0x33, 0xd4, 0x62, 0x64, 0x51,
+	0x62, 0x37, 0x0c, 0xdb, 0x64, 0x64, 0xb1, 0xc2, 0x3d, 0x44, 0xfb, 0x9a, 0x93, 0x4a, 0x00, 0x61,
+	0x61, 0x3c, 0x13, 0x44, 0x51, 0x9e, 0x69, 0xbc, 0x6e, 0xf0, 0x5b, 0x8c, 0x2c, 0xce, 0x4c, 0xf2,
+	0xb9, 0xcb, 0x9d, 0xc8, 0xc1, 0xff, 0x35, 0xb4, 0xa9, 0xcf, 0x07, 0xbe, 0x87, 0x5a, 0x24, 0x4d,
+	0xf9, 0x1c, 0xe2, 0x90, 0x0b, 0x9a, 0xd0, 0x4c, 0x3b, 0xaa, 0x0f, 0x1b, 0xc1, 0x9e, 0x0b, 0xff,
+	0x62, 0xa3, 0xab, 0xe0, 0x18, 0x48, 0x0c, 0x42, 0xdb, 0x59, 0x05, 0x5f, 0xd8, 0xa8, 0x06, 0x61,
+	0x31, 0xe5, 0x72, 0x05, 0xac, 0x5b, 0xd0, 0x85, 0x73, 0xf0, 0x73, 0xd4, 0x31, 0xd2, 0x30, 0x12,
+	0x10, 0x43, 0xa6, 0x28, 0x49, 0xa5, 0xb7, 0x69, 0x6e, 0xb8, 0xb6, 0x49, 0x3c, 0xbb, 0x8a, 0xe3,
+	0x1e, 0x6a, 0xe8, 0x5d, 0x92, 0x04, 0x42, 0xe9, 0xee, 0xcc, 0x1b, 0x8c, 0x2c, 0xbe, 0x4b, 0xe0,
+	0x6c, 0x70, 0x81, 0x5a, 0x6b, 0xb7, 0x19, 0xfe, 0xa6, 0xf2, 0xc3, 0xe3, 0xbd, 0x79, 0x7d, 0xa7,
+	0x8b, 0xf0, 0xab, 0xf5, 0xef, 0xce, 0xda, 0x57, 0x67, 0x1f, 0x6d, 0xdb, 0xcb, 0xd0, 0x35, 0xdc,
+	0xfd, 0x3b, 0xdf, 0x36, 0x9f, 0xb7, 0x87, 0x6f, 0x07, 0x00, 0x26, 0xd8, 0x84, 0x4e, 0xcb, 0x07,
+	0x00, 0x00,
 }
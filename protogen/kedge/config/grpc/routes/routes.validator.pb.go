@@ -9,6 +9,9 @@ It is generated from these files:
 
 It has these top-level messages:
 	Route
+	Timeouts
+	Cors
+	WeightedBackend
 */
 package kedge_config_grpc_routes
 
@@ -18,18 +21,79 @@ import go_proto_validators "github.com/mwitkow/go-proto-validators"
 import proto "github.com/golang/protobuf/proto"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/grpc/backends"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-var _regex_Route_BackendName = regexp.MustCompile(`^[a-z_0-9.]{2,64}$`)
+var _regex_Route_BackendName = regexp.MustCompile(`^([a-z_0-9.]{2,64})?$`)
 
 func (this *Route) Validate() error {
 	if !_regex_Route_BackendName.MatchString(this.BackendName) {
-		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^[a-z_0-9.]{2,64}$"`, this.BackendName))
+		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^([a-z_0-9.]{2,64})?$"`, this.BackendName))
+	}
+	// Validation of proto3 map<> fields is unsupported.
+	for _, item := range this.WeightedBackends {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("WeightedBackends", err)
+			}
+		}
+	}
+	if this.ServiceNamePattern != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.ServiceNamePattern); err != nil {
+			return go_proto_validators.FieldError("ServiceNamePattern", err)
+		}
+	}
+	if this.AuthorityHostPattern != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.AuthorityHostPattern); err != nil {
+			return go_proto_validators.FieldError("AuthorityHostPattern", err)
+		}
 	}
 	// Validation of proto3 map<> fields is unsupported.
+	if this.RateLimit != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.RateLimit); err != nil {
+			return go_proto_validators.FieldError("RateLimit", err)
+		}
+	}
+	if this.Cors != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Cors); err != nil {
+			return go_proto_validators.FieldError("Cors", err)
+		}
+	}
+	if this.MethodPattern != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.MethodPattern); err != nil {
+			return go_proto_validators.FieldError("MethodPattern", err)
+		}
+	}
+	if this.Timeouts != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Timeouts); err != nil {
+			return go_proto_validators.FieldError("Timeouts", err)
+		}
+	}
+	if this.Retry != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Retry); err != nil {
+			return go_proto_validators.FieldError("Retry", err)
+		}
+	}
+	return nil
+}
+func (this *Timeouts) Validate() error {
+	return nil
+}
+func (this *Cors) Validate() error {
+	return nil
+}
+
+var _regex_WeightedBackend_BackendName = regexp.MustCompile(`^[a-z_0-9.]{2,64}$`)
+
+func (this *WeightedBackend) Validate() error {
+	if !_regex_WeightedBackend_BackendName.MatchString(this.BackendName) {
+		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^[a-z_0-9.]{2,64}$"`, this.BackendName))
+	}
 	return nil
 }
@@ -9,6 +9,8 @@ It is generated from these files:
 
 It has these top-level messages:
 	Backend
+	HashPolicy
+	StickySession
 	Middleware
 	Security
 */
@@ -19,6 +21,7 @@ import fmt "fmt"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import kedge_config_common_resolvers "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -37,13 +40,34 @@ type Balancer int32
 const (
 	// ROUND_ROBIN is the simpliest and default load balancing policy
 	Balancer_ROUND_ROBIN Balancer = 0
+	// / LEAST_REQUEST picks the target with the least outstanding requests.
+	Balancer_LEAST_REQUEST Balancer = 1
+	// / POWER_OF_TWO_CHOICES picks the target with less outstanding requests out of two randomly chosen ones.
+	Balancer_POWER_OF_TWO_CHOICES Balancer = 2
+	// / RING_HASH picks the target using consistent hashing (hash ring) of the key specified in hash_policy.
+	Balancer_RING_HASH Balancer = 3
+	// / MAGLEV picks the target using consistent hashing (Maglev) of the key specified in hash_policy.
+	Balancer_MAGLEV Balancer = 4
+	// / WEIGHTED_ROUND_ROBIN spreads requests over targets in proportion to their resolved weights (e.g. weights of SRV
+	// / records). Resolvers without weights give every target weight 1.
+	Balancer_WEIGHTED_ROUND_ROBIN Balancer = 5
 )
 
 var Balancer_name = map[int32]string{
 	0: "ROUND_ROBIN",
+	1: "LEAST_REQUEST",
+	2: "POWER_OF_TWO_CHOICES",
+	3: "RING_HASH",
+	4: "MAGLEV",
+	5: "WEIGHTED_ROUND_ROBIN",
 }
 var Balancer_value = map[string]int32{
-	"ROUND_ROBIN": 0,
+	"ROUND_ROBIN":          0,
+	"LEAST_REQUEST":        1,
+	"POWER_OF_TWO_CHOICES": 2,
+	"RING_HASH":            3,
+	"MAGLEV":               4,
+	"WEIGHTED_ROUND_ROBIN": 5,
 }
 
 func (x Balancer) String() string {
@@ -61,6 +85,15 @@ type Backend struct {
 	DisableConntracking bool `protobuf:"varint,3,opt,name=disable_conntracking,json=disableConntracking" json:"disable_conntracking,omitempty"`
 	// / security controls the TLS connection details for the backend (HTTPS). If not present, insecure HTTP mode is used.
 	Security *Security `protobuf:"bytes,4,opt,name=security" json:"security,omitempty"`
+	// / middlewares controls what middleware will be available on every call made to this backend.
+	// / These will be executed in order from left to right.
+	Middlewares []*Middleware `protobuf:"bytes,5,rep,name=middlewares" json:"middlewares,omitempty"`
+	// / health_check enables active health checking (using the http check) of every target of this backend.
+	HealthCheck *kedge_config_common.HealthCheck `protobuf:"bytes,7,opt,name=health_check,json=healthCheck" json:"health_check,omitempty"`
+	// / hash_policy specifies the key for RING_HASH and MAGLEV balancers.
+	HashPolicy *HashPolicy `protobuf:"bytes,8,opt,name=hash_policy,json=hashPolicy" json:"hash_policy,omitempty"`
+	// / sticky_session keeps requests of a client on the same target using a cookie set by kedge.
+	StickySession *StickySession `protobuf:"bytes,9,opt,name=sticky_session,json=stickySession" json:"sticky_session,omitempty"`
 	// Types that are valid to be assigned to Resolver:
 	//	*Backend_Srv
 	//	*Backend_K8S
@@ -127,6 +160,34 @@ func (m *Backend) GetSecurity() *Security {
 	return nil
 }
 
+func (m *Backend) GetMiddlewares() []*Middleware {
+	if m != nil {
+		return m.Middlewares
+	}
+	return nil
+}
+
+func (m *Backend) GetHealthCheck() *kedge_config_common.HealthCheck {
+	if m != nil {
+		return m.HealthCheck
+	}
+	return nil
+}
+
+func (m *Backend) GetHashPolicy() *HashPolicy {
+	if m != nil {
+		return m.HashPolicy
+	}
+	return nil
+}
+
+func (m *Backend) GetStickySession() *StickySession {
+	if m != nil {
+		return m.StickySession
+	}
+	return nil
+}
+
 func (m *Backend) GetSrv() *kedge_config_common_resolvers.SrvResolver {
 	if x, ok := m.GetResolver().(*Backend_Srv); ok {
 		return x.Srv
@@ -248,17 +309,192 @@ func _Backend_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
-// TODO(bplotka): Implemment that. Not really supported now.
+// / HashPolicy specifies the key used by consistent hashing balancers. Requests without the key are balanced in round
+// / robin manner.
+type HashPolicy struct {
+	// Types that are valid to be assigned to Key:
+	//	*HashPolicy_Header
+	//	*HashPolicy_Cookie
+	//	*HashPolicy_Path
+	Key isHashPolicy_Key `protobuf_oneof:"key"`
+}
+
+func (m *HashPolicy) Reset()                    { *m = HashPolicy{} }
+func (m *HashPolicy) String() string            { return proto.CompactTextString(m) }
+func (*HashPolicy) ProtoMessage()               {}
+func (*HashPolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type isHashPolicy_Key interface {
+	isHashPolicy_Key()
+}
+
+type HashPolicy_Header struct {
+	Header string `protobuf:"bytes,1,opt,name=header,oneof"`
+}
+type HashPolicy_Cookie struct {
+	Cookie string `protobuf:"bytes,2,opt,name=cookie,oneof"`
+}
+type HashPolicy_Path struct {
+	Path bool `protobuf:"varint,3,opt,name=path,oneof"`
+}
+
+func (*HashPolicy_Header) isHashPolicy_Key() {}
+func (*HashPolicy_Cookie) isHashPolicy_Key() {}
+func (*HashPolicy_Path) isHashPolicy_Key()   {}
+
+func (m *HashPolicy) GetKey() isHashPolicy_Key {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *HashPolicy) GetHeader() string {
+	if x, ok := m.GetKey().(*HashPolicy_Header); ok {
+		return x.Header
+	}
+	return ""
+}
+
+func (m *HashPolicy) GetCookie() string {
+	if x, ok := m.GetKey().(*HashPolicy_Cookie); ok {
+		return x.Cookie
+	}
+	return ""
+}
+
+func (m *HashPolicy) GetPath() bool {
+	if x, ok := m.GetKey().(*HashPolicy_Path); ok {
+		return x.Path
+	}
+	return false
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*HashPolicy) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _HashPolicy_OneofMarshaler, _HashPolicy_OneofUnmarshaler, _HashPolicy_OneofSizer, []interface{}{
+		(*HashPolicy_Header)(nil),
+		(*HashPolicy_Cookie)(nil),
+		(*HashPolicy_Path)(nil),
+	}
+}
+
+func _HashPolicy_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*HashPolicy)
+	// key
+	switch x := m.Key.(type) {
+	case *HashPolicy_Header:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Header)
+	case *HashPolicy_Cookie:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Cookie)
+	case *HashPolicy_Path:
+		t := uint64(0)
+		if x.Path {
+			t = 1
+		}
+		b.EncodeVarint(3<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case nil:
+	default:
+		return fmt.Errorf("HashPolicy.Key has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _HashPolicy_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*HashPolicy)
+	switch tag {
+	case 1: // key.header
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Key = &HashPolicy_Header{x}
+		return true, err
+	case 2: // key.cookie
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Key = &HashPolicy_Cookie{x}
+		return true, err
+	case 3: // key.path
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Key = &HashPolicy_Path{x != 0}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _HashPolicy_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*HashPolicy)
+	// key
+	switch x := m.Key.(type) {
+	case *HashPolicy_Header:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Header)))
+		n += len(x.Header)
+	case *HashPolicy_Cookie:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Cookie)))
+		n += len(x.Cookie)
+	case *HashPolicy_Path:
+		n += proto.SizeVarint(3<<3 | proto.WireVarint)
+		n += 1
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// / StickySession makes kedge set a signed cookie with the target chosen for the first request of a client.
+// / Following requests with the cookie are sent to the same target as long as it is resolved and not blacklisted.
+// / Otherwise another target is picked and the cookie is rewritten.
+type StickySession struct {
+	// / cookie_name is the name of the cookie. Defaults to "kedge_session".
+	CookieName string `protobuf:"bytes,1,opt,name=cookie_name,json=cookieName" json:"cookie_name,omitempty"`
+	// / max_age_s is the max age of the cookie in seconds. If 0, the cookie lasts until the browser is closed.
+	MaxAgeS uint32 `protobuf:"varint,2,opt,name=max_age_s,json=maxAgeS" json:"max_age_s,omitempty"`
+}
+
+func (m *StickySession) Reset()                    { *m = StickySession{} }
+func (m *StickySession) String() string            { return proto.CompactTextString(m) }
+func (*StickySession) ProtoMessage()               {}
+func (*StickySession) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *StickySession) GetCookieName() string {
+	if m != nil {
+		return m.CookieName
+	}
+	return ""
+}
+
+func (m *StickySession) GetMaxAgeS() uint32 {
+	if m != nil {
+		return m.MaxAgeS
+	}
+	return 0
+}
+
+// / Middleware is a piece of logic wrapped around every call made to the backend.
 type Middleware struct {
 	// Types that are valid to be assigned to Middleware:
 	//	*Middleware_Retry_
+	//	*Middleware_CircuitBreaker_
 	Middleware isMiddleware_Middleware `protobuf_oneof:"Middleware"`
 }
 
 func (m *Middleware) Reset()                    { *m = Middleware{} }
 func (m *Middleware) String() string            { return proto.CompactTextString(m) }
 func (*Middleware) ProtoMessage()               {}
-func (*Middleware) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*Middleware) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 type isMiddleware_Middleware interface {
 	isMiddleware_Middleware()
@@ -267,8 +503,12 @@ type isMiddleware_Middleware interface {
 type Middleware_Retry_ struct {
 	Retry *Middleware_Retry `protobuf:"bytes,1,opt,name=retry,oneof"`
 }
+type Middleware_CircuitBreaker_ struct {
+	CircuitBreaker *Middleware_CircuitBreaker `protobuf:"bytes,2,opt,name=circuit_breaker,json=circuitBreaker,oneof"`
+}
 
-func (*Middleware_Retry_) isMiddleware_Middleware() {}
+func (*Middleware_Retry_) isMiddleware_Middleware()          {}
+func (*Middleware_CircuitBreaker_) isMiddleware_Middleware() {}
 
 func (m *Middleware) GetMiddleware() isMiddleware_Middleware {
 	if m != nil {
@@ -284,10 +524,18 @@ func (m *Middleware) GetRetry() *Middleware_Retry {
 	return nil
 }
 
+func (m *Middleware) GetCircuitBreaker() *Middleware_CircuitBreaker {
+	if x, ok := m.GetMiddleware().(*Middleware_CircuitBreaker_); ok {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Middleware) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Middleware_OneofMarshaler, _Middleware_OneofUnmarshaler, _Middleware_OneofSizer, []interface{}{
 		(*Middleware_Retry_)(nil),
+		(*Middleware_CircuitBreaker_)(nil),
 	}
 }
 
@@ -300,6 +548,11 @@ func _Middleware_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Retry); err != nil {
 			return err
 		}
+	case *Middleware_CircuitBreaker_:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.CircuitBreaker); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Middleware.Middleware has unexpected type %T", x)
@@ -318,6 +571,14 @@ func _Middleware_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buf
 		err := b.DecodeMessage(msg)
 		m.Middleware = &Middleware_Retry_{msg}
 		return true, err
+	case 2: // Middleware.circuit_breaker
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Middleware_CircuitBreaker)
+		err := b.DecodeMessage(msg)
+		m.Middleware = &Middleware_CircuitBreaker_{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -332,6 +593,11 @@ func _Middleware_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(1<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Middleware_CircuitBreaker_:
+		s := proto.Size(x.CircuitBreaker)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -339,17 +605,27 @@ func _Middleware_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// / Retry retries failed requests on a different target of the same backend.
+// / Only idempotent requests (GET, HEAD, OPTIONS, TRACE, PUT, DELETE) are retried unless retry_non_idempotent is set.
+// / EOFs from the backend are retried like on_codes, so only for requests that are allowed to be retried and whose
+// / body fits in body_buffer_limit_bytes. Dial errors are not handled here: the load balancer tries other targets on
+// / them for every request, as nothing was sent yet.
 type Middleware_Retry struct {
 	// / retry_count specifies how many times to retry.
 	RetryCount uint32 `protobuf:"varint,1,opt,name=retry_count,json=retryCount" json:"retry_count,omitempty"`
 	// / on_codes specifies the list of codes to retry on.
 	OnCodes []uint32 `protobuf:"varint,2,rep,packed,name=on_codes,json=onCodes" json:"on_codes,omitempty"`
+	// / body_buffer_limit_bytes specifies the maximum size of the request body that is buffered to be replayed on retry.
+	// / Requests with bigger bodies are not retried. If 0, only requests without body are retried.
+	BodyBufferLimitBytes uint32 `protobuf:"varint,3,opt,name=body_buffer_limit_bytes,json=bodyBufferLimitBytes" json:"body_buffer_limit_bytes,omitempty"`
+	// / retry_non_idempotent allows retrying non-idempotent requests (e.g POST or PATCH) as well.
+	RetryNonIdempotent bool `protobuf:"varint,4,opt,name=retry_non_idempotent,json=retryNonIdempotent" json:"retry_non_idempotent,omitempty"`
 }
 
 func (m *Middleware_Retry) Reset()                    { *m = Middleware_Retry{} }
 func (m *Middleware_Retry) String() string            { return proto.CompactTextString(m) }
 func (*Middleware_Retry) ProtoMessage()               {}
-func (*Middleware_Retry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1, 0} }
+func (*Middleware_Retry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 0} }
 
 func (m *Middleware_Retry) GetRetryCount() uint32 {
 	if m != nil {
@@ -365,19 +641,109 @@ func (m *Middleware_Retry) GetOnCodes() []uint32 {
 	return nil
 }
 
+func (m *Middleware_Retry) GetBodyBufferLimitBytes() uint32 {
+	if m != nil {
+		return m.BodyBufferLimitBytes
+	}
+	return 0
+}
+
+func (m *Middleware_Retry) GetRetryNonIdempotent() bool {
+	if m != nil {
+		return m.RetryNonIdempotent
+	}
+	return false
+}
+
+// / CircuitBreaker stops sending requests to failing targets of the backend and fails fast if the whole backend is failing.
+// / Breakers are kept for every target and for the backend as a whole. A breaker trips (opens) when any of the
+// / configured conditions is met. After open_duration_ms it lets half_open_requests probe requests through and closes
+// / again if they succeed. 5xx responses and transport errors are considered failures.
+type Middleware_CircuitBreaker struct {
+	// / consecutive_failures trips the breaker after the given number of consecutive failures. 0 disables this condition.
+	ConsecutiveFailures uint32 `protobuf:"varint,1,opt,name=consecutive_failures,json=consecutiveFailures" json:"consecutive_failures,omitempty"`
+	// / failure_ratio trips the breaker when the ratio of failures in the window is above it. 0 disables this condition.
+	FailureRatio float64 `protobuf:"fixed64,2,opt,name=failure_ratio,json=failureRatio" json:"failure_ratio,omitempty"`
+	// / timeout_ratio trips the breaker when the ratio of timeouts in the window is above it. 0 disables this condition.
+	TimeoutRatio float64 `protobuf:"fixed64,3,opt,name=timeout_ratio,json=timeoutRatio" json:"timeout_ratio,omitempty"`
+	// / min_requests is the minimum number of requests in the window for the ratios to be checked. Defaults to 10.
+	MinRequests uint32 `protobuf:"varint,4,opt,name=min_requests,json=minRequests" json:"min_requests,omitempty"`
+	// / window_ms is the length of the window the ratios are computed in. Defaults to 10s.
+	WindowMs uint32 `protobuf:"varint,5,opt,name=window_ms,json=windowMs" json:"window_ms,omitempty"`
+	// / open_duration_ms is the time the breaker stays open before letting probe requests through. Defaults to 5s.
+	OpenDurationMs uint32 `protobuf:"varint,6,opt,name=open_duration_ms,json=openDurationMs" json:"open_duration_ms,omitempty"`
+	// / half_open_requests is the maximum number of concurrent probe requests in half-open state. Defaults to 1.
+	HalfOpenRequests uint32 `protobuf:"varint,7,opt,name=half_open_requests,json=halfOpenRequests" json:"half_open_requests,omitempty"`
+}
+
+func (m *Middleware_CircuitBreaker) Reset()                    { *m = Middleware_CircuitBreaker{} }
+func (m *Middleware_CircuitBreaker) String() string            { return proto.CompactTextString(m) }
+func (*Middleware_CircuitBreaker) ProtoMessage()               {}
+func (*Middleware_CircuitBreaker) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 1} }
+
+func (m *Middleware_CircuitBreaker) GetConsecutiveFailures() uint32 {
+	if m != nil {
+		return m.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetFailureRatio() float64 {
+	if m != nil {
+		return m.FailureRatio
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetTimeoutRatio() float64 {
+	if m != nil {
+		return m.TimeoutRatio
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetMinRequests() uint32 {
+	if m != nil {
+		return m.MinRequests
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetWindowMs() uint32 {
+	if m != nil {
+		return m.WindowMs
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetOpenDurationMs() uint32 {
+	if m != nil {
+		return m.OpenDurationMs
+	}
+	return 0
+}
+
+func (m *Middleware_CircuitBreaker) GetHalfOpenRequests() uint32 {
+	if m != nil {
+		return m.HalfOpenRequests
+	}
+	return 0
+}
+
 // / Security settings for a backend.
 type Security struct {
 	// / insecure_skip_verify skips the server certificate verification completely.
-	// / No TLS config (for testclient or server) will be used. This should *not* be used in production software.
+	// / Client certificate from config_name is still presented if specified. This should *not* be used in production software.
 	InsecureSkipVerify bool `protobuf:"varint,1,opt,name=insecure_skip_verify,json=insecureSkipVerify" json:"insecure_skip_verify,omitempty"`
-	// / config_name indicates the TlsServerConfig to be used for this connection.
+	// / config_name indicates the TlsServerConfig (declared in BackendPoolConfig.tls_server_configs) to be used for this connection.
+	// / If empty, the backend's server certificate is verified against root CAs fetched from host.
 	ConfigName string `protobuf:"bytes,2,opt,name=config_name,json=configName" json:"config_name,omitempty"`
 }
 
 func (m *Security) Reset()                    { *m = Security{} }
 func (m *Security) String() string            { return proto.CompactTextString(m) }
 func (*Security) ProtoMessage()               {}
-func (*Security) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*Security) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *Security) GetInsecureSkipVerify() bool {
 	if m != nil {
@@ -395,8 +761,11 @@ func (m *Security) GetConfigName() string {
 
 func init() {
 	proto.RegisterType((*Backend)(nil), "kedge.config.http.backends.Backend")
+	proto.RegisterType((*HashPolicy)(nil), "kedge.config.http.backends.HashPolicy")
+	proto.RegisterType((*StickySession)(nil), "kedge.config.http.backends.StickySession")
 	proto.RegisterType((*Middleware)(nil), "kedge.config.http.backends.Middleware")
 	proto.RegisterType((*Middleware_Retry)(nil), "kedge.config.http.backends.Middleware.Retry")
+	proto.RegisterType((*Middleware_CircuitBreaker)(nil), "kedge.config.http.backends.Middleware.CircuitBreaker")
 	proto.RegisterType((*Security)(nil), "kedge.config.http.backends.Security")
 	proto.RegisterEnum("kedge.config.http.backends.Balancer", Balancer_name, Balancer_value)
 }
@@ -404,39 +773,72 @@ func init() {
 func init() { proto.RegisterFile("kedge/config/http/backends/backend.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 529 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x93, 0x4f, 0x6f, 0xd3, 0x30,
-	0x18, 0xc6, 0x97, 0x75, 0x7f, 0xb2, 0xb7, 0x1b, 0x20, 0xb3, 0x43, 0x28, 0x87, 0x55, 0x55, 0x0f,
-	0xd1, 0x68, 0x93, 0x51, 0xd0, 0x54, 0x2e, 0x08, 0xd2, 0x1d, 0x8a, 0x10, 0x9d, 0xe4, 0x0a, 0x2e,
-	0x68, 0x44, 0x6e, 0xe2, 0xa5, 0x56, 0x1a, 0xbb, 0xb2, 0xdd, 0x56, 0x03, 0xf1, 0x71, 0xf8, 0x5c,
-	0x48, 0xf0, 0x45, 0x50, 0x9c, 0xa6, 0x7f, 0x0e, 0x0c, 0x6e, 0xce, 0xf3, 0x3e, 0xbf, 0xe7, 0xb5,
-	0x5f, 0x3b, 0xe0, 0xa6, 0x34, 0x4e, 0xa8, 0x1f, 0x09, 0x7e, 0xcb, 0x12, 0x7f, 0xac, 0xf5, 0xd4,
-	0x1f, 0x91, 0x28, 0xa5, 0x3c, 0x56, 0xe5, 0xc2, 0x9b, 0x4a, 0xa1, 0x05, 0xaa, 0x19, 0xa7, 0x57,
-	0x38, 0xbd, 0xdc, 0xe9, 0x95, 0xce, 0xda, 0x65, 0xc2, 0xf4, 0x78, 0x36, 0xf2, 0x22, 0x91, 0xf9,
-	0xd9, 0x82, 0xe9, 0x54, 0x2c, 0xfc, 0x44, 0xb4, 0x0d, 0xd8, 0x9e, 0x93, 0x09, 0x8b, 0x89, 0x16,
-	0x52, 0xf9, 0xab, 0x65, 0x91, 0x59, 0x6b, 0x6f, 0x75, 0x8f, 0x44, 0x96, 0x09, 0xee, 0x4b, 0xaa,
-	0xc4, 0x64, 0x4e, 0xa5, 0x5a, 0xaf, 0x0a, 0x7b, 0xe3, 0x77, 0x05, 0x0e, 0x83, 0xa2, 0x27, 0x6a,
-	0xc1, 0x1e, 0x27, 0x19, 0x75, 0xac, 0xba, 0xe5, 0x1e, 0x05, 0xce, 0xaf, 0x9f, 0x67, 0xa7, 0x80,
-	0xbe, 0x7c, 0x26, 0xed, 0xaf, 0xe1, 0x45, 0xfb, 0x95, 0x77, 0xf3, 0xad, 0xd3, 0xba, 0x7c, 0xf9,
-	0xbd, 0x89, 0x8d, 0x0b, 0xbd, 0x01, 0x7b, 0x44, 0x26, 0x84, 0x47, 0x54, 0x3a, 0xbb, 0x75, 0xcb,
-	0x7d, 0xd0, 0x69, 0x7a, 0x7f, 0x3f, 0x8f, 0x17, 0x2c, 0xbd, 0x78, 0x45, 0xa1, 0xe7, 0x70, 0x1a,
-	0x33, 0x45, 0x46, 0x13, 0x1a, 0x46, 0x82, 0x73, 0x2d, 0x49, 0x94, 0x32, 0x9e, 0x38, 0x95, 0xba,
-	0xe5, 0xda, 0xf8, 0xf1, 0xb2, 0xd6, 0xdb, 0x28, 0xe5, 0x4d, 0x15, 0x8d, 0x66, 0x92, 0xe9, 0x3b,
-	0x67, 0xaf, 0x6e, 0xb9, 0xd5, 0xfb, 0x9b, 0x0e, 0x97, 0x5e, 0xbc, 0xa2, 0xd0, 0x6b, 0xa8, 0x28,
-	0x39, 0x77, 0xc0, 0xc0, 0xe7, 0xdb, 0x70, 0x31, 0x2d, 0x6f, 0x3d, 0xa3, 0xa1, 0x9c, 0xe3, 0xe5,
-	0x47, 0x7f, 0x07, 0xe7, 0x60, 0xce, 0xa7, 0x5d, 0xe5, 0x54, 0xff, 0x8b, 0x7f, 0xdf, 0x55, 0x9b,
-	0x7c, 0xda, 0x55, 0xe8, 0x2d, 0xec, 0x8d, 0x85, 0xd2, 0xce, 0xb1, 0x09, 0x78, 0xf6, 0x8f, 0x80,
-	0xbe, 0x50, 0x7a, 0x23, 0xc1, 0xa0, 0xa8, 0x09, 0x27, 0x64, 0xa6, 0x45, 0x42, 0x39, 0x95, 0x44,
-	0xd3, 0xd8, 0x39, 0x30, 0x03, 0xdb, 0x16, 0x03, 0x00, 0xbb, 0xcc, 0x69, 0xfc, 0xb0, 0x00, 0x3e,
-	0xb0, 0x38, 0x9e, 0xd0, 0x05, 0x91, 0x14, 0x5d, 0xc1, 0xbe, 0xa4, 0x5a, 0xde, 0x99, 0x9b, 0xae,
-	0x76, 0x5a, 0xf7, 0x8d, 0x70, 0x8d, 0x79, 0x38, 0x67, 0xfa, 0x3b, 0xb8, 0x80, 0x6b, 0x3d, 0xd8,
-	0x37, 0x0a, 0x3a, 0x83, 0xaa, 0x51, 0xc2, 0x48, 0xcc, 0xb8, 0x36, 0xa1, 0x27, 0x18, 0x8c, 0xd4,
-	0xcb, 0x15, 0xf4, 0x04, 0x6c, 0xc1, 0xc3, 0x48, 0xc4, 0x54, 0x39, 0xbb, 0xf5, 0x8a, 0x7b, 0x82,
-	0x0f, 0x05, 0xef, 0xe5, 0x9f, 0xc1, 0xf1, 0xe6, 0xc6, 0x1a, 0x37, 0x60, 0x97, 0x57, 0x86, 0x2e,
-	0xe0, 0x94, 0x71, 0x73, 0x6d, 0x34, 0x54, 0x29, 0x9b, 0x86, 0x73, 0x2a, 0xd9, 0x6d, 0xb1, 0x67,
-	0x1b, 0xa3, 0xb2, 0x36, 0x4c, 0xd9, 0xf4, 0x93, 0xa9, 0xe4, 0xfb, 0x28, 0x8e, 0x10, 0x9a, 0x67,
-	0x9c, 0x3f, 0xca, 0x23, 0x0c, 0x85, 0x34, 0x20, 0x19, 0x3d, 0x7f, 0x0a, 0x76, 0xf9, 0x0c, 0xd1,
-	0x43, 0xa8, 0xe2, 0xeb, 0x8f, 0x83, 0xab, 0x10, 0x5f, 0x07, 0xef, 0x06, 0x8f, 0x76, 0x46, 0x07,
-	0xe6, 0x87, 0x78, 0xf1, 0x27, 0x00, 0x00, 0xff, 0xff, 0x71, 0x49, 0x93, 0x3c, 0xbf, 0x03, 0x00,
-	0x00,
+	// 1058 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xef, 0x6e, 0x1b, 0x45,
+	0x10, 0xb7, 0xe3, 0xd8, 0x71, 0xc6, 0x71, 0x6a, 0xb6, 0x16, 0x1c, 0xe6, 0x43, 0x4d, 0x54, 0x90,
+	0x29, 0x89, 0x5d, 0x02, 0xad, 0x8a, 0x90, 0x80, 0xd8, 0x71, 0x73, 0x16, 0x49, 0xdc, 0xae, 0xd3,
+	0xe6, 0x03, 0x0a, 0xcb, 0xfa, 0x6e, 0xed, 0x5b, 0x9d, 0xef, 0xd6, 0xdc, 0xae, 0x9d, 0x1a, 0xd4,
+	0x47, 0xe0, 0x4d, 0x78, 0x11, 0x1e, 0x80, 0xcf, 0x95, 0xfa, 0x04, 0x3c, 0x02, 0xda, 0xbd, 0xf3,
+	0x3f, 0xa9, 0xa4, 0xc9, 0x97, 0xec, 0xce, 0xef, 0xf7, 0x9b, 0xd9, 0x19, 0xcf, 0xcc, 0x41, 0xcd,
+	0x67, 0xee, 0x90, 0x35, 0x1c, 0x11, 0x0e, 0xf8, 0xb0, 0xe1, 0x29, 0x35, 0x6e, 0xf4, 0xa9, 0xe3,
+	0xb3, 0xd0, 0x95, 0xf3, 0x43, 0x7d, 0x1c, 0x09, 0x25, 0x50, 0xc5, 0x30, 0xeb, 0x31, 0xb3, 0xae,
+	0x99, 0xf5, 0x39, 0xb3, 0xf2, 0x78, 0xc8, 0x95, 0x37, 0xe9, 0xd7, 0x1d, 0x11, 0x34, 0x82, 0x6b,
+	0xae, 0x7c, 0x71, 0xdd, 0x18, 0x8a, 0x03, 0x23, 0x3c, 0x98, 0xd2, 0x11, 0x77, 0xa9, 0x12, 0x91,
+	0x6c, 0x2c, 0x8e, 0xb1, 0xcf, 0xca, 0xc1, 0x5a, 0x74, 0x47, 0x04, 0x81, 0x08, 0x1b, 0x11, 0x93,
+	0x62, 0x34, 0x65, 0x91, 0x5c, 0x9e, 0x12, 0xfa, 0x67, 0xef, 0xa2, 0x7b, 0x8c, 0x8e, 0x94, 0xe7,
+	0x78, 0xcc, 0xf1, 0x63, 0xda, 0xde, 0x9f, 0x39, 0xd8, 0x6a, 0xc6, 0x4f, 0x43, 0xfb, 0xb0, 0x19,
+	0xd2, 0x80, 0x59, 0xe9, 0x6a, 0xba, 0xb6, 0xdd, 0xb4, 0xde, 0xbe, 0xb9, 0x57, 0x06, 0xf4, 0xcb,
+	0xcf, 0xf4, 0xe0, 0x77, 0xf2, 0xf0, 0xe0, 0xdb, 0xfa, 0xd5, 0x1f, 0x87, 0xfb, 0x8f, 0xbf, 0x79,
+	0x7d, 0x1f, 0x1b, 0x16, 0xfa, 0x11, 0xf2, 0x7d, 0x3a, 0xa2, 0xa1, 0xc3, 0x22, 0x6b, 0xa3, 0x9a,
+	0xae, 0xed, 0x1e, 0xde, 0xaf, 0xff, 0x7f, 0xda, 0xf5, 0x66, 0xc2, 0xc5, 0x0b, 0x15, 0xfa, 0x0a,
+	0xca, 0x2e, 0x97, 0xb4, 0x3f, 0x62, 0xc4, 0x11, 0x61, 0xa8, 0x22, 0xea, 0xf8, 0x3c, 0x1c, 0x5a,
+	0x99, 0x6a, 0xba, 0x96, 0xc7, 0x77, 0x13, 0xac, 0xb5, 0x02, 0xe9, 0xa0, 0x92, 0x39, 0x93, 0x88,
+	0xab, 0x99, 0xb5, 0x59, 0x4d, 0xd7, 0x0a, 0x37, 0x07, 0xed, 0x25, 0x5c, 0xbc, 0x50, 0x21, 0x1b,
+	0x0a, 0x01, 0x77, 0xdd, 0x11, 0xbb, 0xa6, 0x11, 0x93, 0x56, 0xb6, 0x9a, 0xa9, 0x15, 0x0e, 0x3f,
+	0xbf, 0xc9, 0xc9, 0xd9, 0x82, 0x8e, 0x57, 0xa5, 0xa8, 0x05, 0x3b, 0x71, 0x3d, 0x89, 0x29, 0xa8,
+	0xb5, 0x65, 0xde, 0x53, 0x5d, 0x77, 0x15, 0x17, 0xbe, 0x6e, 0x1b, 0x62, 0x4b, 0xf3, 0x70, 0xc1,
+	0x5b, 0x5e, 0xd0, 0x09, 0x14, 0x3c, 0x2a, 0x3d, 0x32, 0x16, 0x23, 0xee, 0xcc, 0xac, 0x7c, 0x35,
+	0xfd, 0xbe, 0xe7, 0xd8, 0x54, 0x7a, 0xcf, 0x0c, 0x1b, 0x83, 0xb7, 0x38, 0xa3, 0x67, 0xb0, 0x2b,
+	0x15, 0x77, 0xfc, 0x19, 0x91, 0x4c, 0x4a, 0x2e, 0x42, 0x6b, 0xdb, 0xf8, 0xfa, 0xe2, 0xc6, 0xfa,
+	0x18, 0x45, 0x2f, 0x16, 0xe0, 0xa2, 0x5c, 0xbd, 0xa2, 0xef, 0x21, 0x23, 0xa3, 0xa9, 0x05, 0xc6,
+	0xcd, 0x83, 0x77, 0xa6, 0xb5, 0x6c, 0xba, 0x5e, 0x34, 0xc5, 0xc9, 0xc5, 0x4e, 0x61, 0x2d, 0xd4,
+	0x7a, 0xff, 0x89, 0xb4, 0x0a, 0xb7, 0xd2, 0xff, 0xf4, 0x44, 0xae, 0xea, 0xfd, 0x27, 0x12, 0x1d,
+	0xc1, 0xa6, 0x27, 0xa4, 0xb2, 0x76, 0x8c, 0x83, 0x2f, 0xdf, 0xe3, 0xc0, 0x16, 0x52, 0xad, 0x78,
+	0x30, 0x52, 0x74, 0x1f, 0x8a, 0x74, 0xa2, 0xc4, 0x90, 0x85, 0x2c, 0xa2, 0x8a, 0xb9, 0x56, 0xce,
+	0xb4, 0xd6, 0xba, 0xb1, 0x09, 0x90, 0x9f, 0xfb, 0xd9, 0xbb, 0x02, 0x58, 0x16, 0x18, 0x59, 0x90,
+	0xf3, 0x18, 0x75, 0x59, 0x14, 0xcf, 0x84, 0x9d, 0xc2, 0xc9, 0x5d, 0x23, 0x8e, 0x10, 0x3e, 0x67,
+	0xd6, 0xc6, 0x1c, 0x89, 0xef, 0xa8, 0x0c, 0x9b, 0x63, 0xaa, 0xbc, 0xb8, 0x8b, 0xf5, 0x4b, 0xf4,
+	0xad, 0x99, 0x85, 0x8c, 0xcf, 0x66, 0x7b, 0xa7, 0x50, 0x5c, 0xab, 0x39, 0xba, 0x07, 0x85, 0x58,
+	0x47, 0x96, 0xa3, 0x87, 0x21, 0x36, 0x9d, 0xeb, 0x31, 0xab, 0xc0, 0x76, 0x40, 0x5f, 0x11, 0x3a,
+	0x64, 0x44, 0x9a, 0x58, 0x45, 0xbc, 0x15, 0xd0, 0x57, 0x47, 0x43, 0xd6, 0xdb, 0xfb, 0x3b, 0x0b,
+	0xb0, 0xec, 0x4e, 0x74, 0x0c, 0xd9, 0x88, 0xa9, 0x68, 0x66, 0xbc, 0x14, 0x0e, 0xf7, 0x6f, 0xd7,
+	0xd4, 0x75, 0xac, 0x35, 0x76, 0x0a, 0xc7, 0x62, 0xf4, 0x2b, 0xdc, 0x71, 0x78, 0xe4, 0x4c, 0xb8,
+	0x22, 0xfd, 0x88, 0x51, 0x3f, 0x19, 0xef, 0xc2, 0xe1, 0xa3, 0x5b, 0xfa, 0x6b, 0xc5, 0xea, 0x66,
+	0x2c, 0xb6, 0x53, 0x78, 0xd7, 0x59, 0xb3, 0x54, 0xfe, 0x4a, 0x43, 0xd6, 0x04, 0xd5, 0xd9, 0x9b,
+	0xa0, 0xc4, 0x11, 0x93, 0x50, 0x99, 0x77, 0x17, 0x31, 0x18, 0x53, 0x4b, 0x5b, 0xd0, 0xc7, 0x90,
+	0x17, 0x21, 0x71, 0x84, 0xcb, 0x74, 0xf2, 0x19, 0x9d, 0xbc, 0x08, 0x5b, 0xfa, 0x8a, 0x1e, 0xc1,
+	0x47, 0x7d, 0xe1, 0xce, 0x48, 0x7f, 0x32, 0x18, 0xb0, 0x88, 0x8c, 0x78, 0xa0, 0x5f, 0x3c, 0x53,
+	0x4c, 0x9a, 0xd2, 0x17, 0x71, 0x59, 0xc3, 0x4d, 0x83, 0x9e, 0x6a, 0xb0, 0xa9, 0x31, 0xf4, 0x10,
+	0xca, 0x71, 0xc8, 0x50, 0x84, 0x84, 0xbb, 0x2c, 0x18, 0x0b, 0xc5, 0x42, 0x65, 0xb6, 0x49, 0x1e,
+	0x23, 0x83, 0x9d, 0x8b, 0xb0, 0xb3, 0x40, 0x2a, 0xff, 0x6c, 0xc0, 0xee, 0x7a, 0x4e, 0x7a, 0x73,
+	0x39, 0x22, 0xd4, 0x3b, 0x45, 0xf1, 0x29, 0x23, 0x03, 0xca, 0x47, 0x13, 0xbd, 0x4d, 0xe2, 0x04,
+	0xee, 0xae, 0x60, 0x4f, 0x13, 0x08, 0x7d, 0x07, 0xc5, 0x84, 0x46, 0x22, 0xaa, 0xb8, 0x30, 0x45,
+	0x4d, 0x37, 0x3f, 0x7c, 0xfb, 0xe6, 0x1e, 0xea, 0xa4, 0x92, 0xbf, 0xe7, 0xf1, 0xbf, 0x7f, 0x7f,
+	0xc0, 0x3b, 0x09, 0x19, 0x6b, 0xae, 0x16, 0x2b, 0x1e, 0x30, 0x31, 0x51, 0x89, 0x38, 0x73, 0xb3,
+	0x38, 0x21, 0xc7, 0xe2, 0x4f, 0x61, 0x27, 0xe0, 0x21, 0x89, 0xd8, 0x6f, 0x13, 0x26, 0x95, 0x34,
+	0x99, 0x16, 0xf5, 0x2a, 0x0b, 0x71, 0x62, 0x42, 0x9f, 0xc0, 0xf6, 0x35, 0x0f, 0x5d, 0x71, 0x4d,
+	0x02, 0xbd, 0x12, 0x35, 0x9e, 0x8f, 0x0d, 0x67, 0x12, 0xd5, 0xa0, 0x24, 0xc6, 0x2c, 0x24, 0xee,
+	0xc4, 0xc4, 0x0e, 0x35, 0x27, 0x67, 0x38, 0xbb, 0xda, 0x7e, 0x9c, 0x98, 0xcf, 0x24, 0xda, 0x07,
+	0xe4, 0xd1, 0xd1, 0x80, 0x18, 0xfa, 0x22, 0xde, 0x96, 0xe1, 0x96, 0x34, 0xd2, 0x1d, 0xb3, 0x45,
+	0xd0, 0xe6, 0xce, 0x6a, 0xf3, 0xee, 0x5d, 0x41, 0x7e, 0xbe, 0xad, 0xf5, 0x6f, 0xc4, 0x4d, 0x05,
+	0x23, 0x46, 0xa4, 0xcf, 0xc7, 0x64, 0xca, 0x22, 0x3e, 0x88, 0xfb, 0x3a, 0x8f, 0xd1, 0x1c, 0xeb,
+	0xf9, 0x7c, 0xfc, 0xd2, 0x20, 0xf1, 0x18, 0xe9, 0xb6, 0x8c, 0xc7, 0x68, 0x63, 0x3e, 0x46, 0xda,
+	0xa4, 0xc7, 0xe8, 0xc1, 0x6b, 0xc8, 0xcf, 0xbf, 0x40, 0xe8, 0x0e, 0x14, 0x70, 0xf7, 0xc5, 0xf9,
+	0x31, 0xc1, 0xdd, 0x66, 0xe7, 0xbc, 0x94, 0x42, 0x1f, 0x40, 0xf1, 0xb4, 0x7d, 0xd4, 0xbb, 0x20,
+	0xb8, 0xfd, 0xfc, 0x45, 0xbb, 0x77, 0x51, 0x4a, 0x23, 0x0b, 0xca, 0xcf, 0xba, 0x97, 0x6d, 0x4c,
+	0xba, 0x4f, 0xc9, 0xc5, 0x65, 0x97, 0xb4, 0xec, 0x6e, 0xa7, 0xd5, 0xee, 0x95, 0x36, 0x50, 0x11,
+	0xb6, 0x71, 0xe7, 0xfc, 0x84, 0xd8, 0x47, 0x3d, 0xbb, 0x94, 0x41, 0x00, 0xb9, 0xb3, 0xa3, 0x93,
+	0xd3, 0xf6, 0xcb, 0xd2, 0xa6, 0x16, 0x5d, 0xb6, 0x3b, 0x27, 0xf6, 0x45, 0xfb, 0x98, 0xac, 0x46,
+	0xc8, 0xf6, 0x73, 0xe6, 0x6b, 0xfb, 0xf5, 0x7f, 0x03, 0x00, 0xa6, 0x0a, 0xe0, 0x1c, 0x43, 0x08,
+	0x00, 0x00,
 }
@@ -9,6 +9,8 @@ It is generated from these files:
 
 It has these top-level messages:
 	Backend
+	HashPolicy
+	StickySession
 	Middleware
 	Security
 */
@@ -21,6 +23,7 @@ import proto "github.com/golang/protobuf/proto"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
 import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -38,6 +41,28 @@ func (this *Backend) Validate() error {
 			return go_proto_validators.FieldError("Security", err)
 		}
 	}
+	for _, item := range this.Middlewares {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("Middlewares", err)
+			}
+		}
+	}
+	if this.HealthCheck != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.HealthCheck); err != nil {
+			return go_proto_validators.FieldError("HealthCheck", err)
+		}
+	}
+	if this.HashPolicy != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.HashPolicy); err != nil {
+			return go_proto_validators.FieldError("HashPolicy", err)
+		}
+	}
+	if this.StickySession != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.StickySession); err != nil {
+			return go_proto_validators.FieldError("StickySession", err)
+		}
+	}
 	if oneOfNester, ok := this.GetResolver().(*Backend_Srv); ok {
 		if oneOfNester.Srv != nil {
 			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Srv); err != nil {
@@ -61,6 +86,12 @@ func (this *Backend) Validate() error {
 	}
 	return nil
 }
+func (this *HashPolicy) Validate() error {
+	return nil
+}
+func (this *StickySession) Validate() error {
+	return nil
+}
 func (this *Middleware) Validate() error {
 	if oneOfNester, ok := this.GetMiddleware().(*Middleware_Retry_); ok {
 		if oneOfNester.Retry != nil {
@@ -69,11 +100,33 @@ func (this *Middleware) Validate() error {
 			}
 		}
 	}
+	if oneOfNester, ok := this.GetMiddleware().(*Middleware_CircuitBreaker_); ok {
+		if oneOfNester.CircuitBreaker != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.CircuitBreaker); err != nil {
+				return go_proto_validators.FieldError("CircuitBreaker", err)
+			}
+		}
+	}
 	return nil
 }
 func (this *Middleware_Retry) Validate() error {
 	return nil
 }
+func (this *Middleware_CircuitBreaker) Validate() error {
+	if !(this.FailureRatio >= 0) {
+		return go_proto_validators.FieldError("FailureRatio", fmt.Errorf(`value '%v' must be greater than or equal to '0'`, this.FailureRatio))
+	}
+	if !(this.FailureRatio <= 1) {
+		return go_proto_validators.FieldError("FailureRatio", fmt.Errorf(`value '%v' must be lower than or equal to '1'`, this.FailureRatio))
+	}
+	if !(this.TimeoutRatio >= 0) {
+		return go_proto_validators.FieldError("TimeoutRatio", fmt.Errorf(`value '%v' must be greater than or equal to '0'`, this.TimeoutRatio))
+	}
+	if !(this.TimeoutRatio <= 1) {
+		return go_proto_validators.FieldError("TimeoutRatio", fmt.Errorf(`value '%v' must be lower than or equal to '1'`, this.TimeoutRatio))
+	}
+	return nil
+}
 func (this *Security) Validate() error {
 	return nil
 }
@@ -9,6 +9,14 @@ It is generated from these files:
 
 It has these top-level messages:
 	Route
+	Upgrade
+	HeaderActions
+	PathRewrite
+	PrefixRewrite
+	RegexRewrite
+	Mirror
+	WeightedBackend
+	StickySplit
 */
 package kedge_config_http_routes
 
@@ -16,6 +24,8 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import kedge_config_common1 "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -63,6 +73,7 @@ func (ProxyMode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []in
 // / Route describes a mapping between a stable proxying endpoint and a pre-defined backend.
 type Route struct {
 	// / backend_name is the string identifying the HTTP backend pool to send data to.
+	// / Either backend_name or weighted_backends needs to be specified.
 	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
 	// / path_rules is a globbing expression that matches a URL path of the request.
 	// / See: https://cloud.google.com/compute/docs/load-balancing/http/url-map
@@ -83,6 +94,40 @@ type Route struct {
 	// TODO(bplotka): Type is not consistend with authority_host_matcher
 	PortMatcher   uint32 `protobuf:"varint,6,opt,name=port_matcher,json=portMatcher" json:"port_matcher,omitempty"`
 	Autogenerated bool   `protobuf:"varint,7,opt,name=autogenerated" json:"autogenerated,omitempty"`
+	// / weighted_backends splits the traffic of this route between multiple backends proportionally to their weights.
+	// / If present, backend_name is ignored.
+	WeightedBackends []*WeightedBackend `protobuf:"bytes,8,rep,name=weighted_backends,json=weightedBackends" json:"weighted_backends,omitempty"`
+	// / sticky_split makes the choice between weighted_backends sticky, so requests with the same header or cookie value
+	// / are always sent to the same backend (as long as weights do not change).
+	// / If not present (or the request does not have the header or cookie), the backend is chosen randomly.
+	StickySplit *StickySplit `protobuf:"bytes,9,opt,name=sticky_split,json=stickySplit" json:"sticky_split,omitempty"`
+	// / name is an optional name of the route used in metrics. If not present, the position of the route is used.
+	Name string `protobuf:"bytes,10,opt,name=name" json:"name,omitempty"`
+	// / mirror sends copies of requests matched by this route to another backend. See Mirror.
+	Mirror *Mirror `protobuf:"bytes,11,opt,name=mirror" json:"mirror,omitempty"`
+	// / path_rewrite rewrites the URL path of the request before it is sent to the backend. See PathRewrite.
+	PathRewrite *PathRewrite `protobuf:"bytes,12,opt,name=path_rewrite,json=pathRewrite" json:"path_rewrite,omitempty"`
+	// / host_rewrite, if set, replaces the Host header of the request sent to the backend.
+	HostRewrite string `protobuf:"bytes,13,opt,name=host_rewrite,json=hostRewrite" json:"host_rewrite,omitempty"`
+	// / request_headers modifies headers of the request sent to the backend.
+	RequestHeaders *HeaderActions `protobuf:"bytes,14,opt,name=request_headers,json=requestHeaders" json:"request_headers,omitempty"`
+	// / response_headers modifies headers of the response sent back to the client.
+	ResponseHeaders *HeaderActions `protobuf:"bytes,15,opt,name=response_headers,json=responseHeaders" json:"response_headers,omitempty"`
+	// / host_pattern matches the host of the request, e.g. {"wildcard": "*.svc.example.com"}.
+	// / It is checked in addition to host_matcher.
+	HostPattern *kedge_config_common.StringMatcher `protobuf:"bytes,16,opt,name=host_pattern,json=hostPattern" json:"host_pattern,omitempty"`
+	// / path_patterns match the URL path of the request. At least one of them needs to match.
+	// / They are checked in addition to path_rules.
+	PathPatterns []*kedge_config_common.StringMatcher `protobuf:"bytes,17,rep,name=path_patterns,json=pathPatterns" json:"path_patterns,omitempty"`
+	// / header_patterns match values of the HTTP inbound request headers. Each key provided must find a match.
+	// / They are checked in addition to header_matcher.
+	HeaderPatterns map[string]*kedge_config_common.StringMatcher `protobuf:"bytes,18,rep,name=header_patterns,json=headerPatterns" json:"header_patterns,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// / rate_limit limits the rate of requests matched by this route per client. Requests over the limit are rejected
+	// / with 429 Too Many Requests.
+	RateLimit *kedge_config_common1.RateLimit `protobuf:"bytes,19,opt,name=rate_limit,json=rateLimit" json:"rate_limit,omitempty"`
+	// / upgrade limits connections upgraded through this route (e.g. WebSocket or SPDY used by kubectl exec).
+	// / If not specified, limits from --http_upgrade_idle_timeout and --http_upgrade_max_duration flags are used.
+	Upgrade *Upgrade `protobuf:"bytes,20,opt,name=upgrade" json:"upgrade,omitempty"`
 }
 
 func (m *Route) Reset()                    { *m = Route{} }
@@ -139,40 +184,595 @@ func (m *Route) GetAutogenerated() bool {
 	return false
 }
 
+func (m *Route) GetWeightedBackends() []*WeightedBackend {
+	if m != nil {
+		return m.WeightedBackends
+	}
+	return nil
+}
+
+func (m *Route) GetStickySplit() *StickySplit {
+	if m != nil {
+		return m.StickySplit
+	}
+	return nil
+}
+
+func (m *Route) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Route) GetMirror() *Mirror {
+	if m != nil {
+		return m.Mirror
+	}
+	return nil
+}
+
+func (m *Route) GetPathRewrite() *PathRewrite {
+	if m != nil {
+		return m.PathRewrite
+	}
+	return nil
+}
+
+func (m *Route) GetHostRewrite() string {
+	if m != nil {
+		return m.HostRewrite
+	}
+	return ""
+}
+
+func (m *Route) GetRequestHeaders() *HeaderActions {
+	if m != nil {
+		return m.RequestHeaders
+	}
+	return nil
+}
+
+func (m *Route) GetResponseHeaders() *HeaderActions {
+	if m != nil {
+		return m.ResponseHeaders
+	}
+	return nil
+}
+
+func (m *Route) GetHostPattern() *kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.HostPattern
+	}
+	return nil
+}
+
+func (m *Route) GetPathPatterns() []*kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.PathPatterns
+	}
+	return nil
+}
+
+func (m *Route) GetHeaderPatterns() map[string]*kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.HeaderPatterns
+	}
+	return nil
+}
+
+func (m *Route) GetRateLimit() *kedge_config_common1.RateLimit {
+	if m != nil {
+		return m.RateLimit
+	}
+	return nil
+}
+
+func (m *Route) GetUpgrade() *Upgrade {
+	if m != nil {
+		return m.Upgrade
+	}
+	return nil
+}
+
+// / Upgrade configures limits of upgraded connections. Upgraded connections are not limited by the HTTP server
+// / write and read timeouts.
+type Upgrade struct {
+	// / idle_timeout_ms closes the connection if no data was sent in either direction for that time.
+	IdleTimeoutMs uint32 `protobuf:"varint,1,opt,name=idle_timeout_ms,json=idleTimeoutMs" json:"idle_timeout_ms,omitempty"`
+	// / max_duration_ms closes the connection after that time.
+	MaxDurationMs uint32 `protobuf:"varint,2,opt,name=max_duration_ms,json=maxDurationMs" json:"max_duration_ms,omitempty"`
+}
+
+func (m *Upgrade) Reset()                    { *m = Upgrade{} }
+func (m *Upgrade) String() string            { return proto.CompactTextString(m) }
+func (*Upgrade) ProtoMessage()               {}
+func (*Upgrade) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Upgrade) GetIdleTimeoutMs() uint32 {
+	if m != nil {
+		return m.IdleTimeoutMs
+	}
+	return 0
+}
+
+func (m *Upgrade) GetMaxDurationMs() uint32 {
+	if m != nil {
+		return m.MaxDurationMs
+	}
+	return 0
+}
+
+// / HeaderActions describes header modifications. Removals are applied first, then sets and then adds.
+// / Values are Go templates that can use the following request data:
+// /  - {{.OIDCSubject}} - subject of the OIDC token used for proxy auth (if any)
+// /  - {{.ClientCertCN}} - common name of the client certificate (if any)
+// /  - {{.RequestID}} - kedge request ID (if any)
+// /  - {{.OriginalHost}} - Host of the inbound request (before host_rewrite)
+type HeaderActions struct {
+	// / add appends values to the headers, keeping existing values.
+	Add map[string]string `protobuf:"bytes,1,rep,name=add" json:"add,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// / set sets values of the headers, replacing existing values.
+	Set map[string]string `protobuf:"bytes,2,rep,name=set" json:"set,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// / remove removes the headers.
+	Remove []string `protobuf:"bytes,3,rep,name=remove" json:"remove,omitempty"`
+}
+
+func (m *HeaderActions) Reset()                    { *m = HeaderActions{} }
+func (m *HeaderActions) String() string            { return proto.CompactTextString(m) }
+func (*HeaderActions) ProtoMessage()               {}
+func (*HeaderActions) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *HeaderActions) GetAdd() map[string]string {
+	if m != nil {
+		return m.Add
+	}
+	return nil
+}
+
+func (m *HeaderActions) GetSet() map[string]string {
+	if m != nil {
+		return m.Set
+	}
+	return nil
+}
+
+func (m *HeaderActions) GetRemove() []string {
+	if m != nil {
+		return m.Remove
+	}
+	return nil
+}
+
+// / PathRewrite allows backends to serve paths different from the ones exposed through kedge, e.g. to map
+// / '/teams/foo/api/*' to a backend serving '/api/*'.
+type PathRewrite struct {
+	// Types that are valid to be assigned to Rewrite:
+	//	*PathRewrite_Prefix
+	//	*PathRewrite_Regex
+	Rewrite isPathRewrite_Rewrite `protobuf_oneof:"rewrite"`
+}
+
+func (m *PathRewrite) Reset()                    { *m = PathRewrite{} }
+func (m *PathRewrite) String() string            { return proto.CompactTextString(m) }
+func (*PathRewrite) ProtoMessage()               {}
+func (*PathRewrite) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type isPathRewrite_Rewrite interface {
+	isPathRewrite_Rewrite()
+}
+
+type PathRewrite_Prefix struct {
+	Prefix *PrefixRewrite `protobuf:"bytes,1,opt,name=prefix,oneof"`
+}
+type PathRewrite_Regex struct {
+	Regex *RegexRewrite `protobuf:"bytes,2,opt,name=regex,oneof"`
+}
+
+func (*PathRewrite_Prefix) isPathRewrite_Rewrite() {}
+func (*PathRewrite_Regex) isPathRewrite_Rewrite()  {}
+
+func (m *PathRewrite) GetRewrite() isPathRewrite_Rewrite {
+	if m != nil {
+		return m.Rewrite
+	}
+	return nil
+}
+
+func (m *PathRewrite) GetPrefix() *PrefixRewrite {
+	if x, ok := m.GetRewrite().(*PathRewrite_Prefix); ok {
+		return x.Prefix
+	}
+	return nil
+}
+
+func (m *PathRewrite) GetRegex() *RegexRewrite {
+	if x, ok := m.GetRewrite().(*PathRewrite_Regex); ok {
+		return x.Regex
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*PathRewrite) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _PathRewrite_OneofMarshaler, _PathRewrite_OneofUnmarshaler, _PathRewrite_OneofSizer, []interface{}{
+		(*PathRewrite_Prefix)(nil),
+		(*PathRewrite_Regex)(nil),
+	}
+}
+
+func _PathRewrite_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*PathRewrite)
+	// rewrite
+	switch x := m.Rewrite.(type) {
+	case *PathRewrite_Prefix:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Prefix); err != nil {
+			return err
+		}
+	case *PathRewrite_Regex:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Regex); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("PathRewrite.Rewrite has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _PathRewrite_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*PathRewrite)
+	switch tag {
+	case 1: // rewrite.prefix
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PrefixRewrite)
+		err := b.DecodeMessage(msg)
+		m.Rewrite = &PathRewrite_Prefix{msg}
+		return true, err
+	case 2: // rewrite.regex
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RegexRewrite)
+		err := b.DecodeMessage(msg)
+		m.Rewrite = &PathRewrite_Regex{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _PathRewrite_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*PathRewrite)
+	// rewrite
+	switch x := m.Rewrite.(type) {
+	case *PathRewrite_Prefix:
+		s := proto.Size(x.Prefix)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *PathRewrite_Regex:
+		s := proto.Size(x.Regex)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type PrefixRewrite struct {
+	// / from is the prefix to be replaced, e.g. '/teams/foo'.
+	From string `protobuf:"bytes,1,opt,name=from" json:"from,omitempty"`
+	// / to is the replacement of the prefix. If empty, the prefix is stripped.
+	To string `protobuf:"bytes,2,opt,name=to" json:"to,omitempty"`
+}
+
+func (m *PrefixRewrite) Reset()                    { *m = PrefixRewrite{} }
+func (m *PrefixRewrite) String() string            { return proto.CompactTextString(m) }
+func (*PrefixRewrite) ProtoMessage()               {}
+func (*PrefixRewrite) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *PrefixRewrite) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *PrefixRewrite) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type RegexRewrite struct {
+	// / pattern is the RE2 regex matched against the path, e.g. '^/teams/([a-z]+)/api/(.*)$'.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern" json:"pattern,omitempty"`
+	// / replacement is the replacement for the matches of the pattern. Capture groups can be referenced with $1, $2 etc.
+	Replacement string `protobuf:"bytes,2,opt,name=replacement" json:"replacement,omitempty"`
+}
+
+func (m *RegexRewrite) Reset()                    { *m = RegexRewrite{} }
+func (m *RegexRewrite) String() string            { return proto.CompactTextString(m) }
+func (*RegexRewrite) ProtoMessage()               {}
+func (*RegexRewrite) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *RegexRewrite) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *RegexRewrite) GetReplacement() string {
+	if m != nil {
+		return m.Replacement
+	}
+	return ""
+}
+
+// / Mirror configures shadowing of the route's traffic. Copies of requests are sent in a fire-and-forget manner and their
+// / responses are discarded, so mirroring never affects responses sent to the clients.
+// / Requests are not mirrored if there are too many mirrored requests in flight or their body is too big to be buffered.
+type Mirror struct {
+	// / backend_name is the string identifying the HTTP backend pool to send copies of the requests to.
+	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
+	// / percentage of the matched requests that are mirrored, up to 100. If 0, no requests are mirrored.
+	Percentage uint32 `protobuf:"varint,2,opt,name=percentage" json:"percentage,omitempty"`
+}
+
+func (m *Mirror) Reset()                    { *m = Mirror{} }
+func (m *Mirror) String() string            { return proto.CompactTextString(m) }
+func (*Mirror) ProtoMessage()               {}
+func (*Mirror) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Mirror) GetBackendName() string {
+	if m != nil {
+		return m.BackendName
+	}
+	return ""
+}
+
+func (m *Mirror) GetPercentage() uint32 {
+	if m != nil {
+		return m.Percentage
+	}
+	return 0
+}
+
+// / WeightedBackend is a backend that receives a share of the route's traffic.
+type WeightedBackend struct {
+	// / backend_name is the string identifying the HTTP backend pool to send data to.
+	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
+	// / weight is the relative share of the traffic sent to this backend. Backends with 0 weight receive no traffic.
+	Weight uint32 `protobuf:"varint,2,opt,name=weight" json:"weight,omitempty"`
+}
+
+func (m *WeightedBackend) Reset()                    { *m = WeightedBackend{} }
+func (m *WeightedBackend) String() string            { return proto.CompactTextString(m) }
+func (*WeightedBackend) ProtoMessage()               {}
+func (*WeightedBackend) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *WeightedBackend) GetBackendName() string {
+	if m != nil {
+		return m.BackendName
+	}
+	return ""
+}
+
+func (m *WeightedBackend) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+// / StickySplit specifies the part of the request used to make the weighted backend choice sticky.
+type StickySplit struct {
+	// Types that are valid to be assigned to Key:
+	//	*StickySplit_Header
+	//	*StickySplit_Cookie
+	Key isStickySplit_Key `protobuf_oneof:"key"`
+}
+
+func (m *StickySplit) Reset()                    { *m = StickySplit{} }
+func (m *StickySplit) String() string            { return proto.CompactTextString(m) }
+func (*StickySplit) ProtoMessage()               {}
+func (*StickySplit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+type isStickySplit_Key interface {
+	isStickySplit_Key()
+}
+
+type StickySplit_Header struct {
+	Header string `protobuf:"bytes,1,opt,name=header,oneof"`
+}
+type StickySplit_Cookie struct {
+	Cookie string `protobuf:"bytes,2,opt,name=cookie,oneof"`
+}
+
+func (*StickySplit_Header) isStickySplit_Key() {}
+func (*StickySplit_Cookie) isStickySplit_Key() {}
+
+func (m *StickySplit) GetKey() isStickySplit_Key {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *StickySplit) GetHeader() string {
+	if x, ok := m.GetKey().(*StickySplit_Header); ok {
+		return x.Header
+	}
+	return ""
+}
+
+func (m *StickySplit) GetCookie() string {
+	if x, ok := m.GetKey().(*StickySplit_Cookie); ok {
+		return x.Cookie
+	}
+	return ""
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*StickySplit) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _StickySplit_OneofMarshaler, _StickySplit_OneofUnmarshaler, _StickySplit_OneofSizer, []interface{}{
+		(*StickySplit_Header)(nil),
+		(*StickySplit_Cookie)(nil),
+	}
+}
+
+func _StickySplit_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*StickySplit)
+	// key
+	switch x := m.Key.(type) {
+	case *StickySplit_Header:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Header)
+	case *StickySplit_Cookie:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Cookie)
+	case nil:
+	default:
+		return fmt.Errorf("StickySplit.Key has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _StickySplit_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*StickySplit)
+	switch tag {
+	case 1: // key.header
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Key = &StickySplit_Header{x}
+		return true, err
+	case 2: // key.cookie
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Key = &StickySplit_Cookie{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _StickySplit_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*StickySplit)
+	// key
+	switch x := m.Key.(type) {
+	case *StickySplit_Header:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Header)))
+		n += len(x.Header)
+	case *StickySplit_Cookie:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Cookie)))
+		n += len(x.Cookie)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
 func init() {
 	proto.RegisterType((*Route)(nil), "kedge.config.http.routes.Route")
+	proto.RegisterType((*Upgrade)(nil), "kedge.config.http.routes.Upgrade")
+	proto.RegisterType((*HeaderActions)(nil), "kedge.config.http.routes.HeaderActions")
+	proto.RegisterType((*PathRewrite)(nil), "kedge.config.http.routes.PathRewrite")
+	proto.RegisterType((*PrefixRewrite)(nil), "kedge.config.http.routes.PrefixRewrite")
+	proto.RegisterType((*RegexRewrite)(nil), "kedge.config.http.routes.RegexRewrite")
+	proto.RegisterType((*Mirror)(nil), "kedge.config.http.routes.Mirror")
+	proto.RegisterType((*WeightedBackend)(nil), "kedge.config.http.routes.WeightedBackend")
+	proto.RegisterType((*StickySplit)(nil), "kedge.config.http.routes.StickySplit")
 	proto.RegisterEnum("kedge.config.http.routes.ProxyMode", ProxyMode_name, ProxyMode_value)
 }
 
 func init() { proto.RegisterFile("kedge/config/http/routes/routes.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 420 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xdf, 0x6a, 0xd4, 0x40,
-	0x14, 0xc6, 0xcd, 0xc6, 0x6d, 0xcd, 0xd9, 0x6e, 0x59, 0x87, 0x5e, 0x0c, 0x05, 0x31, 0x6a, 0x85,
-	0x20, 0xee, 0x44, 0x56, 0x29, 0x5a, 0x6f, 0x6c, 0x31, 0xe2, 0x4d, 0xff, 0x30, 0x82, 0xba, 0x88,
-	0x86, 0xd9, 0xe4, 0x98, 0x84, 0x6c, 0x32, 0x61, 0x32, 0x69, 0x5d, 0xc5, 0x77, 0xf2, 0x8d, 0x04,
-	0x9f, 0x44, 0x26, 0x49, 0x57, 0x45, 0x7a, 0x35, 0x67, 0x7e, 0xf3, 0x9d, 0x6f, 0xce, 0x37, 0x03,
-	0xf7, 0x73, 0x8c, 0x13, 0xf4, 0x23, 0x59, 0x7e, 0xce, 0x12, 0x3f, 0xd5, 0xba, 0xf2, 0x95, 0x6c,
-	0x34, 0xd6, 0xfd, 0xc2, 0x2a, 0x25, 0xb5, 0x24, 0xb4, 0x95, 0xb1, 0x4e, 0xc6, 0x8c, 0x8c, 0x75,
-	0xe7, 0xbb, 0xfb, 0x49, 0xa6, 0xd3, 0x66, 0xc1, 0x22, 0x59, 0xf8, 0xc5, 0x45, 0xa6, 0x73, 0x79,
-	0xe1, 0x27, 0x72, 0xda, 0xb6, 0x4d, 0xcf, 0xc5, 0x32, 0x8b, 0x85, 0x96, 0xaa, 0xf6, 0xd7, 0x65,
-	0xe7, 0x78, 0xf7, 0x87, 0x0d, 0x43, 0x6e, 0x2c, 0xc8, 0x73, 0xd8, 0x5a, 0x88, 0x28, 0xc7, 0x32,
-	0x0e, 0x4b, 0x51, 0x20, 0xb5, 0x5c, 0xcb, 0x73, 0x8e, 0xe8, 0xaf, 0x9f, 0xb7, 0x77, 0x80, 0x7c,
-	0xfa, 0x20, 0xa6, 0x5f, 0xc3, 0x47, 0xd3, 0x67, 0xec, 0xe3, 0xb7, 0xd9, 0xc3, 0xfd, 0x27, 0xdf,
-	0xf7, 0xf8, 0xa8, 0x57, 0x9f, 0x88, 0x02, 0xc9, 0x2d, 0x80, 0x4a, 0xe8, 0x34, 0x54, 0xcd, 0x12,
-	0x6b, 0x3a, 0x70, 0x6d, 0xcf, 0xe1, 0x8e, 0x21, 0xdc, 0x00, 0x72, 0x07, 0xb6, 0x52, 0x59, 0xeb,
-	0xb0, 0x10, 0x3a, 0x4a, 0x51, 0x51, 0xdb, 0x78, 0xf3, 0x91, 0x61, 0xc7, 0x1d, 0x22, 0x73, 0xd8,
-	0x4e, 0x51, 0xc4, 0xa8, 0xd6, 0xa2, 0xeb, 0xae, 0xed, 0x8d, 0x66, 0x33, 0x76, 0x55, 0x66, 0xd6,
-	0xce, 0xcd, 0x5e, 0xb7, 0x5d, 0xbd, 0x4d, 0x50, 0x6a, 0xb5, 0xe2, 0xe3, 0xf4, 0x6f, 0x46, 0x8e,
-	0x00, 0x2a, 0x25, 0xbf, 0xac, 0xc2, 0x42, 0xc6, 0x48, 0x87, 0xae, 0xe5, 0x6d, 0xcf, 0xee, 0x5d,
-	0x6d, 0x7b, 0x66, 0xb4, 0xc7, 0x32, 0x46, 0xee, 0x54, 0x97, 0xa5, 0x49, 0x50, 0x49, 0xf5, 0x27,
-	0xc1, 0x86, 0x6b, 0x79, 0x63, 0x3e, 0x32, 0xec, 0xf2, 0x9a, 0x3d, 0x18, 0x8b, 0x46, 0xcb, 0x04,
-	0x4b, 0x54, 0x42, 0x63, 0x4c, 0x37, 0x5d, 0xcb, 0xbb, 0xc1, 0xff, 0x85, 0xbb, 0x2f, 0x80, 0xfc,
-	0x3f, 0x31, 0x99, 0x80, 0x9d, 0xe3, 0xaa, 0x7b, 0x73, 0x6e, 0x4a, 0xb2, 0x03, 0xc3, 0x73, 0xb1,
-	0x6c, 0x90, 0x0e, 0x5a, 0xd6, 0x6d, 0x0e, 0x06, 0x4f, 0xad, 0x07, 0x07, 0xe0, 0xac, 0x47, 0x24,
-	0x9b, 0x60, 0x1f, 0x9e, 0xcc, 0x27, 0xd7, 0xc8, 0x4d, 0x18, 0xf3, 0xe0, 0x6d, 0xc0, 0xdf, 0x04,
-	0xe1, 0x19, 0x3f, 0x7d, 0x3f, 0x9f, 0x58, 0x06, 0xbd, 0x3a, 0xe5, 0xef, 0x0e, 0xf9, 0xcb, 0x1e,
-	0x0d, 0x16, 0x1b, 0xed, 0xaf, 0x3f, 0xfe, 0x1d, 0x00, 0x00, 0xff, 0xff, 0xee, 0xa4, 0x0f, 0xfb,
-	0x70, 0x02, 0x00, 0x00,
+	// 1090 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x5b, 0x6f, 0x1b, 0x45,
+	0x14, 0xee, 0xda, 0xb5, 0x1d, 0x9f, 0x8d, 0x2f, 0x99, 0x86, 0x32, 0x0a, 0x82, 0x38, 0x6e, 0x1a,
+	0xdc, 0x8a, 0xac, 0xab, 0x14, 0x45, 0xa1, 0x15, 0xa5, 0xb1, 0x1a, 0xc8, 0x03, 0x69, 0xad, 0x09,
+	0xb4, 0x8d, 0x80, 0xac, 0x36, 0xde, 0x89, 0xbd, 0xb2, 0x77, 0x67, 0x99, 0x1d, 0xe7, 0x02, 0xe2,
+	0x99, 0x1f, 0xc1, 0x03, 0x3f, 0x2d, 0x52, 0x7e, 0x09, 0x9a, 0xcb, 0xfa, 0x12, 0x62, 0x9a, 0x88,
+	0xa7, 0x9d, 0xf9, 0xe6, 0x3b, 0xdf, 0x39, 0xb3, 0xe7, 0xcc, 0x99, 0x81, 0x87, 0x7d, 0xea, 0x77,
+	0x69, 0xb3, 0xc3, 0xa2, 0xe3, 0xa0, 0xdb, 0xec, 0x09, 0x11, 0x37, 0x39, 0x1b, 0x0a, 0x9a, 0x98,
+	0x8f, 0x13, 0x73, 0x26, 0x18, 0xc2, 0x8a, 0xe6, 0x68, 0x9a, 0x23, 0x69, 0x8e, 0x5e, 0x5f, 0xda,
+	0xec, 0x06, 0xa2, 0x37, 0x3c, 0x72, 0x3a, 0x2c, 0x6c, 0x86, 0xa7, 0x81, 0xe8, 0xb3, 0xd3, 0x66,
+	0x97, 0xad, 0x2b, 0xb3, 0xf5, 0x13, 0x6f, 0x10, 0xf8, 0x9e, 0x60, 0x3c, 0x69, 0x8e, 0x86, 0x5a,
+	0x71, 0x69, 0x65, 0xca, 0x71, 0x87, 0x85, 0x21, 0x8b, 0x9a, 0xa1, 0x27, 0x3a, 0x3d, 0x9a, 0x52,
+	0x1e, 0x5c, 0x47, 0xe1, 0x9e, 0xa0, 0x83, 0x20, 0x0c, 0x84, 0x26, 0xd5, 0xff, 0xb4, 0x21, 0x47,
+	0x64, 0x28, 0xe8, 0x05, 0xcc, 0x1f, 0x79, 0x9d, 0x3e, 0x8d, 0x7c, 0x37, 0xf2, 0x42, 0x8a, 0xad,
+	0x9a, 0xd5, 0x28, 0xb6, 0x3e, 0xb9, 0xbc, 0x58, 0xfe, 0x18, 0x3e, 0x3a, 0x6c, 0xfc, 0xe4, 0xad,
+	0xff, 0xe6, 0x3e, 0x59, 0xff, 0xca, 0xf9, 0xe5, 0xf7, 0x8d, 0x2f, 0x36, 0xbf, 0xfc, 0xe3, 0xd1,
+	0x37, 0xab, 0xc4, 0x36, 0x06, 0xaf, 0xbd, 0x90, 0xa2, 0x4f, 0x01, 0x62, 0x4f, 0xf4, 0x5c, 0x3e,
+	0x1c, 0xd0, 0x04, 0x67, 0x6a, 0xd9, 0x46, 0x91, 0x14, 0x25, 0x42, 0x24, 0x80, 0x56, 0x60, 0xbe,
+	0xc7, 0x12, 0xe1, 0x9a, 0x18, 0x71, 0x56, 0xca, 0x13, 0x5b, 0x62, 0x7b, 0x1a, 0x42, 0x07, 0x50,
+	0xee, 0x51, 0xcf, 0xa7, 0x7c, 0x44, 0xba, 0x5b, 0xcb, 0x36, 0xec, 0x8d, 0x0d, 0x67, 0xd6, 0xef,
+	0x73, 0x54, 0xe8, 0xce, 0xae, 0xb2, 0x32, 0x32, 0x3b, 0x91, 0xe0, 0xe7, 0xa4, 0xd4, 0x9b, 0xc4,
+	0x50, 0x0b, 0x20, 0xe6, 0xec, 0xec, 0xdc, 0x0d, 0x99, 0x4f, 0x71, 0xae, 0x66, 0x35, 0xca, 0x1b,
+	0x0f, 0x66, 0xcb, 0xb6, 0x25, 0x77, 0x8f, 0xf9, 0x94, 0x14, 0xe3, 0x74, 0x28, 0x77, 0x10, 0x33,
+	0x3e, 0xde, 0x41, 0xbe, 0x66, 0x35, 0x4a, 0xc4, 0x96, 0x58, 0xea, 0x66, 0x15, 0x4a, 0xde, 0x50,
+	0xb0, 0x2e, 0x8d, 0xa8, 0xfc, 0xd1, 0x3e, 0x2e, 0xd4, 0xac, 0xc6, 0x1c, 0x99, 0x06, 0xd1, 0x5b,
+	0x58, 0x38, 0xa5, 0x41, 0xb7, 0x27, 0xa8, 0xef, 0x9a, 0x3f, 0x98, 0xe0, 0x39, 0xb5, 0xd5, 0x47,
+	0xb3, 0x63, 0x7a, 0x67, 0x4c, 0x5a, 0xda, 0x82, 0x54, 0x4f, 0xa7, 0x81, 0x04, 0xed, 0xc2, 0x7c,
+	0x22, 0x82, 0x4e, 0xff, 0xdc, 0x4d, 0xe2, 0x41, 0x20, 0x70, 0xb1, 0x66, 0x35, 0xec, 0x8d, 0x87,
+	0xb3, 0x25, 0xf7, 0x15, 0x7b, 0x5f, 0x92, 0x89, 0x9d, 0x8c, 0x27, 0x08, 0xc1, 0x5d, 0x55, 0x03,
+	0xa0, 0x92, 0xa4, 0xc6, 0x68, 0x0b, 0xf2, 0x61, 0xc0, 0x39, 0xe3, 0xd8, 0x56, 0xba, 0xb5, 0xd9,
+	0xba, 0x7b, 0x8a, 0x47, 0x0c, 0x5f, 0xc6, 0xa5, 0x2b, 0x83, 0x9e, 0xf2, 0x40, 0x50, 0x3c, 0xff,
+	0xa1, 0xb8, 0xda, 0xb2, 0x6a, 0x34, 0x99, 0xd8, 0xf1, 0x78, 0x32, 0x2a, 0xa2, 0x54, 0xa9, 0x34,
+	0x2e, 0xa2, 0x94, 0xd2, 0x86, 0x0a, 0xa7, 0xbf, 0x0e, 0x69, 0x22, 0x5c, 0x5d, 0x02, 0x09, 0x2e,
+	0x2b, 0x7f, 0x9f, 0xcf, 0xf6, 0xa7, 0xeb, 0x67, 0xbb, 0x23, 0x02, 0x16, 0x25, 0xa4, 0x6c, 0xec,
+	0x35, 0x9a, 0x20, 0x02, 0x55, 0x4e, 0x93, 0x98, 0x45, 0x09, 0x1d, 0x49, 0x56, 0x6e, 0x27, 0x59,
+	0x49, 0x05, 0x52, 0xcd, 0x1d, 0xb3, 0x91, 0xd8, 0x13, 0x82, 0xf2, 0x08, 0x57, 0x95, 0x5e, 0x7d,
+	0x5a, 0x4f, 0x1f, 0x59, 0x67, 0x5f, 0xf0, 0x20, 0xea, 0x9a, 0x12, 0xd3, 0x9b, 0x6d, 0x6b, 0x33,
+	0xf4, 0x1d, 0x94, 0xd4, 0x9f, 0x35, 0x32, 0x09, 0x5e, 0xa8, 0x65, 0x6f, 0xa8, 0xa3, 0x52, 0x62,
+	0x74, 0x12, 0xf4, 0x33, 0x54, 0xcc, 0xd1, 0x1b, 0x49, 0x21, 0x25, 0xf5, 0xf4, 0x66, 0x67, 0x2f,
+	0x15, 0xd2, 0x87, 0xaf, 0xdc, 0x9b, 0x02, 0xd1, 0xd7, 0x00, 0xb2, 0xf2, 0x5d, 0xd5, 0x78, 0xf0,
+	0x3d, 0xb5, 0xd7, 0xcf, 0xae, 0x8d, 0x91, 0x78, 0x82, 0x7e, 0x2f, 0x59, 0xa4, 0xc8, 0xd3, 0x21,
+	0x7a, 0x0e, 0x85, 0x61, 0xdc, 0xe5, 0x9e, 0x4f, 0xf1, 0xa2, 0xb2, 0x5d, 0x99, 0x1d, 0xd4, 0x8f,
+	0x9a, 0x48, 0x52, 0x8b, 0xa5, 0x97, 0x80, 0xfe, 0xdd, 0x1e, 0x50, 0x15, 0xb2, 0x7d, 0x7a, 0xae,
+	0x7b, 0x1c, 0x91, 0x43, 0xb4, 0x08, 0xb9, 0x13, 0x6f, 0x30, 0xa4, 0x38, 0xa3, 0x30, 0x3d, 0x79,
+	0x96, 0xd9, 0xb2, 0x96, 0x28, 0xdc, 0xbb, 0x66, 0x93, 0xd7, 0x48, 0x6c, 0x4d, 0x4a, 0xdc, 0x2c,
+	0x0b, 0x63, 0x37, 0xf5, 0x03, 0x28, 0x98, 0xe0, 0xd1, 0x1a, 0x54, 0x02, 0x7f, 0x40, 0x5d, 0x11,
+	0x84, 0x94, 0x0d, 0x85, 0x1b, 0x26, 0xca, 0x4d, 0x89, 0x94, 0x24, 0xfc, 0x83, 0x46, 0xf7, 0x12,
+	0xc9, 0x0b, 0xbd, 0x33, 0xd7, 0x1f, 0x72, 0x4f, 0xd6, 0x99, 0xe4, 0x65, 0x34, 0x2f, 0xf4, 0xce,
+	0x5e, 0x19, 0x74, 0x2f, 0xa9, 0xff, 0x9d, 0x81, 0xd2, 0x54, 0x41, 0xa2, 0x16, 0x64, 0x3d, 0xdf,
+	0xc7, 0x96, 0xca, 0xf1, 0x93, 0x1b, 0x96, 0xb1, 0xb3, 0xed, 0xfb, 0x3a, 0xc1, 0xd2, 0x58, 0x6a,
+	0x24, 0x54, 0xe0, 0xcc, 0xed, 0x34, 0xf6, 0xa9, 0x30, 0x1a, 0x09, 0x15, 0xe8, 0x3e, 0xe4, 0x39,
+	0x0d, 0xd9, 0x09, 0xc5, 0x59, 0x75, 0x61, 0x98, 0xd9, 0xd2, 0x26, 0xcc, 0xa5, 0xce, 0x6e, 0x95,
+	0xab, 0x4d, 0x98, 0x4b, 0x1d, 0xdc, 0xc6, 0xae, 0xfe, 0x97, 0x05, 0xf6, 0x44, 0xd7, 0x41, 0xdb,
+	0x90, 0x8f, 0x39, 0x3d, 0x0e, 0xce, 0xb0, 0xf5, 0xa1, 0x93, 0xde, 0x56, 0x3c, 0x63, 0xb8, 0x7b,
+	0x87, 0x18, 0x43, 0xf4, 0x02, 0x72, 0x9c, 0x76, 0xe9, 0x99, 0xa9, 0x86, 0xb5, 0xff, 0x38, 0x48,
+	0x92, 0x36, 0x16, 0xd0, 0x66, 0xad, 0x22, 0x14, 0x4c, 0x9b, 0xab, 0xbf, 0x84, 0xd2, 0x94, 0x17,
+	0xb4, 0x0c, 0x77, 0x8f, 0x39, 0x0b, 0xcd, 0x1d, 0x6d, 0x5f, 0x5e, 0x2c, 0x17, 0x20, 0x77, 0xd8,
+	0x74, 0x1e, 0xaf, 0x12, 0xb5, 0x80, 0xca, 0x90, 0x11, 0xcc, 0x6c, 0x33, 0x23, 0x58, 0x9d, 0xc0,
+	0xfc, 0xa4, 0x17, 0x54, 0x83, 0x42, 0xda, 0x7a, 0xb4, 0x46, 0xfe, 0xf2, 0x62, 0x39, 0xf3, 0xde,
+	0x22, 0x29, 0x8c, 0x6a, 0x60, 0x73, 0x1a, 0x0f, 0xbc, 0x0e, 0x0d, 0x69, 0x24, 0x8c, 0xd4, 0x24,
+	0x54, 0x0f, 0x21, 0xaf, 0x1b, 0x3d, 0x7a, 0x7e, 0xed, 0xd3, 0x01, 0x5f, 0x5e, 0x2c, 0x2f, 0x02,
+	0x3a, 0xbc, 0xfa, 0x72, 0xb8, 0xf2, 0x6e, 0x58, 0x03, 0x88, 0x29, 0xef, 0xd0, 0x48, 0x78, 0x5d,
+	0x9d, 0x99, 0x92, 0x8e, 0x06, 0x53, 0x32, 0xb1, 0x52, 0x3f, 0x86, 0xca, 0x95, 0x2b, 0xf0, 0xff,
+	0xf9, 0xbd, 0x0f, 0x79, 0x7d, 0x83, 0x9a, 0x33, 0x63, 0x66, 0xf5, 0x5d, 0xb0, 0x27, 0xee, 0x45,
+	0x84, 0x21, 0xaf, 0xbb, 0x99, 0x56, 0x97, 0x09, 0xd6, 0x73, 0xb9, 0xd2, 0x61, 0xac, 0x1f, 0x98,
+	0x72, 0x92, 0x2b, 0x7a, 0xde, 0xca, 0xa9, 0xca, 0x7b, 0xfc, 0x0c, 0x8a, 0xa3, 0x87, 0x04, 0x2a,
+	0x40, 0x76, 0xfb, 0xf5, 0x41, 0xf5, 0x0e, 0x5a, 0x80, 0x12, 0xd9, 0x79, 0xbb, 0x43, 0xf6, 0x77,
+	0xdc, 0x36, 0x79, 0xf3, 0xfe, 0xa0, 0x6a, 0x49, 0xe8, 0xdb, 0x37, 0xe4, 0xdd, 0x36, 0x79, 0x65,
+	0xa0, 0xcc, 0x51, 0x5e, 0x3d, 0xcf, 0x9e, 0xfe, 0x33, 0x00, 0xea, 0xf9, 0xeb, 0x94, 0x61, 0x0a,
+	0x00, 0x00,
 }
@@ -9,6 +9,14 @@ It is generated from these files:
 
 It has these top-level messages:
 	Route
+	Upgrade
+	HeaderActions
+	PathRewrite
+	PrefixRewrite
+	RegexRewrite
+	Mirror
+	WeightedBackend
+	StickySplit
 */
 package kedge_config_http_routes
 
@@ -18,18 +26,139 @@ import go_proto_validators "github.com/mwitkow/go-proto-validators"
 import proto "github.com/golang/protobuf/proto"
 import math "math"
 import _ "github.com/mwitkow/go-proto-validators"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+import _ "github.com/improbable-eng/kedge/protogen/kedge/config/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-var _regex_Route_BackendName = regexp.MustCompile(`^[a-z_0-9.]{2,64}$`)
+var _regex_Route_BackendName = regexp.MustCompile(`^([a-z_0-9.]{2,64})?$`)
 
 func (this *Route) Validate() error {
 	if !_regex_Route_BackendName.MatchString(this.BackendName) {
-		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^[a-z_0-9.]{2,64}$"`, this.BackendName))
+		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^([a-z_0-9.]{2,64})?$"`, this.BackendName))
+	}
+	// Validation of proto3 map<> fields is unsupported.
+	for _, item := range this.WeightedBackends {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("WeightedBackends", err)
+			}
+		}
+	}
+	if this.StickySplit != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.StickySplit); err != nil {
+			return go_proto_validators.FieldError("StickySplit", err)
+		}
+	}
+	if this.Mirror != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Mirror); err != nil {
+			return go_proto_validators.FieldError("Mirror", err)
+		}
+	}
+	if this.PathRewrite != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.PathRewrite); err != nil {
+			return go_proto_validators.FieldError("PathRewrite", err)
+		}
+	}
+	if this.RequestHeaders != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.RequestHeaders); err != nil {
+			return go_proto_validators.FieldError("RequestHeaders", err)
+		}
+	}
+	if this.ResponseHeaders != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.ResponseHeaders); err != nil {
+			return go_proto_validators.FieldError("ResponseHeaders", err)
+		}
+	}
+	if this.HostPattern != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.HostPattern); err != nil {
+			return go_proto_validators.FieldError("HostPattern", err)
+		}
+	}
+	for _, item := range this.PathPatterns {
+		if item != nil {
+			if err := go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return go_proto_validators.FieldError("PathPatterns", err)
+			}
+		}
 	}
 	// Validation of proto3 map<> fields is unsupported.
+	if this.RateLimit != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.RateLimit); err != nil {
+			return go_proto_validators.FieldError("RateLimit", err)
+		}
+	}
+	if this.Upgrade != nil {
+		if err := go_proto_validators.CallValidatorIfExists(this.Upgrade); err != nil {
+			return go_proto_validators.FieldError("Upgrade", err)
+		}
+	}
+	return nil
+}
+func (this *Upgrade) Validate() error {
+	return nil
+}
+func (this *HeaderActions) Validate() error {
+	// Validation of proto3 map<> fields is unsupported.
+	// Validation of proto3 map<> fields is unsupported.
+	return nil
+}
+func (this *PathRewrite) Validate() error {
+	if oneOfNester, ok := this.GetRewrite().(*PathRewrite_Prefix); ok {
+		if oneOfNester.Prefix != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Prefix); err != nil {
+				return go_proto_validators.FieldError("Prefix", err)
+			}
+		}
+	}
+	if oneOfNester, ok := this.GetRewrite().(*PathRewrite_Regex); ok {
+		if oneOfNester.Regex != nil {
+			if err := go_proto_validators.CallValidatorIfExists(oneOfNester.Regex); err != nil {
+				return go_proto_validators.FieldError("Regex", err)
+			}
+		}
+	}
+	return nil
+}
+
+var _regex_PrefixRewrite_From = regexp.MustCompile(`^/.*$`)
+
+func (this *PrefixRewrite) Validate() error {
+	if !_regex_PrefixRewrite_From.MatchString(this.From) {
+		return go_proto_validators.FieldError("From", fmt.Errorf(`value '%v' must be a string conforming to regex "^/.*$"`, this.From))
+	}
+	return nil
+}
+func (this *RegexRewrite) Validate() error {
+	if this.Pattern == "" {
+		return go_proto_validators.FieldError("Pattern", fmt.Errorf(`value '%v' must not be an empty string`, this.Pattern))
+	}
+	return nil
+}
+
+var _regex_Mirror_BackendName = regexp.MustCompile(`^[a-z_0-9.]{2,64}$`)
+
+func (this *Mirror) Validate() error {
+	if !_regex_Mirror_BackendName.MatchString(this.BackendName) {
+		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^[a-z_0-9.]{2,64}$"`, this.BackendName))
+	}
+	if !(this.Percentage < 101) {
+		return go_proto_validators.FieldError("Percentage", fmt.Errorf(`value '%v' must be less than '101'`, this.Percentage))
+	}
+	return nil
+}
+
+var _regex_WeightedBackend_BackendName = regexp.MustCompile(`^[a-z_0-9.]{2,64}$`)
+
+func (this *WeightedBackend) Validate() error {
+	if !_regex_WeightedBackend_BackendName.MatchString(this.BackendName) {
+		return go_proto_validators.FieldError("BackendName", fmt.Errorf(`value '%v' must be a string conforming to regex "^[a-z_0-9.]{2,64}$"`, this.BackendName))
+	}
+	return nil
+}
+func (this *StickySplit) Validate() error {
 	return nil
 }
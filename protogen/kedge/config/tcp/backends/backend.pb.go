@@ -0,0 +1,232 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/config/tcp/backends/backend.proto
+
+/*
+Package kedge_config_tcp_backends is a generated protocol buffer package.
+
+It is generated from these files:
+	kedge/config/tcp/backends/backend.proto
+
+It has these top-level messages:
+	Backend
+*/
+package kedge_config_tcp_backends
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+import kedge_config_common_resolvers "github.com/improbable-eng/kedge/protogen/kedge/config/common/resolvers"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// / Backend is a set of targets to which raw TCP connections (e.g. TLS passthrough) are forwarded.
+// / Connections are balanced between resolved targets in round robin manner.
+type Backend struct {
+	// / name is the string identifying the backend in all other configs.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// / disable_conntracking turns off the /debug/events tracing and Prometheus monitoring of the connections to this backend.
+	DisableConntracking bool `protobuf:"varint,2,opt,name=disable_conntracking,json=disableConntracking" json:"disable_conntracking,omitempty"`
+	// Types that are valid to be assigned to Resolver:
+	//	*Backend_Srv
+	//	*Backend_K8S
+	//	*Backend_Host
+	Resolver isBackend_Resolver `protobuf_oneof:"resolver"`
+}
+
+func (m *Backend) Reset()                    { *m = Backend{} }
+func (m *Backend) String() string            { return proto.CompactTextString(m) }
+func (*Backend) ProtoMessage()               {}
+func (*Backend) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type isBackend_Resolver interface {
+	isBackend_Resolver()
+}
+
+type Backend_Srv struct {
+	Srv *kedge_config_common_resolvers.SrvResolver `protobuf:"bytes,10,opt,name=srv,oneof"`
+}
+type Backend_K8S struct {
+	K8S *kedge_config_common_resolvers.K8SResolver `protobuf:"bytes,11,opt,name=k8s,oneof"`
+}
+type Backend_Host struct {
+	Host *kedge_config_common_resolvers.HostResolver `protobuf:"bytes,12,opt,name=host,oneof"`
+}
+
+func (*Backend_Srv) isBackend_Resolver()  {}
+func (*Backend_K8S) isBackend_Resolver()  {}
+func (*Backend_Host) isBackend_Resolver() {}
+
+func (m *Backend) GetResolver() isBackend_Resolver {
+	if m != nil {
+		return m.Resolver
+	}
+	return nil
+}
+
+func (m *Backend) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Backend) GetDisableConntracking() bool {
+	if m != nil {
+		return m.DisableConntracking
+	}
+	return false
+}
+
+func (m *Backend) GetSrv() *kedge_config_common_resolvers.SrvResolver {
+	if x, ok := m.GetResolver().(*Backend_Srv); ok {
+		return x.Srv
+	}
+	return nil
+}
+
+func (m *Backend) GetK8S() *kedge_config_common_resolvers.K8SResolver {
+	if x, ok := m.GetResolver().(*Backend_K8S); ok {
+		return x.K8S
+	}
+	return nil
+}
+
+func (m *Backend) GetHost() *kedge_config_common_resolvers.HostResolver {
+	if x, ok := m.GetResolver().(*Backend_Host); ok {
+		return x.Host
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Backend) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Backend_OneofMarshaler, _Backend_OneofUnmarshaler, _Backend_OneofSizer, []interface{}{
+		(*Backend_Srv)(nil),
+		(*Backend_K8S)(nil),
+		(*Backend_Host)(nil),
+	}
+}
+
+func _Backend_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Backend)
+	// resolver
+	switch x := m.Resolver.(type) {
+	case *Backend_Srv:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Srv); err != nil {
+			return err
+		}
+	case *Backend_K8S:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.K8S); err != nil {
+			return err
+		}
+	case *Backend_Host:
+		b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Host); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Backend.Resolver has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Backend_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Backend)
+	switch tag {
+	case 10: // resolver.srv
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_common_resolvers.SrvResolver)
+		err := b.DecodeMessage(msg)
+		m.Resolver = &Backend_Srv{msg}
+		return true, err
+	case 11: // resolver.k8s
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_common_resolvers.K8SResolver)
+		err := b.DecodeMessage(msg)
+		m.Resolver = &Backend_K8S{msg}
+		return true, err
+	case 12: // resolver.host
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(kedge_config_common_resolvers.HostResolver)
+		err := b.DecodeMessage(msg)
+		m.Resolver = &Backend_Host{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Backend_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Backend)
+	// resolver
+	switch x := m.Resolver.(type) {
+	case *Backend_Srv:
+		s := proto.Size(x.Srv)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Backend_K8S:
+		s := proto.Size(x.K8S)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Backend_Host:
+		s := proto.Size(x.Host)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+func init() {
+	proto.RegisterType((*Backend)(nil), "kedge.config.tcp.backends.Backend")
+}
+
+func init() { proto.RegisterFile("kedge/config/tcp/backends/backend.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 306 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0xc1, 0x4e, 0x2a, 0x31,
+	0x14, 0x86, 0xef, 0x70, 0x89, 0x62, 0x71, 0x35, 0xb2, 0xa8, 0x6c, 0x24, 0xc6, 0x44, 0xa2, 0x4c,
+	0xab, 0x68, 0x08, 0x6e, 0x4c, 0xc4, 0x0d, 0x89, 0xbb, 0x71, 0x69, 0x94, 0x74, 0x3a, 0x75, 0x68,
+	0xca, 0xf4, 0x90, 0xb6, 0x0e, 0x89, 0xc6, 0x07, 0xf3, 0x69, 0x4c, 0x7c, 0x12, 0x43, 0x07, 0x04,
+	0x56, 0xba, 0xfb, 0x27, 0xe7, 0xfb, 0xfe, 0x73, 0xa6, 0xe8, 0x58, 0x89, 0x34, 0x13, 0x94, 0x83,
+	0x7e, 0x96, 0x19, 0x75, 0x7c, 0x4a, 0x13, 0xc6, 0x95, 0xd0, 0xa9, 0x5d, 0x06, 0x32, 0x35, 0xe0,
+	0x20, 0xdc, 0xf7, 0x20, 0x29, 0x41, 0xe2, 0xf8, 0x94, 0x2c, 0xc1, 0x66, 0x2f, 0x93, 0x6e, 0xfc,
+	0x92, 0x10, 0x0e, 0x39, 0xcd, 0x67, 0xd2, 0x29, 0x98, 0xd1, 0x0c, 0x22, 0xef, 0x45, 0x05, 0x9b,
+	0xc8, 0x94, 0x39, 0x30, 0x96, 0xfe, 0xc4, 0xb2, 0xb2, 0x19, 0x6d, 0xec, 0xe6, 0x90, 0xe7, 0xa0,
+	0xa9, 0x11, 0x16, 0x26, 0x85, 0x30, 0x76, 0x95, 0x4a, 0xfc, 0xf0, 0xa3, 0x82, 0xb6, 0x07, 0xe5,
+	0xce, 0xb0, 0x83, 0xaa, 0x9a, 0xe5, 0x02, 0x07, 0xad, 0xa0, 0xbd, 0x33, 0xc0, 0x5f, 0x9f, 0x07,
+	0x0d, 0x14, 0x3e, 0x3d, 0xb0, 0xe8, 0x75, 0x74, 0x16, 0x5d, 0x91, 0xc7, 0xb7, 0x6e, 0xa7, 0x77,
+	0xf9, 0x7e, 0x14, 0x7b, 0x2a, 0x3c, 0x47, 0x8d, 0x54, 0x5a, 0x96, 0x4c, 0xc4, 0x88, 0x83, 0xd6,
+	0xce, 0x30, 0xae, 0xa4, 0xce, 0x70, 0xa5, 0x15, 0xb4, 0x6b, 0xf1, 0xde, 0x62, 0x76, 0xbb, 0x36,
+	0x0a, 0xaf, 0xd1, 0x7f, 0x6b, 0x0a, 0x8c, 0x5a, 0x41, 0xbb, 0xde, 0x3d, 0x21, 0x1b, 0x3f, 0x5f,
+	0x5e, 0x4a, 0x56, 0xf7, 0xdd, 0x9b, 0x22, 0x5e, 0x7c, 0x0c, 0xff, 0xc5, 0x73, 0x71, 0xee, 0xab,
+	0xbe, 0xc5, 0xf5, 0x3f, 0xf9, 0x77, 0x7d, 0xbb, 0xee, 0xab, 0xbe, 0x0d, 0x6f, 0x50, 0x75, 0x0c,
+	0xd6, 0xe1, 0x5d, 0x5f, 0x70, 0xfa, 0x4b, 0xc1, 0x10, 0xac, 0x5b, 0x6b, 0xf0, 0xea, 0x00, 0xa1,
+	0xda, 0x92, 0x48, 0xb6, 0xfc, 0x13, 0x5e, 0x7c, 0x0f, 0x00, 0x29, 0x9f, 0xf9, 0xb0, 0xef, 0x01,
+	0x00, 0x00,
+}
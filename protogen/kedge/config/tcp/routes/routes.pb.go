@@ -0,0 +1,94 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: kedge/config/tcp/routes/routes.proto
+
+/*
+Package kedge_config_tcp_routes is a generated protocol buffer package.
+
+It is generated from these files:
+	kedge/config/tcp/routes/routes.proto
+
+It has these top-level messages:
+	Route
+*/
+package kedge_config_tcp_routes
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "github.com/mwitkow/go-proto-validators"
+import kedge_config_common "github.com/improbable-eng/kedge/protogen/kedge/config/common"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// / Route describes a mapping between the TLS server name (SNI) of an inbound connection and a TCP backend.
+// / TLS is not terminated by kedge; the raw connection is forwarded to the backend.
+type Route struct {
+	// / backend_name is the string identifying the TCP backend to forward connections to.
+	BackendName string `protobuf:"bytes,1,opt,name=backend_name,json=backendName" json:"backend_name,omitempty"`
+	// / sni_pattern matches the server name sent by the client in the TLS ClientHello.
+	// / If not present, the route matches all connections (including ones without SNI).
+	SniPattern *kedge_config_common.StringMatcher `protobuf:"bytes,2,opt,name=sni_pattern,json=sniPattern" json:"sni_pattern,omitempty"`
+	// / name is an optional name of the route used in metrics. If not present, the position of the route is used.
+	Name string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *Route) Reset()                    { *m = Route{} }
+func (m *Route) String() string            { return proto.CompactTextString(m) }
+func (*Route) ProtoMessage()               {}
+func (*Route) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Route) GetBackendName() string {
+	if m != nil {
+		return m.BackendName
+	}
+	return ""
+}
+
+func (m *Route) GetSniPattern() *kedge_config_common.StringMatcher {
+	if m != nil {
+		return m.SniPattern
+	}
+	return nil
+}
+
+func (m *Route) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Route)(nil), "kedge.config.tcp.routes.Route")
+}
+
+func init() { proto.RegisterFile("kedge/config/tcp/routes/routes.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 253 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x8f, 0x4f, 0x4b, 0xc3, 0x30,
+	0x18, 0xc6, 0xa9, 0xff, 0xc0, 0xd4, 0x53, 0x10, 0x2c, 0xbb, 0x38, 0xc7, 0x0e, 0x3b, 0xd8, 0x44,
+	0xa6, 0x0c, 0xc4, 0x9b, 0x9e, 0x15, 0xa9, 0x47, 0xd1, 0x92, 0xa6, 0x31, 0x0b, 0x35, 0x79, 0x4b,
+	0xfa, 0xce, 0x81, 0xe2, 0x47, 0xf1, 0xb3, 0x09, 0x7e, 0x12, 0x59, 0x52, 0x06, 0x3d, 0xe5, 0x81,
+	0xfc, 0x9e, 0xfc, 0x9e, 0x90, 0x69, 0xa3, 0x6a, 0xad, 0xb8, 0x04, 0xf7, 0x66, 0x34, 0x47, 0xd9,
+	0x72, 0x0f, 0x2b, 0x54, 0x5d, 0x7f, 0xb0, 0xd6, 0x03, 0x02, 0x3d, 0x09, 0x14, 0x8b, 0x14, 0x43,
+	0xd9, 0xb2, 0x78, 0x3d, 0x5a, 0x68, 0x83, 0xcb, 0x55, 0xc5, 0x24, 0x58, 0x6e, 0xd7, 0x06, 0x1b,
+	0x58, 0x73, 0x0d, 0x79, 0x68, 0xe5, 0x1f, 0xe2, 0xdd, 0xd4, 0x02, 0xc1, 0x77, 0x7c, 0x1b, 0xe3,
+	0x83, 0xa3, 0xb3, 0x81, 0x56, 0x82, 0xb5, 0xe0, 0xb8, 0x15, 0x28, 0x97, 0xaa, 0x47, 0x26, 0x3f,
+	0x09, 0xd9, 0x2f, 0x36, 0x16, 0x7a, 0x43, 0x8e, 0x2a, 0x21, 0x1b, 0xe5, 0xea, 0xd2, 0x09, 0xab,
+	0xb2, 0x64, 0x9c, 0xcc, 0x0e, 0x6f, 0xb3, 0xbf, 0xdf, 0xd3, 0x63, 0x42, 0x5f, 0x9f, 0x45, 0xfe,
+	0x59, 0x5e, 0xe4, 0xd7, 0xec, 0xe5, 0x6b, 0x7e, 0xbe, 0xb8, 0xfa, 0x9e, 0x16, 0x69, 0x4f, 0x3f,
+	0x08, 0xab, 0xe8, 0x1d, 0x49, 0x3b, 0x67, 0xca, 0x56, 0x20, 0x2a, 0xef, 0xb2, 0x9d, 0x71, 0x32,
+	0x4b, 0xe7, 0x13, 0x36, 0xf8, 0x50, 0xf4, 0xb3, 0x27, 0xf4, 0xc6, 0xe9, 0xfb, 0xb8, 0xa2, 0x20,
+	0x9d, 0x33, 0x8f, 0xb1, 0x45, 0x29, 0xd9, 0x0b, 0xe6, 0xdd, 0x8d, 0xb9, 0x08, 0xb9, 0x3a, 0x08,
+	0x33, 0x2f, 0xff, 0x07, 0x00, 0xbc, 0xe2, 0x31, 0xea, 0x42, 0x01, 0x00, 0x00,
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/oklog/run"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/grpc/naming"
 )
 
 var (
@@ -43,30 +42,22 @@ func main() {
 	}
 	defer watcher.Close()
 
-	var (
-		g     run.Group
-		state = map[string]struct{}{}
-	)
+	var g run.Group
 
 	{
 		ctx, cancel := context.WithCancel(context.Background())
 		g.Add(func() error {
 			for ctx.Err() == nil {
-				updates, err := watcher.Next()
+				addrs, err := watcher.Next()
 				if err != nil {
 					return err
 				}
 
 				var msg string
-				for _, up := range updates {
-					if up.Op == naming.Add {
-						state[up.Addr] = struct{}{}
-					} else {
-						delete(state, up.Addr)
-					}
-					msg += fmt.Sprintf("[op: %v, addr: %s]", up.Op, up.Addr)
+				for _, a := range addrs {
+					msg += fmt.Sprintf("[addr: %s, ready: %v]", a.Addr, a.Ready)
 				}
-				fmt.Printf("Got Updates: %s\nOverall state: %v\n", msg, state)
+				fmt.Printf("Overall state: %s\n", msg)
 			}
 
 			return nil